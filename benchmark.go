@@ -0,0 +1,63 @@
+package conv
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// BenchmarkProfile converts openapi b.N times with opts and reports the conversion
+// profile: allocations, throughput of the spec in MB/s and the mean time each Phase took
+// as "<phase>-ns/op", so a slowdown can be traced to parsing, building or rendering. The
+// observer in opts, if any, still receives every event. Use it with the specs of the
+// benchmarks package, or with your own:
+//
+//	func BenchmarkConvert(b *testing.B) {
+//		conv.BenchmarkProfile(b, spec, conv.ConvertOptions{PackageName: "api", PackagePath: "example.com/api"})
+//	}
+//
+// The benchmark fails if a conversion returns an error. Set opts.CacheDir only to measure
+// cached conversions, since every iteration after the first reads the cache.
+func BenchmarkProfile(b *testing.B, openapi []byte, opts ConvertOptions) {
+	b.Helper()
+	profile := &phaseProfile{next: opts.Observer, total: make(map[Phase]time.Duration)}
+	opts.Observer = profile
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(openapi)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Convert(openapi, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	for _, phase := range []Phase{PhaseParse, PhaseBuild, PhaseProto, PhaseGo} {
+		if total, ok := profile.total[phase]; ok {
+			b.ReportMetric(float64(total.Nanoseconds())/float64(b.N), string(phase)+"-ns/op")
+		}
+	}
+}
+
+// phaseProfile is an Observer summing the duration of each phase, forwarding events to next
+type phaseProfile struct {
+	next  Observer
+	mu    sync.Mutex
+	total map[Phase]time.Duration
+}
+
+func (p *phaseProfile) PhaseDone(phase Phase, duration time.Duration) {
+	p.mu.Lock()
+	p.total[phase] += duration
+	p.mu.Unlock()
+	if p.next != nil {
+		p.next.PhaseDone(phase, duration)
+	}
+}
+
+func (p *phaseProfile) ConversionDone(stats ConversionStats, err error) {
+	if p.next != nil {
+		p.next.ConversionDone(stats, err)
+	}
+}
@@ -0,0 +1,135 @@
+package conv_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+)
+
+// BenchmarkConvertSplitOutput converts a spec that produces both large proto and large
+// Go output, where proto and Go generation run concurrently
+func BenchmarkConvertSplitOutput(b *testing.B) {
+	given := splitSpec(300)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result, err := conv.Convert(given, conv.ConvertOptions{
+			PackageName: "testpkg",
+			PackagePath: "github.com/example/proto/v1",
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(result.Protobuf) == 0 || len(result.Golang) == 0 {
+			b.Fatal("expected proto and Go output")
+		}
+	}
+}
+
+// splitSpec builds n plain object schemas, which become proto messages, and n
+// discriminated unions with two variants each, which become Go types
+func splitSpec(n int) []byte {
+	var spec strings.Builder
+	spec.WriteString(`openapi: 3.0.0
+info:
+  title: Split API
+  version: 1.0.0
+components:
+  schemas:
+`)
+	for i := 0; i < n; i++ {
+		spec.WriteString(fmt.Sprintf(`    Record%d:
+      type: object
+      properties:
+        id:
+          type: string
+        count:
+          type: integer
+        detail:
+          type: object
+          properties:
+            note:
+              type: string
+    Pet%d:
+      oneOf:
+        - $ref: '#/components/schemas/Dog%d'
+        - $ref: '#/components/schemas/Cat%d'
+      discriminator:
+        propertyName: petType
+    Dog%d:
+      type: object
+      properties:
+        petType:
+          type: string
+        bark:
+          type: string
+    Cat%d:
+      type: object
+      properties:
+        petType:
+          type: string
+        meow:
+          type: string
+`, i, i, i, i, i, i))
+	}
+	return []byte(spec.String())
+}
+
+// BenchmarkConvertSharedRefs converts a spec where many properties reference the same
+// few schemas, exercising $ref resolution
+func BenchmarkConvertSharedRefs(b *testing.B) {
+	given := sharedRefSpec(200, 20)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := conv.Convert(given, conv.ConvertOptions{
+			PackageName: "testpkg",
+			PackagePath: "github.com/example/proto/v1",
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// sharedRefSpec builds n object schemas with refs properties each, all referencing one
+// of a handful of shared schemas, including a string enum
+func sharedRefSpec(n, refs int) []byte {
+	shared := []string{"Money", "Address", "Currency", "Timestamped"}
+
+	var spec strings.Builder
+	spec.WriteString(`openapi: 3.0.0
+info:
+  title: Shared API
+  version: 1.0.0
+components:
+  schemas:
+    Money:
+      type: object
+      properties:
+        amount:
+          type: integer
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+    Currency:
+      type: string
+      enum: [USD, EUR, GBP]
+    Timestamped:
+      type: object
+      properties:
+        at:
+          type: string
+          format: date-time
+`)
+	for i := 0; i < n; i++ {
+		spec.WriteString(fmt.Sprintf("    Entity%d:\n      type: object\n      properties:\n", i))
+		for j := 0; j < refs; j++ {
+			spec.WriteString(fmt.Sprintf("        field%d:\n          $ref: '#/components/schemas/%s'\n", j, shared[j%len(shared)]))
+		}
+	}
+	return []byte(spec.String())
+}
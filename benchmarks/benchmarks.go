@@ -0,0 +1,250 @@
+// Package benchmarks provides representative OpenAPI specs for measuring conversion
+// throughput, so contributors can catch performance regressions and downstream users
+// can benchmark the converter against the same inputs:
+//
+//	func BenchmarkConvert(b *testing.B) {
+//		for _, spec := range benchmarks.Specs() {
+//			b.Run(spec.Name, func(b *testing.B) {
+//				conv.BenchmarkProfile(b, spec.OpenAPI, conv.ConvertOptions{
+//					PackageName: "benchpkg",
+//					PackagePath: "github.com/example/proto/v1",
+//				})
+//			})
+//		}
+//	}
+//
+// Every spec repeats one domain model, holding objects with scalar, date-time, array, map
+// and inline object properties, string and integer enums, a discriminated union generated
+// as Go, and CRUD operations referencing them. The specs differ only in how often the
+// model is repeated, and are generated identically on every call.
+package benchmarks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spec is a named OpenAPI document to benchmark
+type Spec struct {
+	// Name is "small", "medium" or "huge"
+	Name string
+	// OpenAPI is the YAML document
+	OpenAPI []byte
+}
+
+// Domain counts of each spec. Every domain adds ten component schemas and two paths.
+const (
+	smallDomains  = 5
+	mediumDomains = 50
+	hugeDomains   = 500
+)
+
+// Small returns a spec with 50 component schemas, the size of a single service
+func Small() []byte {
+	return generate(smallDomains)
+}
+
+// Medium returns a spec with 500 component schemas, the size of a product API
+func Medium() []byte {
+	return generate(mediumDomains)
+}
+
+// Huge returns a spec with 5000 component schemas, the size of a cloud provider API
+func Huge() []byte {
+	return generate(hugeDomains)
+}
+
+// Specs returns the small, medium and huge specs, in that order
+func Specs() []Spec {
+	return []Spec{
+		{Name: "small", OpenAPI: Small()},
+		{Name: "medium", OpenAPI: Medium()},
+		{Name: "huge", OpenAPI: Huge()},
+	}
+}
+
+// generate builds a spec repeating the domain model domains times, with the domain
+// index as the suffix of its schema names, operation ids and paths
+func generate(domains int) []byte {
+	var paths, schemas strings.Builder
+	for i := 0; i < domains; i++ {
+		paths.WriteString(fmt.Sprintf(pathsTemplate, i))
+		schemas.WriteString(fmt.Sprintf(schemasTemplate, i))
+	}
+
+	var spec strings.Builder
+	spec.WriteString(header)
+	spec.WriteString("paths:\n")
+	spec.WriteString(paths.String())
+	spec.WriteString("components:\n  schemas:\n")
+	spec.WriteString(schemas.String())
+	return []byte(spec.String())
+}
+
+const header = `openapi: 3.0.3
+info:
+  title: Benchmark API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+`
+
+const pathsTemplate = `  /orders%[1]d:
+    get:
+      operationId: listOrders%[1]d
+      parameters:
+        - name: pageSize
+          in: query
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: A page of orders
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/OrderPage%[1]d'
+    post:
+      operationId: createOrder%[1]d
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order%[1]d'
+      responses:
+        '201':
+          description: The created order
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order%[1]d'
+  /orders%[1]d/{id}:
+    get:
+      operationId: getOrder%[1]d
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        '200':
+          description: The order
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order%[1]d'
+        default:
+          description: An error
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Problem%[1]d'
+`
+
+const schemasTemplate = `    Order%[1]d:
+      description: An order placed by a customer
+      type: object
+      required: [id, status]
+      properties:
+        id:
+          type: string
+          description: Unique identifier of the order
+          example: ord-123
+        status:
+          $ref: '#/components/schemas/OrderStatus%[1]d'
+        priority:
+          $ref: '#/components/schemas/Priority%[1]d'
+        total:
+          type: number
+          format: double
+          minimum: 0
+        quantity:
+          type: integer
+          format: int32
+        placedAt:
+          type: string
+          format: date-time
+        express:
+          type: boolean
+        lines:
+          type: array
+          items:
+            $ref: '#/components/schemas/OrderLine%[1]d'
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+        shipping:
+          type: object
+          properties:
+            street:
+              type: string
+            city:
+              type: string
+            postalCode:
+              type: string
+    OrderLine%[1]d:
+      type: object
+      properties:
+        sku:
+          type: string
+          pattern: '^[A-Z0-9-]+$'
+        quantity:
+          type: integer
+          format: int64
+        unitPrice:
+          type: number
+          format: float
+    OrderPage%[1]d:
+      type: object
+      properties:
+        orders:
+          type: array
+          items:
+            $ref: '#/components/schemas/Order%[1]d'
+        nextPageToken:
+          type: string
+    OrderStatus%[1]d:
+      type: string
+      enum: [pending, paid, shipped, cancelled]
+    Priority%[1]d:
+      type: integer
+      enum: [1, 2, 3]
+    Invoice%[1]d:
+      type: object
+      properties:
+        orderId:
+          type: string
+        payment:
+          $ref: '#/components/schemas/Payment%[1]d'
+    Payment%[1]d:
+      oneOf:
+        - $ref: '#/components/schemas/CardPayment%[1]d'
+        - $ref: '#/components/schemas/BankPayment%[1]d'
+      discriminator:
+        propertyName: method
+    CardPayment%[1]d:
+      type: object
+      properties:
+        method:
+          type: string
+        last4:
+          type: string
+          maxLength: 4
+    BankPayment%[1]d:
+      type: object
+      properties:
+        method:
+          type: string
+        iban:
+          type: string
+    Problem%[1]d:
+      type: object
+      properties:
+        code:
+          type: integer
+        message:
+          type: string
+`
@@ -0,0 +1,80 @@
+package benchmarks_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/duh-rpc/openapi-proto.go/benchmarks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func BenchmarkConvert(b *testing.B) {
+	for _, spec := range benchmarks.Specs() {
+		b.Run(spec.Name, func(b *testing.B) {
+			conv.BenchmarkProfile(b, spec.OpenAPI, conv.ConvertOptions{
+				PackageName: "benchpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+		})
+	}
+}
+
+// TestAllocationBudget fails when a conversion allocates well beyond what it did when
+// the budget was last recorded. Raise a budget only when the extra work is intended.
+func TestAllocationBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("converts the medium spec")
+	}
+
+	for _, test := range []struct {
+		name   string
+		spec   []byte
+		budget float64
+	}{
+		{name: "small", spec: benchmarks.Small(), budget: 120_000},
+		{name: "medium", spec: benchmarks.Medium(), budget: 1_200_000},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(2, func() {
+				_, err := conv.Convert(test.spec, conv.ConvertOptions{
+					PackageName: "benchpkg",
+					PackagePath: "github.com/example/proto/v1",
+				})
+				require.NoError(t, err)
+			})
+			assert.LessOrEqual(t, allocs, test.budget)
+		})
+	}
+}
+
+func TestSpecs(t *testing.T) {
+	var names []string
+	for _, spec := range benchmarks.Specs() {
+		names = append(names, spec.Name)
+	}
+	assert.Equal(t, []string{"small", "medium", "huge"}, names)
+
+	for _, test := range []struct {
+		name   string
+		spec   []byte
+		types  int
+		routes int
+	}{
+		{name: "small", spec: benchmarks.Small(), types: 50, routes: 15},
+		{name: "medium", spec: benchmarks.Medium(), types: 500, routes: 150},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert(test.spec, conv.ConvertOptions{
+				PackageName: "benchpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			require.NoError(t, err)
+			assert.Len(t, result.TypeMap, test.types)
+			assert.Len(t, result.Routes, test.routes)
+			assert.NotEmpty(t, result.Protobuf)
+			assert.NotEmpty(t, result.Golang)
+			assert.Empty(t, result.Warnings)
+		})
+	}
+}
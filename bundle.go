@@ -0,0 +1,190 @@
+package conv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// BundleFormat is the archive format written by ConvertResult.Bundle
+type BundleFormat string
+
+const (
+	// BundleZip writes a zip archive
+	BundleZip BundleFormat = "zip"
+	// BundleTarGzip writes a gzip compressed tar archive
+	BundleTarGzip BundleFormat = "tar.gz"
+)
+
+// Manifest describes the files in a bundle, stored in it as manifest.json
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes one file in a bundle
+type ManifestFile struct {
+	// Path is the path of the file within the archive, e.g. "proto/api.proto"
+	Path string `json:"path"`
+	// Kind is "proto", "go", "lock", "report" or an ArtifactKind
+	Kind string `json:"kind"`
+	// Size is the length of the file in bytes
+	Size int `json:"size"`
+	// SHA256 is the hex encoded SHA-256 of the file
+	SHA256 string `json:"sha256"`
+}
+
+// Report summarizes a conversion, stored in a bundle as report.json
+type Report struct {
+	Warnings []string `json:"warnings"`
+}
+
+// Bundle writes every output of the result to w as a single archive, for build services
+// that hand generated artifacts to other jobs. The archive contains proto/ and go/
+// directories with the generated files, examples/ and fixtures/ when emitted, routes.json
+// when the spec has operations, lock.json from Lock when there is proto output,
+// report.json with the warnings and finally manifest.json listing every other file with
+// its size and hash. Archives are reproducible: identical results produce identical bytes.
+func (r *ConvertResult) Bundle(w io.Writer, format BundleFormat) error {
+	var archive archiveWriter
+	switch format {
+	case BundleZip:
+		archive = &zipArchive{writer: zip.NewWriter(w)}
+	case BundleTarGzip:
+		gz := gzip.NewWriter(w)
+		archive = &tarArchive{gzip: gz, writer: tar.NewWriter(gz)}
+	default:
+		return fmt.Errorf("unknown bundle format: %s", format)
+	}
+
+	sink := &bundleSink{archive: archive}
+	if err := r.Write(sink); err != nil {
+		return err
+	}
+
+	if len(r.Protobuf) > 0 {
+		if err := sink.writeJSON("lock.json", "lock", r.Lock()); err != nil {
+			return err
+		}
+	}
+	warnings := r.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	if err := sink.writeJSON("report.json", "report", Report{Warnings: warnings}); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(sink.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := archive.add("manifest.json", data); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return archive.close()
+}
+
+// archiveWriter adds files to an archive
+type archiveWriter interface {
+	add(path string, data []byte) error
+	close() error
+}
+
+// bundleSink writes outputs into an archive, recording each in the manifest
+type bundleSink struct {
+	archive  archiveWriter
+	manifest Manifest
+}
+
+func (s *bundleSink) WriteProtoFile(name string, data []byte) error {
+	return s.add("proto/"+name, "proto", data)
+}
+
+func (s *bundleSink) WriteGoFile(name string, data []byte) error {
+	return s.add("go/"+name, "go", data)
+}
+
+func (s *bundleSink) WriteArtifact(kind ArtifactKind, name string, data []byte) error {
+	switch kind {
+	case ArtifactExample:
+		return s.add("examples/"+name, string(kind), data)
+	case ArtifactFixture:
+		return s.add("fixtures/"+name, string(kind), data)
+	case ArtifactRoutes:
+		return s.add(name, string(kind), data)
+	}
+	return s.add(string(kind)+"/"+name, string(kind), data)
+}
+
+// writeJSON adds value encoded as indented JSON
+func (s *bundleSink) writeJSON(path, kind string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.add(path, kind, append(data, '\n'))
+}
+
+func (s *bundleSink) add(path, kind string, data []byte) error {
+	if err := s.archive.add(path, data); err != nil {
+		return err
+	}
+	s.manifest.Files = append(s.manifest.Files, ManifestFile{
+		Path:   path,
+		Kind:   kind,
+		Size:   len(data),
+		SHA256: fmt.Sprintf("%x", sha256.Sum256(data)),
+	})
+	return nil
+}
+
+// zipArchive writes files to a zip archive
+type zipArchive struct {
+	writer *zip.Writer
+}
+
+func (a *zipArchive) add(path string, data []byte) error {
+	f, err := a.writer.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func (a *zipArchive) close() error {
+	return a.writer.Close()
+}
+
+// tarArchive writes files to a gzip compressed tar archive
+type tarArchive struct {
+	gzip   *gzip.Writer
+	writer *tar.Writer
+}
+
+func (a *tarArchive) add(path string, data []byte) error {
+	header := &tar.Header{
+		Name:    path,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Unix(0, 0),
+		Format:  tar.FormatPAX,
+	}
+	if err := a.writer.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := a.writer.Write(data)
+	return err
+}
+
+func (a *tarArchive) close() error {
+	if err := a.writer.Close(); err != nil {
+		return err
+	}
+	return a.gzip.Close()
+}
@@ -0,0 +1,122 @@
+package conv_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertResultBundle(t *testing.T) {
+	result, err := conv.Convert([]byte(outputSpec), conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		GoPackagePath: "github.com/example/types/v1",
+		EmitExamples:  true,
+	})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name   string
+		format conv.BundleFormat
+		read   func(t *testing.T, data []byte) map[string][]byte
+	}{
+		{
+			name:   "zip",
+			format: conv.BundleZip,
+			read:   readZip,
+		},
+		{
+			name:   "tar.gz",
+			format: conv.BundleTarGzip,
+			read:   readTarGzip,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, result.Bundle(&buf, test.format))
+
+			files := test.read(t, buf.Bytes())
+			assert.Equal(t, result.Protobuf, files["proto/testpkg.proto"])
+			assert.Equal(t, result.Golang, files["go/types.go"])
+			assert.Contains(t, files, "examples/User.json")
+			assert.JSONEq(t, `{"warnings": []}`, string(files["report.json"]))
+
+			var lock conv.Lock
+			require.NoError(t, json.Unmarshal(files["lock.json"], &lock))
+			assert.Equal(t, result.Lock(), &lock)
+
+			var manifest conv.Manifest
+			require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+			var paths []string
+			for _, file := range manifest.Files {
+				paths = append(paths, file.Path)
+				assert.Equal(t, len(files[file.Path]), file.Size)
+			}
+			assert.Equal(t, []string{
+				"proto/testpkg.proto",
+				"go/types.go",
+				"examples/Order.json",
+				"examples/User.json",
+				"lock.json",
+				"report.json",
+			}, paths)
+			assert.Equal(t, "proto", manifest.Files[0].Kind)
+
+			// Bundles are reproducible
+			var again bytes.Buffer
+			require.NoError(t, result.Bundle(&again, test.format))
+			assert.Equal(t, buf.Bytes(), again.Bytes())
+		})
+	}
+}
+
+func TestConvertResultBundleUnknownFormat(t *testing.T) {
+	result, err := conv.Convert([]byte(outputSpec), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	err = result.Bundle(io.Discard, "rar")
+	require.ErrorContains(t, err, "unknown bundle format: rar")
+}
+
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+	}
+	return files
+}
+
+func readTarGzip(t *testing.T, data []byte) map[string][]byte {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	reader := tar.NewReader(gz)
+
+	files := make(map[string][]byte)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		files[header.Name], err = io.ReadAll(reader)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,211 @@
+package conv
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/duh-rpc/openapi-proto.go/internal"
+)
+
+// cacheFormat changes whenever the layout of cached results changes, so entries written
+// by an older layout are never decoded
+const cacheFormat = "3"
+
+// cachedResult is a ConvertResult as stored in the cache, including the package names
+// used by Write. Definitions are stored separately since an interface cannot be decoded.
+// JSON keeps nil and empty slices apart, so a cached result is deeply equal to the
+// conversion it was stored from.
+type cachedResult struct {
+	Result        *ConvertResult
+	Definitions   []cachedDefinition
+	PackageName   string
+	PackagePath   string
+	GoPackageName string
+	GoPackagePath string
+	FileOptions   []internal.FileOption
+}
+
+// cachedDefinition holds one element of ConvertResult.Definitions
+type cachedDefinition struct {
+	Message *ProtoMessage `json:",omitempty"`
+	Enum    *ProtoEnum    `json:",omitempty"`
+}
+
+// cacheKey returns the hex encoded hash of the spec, the options and the build of the
+// converter, or an empty key when the build is unknown and results cannot be cached
+func cacheKey(openapi []byte, opts ConvertOptions) (string, error) {
+	version := buildVersion()
+	if version == "" {
+		return "", nil
+	}
+
+	options, err := json.Marshal(opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash options: %w", err)
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%d\x00", cacheFormat, version, len(openapi))
+	hash.Write(openapi)
+	hash.Write(options)
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// executableHash is computed once, the running executable does not change
+var executableHash = sync.OnceValue(func() string {
+	name, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("exe %x", hash.Sum(nil))
+})
+
+// buildVersion identifies the converter code that produces cached results: the module
+// version when it is a released or checksummed version, otherwise a hash of the running
+// executable, so entries written by a working tree go stale once its code changes. It
+// returns an empty string when neither is known.
+func buildVersion() string {
+	if version := moduleVersion(); version != "" {
+		return version
+	}
+	return executableHash()
+}
+
+// moduleVersion returns the version and checksum of this module as recorded in the
+// build, or an empty string when it is built from a working tree, a modified checkout
+// or a local replacement, whose code the version does not identify
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	path := reflect.TypeOf(ConvertResult{}).PkgPath()
+	if info.Main.Path == path {
+		if info.Main.Version == "(devel)" || strings.HasSuffix(info.Main.Version, "+dirty") {
+			return ""
+		}
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != path {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		if dep.Version == "" || dep.Sum == "" {
+			return ""
+		}
+		return dep.Version + " " + dep.Sum
+	}
+	return ""
+}
+
+// loadCached returns the result stored under key. Missing or unreadable entries are
+// a miss, so a damaged entry is replaced by the next conversion.
+func loadCached(dir, key string) (*ConvertResult, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cachedResult
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Result == nil {
+		return nil, false
+	}
+	result := cached.Result
+	if cached.Definitions != nil {
+		result.Definitions = make([]ProtoDefinition, 0, len(cached.Definitions))
+		for _, def := range cached.Definitions {
+			switch {
+			case def.Message != nil:
+				result.Definitions = append(result.Definitions, def.Message)
+			case def.Enum != nil:
+				result.Definitions = append(result.Definitions, def.Enum)
+			}
+		}
+	}
+	result.packageName = cached.PackageName
+	result.packagePath = cached.PackagePath
+	result.goPackageName = cached.GoPackageName
+	result.goPackagePath = cached.GoPackagePath
+	result.fileOptions = cached.FileOptions
+	return result, true
+}
+
+// storeCached writes result under key. The entry is written to a temporary file and
+// renamed into place so concurrent conversions never read a partial entry.
+func storeCached(dir, key string, result *ConvertResult) error {
+	stored := *result
+	stored.Definitions = nil
+	cached := cachedResult{
+		Result:        &stored,
+		PackageName:   result.packageName,
+		PackagePath:   result.packagePath,
+		GoPackageName: result.goPackageName,
+		GoPackagePath: result.goPackagePath,
+		FileOptions:   result.fileOptions,
+	}
+	if result.Definitions != nil {
+		cached.Definitions = make([]cachedDefinition, 0, len(result.Definitions))
+		for _, def := range result.Definitions {
+			switch d := def.(type) {
+			case *ProtoMessage:
+				cached.Definitions = append(cached.Definitions, cachedDefinition{Message: d})
+			case *ProtoEnum:
+				cached.Definitions = append(cached.Definitions, cachedDefinition{Enum: d})
+			}
+		}
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(dir, key+".json"), data)
+}
+
+// writeFileAtomic writes data to a temporary file next to name and renames it into
+// place, so readers never see a partial file. The directory is created if needed.
+func writeFileAtomic(name string, data []byte) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
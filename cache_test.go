@@ -0,0 +1,135 @@
+package conv_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cacheSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+    Status:
+      type: integer
+      enum: [1, 2]
+`
+
+func cacheEntries(t *testing.T, dir string) []string {
+	entries, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	return entries
+}
+
+func TestConvertCache(t *testing.T) {
+	dir := t.TempDir()
+	opts := conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		CacheDir:    dir,
+	}
+
+	first, err := conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	require.Len(t, cacheEntries(t, dir), 1)
+
+	second, err := conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	assert.Equal(t, first.Protobuf, second.Protobuf)
+	assert.Equal(t, first.Definitions, second.Definitions)
+	assert.Equal(t, first.TypeMap, second.TypeMap)
+	assert.Equal(t, first.Lock(), second.Lock())
+	require.Len(t, cacheEntries(t, dir), 1)
+
+	opts.DedupErrors = true
+	_, err = conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	assert.Len(t, cacheEntries(t, dir), 2)
+}
+
+func TestConvertCacheHitSkipsConversion(t *testing.T) {
+	dir := t.TempDir()
+	opts := conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		CacheDir:    dir,
+	}
+	_, err := conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	entry := cacheEntries(t, dir)[0]
+
+	// Store the result of another spec under the key of cacheSpec
+	other := t.TempDir()
+	opts.CacheDir = other
+	expected, err := conv.Convert([]byte(cacheSpec+"    Order:\n      type: object\n"), opts)
+	require.NoError(t, err)
+	data, err := os.ReadFile(cacheEntries(t, other)[0])
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(entry, data, 0o644))
+
+	opts.CacheDir = dir
+	actual, err := conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Protobuf, actual.Protobuf)
+}
+
+func TestConvertCacheDamagedEntry(t *testing.T) {
+	dir := t.TempDir()
+	opts := conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		CacheDir:    dir,
+	}
+	expected, err := conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	entry := cacheEntries(t, dir)[0]
+	require.NoError(t, os.WriteFile(entry, []byte("damaged"), 0o644))
+
+	actual, err := conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Protobuf, actual.Protobuf)
+	assert.Empty(t, actual.Warnings)
+}
+
+func TestConvertCacheErrorNotStored(t *testing.T) {
+	dir := t.TempDir()
+	_, err := conv.Convert([]byte("not: [valid"), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		CacheDir:    dir,
+	})
+	require.Error(t, err)
+	assert.Empty(t, cacheEntries(t, dir))
+}
+
+func TestConvertCacheValidatesOptions(t *testing.T) {
+	modFile := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(modFile, []byte("module github.com/example/service\n"), 0o644))
+	opts := conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		GoPackagePath: "github.com/example/service/gen/api",
+		GoModFile:     modFile,
+		CacheDir:      t.TempDir(),
+	}
+	_, err := conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	require.Len(t, cacheEntries(t, opts.CacheDir), 1)
+
+	// The options are unchanged, but no longer valid for the module
+	require.NoError(t, os.WriteFile(modFile, []byte("module github.com/example/other\n"), 0o644))
+	_, err = conv.Convert([]byte(cacheSpec), opts)
+	require.ErrorContains(t, err, "is outside module 'github.com/example/other'")
+}
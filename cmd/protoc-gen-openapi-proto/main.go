@@ -0,0 +1,40 @@
+// Command protoc-gen-openapi-proto is a protoc and buf plugin converting the OpenAPI spec
+// anchored by each proto file to generate. See package plugin for how specs are found
+// and the parameters it accepts.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/duh-rpc/openapi-proto.go/plugin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func main() {
+	if err := run(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "protoc-gen-openapi-proto: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in io.Reader, out io.Writer) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+
+	var req pluginpb.CodeGeneratorRequest
+	if err := proto.Unmarshal(data, &req); err != nil {
+		return fmt.Errorf("failed to parse request: %w", err)
+	}
+
+	data, err = proto.Marshal(plugin.Generate(&req))
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	_, err = out.Write(data)
+	return err
+}
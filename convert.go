@@ -1,10 +1,17 @@
 package conv
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/duh-rpc/openapi-proto.go/internal"
 	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+	"golang.org/x/sync/errgroup"
 )
 
 // ConvertResult contains the outputs from converting OpenAPI to proto3 and Go code.
@@ -22,12 +29,87 @@ type ConvertResult struct {
 	Protobuf []byte
 	Golang   []byte
 	TypeMap  map[string]*TypeInfo
+	// Servers lists the spec's servers in declaration order so generated clients
+	// can default their base URL from the spec
+	Servers []Server
+	// Callbacks lists the callbacks of every operation in declaration order, also
+	// rendered as a commented out service at the end of Protobuf
+	Callbacks []Callback
+	// Routes lists every operation of the spec in declaration order with the types of
+	// its request and response, for configuring HTTP routers and API gateways. Write
+	// emits it as routes.json.
+	Routes []Route
+	// Examples maps proto message names to sample protobuf JSON documents built from
+	// the OpenAPI example/examples values. Only populated when ConvertOptions.EmitExamples is set.
+	Examples map[string][]byte
+	// Definitions holds the enums and messages rendered into Protobuf, in output order,
+	// so callers can do custom rendering or analysis without re-parsing the proto text
+	Definitions []ProtoDefinition
+	// Fixtures maps proto message names to populated protobuf text format instances built
+	// from example values, minimum/maximum boundaries and enum members. Only populated
+	// when ConvertOptions.EmitFixtures is set.
+	Fixtures map[string][]byte
+	// Pagination maps proto message names to the pagination convention they follow,
+	// detected from page/pageSize or cursor/nextPageToken shaped fields
+	Pagination map[string]PaginationStyle
+	// Warnings describes adjustments made during conversion that callers should review
+	Warnings []string
+
+	packageName   string
+	packagePath   string
+	goPackageName string
+	goPackagePath string
+	fileOptions   []internal.FileOption
+}
+
+// PaginationStyle identifies the pagination convention a message follows
+type PaginationStyle string
+
+const (
+	// PaginationPage is page number pagination, e.g. page and pageSize fields
+	PaginationPage PaginationStyle = "page"
+	// PaginationCursor is token pagination, e.g. cursor, pageToken or nextPageToken fields
+	PaginationCursor PaginationStyle = "cursor"
+)
+
+// Server describes an entry from the OpenAPI servers list
+type Server struct {
+	// URL is the server URL as written in the spec, including any {variable} placeholders
+	URL string
+	// BaseURL is URL with every variable replaced by its default value
+	BaseURL     string
+	Description string
+}
+
+// Callback describes a request an operation sends back to the client, from the
+// operation's OpenAPI callbacks
+type Callback struct {
+	// Operation is the operationId of the operation, or its method and path, e.g.
+	// "POST /subscriptions", when it has none
+	Operation string
+	// Name is the callback name, e.g. "onUserCreated"
+	Name string
+	// Expression is the runtime expression of the callback URL, e.g.
+	// "{$request.body#/callbackUrl}"
+	Expression string
+	// Method is the HTTP method of the callback request in upper case
+	Method string
+	// Request is the schema of the callback request body, empty if it has none. An inline
+	// object body is converted as a schema named after the callback's rpc, e.g.
+	// "SubscribeOnEventRequest", which ProtoTypeFor resolves to its message.
+	Request string
 }
 
 // TypeInfo contains metadata about where a type is generated and why
 type TypeInfo struct {
 	Location TypeLocation
 	Reason   string
+	// AliasOf names the shared proto message this schema was collapsed into by
+	// DedupErrors, the name derived from its title by TitleNames,
+	// google.protobuf.Empty with EmptyMessagesWellKnown, or the qualified definition an
+	// alias skipped by SchemaAliases references. Empty unless the schema's own message
+	// was removed or renamed.
+	AliasOf string
 }
 
 // TypeLocation indicates whether a type is generated as proto or golang
@@ -46,8 +128,443 @@ type ConvertOptions struct {
 	PackagePath string
 	// GoPackagePath is the path for generated Go code (defaults to PackagePath if empty)
 	GoPackagePath string
+	// GoModFile is the path of the go.mod file of the module the generated Go code is
+	// compiled in. When set, validation fails unless GoPackagePath (or PackagePath) is
+	// inside the module it declares, so the generated package can be imported.
+	GoModFile string `json:"-"`
+	// PreserveUnknownEnums makes generated Go enum types keep unrecognized values when
+	// decoding JSON instead of returning an error, and write them back unchanged when
+	// encoding. Both string and integer enums store the raw value. Integer values beyond
+	// int32, or equal to the number of a declared proto constant, which would read back as
+	// that constant, fail to decode. IsKnown reports whether a value was declared.
+	PreserveUnknownEnums bool
+	// GoConstructors generates a NewX function for each Go struct that returns it with the
+	// schema defaults of its fields applied, slices non-nil and nested structs initialized,
+	// so handlers do not have to guard against nil. Unions are left to the caller.
+	GoConstructors bool
+	// GoCloneEqual generates Clone and Equal methods for each Go struct, mirroring
+	// proto.Clone and proto.Equal. Proto messages referenced from Go structs are copied
+	// and compared with those functions, so mixed object graphs are handled uniformly.
+	GoCloneEqual bool
+	// GoUnionVisitors generates a visitor interface for each union with a method per
+	// variant, e.g. PetVisitor with VisitDog and VisitCat, and a Visit method calling the
+	// one for the variant that is set. Handling a union through a visitor makes the
+	// compiler report code that misses a variant added to the spec.
+	GoUnionVisitors bool
+	// GoOptional declares a generic Optional[T] type in the Go output and uses it for
+	// nullable scalar properties, so null can be told apart from the zero value. JSON null
+	// and absent properties decode to an Optional that is not Valid.
+	GoOptional bool
+	// GoKubernetes prepares Go structs for embedding in Kubernetes custom resources: fields
+	// get yaml tags next to their json tags, and each struct gets DeepCopyInto and DeepCopy
+	// methods in the form deepcopy-gen generates, as controller-gen expects of nested types
+	GoKubernetes bool
+	// EmitExamples populates ConvertResult.Examples with a sample protobuf JSON document for
+	// each proto message that has an example, usable as test fixtures for the generated types
+	EmitExamples bool
+	// ExampleOption emits the example of each property as a custom string field option,
+	// e.g. [(api.example) = "jane@example.com"], so doc generators working from the proto
+	// can show it. Disabled unless Name is set.
+	ExampleOption ExampleOption
+	// EmitFixtures populates ConvertResult.Fixtures with a populated protobuf text format
+	// instance of each proto message, for seeding table-driven tests
+	EmitFixtures bool
+	// NormalizePagination renames fields of detected pagination shapes to the AIP-158
+	// names page_size, page_token and next_page_token. json_name keeps the original
+	// name so the JSON wire format is unchanged. Each rename is reported in Warnings.
+	NormalizePagination bool
+	// DedupErrors collapses structurally equivalent error schemas (names containing
+	// "Error") into one shared proto message, named Error when that name is free.
+	// Each collapsed schema records the shared name in TypeInfo.AliasOf.
+	DedupErrors bool
+	// TitleNames names the message of a schema after its title when the schema key is
+	// unusable as a message name, because it starts with a digit (e.g. "200_response") or
+	// is generic (e.g. "Object" or "Data"). Each renamed schema records the new name in
+	// TypeInfo.AliasOf.
+	TitleNames bool
+	// Observer receives the duration of each phase and statistics of every call to
+	// Convert, so services embedding the converter can export conversion metrics
+	Observer Observer `json:"-"`
+	// Deterministic runs the conversion twice and returns an error if the two results
+	// differ. Output is always byte-identical for identical input and options; this mode
+	// exists to assert that guarantee in tests.
+	Deterministic bool
+	// FieldOrder controls how fields are rendered within each proto message. Field numbers
+	// are unaffected. Defaults to FieldOrderSpec.
+	FieldOrder FieldOrder
+	// InlineObjects controls whether inline object properties become nested or top-level
+	// messages. Defaults to InlineObjectsNested.
+	InlineObjects InlineObjects
+	// InlineEnums controls whether inline integer enums are declared at file scope or
+	// inside the message that uses them. Defaults to InlineEnumsFileScope.
+	InlineEnums InlineEnums
+	// EmptyMessages controls how schemas without properties are emitted. Defaults to
+	// EmptyMessagesKeep.
+	EmptyMessages EmptyMessages
+	// OneOfStrategy controls how schemas with a top-level oneOf are generated. Defaults
+	// to OneOfStrategyGo.
+	OneOfStrategy OneOfStrategy
+	// NullableFields controls how nullable properties, declared with nullable: true or a
+	// "null" type, are converted. Defaults to NullableFieldsIgnore.
+	NullableFields NullableFields
+	// OptionalUnlessRequired declares scalar and enum fields whose property is not listed
+	// in the schema's required array with the proto3 optional label, so generated code
+	// can tell an unset field from one set to its zero value. Message, repeated and map
+	// fields are unchanged.
+	OptionalUnlessRequired bool
+	// UnsignedFromMinimum maps integer properties declaring a minimum of 0 or more to
+	// uint32, or uint64 with format int64, since they cannot hold negative values. Set
+	// x-proto-unsigned: false on a property to keep it signed.
+	UnsignedFromMinimum bool
+	// AnyOfStrategy controls how properties using anyOf are converted. By default they
+	// fail the conversion with ErrorCodeUnsupportedAnyOf.
+	AnyOfStrategy AnyOfStrategy
+	// SchemaAliases controls how top-level schemas that are only a $ref to another
+	// component schema are converted. Defaults to SchemaAliasesCopy.
+	SchemaAliases SchemaAliases
+	// Format controls the layout of the generated proto file so it can match
+	// hand-written files in the same repository
+	Format FormatOptions
+	// Descriptions controls how schema descriptions are carried into proto comments
+	// and Go doc comments
+	Descriptions DescriptionOptions
+	// LowMemory drops paths, webhooks and components other than schemas before building
+	// the OpenAPI model, reducing peak memory on large specs. Schemas must not reference
+	// the dropped sections, and line numbers in parse errors refer to the pruned document.
+	LowMemory bool
+	// OperationsOnly generates only the schemas operations use as parameters, request
+	// bodies or responses, plus the schemas they reference, ignoring other component
+	// schemas. For specs used purely to define an RPC surface. Cannot be combined with
+	// LowMemory, which drops the paths.
+	OperationsOnly bool
+	// OperationMessages generates a message for each inline object schema of a request
+	// body or first 2xx response, named after the operation's RPC with a Request or
+	// Response suffix, e.g. CreateUserRequest. Specs declaring their payloads under paths
+	// rather than components then produce a complete proto, and Routes name these
+	// messages. A name already taken by a component schema gets a numeric suffix, as in
+	// CreateUserRequest2. Cannot be combined with LowMemory, which drops the paths.
+	OperationMessages bool
+	// BodyStrategies maps request body media types, e.g. "multipart/form-data", to how
+	// OperationMessages generates their request messages. JSON, multipart/form-data and
+	// application/x-www-form-urlencoded bodies default to BodyStrategyFields, other media
+	// types such as application/octet-stream to BodyStrategyBytes. The JSON media type of
+	// a body listing several is used. Requires OperationMessages.
+	BodyStrategies map[string]BodyStrategy
+	// FlattenAllOf merges the members of each allOf, inline objects or $refs to object
+	// schemas, into one message instead of rejecting it. Fields are numbered in member
+	// order, followed by the properties declared next to the allOf, and the required
+	// lists are combined. A property declared differently by two members is an
+	// ErrorCodeUnsupportedAllOf error. An allOf of one $ref with no properties next to it
+	// keeps referencing that schema.
+	FlattenAllOf bool
+	// Limits bounds the work done for a spec so services converting untrusted or
+	// pathological specs cannot be made to exhaust memory. The zero value sets no limits.
+	Limits Limits
+	// ResourceReferences controls google.api.resource_reference annotations on fields
+	// that reference other resources
+	ResourceReferences ResourceReferenceOptions
+	// FormatError, when set, rewrites the message of every returned *Error, for example to
+	// localize it for end users. Code and the wrapped Err are left unchanged, so
+	// errors.As and errors.Is behave the same with or without it.
+	FormatError func(err *Error) string `json:"-"`
+	// Lock is the result of ConvertResult.Lock from a previous conversion. Fields it
+	// records that are no longer generated have their numbers and names reserved in
+	// their message, so later fields cannot reuse them. A removed number already taken
+	// by another field cannot be reserved and is reported in Warnings.
+	Lock *Lock
+	// ImportRewrites replaces the paths of emitted import statements to match an
+	// organization's proto layout, e.g. "google/api/field_behavior.proto" to
+	// "third_party/google/api/field_behavior.proto". A key ending in "*" matches every
+	// path with that prefix, and a trailing "*" in its value stands for the rest of the
+	// path, so "google/api/*" to "vendor/google/api/*" moves a whole directory. Exact
+	// keys take precedence over prefixes, and longer prefixes over shorter ones.
+	ImportRewrites map[string]string
+	// ImportKinds emits the listed imports as `import public` or `import weak`, keyed by
+	// the path after ImportRewrites. Public imports let files importing the generated
+	// file use the imported definitions too.
+	ImportKinds map[string]ImportKind
+	// NoteDropped adds a "// NOTE: dropped <construct> from <pointer>" comment to each
+	// proto message and field whose schema uses a construct the output silently ignores:
+	// formats that do not change the proto type, such as uuid, and additionalProperties.
+	// The notes are also listed in ProtoMessage.Notes and ProtoField.Notes.
+	NoteDropped bool
+	// ProtoHeader and GoHeader are text/template templates rendered at the top of the
+	// proto and Go output, e.g. a license banner or the comments a linter requires.
+	// They are executed with a HeaderData holding the info metadata of the spec, and
+	// every line they render must be a // comment. The Go header comes before the
+	// Provenance header.
+	ProtoHeader string
+	GoHeader    string
+	// Provenance stamps the Go output with where it was generated from, for
+	// regeneration commands and drift detection
+	Provenance ProvenanceOptions
+	// CacheDir, when set, stores each successful result in this directory keyed by a hash
+	// of the spec, the options and the version of this module, or a hash of the running
+	// executable when the module has no released version, so converting an unchanged
+	// spec again returns the stored result without converting. The directory is created
+	// if needed. Failing to store a result, or not knowing the build, adds a warning
+	// instead of failing the conversion.
+	CacheDir string `json:"-"`
+	// BaseDir enables $refs into other files, such as
+	// './common.yaml#/components/schemas/Address', resolving relative paths against this
+	// directory. Each referenced schema is copied into the components of the spec under
+	// the last segment of the reference, or the file name when the whole file is the
+	// schema, together with the schemas it references in turn. A copied name clashing with
+	// another schema is an error. Without BaseDir, file references fail to resolve.
+	// Referenced files must lie inside RootDir, so absolute paths, '../' and symlinks
+	// cannot reach other files on the host.
+	BaseDir string `json:"-"`
+	// RootDir is the directory every file reference must resolve inside, for specs whose
+	// files sit next to the directory of the spec, e.g. '../common/money.yaml'. Must
+	// contain BaseDir. Defaults to BaseDir.
+	RootDir string `json:"-"`
+	// RemoteRefs enables $refs to URLs on the hosts it allows, resolved like file
+	// references. Relative references inside a fetched document resolve against its URL.
+	RemoteRefs RemoteRefOptions `json:"-"`
+}
+
+// ProvenanceOptions controls the header of the Go output. The zero value emits no header.
+type ProvenanceOptions struct {
+	// Source names the spec file, e.g. "api/openapi.yaml". When set, the Go output starts
+	// with a "// Code generated by openapi-proto. DO NOT EDIT." line followed by
+	// "// source: <Source>@sha256:<hash>", where hash is the SHA-256 of the openapi input.
+	Source string
+	// GoGenerate lists commands emitted as //go:generate directives after the header,
+	// e.g. "go run ./cmd/gen -spec api/openapi.yaml"
+	GoGenerate []string
+}
+
+// ImportKind is the modifier of an import statement
+type ImportKind string
+
+const (
+	// ImportPublic re-exports the imported file to files importing the generated one
+	ImportPublic ImportKind = "public"
+	// ImportWeak allows the imported file to be absent at runtime
+	ImportWeak ImportKind = "weak"
+)
+
+// Limits bounds the size of the input and output of a conversion. Zero values mean no limit.
+type Limits struct {
+	// MaxSpecBytes rejects specs larger than this many bytes before parsing
+	MaxSpecBytes int
+	// MaxDepth limits how deeply inline objects (including array items) may nest
+	// below a top-level schema
+	MaxDepth int
+	// MaxMessages limits the total number of proto messages, including nested ones
+	MaxMessages int
+}
+
+// ResourceReferenceOptions controls which string fields are annotated with
+// (google.api.resource_reference). Properties with an x-proto-resource-ref extension
+// are always annotated; the zero value annotates nothing else.
+type ResourceReferenceOptions struct {
+	// Detect treats string properties named after another schema plus an Id suffix,
+	// such as userId next to a User schema, as references to that schema. Reference
+	// fields are renamed without the suffix (user) and keep their json_name.
+	Detect bool
+	// Service prefixes resource types given as a schema name, e.g. "library.example.com"
+	// yields "library.example.com/User". Defaults to PackageName.
+	Service string
+}
+
+// DescriptionOptions controls how schema descriptions appear in generated comments.
+// The zero value copies descriptions unchanged into both outputs.
+type DescriptionOptions struct {
+	// MaxLength truncates descriptions longer than this many characters at a word
+	// boundary and appends "...". Zero means no limit.
+	MaxLength int
+	// StripMarkdown removes markdown formatting such as headings, emphasis, inline code,
+	// links and code fences, keeping the text
+	StripMarkdown bool
+	// OmitFromProto leaves descriptions out of the proto output
+	OmitFromProto bool
+	// OmitFromGo leaves descriptions out of the Go doc comments
+	OmitFromGo bool
+	// Require checks that every converted schema and every property not using a $ref
+	// has a description, for organizations that require documented protos. Inline
+	// objects and array items are checked below their property.
+	Require RequireDescriptions
+	// Exempt lists the schemas Require skips, along with their properties
+	Exempt []string
+}
+
+// RequireDescriptions controls what DescriptionOptions.Require does about missing
+// descriptions
+type RequireDescriptions string
+
+const (
+	// RequireDescriptionsWarn adds a warning listing each schema and property without
+	// a description, e.g. "missing description: User.email"
+	RequireDescriptionsWarn RequireDescriptions = "warn"
+	// RequireDescriptionsError fails the conversion with ErrorCodeMissingDescription,
+	// listing every schema and property without a description
+	RequireDescriptionsError RequireDescriptions = "error"
+)
+
+// FormatOptions controls the layout of the generated proto file. The zero value
+// produces the default layout.
+type FormatOptions struct {
+	// IndentWidth is the number of spaces per nesting level. Defaults to 2.
+	IndentWidth int
+	// BlankLineBetweenFields separates consecutive fields (and their comments) with an empty line
+	BlankLineBetweenFields bool
+	// SingleTrailingNewline ends the file with exactly one newline instead of a trailing blank line
+	SingleTrailingNewline bool
+	// MaxCommentWidth wraps description comments at word boundaries so lines, including
+	// indentation, do not exceed this many characters. Zero disables wrapping.
+	MaxCommentWidth int
+	// BufFormat post-processes the output into the canonical style enforced by
+	// `buf format --diff`: two space indentation, no blank lines at the start or end of
+	// a block, empty bodies collapsed to {} and a single trailing newline. When set,
+	// IndentWidth and SingleTrailingNewline are ignored.
+	BufFormat bool
+	// AnchorComments wraps each top-level message and enum, including its leading
+	// comments, in "// openapi-proto:begin message User" and
+	// "// openapi-proto:end message User" lines, so tooling can merge hand-written
+	// additions kept outside the markers back into regenerated files
+	AnchorComments bool
+}
+
+// ExampleOption names the custom field option property examples are emitted as.
+// The option must be a string extension of google.protobuf.FieldOptions, e.g.
+//
+//	extend google.protobuf.FieldOptions { string example = 50001; }
+type ExampleOption struct {
+	// Name is the full name of the extension, e.g. "api.example"
+	Name string
+	// Import is the proto file declaring the extension, e.g. "api/annotations.proto",
+	// imported by the generated file when any field carries the option
+	Import string
 }
 
+// FieldOrder controls the order fields are rendered within a proto message
+type FieldOrder string
+
+const (
+	// FieldOrderSpec renders fields in the order the properties appear in the spec
+	FieldOrderSpec FieldOrder = "spec"
+	// FieldOrderByNumber renders fields sorted by field number
+	FieldOrderByNumber FieldOrder = "number"
+	// FieldOrderAlphabetical renders fields sorted by field name
+	FieldOrderAlphabetical FieldOrder = "alphabetical"
+)
+
+// InlineObjects controls where messages for inline object properties are declared
+type InlineObjects string
+
+const (
+	// InlineObjectsNested declares them inside their parent message, e.g. User.Profile
+	InlineObjectsNested InlineObjects = "nested"
+	// InlineObjectsHoisted declares them as top-level messages named after the parent
+	// and property, e.g. UserProfile
+	InlineObjectsHoisted InlineObjects = "hoisted"
+)
+
+// InlineEnums controls where enums for inline integer enum properties are declared
+type InlineEnums string
+
+const (
+	// InlineEnumsFileScope declares them at file scope, e.g. Status
+	InlineEnumsFileScope InlineEnums = "file"
+	// InlineEnumsNested declares them inside the message with the property, e.g. User.Status
+	InlineEnumsNested InlineEnums = "nested"
+)
+
+// EmptyMessages controls how messages for schemas without properties are emitted
+type EmptyMessages string
+
+const (
+	// EmptyMessagesKeep emits them as empty messages, e.g. message Ping {}
+	EmptyMessagesKeep EmptyMessages = "keep"
+	// EmptyMessagesOmit drops the ones no field references. Referenced ones are kept so
+	// the output still compiles.
+	EmptyMessagesOmit EmptyMessages = "omit"
+	// EmptyMessagesWellKnown drops them all, referencing google.protobuf.Empty instead
+	EmptyMessagesWellKnown EmptyMessages = "well-known"
+	// EmptyMessagesTODO emits them with a TODO comment so they are filled in or removed
+	EmptyMessagesTODO EmptyMessages = "todo"
+)
+
+// OneOfStrategy controls how schemas with a top-level oneOf are generated
+type OneOfStrategy string
+
+const (
+	// OneOfStrategyGo generates them, their variants and every schema referencing them as
+	// Go structs with custom JSON marshaling, preserving the discriminated JSON shape
+	OneOfStrategyGo OneOfStrategy = "go"
+	// OneOfStrategyProtoOneof generates them as proto messages holding a oneof with one
+	// field per variant, e.g. message Pet { oneof pet { Dog dog = 1; Cat cat = 2; } }.
+	// Everything stays in the proto output, but the protobuf JSON form wraps the variant
+	// in its field, e.g. {"dog": {...}}, rather than the flat discriminated document.
+	OneOfStrategyProtoOneof OneOfStrategy = "proto-oneof"
+)
+
+// BodyStrategy controls how OperationMessages generates the request message of a body
+type BodyStrategy string
+
+const (
+	// BodyStrategyFields declares a field for each property of an inline object body, or
+	// each part of a multipart body. Other bodies get no request message.
+	BodyStrategyFields BodyStrategy = "fields"
+	// BodyStrategyBytes declares a single bytes field named body holding the raw request
+	// body, for any body schema, including $refs
+	BodyStrategyBytes BodyStrategy = "bytes"
+)
+
+// NullableFields controls how nullable properties are converted
+type NullableFields string
+
+const (
+	// NullableFieldsIgnore converts them like other properties, so null and the zero value
+	// cannot be told apart in proto
+	NullableFieldsIgnore NullableFields = "ignore"
+	// NullableFieldsOptional declares nullable scalar and enum fields with the proto3
+	// optional label, so presence survives the conversion. Message fields already track
+	// presence and repeated and map fields cannot be optional, so they are unchanged.
+	NullableFieldsOptional NullableFields = "optional"
+	// NullableFieldsWrappers declares nullable scalar fields with the well-known wrapper
+	// messages, e.g. google.protobuf.StringValue for a string, importing
+	// google/protobuf/wrappers.proto. Enums have no wrapper and are unchanged, as are
+	// message, repeated and map fields.
+	NullableFieldsWrappers NullableFields = "wrappers"
+)
+
+// AnyOfStrategy controls how properties using anyOf are converted. Schemas with a
+// top-level anyOf are rejected with every strategy.
+type AnyOfStrategy string
+
+const (
+	// AnyOfStrategyWidest collapses members to the widest scalar type accepting all of
+	// them: integers widen to int64 or double, booleans and numbers mixed with anything
+	// else become string. Each collapse is reported in Warnings. Members that only allow
+	// null are skipped and make the property nullable, see NullableFields. Other members
+	// that are not scalars fail the conversion.
+	AnyOfStrategyWidest AnyOfStrategy = "widest"
+	// AnyOfStrategyAny maps the property to google.protobuf.Any, and to any in Go structs,
+	// leaving the caller to pack and unpack the value. Its protobuf JSON is an object with
+	// an @type field rather than the plain value, so each such property is reported in
+	// Warnings.
+	AnyOfStrategyAny AnyOfStrategy = "any"
+)
+
+// SchemaAliases controls how aliases, top-level schemas such as
+// Customer: {$ref: '#/components/schemas/User'}, are converted. Aliases of oneOf schemas
+// are generated as Go structs and always copied.
+type SchemaAliases string
+
+const (
+	// SchemaAliasesCopy generates a definition for the alias identical to the one of the
+	// schema it references
+	SchemaAliasesCopy SchemaAliases = "copy"
+	// SchemaAliasesSkip generates no definition for the alias. References to it use the
+	// referenced definition and TypeInfo.AliasOf names it.
+	SchemaAliasesSkip SchemaAliases = "skip"
+	// SchemaAliasesComment skips aliases like SchemaAliasesSkip and lists them in the
+	// comment of the referenced definition, e.g. // Aliases: Customer.
+	SchemaAliasesComment SchemaAliases = "comment"
+)
+
 // Convert converts OpenAPI 3.x schemas (3.0, 3.1, 3.2) to Protocol Buffer 3 format.
 // It takes OpenAPI specification bytes (YAML or JSON) and conversion options,
 // and returns a ConvertResult containing proto3 output, Go output, and type metadata.
@@ -62,91 +579,705 @@ type ConvertOptions struct {
 //   - status-code → status_code [json_name = "status-code"]
 //
 // The function validates inputs, parses the OpenAPI document, extracts schemas,
-// and generates corresponding proto3 message definitions.
+// and generates corresponding proto3 message definitions. Once schemas are classified,
+// proto and Go output are generated concurrently.
 //
 // Returns an error if:
 //   - openapi is empty
-//   - opts.PackageName is empty
-//   - opts.PackagePath is empty
+//   - opts.Validate reports problems, returned together as an *OptionsError
+//   - openapi is larger than opts.Limits.MaxSpecBytes
 //   - the OpenAPI document is invalid or not version 3.x
 //   - any schema contains unsupported features
+//   - inline objects nest deeper than opts.Limits.MaxDepth or more than
+//     opts.Limits.MaxMessages messages are generated
+//   - opts.Deterministic is set and two runs produce different results
+//
+// Every returned error is an *Error whose Code classifies the failure.
 func Convert(openapi []byte, opts ConvertOptions) (*ConvertResult, error) {
+	return convertParsed(openapi, opts, nil)
+}
+
+// parsedHook receives the schemas of the parsed document before they are built, so a
+// caller can inspect the spec without parsing it again
+type parsedHook func(schemas []*parser.SchemaEntry)
+
+// convertParsed is Convert, calling parsed, if not nil, once the document is parsed. A
+// result loaded from the cache is not parsed, so parsed is not called for it.
+func convertParsed(openapi []byte, opts ConvertOptions, parsed parsedHook) (*ConvertResult, error) {
+	start := time.Now()
+	var result *ConvertResult
+	var cached bool
+	err := recoverPanic(nil, func() (err error) {
+		result, cached, err = convertCached(openapi, opts, parsed)
+		return err
+	})
+	observeConversion(opts, start, result, cached, err)
+	return result, err
+}
+
+// convertCached converts openapi, reusing the result stored in opts.CacheDir if there is
+// one, and reports whether it did
+func convertCached(openapi []byte, opts ConvertOptions, parsed parsedHook) (*ConvertResult, bool, error) {
+	if opts.CacheDir == "" {
+		result, err := convertChecked(openapi, opts, parsed)
+		return result, false, err
+	}
+
+	// Invalid options must fail even when an earlier conversion stored a result for them
+	if err := opts.Validate(); err != nil {
+		return nil, false, formatError(withErrorCode(err), opts)
+	}
+
+	// Resolve file references first, so the cache key covers the referenced files
+	openapi, err := resolveExternalRefs(openapi, opts)
+	if err != nil {
+		return nil, false, formatError(err, opts)
+	}
+
+	key, err := cacheKey(openapi, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	if key == "" {
+		result, err := convertChecked(openapi, opts, parsed)
+		if err != nil {
+			return nil, false, err
+		}
+		result.Warnings = append(result.Warnings, "conversion cache skipped: the converter build is unknown")
+		return result, false, nil
+	}
+	if result, ok := loadCached(opts.CacheDir, key); ok {
+		return result, true, nil
+	}
+
+	result, err := convertChecked(openapi, opts, parsed)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := storeCached(opts.CacheDir, key, result); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("conversion cache not updated: %v", err))
+	}
+	return result, false, nil
+}
+
+// convertChecked converts openapi, a second time with Deterministic to compare the results
+func convertChecked(openapi []byte, opts ConvertOptions, parsed parsedHook) (*ConvertResult, error) {
+	result, err := convertSplit(openapi, opts, nil, parsed)
+	if err != nil {
+		return nil, formatError(withErrorCode(err), opts)
+	}
+	if !opts.Deterministic {
+		return result, nil
+	}
+
+	again, err := convertSplit(openapi, opts, nil, nil)
+	if err != nil {
+		return nil, formatError(withErrorCode(err), opts)
+	}
+
+	if err := compareResults(result, again); err != nil {
+		return nil, formatError(&Error{Code: ErrorCodeNondeterministic, Err: err}, opts)
+	}
+
+	return result, nil
+}
+
+// compareResults returns an error naming the first field that differs between two results
+func compareResults(a, b *ConvertResult) error {
+	switch {
+	case !bytes.Equal(a.Protobuf, b.Protobuf):
+		return fmt.Errorf("nondeterministic output: Protobuf differs between runs")
+	case !bytes.Equal(a.Golang, b.Golang):
+		return fmt.Errorf("nondeterministic output: Golang differs between runs")
+	case !reflect.DeepEqual(a.TypeMap, b.TypeMap):
+		return fmt.Errorf("nondeterministic output: TypeMap differs between runs")
+	case !reflect.DeepEqual(a.Servers, b.Servers):
+		return fmt.Errorf("nondeterministic output: Servers differs between runs")
+	case !reflect.DeepEqual(a.Callbacks, b.Callbacks):
+		return fmt.Errorf("nondeterministic output: Callbacks differs between runs")
+	case !reflect.DeepEqual(a.Routes, b.Routes):
+		return fmt.Errorf("nondeterministic output: Routes differs between runs")
+	case !reflect.DeepEqual(a.Examples, b.Examples):
+		return fmt.Errorf("nondeterministic output: Examples differs between runs")
+	case !reflect.DeepEqual(a.Definitions, b.Definitions):
+		return fmt.Errorf("nondeterministic output: Definitions differs between runs")
+	case !reflect.DeepEqual(a.Fixtures, b.Fixtures):
+		return fmt.Errorf("nondeterministic output: Fixtures differs between runs")
+	case !reflect.DeepEqual(a.Pagination, b.Pagination):
+		return fmt.Errorf("nondeterministic output: Pagination differs between runs")
+	case !reflect.DeepEqual(a.Warnings, b.Warnings):
+		return fmt.Errorf("nondeterministic output: Warnings differs between runs")
+	}
+	return nil
+}
+
+// definitionSplit removes top-level definitions from a conversion, used by
+// ConvertVersions to move definitions shared by every version into a common package
+type definitionSplit struct {
+	// names lists the proto names of the definitions to remove
+	names map[string]bool
+	// qualifier is the proto package now declaring the removed definitions, or empty if
+	// nothing references them
+	qualifier string
+	// importPath is the proto file declaring the removed definitions
+	importPath string
+}
+
+// convertSplit converts openapi, leaving out the definitions named by split if not nil,
+// and calls parsed, if not nil, with the parsed schemas
+func convertSplit(openapi []byte, opts ConvertOptions, split *definitionSplit, parsed parsedHook) (result *ConvertResult, err error) {
+	err = recoverPanic(nil, func() (err error) {
+		result, err = convertStages(openapi, opts, split, parsed)
+		return err
+	})
+	return result, err
+}
+
+// convertStages runs the parse, build and generation stages of convertSplit
+func convertStages(openapi []byte, opts ConvertOptions, split *definitionSplit, parsed parsedHook) (*ConvertResult, error) {
+	start := time.Now()
 	if len(openapi) == 0 {
-		return nil, fmt.Errorf("openapi input cannot be empty")
+		return nil, &Error{Code: ErrorCodeInvalidInput, Err: fmt.Errorf("openapi input cannot be empty")}
 	}
 
-	if opts.PackageName == "" {
-		return nil, fmt.Errorf("package name cannot be empty")
+	if err := opts.Validate(); err != nil {
+		return nil, err
 	}
 
-	if opts.PackagePath == "" {
-		return nil, fmt.Errorf("package path cannot be empty")
+	if opts.Limits.MaxSpecBytes > 0 && len(openapi) > opts.Limits.MaxSpecBytes {
+		return nil, &Error{Code: ErrorCodeLimitExceeded,
+			Err: fmt.Errorf("spec size %d bytes exceeds limit of %d", len(openapi), opts.Limits.MaxSpecBytes)}
 	}
 
+	openapi, err := resolveExternalRefs(openapi, opts)
+	if err != nil {
+		return nil, err
+	}
 	// Default GoPackagePath to PackagePath if not provided
 	if opts.GoPackagePath == "" {
 		opts.GoPackagePath = opts.PackagePath
 	}
 
+	// Hash the input as given, with referenced files included, before FlattenAllOf and
+	// LowMemory rewrite it
+	header := goHeader(opts.Provenance, openapi)
+	specHash := fmt.Sprintf("%x", sha256.Sum256(openapi))
+
+	if opts.FlattenAllOf {
+		openapi, err = parser.FlattenAllOf(openapi)
+		if err != nil {
+			return nil, &Error{Code: ErrorCodeUnsupportedAllOf, Err: err}
+		}
+	}
+
+	var warnings []string
+	if opts.LowMemory {
+		pruned, patched, err := parser.PruneDocument(openapi)
+		if err != nil {
+			return nil, &Error{Code: ErrorCodeParse, Err: err}
+		}
+		openapi = pruned
+		if patched {
+			warnings = append(warnings, "FieldMask helpers skipped for PATCH request bodies: low memory drops paths")
+		}
+	}
+
 	doc, err := parser.ParseDocument(openapi)
 	if err != nil {
-		return nil, err
+		return nil, &Error{Code: ErrorCodeParse, Err: err}
 	}
 
 	schemas, err := doc.Schemas()
 	if err != nil {
 		return nil, err
 	}
+	if parsed != nil {
+		parsed(schemas)
+	}
+	observePhase(opts, PhaseParse, start)
 
-	ctx := internal.NewContext()
-	graph, err := internal.BuildMessages(schemas, ctx)
+	info := doc.Info()
+	headerData := HeaderData{
+		Title:       info.Title,
+		Version:     info.Version,
+		Description: info.Description,
+		Source:      opts.Provenance.Source,
+		SHA256:      specHash,
+		Package:     internal.ExtractPackageName(opts.GoPackagePath),
+	}
+	custom, err := renderHeader("go header", opts.GoHeader, headerData)
+	if err != nil {
+		return nil, &Error{Code: ErrorCodeInvalidOptions, Err: err}
+	}
+	header = custom + header
+	headerData.Package = opts.PackageName
+	protoHeader, err := renderHeader("proto header", opts.ProtoHeader, headerData)
+	if err != nil {
+		return nil, &Error{Code: ErrorCodeInvalidOptions, Err: err}
+	}
+
+	fileOptions, err := internal.BuildFileOptions(info.Extensions)
 	if err != nil {
 		return nil, err
 	}
 
+	routes := doc.Routes()
+	if opts.OperationsOnly {
+		schemas = filterOperationSchemas(schemas, doc.OperationSchemas())
+	}
+	if opts.OperationMessages {
+		schemas = append(schemas, operationMessages(routes, schemas, opts.BodyStrategies)...)
+	}
+	callbacks := doc.Callbacks()
+	schemas = append(schemas, callbackMessages(callbacks, schemas)...)
+
+	if opts.Descriptions.Require != "" {
+		exempt := make(map[string]bool, len(opts.Descriptions.Exempt))
+		for _, name := range opts.Descriptions.Exempt {
+			exempt[name] = true
+		}
+		missing := internal.MissingDescriptions(schemas, exempt)
+		switch {
+		case len(missing) == 0:
+		case opts.Descriptions.Require == RequireDescriptionsError:
+			return nil, &Error{Code: ErrorCodeMissingDescription,
+				Err: fmt.Errorf("missing descriptions: %s", strings.Join(missing, ", "))}
+		default:
+			for _, path := range missing {
+				warnings = append(warnings, "missing description: "+path)
+			}
+		}
+	}
+
+	servers := doc.Servers()
+	patchTypes := doc.PatchSchemas()
+
+	start = time.Now()
+	ctx := internal.NewContext()
+	ctx.Callbacks = callbacks
+	ctx.FileOptions = fileOptions
+	ctx.Limits = internal.Limits{
+		MaxDepth:    opts.Limits.MaxDepth,
+		MaxMessages: opts.Limits.MaxMessages,
+	}
+	ctx.ProtoOneof = opts.OneOfStrategy == OneOfStrategyProtoOneof
+	ctx.ExampleOption = opts.ExampleOption.Name
+	ctx.ExampleImport = opts.ExampleOption.Import
+	ctx.AnyOf = internal.AnyOfStrategy(opts.AnyOfStrategy)
+	ctx.NullableOptional = opts.NullableFields == NullableFieldsOptional
+	ctx.NullableWrappers = opts.NullableFields == NullableFieldsWrappers
+	ctx.OptionalUnlessRequired = opts.OptionalUnlessRequired
+	ctx.UnsignedFromMinimum = opts.UnsignedFromMinimum
+	ctx.SkipAliases = opts.SchemaAliases == SchemaAliasesSkip || opts.SchemaAliases == SchemaAliasesComment
+	ctx.CommentAliases = opts.SchemaAliases == SchemaAliasesComment
+	var graph *internal.DependencyGraph
+	err = recoverPanic(func() string { return ctx.Pointer }, func() (err error) {
+		graph, err = internal.BuildMessages(schemas, ctx)
+		return err
+	})
+	if err != nil {
+		return nil, withFixes(err, schemas)
+	}
+	warnings = append(warnings, ctx.Warnings...)
+
 	// Compute transitive closure to classify types
 	goTypes, protoTypes, reasons := graph.ComputeTransitiveClosure()
 
 	// Build TypeMap using classification results
 	typeMap := buildTypeMap(goTypes, protoTypes, reasons)
+	observePhase(opts, PhaseBuild, start)
 
-	// Generate proto for proto-only types
-	// Skip proto generation only if there are Go types but no proto types
-	var protoBytes []byte
+	// Proto and Go generation are independent after classification, so run them
+	// concurrently. Skip proto generation only if there are Go types but no proto types.
+	var proto protoOutput
+	var goBytes []byte
+	var protoErr, goErr error
+	var g errgroup.Group
 	if len(protoTypes) > 0 || len(goTypes) == 0 {
-		protoMessages := filterProtoMessages(ctx.Messages, protoTypes)
-		// Create new context with filtered messages
-		protoCtx := internal.NewContext()
-		protoCtx.Messages = protoMessages
-		protoCtx.Enums = ctx.Enums
-		protoCtx.Definitions = filterProtoDefinitions(ctx.Definitions, protoTypes)
-		protoCtx.UsesTimestamp = ctx.UsesTimestamp
-
-		protoBytes, err = internal.Generate(opts.PackageName, opts.PackagePath, protoCtx)
-		if err != nil {
-			return nil, err
+		g.Go(func() error {
+			start := time.Now()
+			protoErr = recoverPanic(nil, func() (err error) {
+				proto, err = generateProto(opts, schemas, servers, ctx, protoTypes, typeMap, split)
+				if err == nil {
+					observePhase(opts, PhaseProto, start)
+				}
+				return err
+			})
+			return protoErr
+		})
+	}
+	if len(goTypes) > 0 {
+		g.Go(func() error {
+			start := time.Now()
+			goErr = recoverPanic(nil, func() (err error) {
+				goBytes, err = generateGo(opts, schemas, goTypes, graph, header, patchTypes, ctx.Aliases)
+				if err == nil {
+					observePhase(opts, PhaseGo, start)
+				}
+				return err
+			})
+			return goErr
+		})
+	}
+
+	// Report the proto error first when both fail so errors do not depend on timing
+	if err := g.Wait(); err != nil {
+		if protoErr != nil {
+			return nil, protoErr
 		}
+		return nil, goErr
+	}
+	if protoHeader != "" && len(proto.protobuf) > 0 {
+		proto.protobuf = append([]byte(protoHeader), proto.protobuf...)
 	}
 
-	// Generate Go for Go-only types
-	var goBytes []byte
-	if len(goTypes) > 0 {
-		goCtx := internal.NewGoContext(internal.ExtractPackageName(opts.GoPackagePath))
-		err := internal.BuildGoStructs(schemas, goTypes, graph, goCtx)
+	result := &ConvertResult{
+		Protobuf:      proto.protobuf,
+		Golang:        goBytes,
+		TypeMap:       typeMap,
+		Servers:       buildServers(servers),
+		Callbacks:     buildCallbacks(ctx.Callbacks),
+		Examples:      proto.examples,
+		Definitions:   proto.definitions,
+		Fixtures:      proto.fixtures,
+		Pagination:    proto.pagination,
+		Warnings:      append(warnings, proto.warnings...),
+		packageName:   opts.PackageName,
+		goPackageName: internal.ExtractPackageName(opts.GoPackagePath),
+		goPackagePath: opts.GoPackagePath,
+		packagePath:   opts.PackagePath,
+		fileOptions:   fileOptions,
+	}
+	for alias, target := range ctx.Aliases {
+		if info, ok := typeMap[alias]; !ok || info.Location != TypeLocationProto {
+			continue
+		}
+		if name, ok := result.ProtoTypeFor(target); ok {
+			typeMap[alias].AliasOf = name
+		}
+	}
+	result.Routes = buildRoutes(routes, result)
+	return result, nil
+}
+
+// protoOutput holds what proto generation contributes to a ConvertResult
+type protoOutput struct {
+	protobuf    []byte
+	examples    map[string][]byte
+	definitions []ProtoDefinition
+	fixtures    map[string][]byte
+	pagination  map[string]PaginationStyle
+	warnings    []string
+}
+
+// generateProto renders the proto output for proto types. It only records aliases in
+// typeMap and does not touch state used by generateGo, so the two can run concurrently.
+func generateProto(opts ConvertOptions, schemas []*parser.SchemaEntry, servers []*parser.ServerEntry,
+	ctx *internal.Context, protoTypes map[string]bool, typeMap map[string]*TypeInfo, split *definitionSplit) (protoOutput, error) {
+	var out protoOutput
+
+	protoMessages := filterProtoMessages(ctx.Messages, protoTypes)
+	// Create new context with filtered messages
+	protoCtx := internal.NewContext()
+	protoCtx.Messages = protoMessages
+	protoCtx.Enums = ctx.Enums
+	protoCtx.Definitions = filterProtoDefinitions(ctx.Definitions, protoTypes)
+	protoCtx.UsesTimestamp = ctx.UsesTimestamp
+	protoCtx.Imports = ctx.Imports
+	protoCtx.FileOptions = ctx.FileOptions
+	protoCtx.ImportRewrites = opts.ImportRewrites
+	protoCtx.ImportKinds = make(map[string]string, len(opts.ImportKinds))
+	for path, kind := range opts.ImportKinds {
+		protoCtx.ImportKinds[path] = string(kind)
+	}
+	protoCtx.Servers = servers
+	protoCtx.Callbacks = ctx.Callbacks
+	protoCtx.Format = internal.Format{
+		FieldOrder:             internal.FieldOrder(opts.FieldOrder),
+		IndentWidth:            opts.Format.IndentWidth,
+		BlankLineBetweenFields: opts.Format.BlankLineBetweenFields,
+		SingleTrailingNewline:  opts.Format.SingleTrailingNewline,
+		MaxCommentWidth:        opts.Format.MaxCommentWidth,
+		BufFormat:              opts.Format.BufFormat,
+		Anchors:                opts.Format.AnchorComments,
+	}
+
+	if opts.TitleNames {
+		for schema, name := range internal.ApplyTitleNames(protoCtx, schemas) {
+			typeMap[schema].AliasOf = name
+		}
+	}
+
+	if opts.DedupErrors {
+		for schema, shared := range internal.DedupErrorMessages(protoCtx) {
+			typeMap[schema].AliasOf = shared
+		}
+		protoMessages = protoCtx.Messages
+	}
+
+	detected, paginationWarnings := internal.DetectPagination(protoMessages, opts.NormalizePagination)
+	for name, style := range detected {
+		if out.pagination == nil {
+			out.pagination = make(map[string]PaginationStyle, len(detected))
+		}
+		out.pagination[name] = PaginationStyle(style)
+	}
+	out.warnings = append(out.warnings, paginationWarnings...)
+
+	service := opts.ResourceReferences.Service
+	if service == "" {
+		service = opts.PackageName
+	}
+	resourceWarnings, err := internal.ApplyResourceReferences(schemas, protoMessages, internal.ResourceReferences{
+		Detect:  opts.ResourceReferences.Detect,
+		Service: service,
+	}, protoCtx)
+	if err != nil {
+		return out, err
+	}
+	out.warnings = append(out.warnings, resourceWarnings...)
+
+	if opts.NoteDropped {
+		internal.NoteDropped(schemas, protoMessages)
+	}
+
+	if opts.EmitExamples {
+		out.examples, err = internal.BuildExamples(schemas, protoMessages, protoCtx.Enums)
 		if err != nil {
-			return nil, err
+			return out, err
 		}
-		goBytes, err = internal.GenerateGo(goCtx)
+	}
+
+	if opts.EmitFixtures {
+		out.fixtures, err = internal.BuildFixtures(schemas, protoMessages, protoCtx.Enums)
 		if err != nil {
-			return nil, err
+			return out, err
 		}
 	}
 
-	return &ConvertResult{
-		Protobuf: protoBytes,
-		Golang:   goBytes,
-		TypeMap:  typeMap,
-	}, nil
+	// Place inline enums and objects after examples and fixtures, which walk nested
+	// messages alongside their inline schemas and look up enums at file scope
+	if opts.InlineEnums == InlineEnumsNested {
+		internal.NestInlineEnums(protoCtx, ctx.InlineEnums)
+	}
+	if opts.InlineObjects == InlineObjectsHoisted {
+		protoCtx.Tracker = ctx.Tracker
+		internal.HoistInlineObjects(protoCtx)
+	}
+
+	for schema, alias := range internal.ApplyEmptyMessages(protoCtx, internal.EmptyMessages(opts.EmptyMessages)) {
+		typeMap[schema].AliasOf = alias
+	}
+
+	if split != nil {
+		for schema, name := range internal.RemoveDefinitions(protoCtx, split.names, split.qualifier, split.importPath) {
+			if name == "" {
+				delete(typeMap, schema)
+				continue
+			}
+			typeMap[schema].AliasOf = name
+		}
+		for name := range split.names {
+			delete(out.examples, name)
+			delete(out.fixtures, name)
+			delete(out.pagination, name)
+		}
+	}
+
+	if opts.Lock != nil {
+		out.warnings = append(out.warnings, internal.ApplyReservations(protoCtx.Messages, opts.Lock.messages())...)
+	}
+
+	protoCtx.CallbackTypes = callbackTypes(ctx.Callbacks, protoCtx.Messages, typeMap)
+
+	// Check after every pass that renames fields
+	jsonWarnings, err := internal.CheckJSONNames(protoCtx.Messages)
+	if err != nil {
+		return out, err
+	}
+	out.warnings = append(out.warnings, jsonWarnings...)
+
+	internal.ApplyProtoDescriptions(protoCtx.Definitions, internal.DescriptionOptions{
+		MaxLength:     opts.Descriptions.MaxLength,
+		StripMarkdown: opts.Descriptions.StripMarkdown,
+		Omit:          opts.Descriptions.OmitFromProto,
+	})
+
+	out.protobuf, err = internal.Generate(opts.PackageName, opts.PackagePath, protoCtx)
+	if err != nil {
+		return out, err
+	}
+	out.definitions = buildDefinitions(protoCtx.Definitions)
+
+	return out, nil
+}
+
+// generateGo renders the Go output for Go-only types
+func generateGo(opts ConvertOptions, schemas []*parser.SchemaEntry, goTypes map[string]bool, graph *internal.DependencyGraph, header string, patchTypes map[string]bool, aliases map[string]string) ([]byte, error) {
+	goCtx := internal.NewGoContext(internal.ExtractPackageName(opts.GoPackagePath))
+	goCtx.PreserveUnknownEnums = opts.PreserveUnknownEnums
+	goCtx.AnyOf = internal.AnyOfStrategy(opts.AnyOfStrategy)
+	goCtx.Constructors = opts.GoConstructors
+	goCtx.CloneEqual = opts.GoCloneEqual
+	goCtx.UnionVisitors = opts.GoUnionVisitors
+	goCtx.Optional = opts.GoOptional
+	goCtx.Kubernetes = opts.GoKubernetes
+	goCtx.Header = header
+	goCtx.PatchTypes = patchTypes
+	goCtx.Aliases = aliases
+
+	// Recover here, where the pointer being processed is known
+	var out []byte
+	err := recoverPanic(func() string { return goCtx.Pointer }, func() error {
+		if err := internal.BuildGoStructs(schemas, goTypes, graph, goCtx); err != nil {
+			return err
+		}
+		internal.ApplyGoDescriptions(goCtx, internal.DescriptionOptions{
+			MaxLength:     opts.Descriptions.MaxLength,
+			StripMarkdown: opts.Descriptions.StripMarkdown,
+			Omit:          opts.Descriptions.OmitFromGo,
+		})
+		var err error
+		out, err = internal.GenerateGo(goCtx)
+		return err
+	})
+	return out, err
+}
+
+// goHeader renders the provenance header of the Go output, ending in a blank line, or
+// an empty string if provenance is not configured
+func goHeader(provenance ProvenanceOptions, openapi []byte) string {
+	var result strings.Builder
+	if provenance.Source != "" {
+		result.WriteString("// Code generated by openapi-proto. DO NOT EDIT.\n")
+		result.WriteString(fmt.Sprintf("// source: %s@sha256:%x\n\n", provenance.Source, sha256.Sum256(openapi)))
+	}
+	if len(provenance.GoGenerate) > 0 {
+		for _, command := range provenance.GoGenerate {
+			result.WriteString("//go:generate " + command + "\n")
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// resolveExternalRefs copies the schemas openapi references in other files or at allowed
+// URLs into it when opts.BaseDir or opts.RemoteRefs is set
+func resolveExternalRefs(openapi []byte, opts ConvertOptions) ([]byte, error) {
+	if opts.BaseDir == "" && len(opts.RemoteRefs.AllowedHosts) == 0 {
+		return openapi, nil
+	}
+	resolved, err := parser.ResolveExternalRefs(openapi, opts.BaseDir, opts.RootDir, newRemoteFetcher(opts))
+	if err != nil {
+		return nil, &Error{Code: ErrorCodeInvalidReference, Err: err}
+	}
+	return resolved, nil
+}
+
+// recoverPanic runs fn and returns a panic as an error, so unexpected states in a spec
+// never crash the caller. location, if not nil, returns the JSON pointer being processed
+// when the panic happened. Generation runs on its own goroutine, where a panic cannot be
+// recovered by the caller of Convert, so it recovers separately.
+func recoverPanic(location func() string, fn func() error) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		var pointer string
+		if location != nil {
+			pointer = location()
+		}
+		if pointer == "" {
+			err = &Error{Code: ErrorCodePanic, Err: fmt.Errorf("conversion panicked: %v", r)}
+			return
+		}
+		err = &Error{Code: ErrorCodePanic, Err: fmt.Errorf("conversion panicked at %s: %v", pointer, r)}
+	}()
+	return fn()
+}
+
+// buildServers converts parsed server entries, resolving URL variables to their defaults
+func buildServers(entries []*parser.ServerEntry) []Server {
+	servers := make([]Server, 0, len(entries))
+	for _, entry := range entries {
+		names := make([]string, 0, len(entry.Variables))
+		for name := range entry.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		baseURL := entry.URL
+		for _, name := range names {
+			baseURL = strings.ReplaceAll(baseURL, "{"+name+"}", entry.Variables[name])
+		}
+
+		servers = append(servers, Server{
+			URL:         entry.URL,
+			BaseURL:     baseURL,
+			Description: entry.Description,
+		})
+	}
+	return servers
+}
+
+// callbackMessages returns a schema entry for the inline object schema of each callback
+// request body, named after the callback's rpc, e.g. SubscribeOnEventRequest, and points
+// the callback at it. Names taken by schemas, or by an earlier callback, get a numeric
+// suffix.
+func callbackMessages(entries []*parser.CallbackEntry, schemas []*parser.SchemaEntry) []*parser.SchemaEntry {
+	tracker := internal.NewNameTracker()
+	for _, entry := range schemas {
+		tracker.UniqueName(entry.Name)
+	}
+
+	var messages []*parser.SchemaEntry
+	for _, entry := range entries {
+		if entry.RequestSchema == nil || !isObjectSchema(entry.RequestSchema.Schema()) {
+			continue
+		}
+		entry.Request = tracker.UniqueName(internal.CallbackRPC(entry) + "Request")
+		messages = append(messages, &parser.SchemaEntry{Name: entry.Request, Proxy: entry.RequestSchema})
+	}
+	return messages
+}
+
+// callbackTypes maps the request schemas of callbacks to the proto messages generated for
+// them, leaving out schemas generated as Go
+func callbackTypes(entries []*parser.CallbackEntry, messages []*internal.ProtoMessage, typeMap map[string]*TypeInfo) map[string]string {
+	types := make(map[string]string)
+	for _, entry := range entries {
+		info, ok := typeMap[entry.Request]
+		if !ok || info.Location != TypeLocationProto {
+			continue
+		}
+		if info.AliasOf != "" {
+			types[entry.Request] = info.AliasOf
+			continue
+		}
+		for _, msg := range messages {
+			if msg.OriginalSchema == entry.Request {
+				types[entry.Request] = msg.Name
+				break
+			}
+		}
+	}
+	return types
+}
+
+// buildCallbacks converts parsed callback entries
+func buildCallbacks(entries []*parser.CallbackEntry) []Callback {
+	callbacks := make([]Callback, 0, len(entries))
+	for _, entry := range entries {
+		callbacks = append(callbacks, Callback{
+			Operation:  entry.Operation,
+			Name:       entry.Name,
+			Expression: entry.Expression,
+			Method:     entry.Method,
+			Request:    entry.Request,
+		})
+	}
+	return callbacks
 }
 
 // buildTypeMap creates a TypeMap from dependency graph classification results
@@ -172,6 +1303,17 @@ func buildTypeMap(goTypes, protoTypes map[string]bool, reasons map[string]string
 	return typeMap
 }
 
+// filterOperationSchemas keeps the schemas used by operations, in spec order
+func filterOperationSchemas(schemas []*parser.SchemaEntry, used map[string]bool) []*parser.SchemaEntry {
+	filtered := make([]*parser.SchemaEntry, 0, len(used))
+	for _, entry := range schemas {
+		if used[entry.Name] {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
 // filterProtoMessages removes messages marked as Go-only from proto output
 func filterProtoMessages(messages []*internal.ProtoMessage, protoTypes map[string]bool) []*internal.ProtoMessage {
 	filtered := make([]*internal.ProtoMessage, 0, len(protoTypes))
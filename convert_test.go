@@ -50,6 +50,30 @@ func TestConvertBasics(t *testing.T) {
 			opts:    conv.ConvertOptions{PackageName: "testpkg"},
 			wantErr: "package path cannot be empty",
 		},
+		{
+			name:    "unknown field order",
+			given:   []byte("openapi: 3.0.0"),
+			opts:    conv.ConvertOptions{PackageName: "testpkg", PackagePath: "github.com/example/proto/v1", FieldOrder: "random"},
+			wantErr: "unknown field order: random",
+		},
+		{
+			name:    "unknown inline objects style",
+			given:   []byte("openapi: 3.0.0"),
+			opts:    conv.ConvertOptions{PackageName: "testpkg", PackagePath: "github.com/example/proto/v1", InlineObjects: "flat"},
+			wantErr: "unknown inline objects style: flat",
+		},
+		{
+			name:    "unknown inline enums placement",
+			given:   []byte("openapi: 3.0.0"),
+			opts:    conv.ConvertOptions{PackageName: "testpkg", PackagePath: "github.com/example/proto/v1", InlineEnums: "global"},
+			wantErr: "unknown inline enums placement: global",
+		},
+		{
+			name:    "unknown empty messages mode",
+			given:   []byte("openapi: 3.0.0"),
+			opts:    conv.ConvertOptions{PackageName: "testpkg", PackagePath: "github.com/example/proto/v1", EmptyMessages: "drop"},
+			wantErr: "unknown empty messages mode: drop",
+		},
 		{
 			name:    "both empty",
 			given:   []byte("openapi: 3.0.0"),
@@ -427,6 +451,96 @@ message Order {
 	assert.Equal(t, expected, string(result.Protobuf))
 }
 
+func TestConvertFieldOrderOption(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        status:
+          type: string
+          x-proto-number: 3
+        amount:
+          type: number
+          x-proto-number: 1
+        item:
+          type: object
+          x-proto-number: 2
+          properties:
+            sku:
+              type: string
+            count:
+              type: integer
+`
+
+	for _, test := range []struct {
+		name     string
+		order    conv.FieldOrder
+		expected string
+	}{
+		{
+			name:  "spec order by default",
+			order: "",
+			expected: `message Order {
+  message Item {
+    string sku = 1 [json_name = "sku"];
+    int32 count = 2 [json_name = "count"];
+  }
+
+  string status = 3 [json_name = "status"];
+  double amount = 1 [json_name = "amount"];
+  Item item = 2 [json_name = "item"];
+}
+`,
+		},
+		{
+			name:  "by number",
+			order: conv.FieldOrderByNumber,
+			expected: `message Order {
+  message Item {
+    string sku = 1 [json_name = "sku"];
+    int32 count = 2 [json_name = "count"];
+  }
+
+  double amount = 1 [json_name = "amount"];
+  Item item = 2 [json_name = "item"];
+  string status = 3 [json_name = "status"];
+}
+`,
+		},
+		{
+			name:  "alphabetical",
+			order: conv.FieldOrderAlphabetical,
+			expected: `message Order {
+  message Item {
+    int32 count = 2 [json_name = "count"];
+    string sku = 1 [json_name = "sku"];
+  }
+
+  double amount = 1 [json_name = "amount"];
+  Item item = 2 [json_name = "item"];
+  string status = 3 [json_name = "status"];
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				FieldOrder:  test.order,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
+
 func TestConvertCompleteExample(t *testing.T) {
 	given := `openapi: 3.0.0
 info:
@@ -0,0 +1,146 @@
+package conv
+
+import "github.com/duh-rpc/openapi-proto.go/internal"
+
+// ProtoDefinition is a top-level definition in the proto output, either a
+// *ProtoMessage or a *ProtoEnum
+type ProtoDefinition interface {
+	protoDefinition()
+}
+
+// ProtoMessage describes a generated proto3 message
+type ProtoMessage struct {
+	Name        string
+	Description string
+	Fields      []*ProtoField
+	// Nested lists messages declared inside this message, in output order
+	Nested []*ProtoMessage
+	// Enums lists enums declared inside this message, rendered before Nested
+	Enums []*ProtoEnum
+	// OriginalSchema is the OpenAPI schema name the message was built from. Inline objects
+	// hoisted with InlineObjectsHoisted carry their parent's schema name.
+	OriginalSchema string
+	// ReservedNumbers and ReservedNames list fields removed since the conversion that
+	// produced ConvertOptions.Lock, rendered as reserved statements
+	ReservedNumbers []int
+	ReservedNames   []string
+	// Notes lists constructs of the schema dropped from the output, set by
+	// ConvertOptions.NoteDropped
+	Notes []string
+	// Oneof names the oneof block holding every field, set for oneOf schemas converted
+	// with OneOfStrategyProtoOneof
+	Oneof string
+}
+
+// ProtoField describes a field of a generated proto3 message
+type ProtoField struct {
+	Name string
+	// Type is the field type, the value type for map fields
+	Type string
+	// MapKey is the key type of a map field, e.g. string for map<string, User>, and
+	// empty for other fields
+	MapKey      string
+	Number      int
+	JSONName    string
+	Description string
+	Repeated    bool
+	// Optional is set for fields declared with the proto3 optional label, see
+	// ConvertOptions.NullableFields
+	Optional bool
+	// EnumValues lists the allowed values of a string enum field, rendered as a comment
+	EnumValues []string
+	// Options lists field options rendered after json_name, e.g. (google.api.field_behavior) = REQUIRED
+	Options []string
+	// Notes lists constructs of the property dropped from the output, set by
+	// ConvertOptions.NoteDropped
+	Notes []string
+}
+
+// ProtoEnum describes a generated proto3 enum
+type ProtoEnum struct {
+	Name        string
+	Description string
+	Values      []*ProtoEnumValue
+}
+
+// ProtoEnumValue describes a value of a generated proto3 enum
+type ProtoEnumValue struct {
+	Name   string
+	Number int
+}
+
+func (*ProtoMessage) protoDefinition() {}
+func (*ProtoEnum) protoDefinition()    {}
+
+// buildDefinitions converts the internal proto definitions into their public form
+func buildDefinitions(definitions []interface{}) []ProtoDefinition {
+	result := make([]ProtoDefinition, 0, len(definitions))
+	for _, def := range definitions {
+		switch d := def.(type) {
+		case *internal.ProtoMessage:
+			result = append(result, buildMessage(d))
+		case *internal.ProtoEnum:
+			result = append(result, buildEnum(d))
+		}
+	}
+	return result
+}
+
+// buildMessage converts an internal message, including its fields, nested messages and enums
+func buildMessage(msg *internal.ProtoMessage) *ProtoMessage {
+	result := &ProtoMessage{
+		Name:            msg.Name,
+		Description:     msg.Description,
+		Fields:          make([]*ProtoField, 0, len(msg.Fields)),
+		Nested:          make([]*ProtoMessage, 0, len(msg.Nested)),
+		OriginalSchema:  msg.OriginalSchema,
+		ReservedNumbers: msg.ReservedNumbers,
+		ReservedNames:   msg.ReservedNames,
+		Notes:           msg.Notes,
+		Oneof:           msg.Oneof,
+	}
+
+	for _, field := range msg.Fields {
+		result.Fields = append(result.Fields, &ProtoField{
+			Name:        field.Name,
+			Type:        field.Type,
+			MapKey:      field.MapKey,
+			Number:      field.Number,
+			JSONName:    field.JSONName,
+			Description: field.Description,
+			Repeated:    field.Repeated,
+			Optional:    field.Optional,
+			EnumValues:  field.EnumValues,
+			Options:     field.Options,
+			Notes:       field.Notes,
+		})
+	}
+
+	for _, nested := range msg.Nested {
+		result.Nested = append(result.Nested, buildMessage(nested))
+	}
+
+	for _, enum := range msg.Enums {
+		result.Enums = append(result.Enums, buildEnum(enum))
+	}
+
+	return result
+}
+
+// buildEnum converts an internal enum and its values
+func buildEnum(enum *internal.ProtoEnum) *ProtoEnum {
+	result := &ProtoEnum{
+		Name:        enum.Name,
+		Description: enum.Description,
+		Values:      make([]*ProtoEnumValue, 0, len(enum.Values)),
+	}
+
+	for _, value := range enum.Values {
+		result.Values = append(result.Values, &ProtoEnumValue{
+			Name:   value.Name,
+			Number: value.Number,
+		})
+	}
+
+	return result
+}
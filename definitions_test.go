@@ -0,0 +1,122 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertResultDefinitions(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Code:
+      type: integer
+      description: Result code
+      enum: [200, 404]
+    Order:
+      type: object
+      description: A customer order
+      properties:
+        status:
+          type: string
+          enum: [open, closed]
+        tags:
+          type: array
+          items:
+            type: string
+        item:
+          type: object
+          properties:
+            sku:
+              type: string
+        code:
+          $ref: '#/components/schemas/Code'
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []conv.ProtoDefinition{
+		&conv.ProtoEnum{
+			Name:        "Code",
+			Description: "Result code",
+			Values: []*conv.ProtoEnumValue{
+				{Name: "CODE_UNSPECIFIED", Number: 0},
+				{Name: "CODE_200", Number: 1},
+				{Name: "CODE_404", Number: 2},
+			},
+		},
+		&conv.ProtoMessage{
+			Name:        "Order",
+			Description: "A customer order",
+			Fields: []*conv.ProtoField{
+				{Name: "status", Type: "string", Number: 1, JSONName: "status", EnumValues: []string{"open", "closed"}},
+				{Name: "tags", Type: "string", Number: 2, JSONName: "tags", Repeated: true},
+				{Name: "item", Type: "Item", Number: 3, JSONName: "item"},
+				{Name: "code", Type: "Code", Number: 4, JSONName: "code"},
+			},
+			Nested: []*conv.ProtoMessage{
+				{
+					Name: "Item",
+					Fields: []*conv.ProtoField{
+						{Name: "sku", Type: "string", Number: 1, JSONName: "sku"},
+					},
+					Nested:         []*conv.ProtoMessage{},
+					OriginalSchema: "item",
+				},
+			},
+			OriginalSchema: "Order",
+		},
+	}, result.Definitions)
+}
+
+func TestConvertResultDefinitionsExcludeGoTypes(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Definitions, 1)
+
+	msg, ok := result.Definitions[0].(*conv.ProtoMessage)
+	require.True(t, ok)
+	assert.Equal(t, "Address", msg.Name)
+}
@@ -0,0 +1,312 @@
+package conv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/duh-rpc/openapi-proto.go/internal"
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+// ChangeKind classifies how a schema's generated output changed between two spec versions
+type ChangeKind string
+
+const (
+	ChangeUnchanged ChangeKind = "unchanged"
+	ChangeAdded     ChangeKind = "added"
+	ChangeRemoved   ChangeKind = "removed"
+	ChangeChanged   ChangeKind = "changed"
+)
+
+// SchemaHint describes how the generated output for one schema changed between two spec
+// versions, so build systems can skip regenerating or re-reviewing untouched outputs
+type SchemaHint struct {
+	Schema string
+	Kind   ChangeKind
+	// Changes lists what changed, e.g. "field added totalTax=8". Empty unless Kind is ChangeChanged.
+	Changes []string
+}
+
+// String renders the hint, e.g. "User unchanged" or "Order: field added totalTax=8"
+func (h SchemaHint) String() string {
+	if h.Kind == ChangeChanged {
+		return fmt.Sprintf("%s: %s", h.Schema, strings.Join(h.Changes, ", "))
+	}
+	return fmt.Sprintf("%s %s", h.Schema, h.Kind)
+}
+
+// Diff converts two versions of a spec with the same options and returns a hint for
+// every schema in either version. Hints for schemas in current come first in spec
+// order, followed by schemas removed from previous.
+//
+// Proto types are compared by their generated definitions, so a spec edit that does
+// not affect the output (an example, for instance) is reported as unchanged. Go types
+// and schemas without a proto definition are compared by their spec content.
+func Diff(previous, current []byte, opts ConvertOptions) ([]SchemaHint, error) {
+	before, err := loadDiffSide(previous, opts)
+	if err != nil {
+		return nil, fmt.Errorf("previous spec: %w", err)
+	}
+
+	after, err := loadDiffSide(current, opts)
+	if err != nil {
+		return nil, fmt.Errorf("current spec: %w", err)
+	}
+
+	hints := make([]SchemaHint, 0, len(after.order))
+	for _, name := range after.order {
+		if _, ok := before.hashes[name]; !ok {
+			hints = append(hints, SchemaHint{Schema: name, Kind: ChangeAdded})
+			continue
+		}
+
+		changes := diffSchema(name, before, after)
+		if len(changes) == 0 {
+			hints = append(hints, SchemaHint{Schema: name, Kind: ChangeUnchanged})
+			continue
+		}
+		hints = append(hints, SchemaHint{Schema: name, Kind: ChangeChanged, Changes: changes})
+	}
+
+	for _, name := range before.order {
+		if _, ok := after.hashes[name]; !ok {
+			hints = append(hints, SchemaHint{Schema: name, Kind: ChangeRemoved})
+		}
+	}
+
+	return hints, nil
+}
+
+// diffSide holds what Diff compares for one spec version
+type diffSide struct {
+	result      *ConvertResult
+	order       []string
+	hashes      map[string][32]byte
+	definitions map[string]ProtoDefinition // schema name -> generated definition
+}
+
+// loadDiffSide converts and parses one spec version
+func loadDiffSide(openapi []byte, opts ConvertOptions) (*diffSide, error) {
+	result, err := Convert(openapi, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := parser.ParseDocument(openapi)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := doc.Schemas()
+	if err != nil {
+		return nil, err
+	}
+
+	side := &diffSide{
+		result:      result,
+		hashes:      make(map[string][32]byte, len(entries)),
+		definitions: make(map[string]ProtoDefinition),
+	}
+	for _, entry := range entries {
+		side.order = append(side.order, entry.Name)
+		if schema := entry.Proxy.Schema(); schema != nil && schema.GoLow() != nil {
+			side.hashes[entry.Name] = schema.GoLow().Hash()
+		} else {
+			side.hashes[entry.Name] = [32]byte{}
+		}
+	}
+
+	enumSchemas := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		enumSchemas[internal.ToPascalCase(entry.Name)] = entry.Name
+	}
+	for _, def := range result.Definitions {
+		switch d := def.(type) {
+		case *ProtoMessage:
+			// Hoisted inline objects share their parent's OriginalSchema and follow it
+			if _, ok := side.definitions[d.OriginalSchema]; !ok {
+				side.definitions[d.OriginalSchema] = d
+			}
+		case *ProtoEnum:
+			if name, ok := enumSchemas[d.Name]; ok {
+				side.definitions[name] = d
+			}
+		}
+	}
+
+	return side, nil
+}
+
+// diffSchema lists the output changes for a schema present in both versions
+func diffSchema(name string, before, after *diffSide) []string {
+	var changes []string
+
+	oldLocation, newLocation := location(before.result, name), location(after.result, name)
+	if oldLocation != newLocation {
+		changes = append(changes, fmt.Sprintf("moved from %s to %s", oldLocation, newLocation))
+	}
+
+	oldDef, oldOK := before.definitions[name]
+	newDef, newOK := after.definitions[name]
+	if oldOK && newOK {
+		switch o := oldDef.(type) {
+		case *ProtoMessage:
+			if n, ok := newDef.(*ProtoMessage); ok {
+				return append(changes, diffMessage("", o, n)...)
+			}
+		case *ProtoEnum:
+			if n, ok := newDef.(*ProtoEnum); ok {
+				return append(changes, diffEnum(o, n)...)
+			}
+		}
+		return append(changes, "definition kind changed")
+	}
+
+	if before.hashes[name] != after.hashes[name] {
+		changes = append(changes, "schema changed")
+	}
+	return changes
+}
+
+// location returns where a schema is generated, or an empty string if it is not in the TypeMap
+func location(result *ConvertResult, name string) TypeLocation {
+	if info, ok := result.TypeMap[name]; ok {
+		return info.Location
+	}
+	return ""
+}
+
+// diffMessage lists field, nested enum and nested message changes. prefix qualifies nested definitions.
+func diffMessage(prefix string, before, after *ProtoMessage) []string {
+	var changes []string
+
+	if before.Name != after.Name {
+		changes = append(changes, fmt.Sprintf("%srenamed %s to %s", prefix, before.Name, after.Name))
+	}
+	if before.Description != after.Description {
+		changes = append(changes, prefix+"description changed")
+	}
+
+	oldFields := make(map[string]*ProtoField, len(before.Fields))
+	for _, field := range before.Fields {
+		oldFields[field.Name] = field
+	}
+	newFields := make(map[string]*ProtoField, len(after.Fields))
+	for _, field := range after.Fields {
+		newFields[field.Name] = field
+	}
+
+	for _, field := range after.Fields {
+		old, ok := oldFields[field.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%sfield added %s=%d", prefix, field.Name, field.Number))
+			continue
+		}
+		if old.Number != field.Number {
+			changes = append(changes, fmt.Sprintf("%sfield renumbered %s=%d to %d", prefix, field.Name, old.Number, field.Number))
+		}
+		if old.Type != field.Type || old.Repeated != field.Repeated {
+			changes = append(changes, fmt.Sprintf("%sfield type changed %s: %s to %s", prefix, field.Name, fieldType(old), fieldType(field)))
+		}
+		if old.JSONName != field.JSONName {
+			changes = append(changes, fmt.Sprintf("%sfield json_name changed %s: %s to %s", prefix, field.Name, old.JSONName, field.JSONName))
+		}
+		if old.Description != field.Description || !reflect.DeepEqual(old.EnumValues, field.EnumValues) {
+			changes = append(changes, fmt.Sprintf("%sfield comment changed %s", prefix, field.Name))
+		}
+		if !reflect.DeepEqual(old.Options, field.Options) {
+			changes = append(changes, fmt.Sprintf("%sfield options changed %s", prefix, field.Name))
+		}
+	}
+	for _, field := range before.Fields {
+		if _, ok := newFields[field.Name]; !ok {
+			changes = append(changes, fmt.Sprintf("%sfield removed %s=%d", prefix, field.Name, field.Number))
+		}
+	}
+
+	oldEnums := make(map[string]*ProtoEnum, len(before.Enums))
+	for _, enum := range before.Enums {
+		oldEnums[enum.Name] = enum
+	}
+	newEnums := make(map[string]bool, len(after.Enums))
+	for _, enum := range after.Enums {
+		newEnums[enum.Name] = true
+		old, ok := oldEnums[enum.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%senum added %s", prefix, enum.Name))
+			continue
+		}
+		for _, change := range diffEnum(old, enum) {
+			changes = append(changes, prefix+enum.Name+"."+change)
+		}
+	}
+	for _, enum := range before.Enums {
+		if !newEnums[enum.Name] {
+			changes = append(changes, fmt.Sprintf("%senum removed %s", prefix, enum.Name))
+		}
+	}
+
+	oldNested := make(map[string]*ProtoMessage, len(before.Nested))
+	for _, nested := range before.Nested {
+		oldNested[nested.Name] = nested
+	}
+	newNested := make(map[string]bool, len(after.Nested))
+	for _, nested := range after.Nested {
+		newNested[nested.Name] = true
+		old, ok := oldNested[nested.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%smessage added %s", prefix, nested.Name))
+			continue
+		}
+		changes = append(changes, diffMessage(prefix+nested.Name+".", old, nested)...)
+	}
+	for _, nested := range before.Nested {
+		if !newNested[nested.Name] {
+			changes = append(changes, fmt.Sprintf("%smessage removed %s", prefix, nested.Name))
+		}
+	}
+
+	return changes
+}
+
+// diffEnum lists enum value changes
+func diffEnum(before, after *ProtoEnum) []string {
+	var changes []string
+
+	if before.Description != after.Description {
+		changes = append(changes, "description changed")
+	}
+
+	oldValues := make(map[string]int, len(before.Values))
+	for _, value := range before.Values {
+		oldValues[value.Name] = value.Number
+	}
+	newValues := make(map[string]bool, len(after.Values))
+	for _, value := range after.Values {
+		newValues[value.Name] = true
+		number, ok := oldValues[value.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("value added %s=%d", value.Name, value.Number))
+			continue
+		}
+		if number != value.Number {
+			changes = append(changes, fmt.Sprintf("value renumbered %s=%d to %d", value.Name, number, value.Number))
+		}
+	}
+	for _, value := range before.Values {
+		if !newValues[value.Name] {
+			changes = append(changes, fmt.Sprintf("value removed %s=%d", value.Name, value.Number))
+		}
+	}
+
+	return changes
+}
+
+// fieldType renders a field's type as it appears in the proto output
+func fieldType(field *ProtoField) string {
+	if field.Repeated {
+		return "repeated " + field.Type
+	}
+	return field.Type
+}
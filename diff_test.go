@@ -0,0 +1,181 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	previous := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+        total:
+          type: number
+        notes:
+          type: string
+        item:
+          type: object
+          properties:
+            sku:
+              type: string
+    Status:
+      type: integer
+      enum: [1, 2]
+    Legacy:
+      type: object
+      properties:
+        flag:
+          type: boolean
+    Tag:
+      type: string
+      enum: [a, b]
+`
+
+	// Order drops notes=3 and adds totalTax=8 while keeping the other numbers stable
+	current := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 2.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Alice
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+          x-proto-number: 1
+        total:
+          type: integer
+          x-proto-number: 2
+        item:
+          type: object
+          x-proto-number: 4
+          properties:
+            sku:
+              type: string
+            count:
+              type: integer
+        totalTax:
+          type: number
+          x-proto-number: 8
+    Status:
+      type: integer
+      enum: [1, 2, 3]
+    Tag:
+      type: string
+      enum: [a, b, c]
+    Invoice:
+      type: object
+      properties:
+        amount:
+          type: number
+`
+
+	hints, err := conv.Diff([]byte(previous), []byte(current), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	var actual []string
+	for _, hint := range hints {
+		actual = append(actual, hint.String())
+	}
+	assert.Equal(t, []string{
+		"User unchanged",
+		"Order: field type changed total: double to int32, field added totalTax=8, field removed notes=3, Item.field added count=2",
+		"Status: value added STATUS_3=3",
+		"Tag: schema changed",
+		"Invoice added",
+		"Legacy removed",
+	}, actual)
+
+	assert.Equal(t, conv.ChangeUnchanged, hints[0].Kind)
+	assert.Equal(t, conv.ChangeChanged, hints[1].Kind)
+	assert.Equal(t, conv.ChangeAdded, hints[4].Kind)
+	assert.Equal(t, conv.ChangeRemoved, hints[5].Kind)
+}
+
+func TestDiffMovedToGolang(t *testing.T) {
+	previous := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+	current := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+        pet:
+          $ref: '#/components/schemas/Pet'
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	hints, err := conv.Diff([]byte(previous), []byte(current), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	require.Len(t, hints, 4)
+	assert.Equal(t, "Owner: moved from proto to golang, schema changed", hints[0].String())
+}
+
+func TestDiffInvalidSpec(t *testing.T) {
+	_, err := conv.Diff([]byte("not: [valid"), []byte("openapi: 3.0.0"), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.ErrorContains(t, err, "previous spec: failed to parse OpenAPI document")
+}
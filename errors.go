@@ -0,0 +1,190 @@
+package conv
+
+import (
+	"errors"
+
+	"github.com/duh-rpc/openapi-proto.go/internal"
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+// ErrorCode classifies why a conversion failed. Every error returned by Convert and
+// ConvertUntrusted is an *Error carrying one, so callers can branch on the category
+// without matching error text.
+type ErrorCode string
+
+const (
+	// ErrorCodeInternal covers failures without a more specific category, such as
+	// template errors or schemas the parser could not build
+	ErrorCodeInternal = ErrorCode(internal.CodeInternal)
+	// ErrorCodeInvalidInput means the openapi input is empty
+	ErrorCodeInvalidInput ErrorCode = "invalid_input"
+	// ErrorCodeInvalidOptions means ConvertOptions failed validation; the error also
+	// unwraps to an *OptionsError
+	ErrorCodeInvalidOptions ErrorCode = "invalid_options"
+	// ErrorCodeParse means the document is not valid OpenAPI 3.x YAML or JSON
+	ErrorCodeParse = ErrorCode(internal.CodeParse)
+	// ErrorCodeLimitExceeded means the spec exceeded one of ConvertOptions.Limits
+	ErrorCodeLimitExceeded = ErrorCode(internal.CodeLimitExceeded)
+	// ErrorCodeCanceled means the context passed to ConvertUntrusted was done
+	ErrorCodeCanceled ErrorCode = "canceled"
+	// ErrorCodePanic means conversion panicked and the panic was recovered
+	ErrorCodePanic ErrorCode = "panic"
+	// ErrorCodeNondeterministic means ConvertOptions.Deterministic found output that
+	// differs between runs
+	ErrorCodeNondeterministic ErrorCode = "nondeterministic"
+	// ErrorCodeUnsupportedAllOf means a schema or property uses allOf
+	ErrorCodeUnsupportedAllOf = ErrorCode(internal.CodeUnsupportedAllOf)
+	// ErrorCodeUnsupportedAnyOf means a schema or property uses anyOf
+	ErrorCodeUnsupportedAnyOf = ErrorCode(internal.CodeUnsupportedAnyOf)
+	// ErrorCodeUnsupportedNot means a schema or property uses not
+	ErrorCodeUnsupportedNot = ErrorCode(internal.CodeUnsupportedNot)
+	// ErrorCodeInvalidOneOf means a oneOf lacks a discriminator, has fewer than two
+	// variants or has an inline variant
+	ErrorCodeInvalidOneOf = ErrorCode(internal.CodeInvalidOneOf)
+	// ErrorCodeInvalidDiscriminator means a discriminator mapping conflicts, misses a
+	// variant or names a property a variant does not have
+	ErrorCodeInvalidDiscriminator = ErrorCode(internal.CodeInvalidDiscriminator)
+	// ErrorCodeUnsupportedType means a type, format or shape has no proto or Go mapping,
+	// such as nested arrays or multi-type properties
+	ErrorCodeUnsupportedType = ErrorCode(internal.CodeUnsupportedType)
+	// ErrorCodePluralInlineName means an inline object or enum property name is plural,
+	// so no type name can be derived from it
+	ErrorCodePluralInlineName = ErrorCode(internal.CodePluralInlineName)
+	// ErrorCodeInvalidFieldName means a property name cannot be made a proto field name
+	ErrorCodeInvalidFieldName = ErrorCode(internal.CodeInvalidFieldName)
+	// ErrorCodeInvalidFieldNumber means an x-proto-number is not an integer or is out of range
+	ErrorCodeInvalidFieldNumber = ErrorCode(internal.CodeInvalidFieldNumber)
+	// ErrorCodeReservedFieldNumber means an x-proto-number is in the reserved range 19000-19999
+	ErrorCodeReservedFieldNumber = ErrorCode(internal.CodeReservedFieldNumber)
+	// ErrorCodeDuplicateFieldNumber means two properties share an x-proto-number
+	ErrorCodeDuplicateFieldNumber = ErrorCode(internal.CodeDuplicateFieldNumber)
+	// ErrorCodeDuplicateJSONName means two fields of a message share a json_name
+	ErrorCodeDuplicateJSONName = ErrorCode(internal.CodeDuplicateJSONName)
+	// ErrorCodeMixedNumbering means x-proto-number is set on some properties of a schema
+	// but not all
+	ErrorCodeMixedNumbering = ErrorCode(internal.CodeMixedNumbering)
+	// ErrorCodeInvalidEnum means an enum has no type, a null value or mixed value types
+	ErrorCodeInvalidEnum = ErrorCode(internal.CodeInvalidEnum)
+	// ErrorCodeInvalidReference means a $ref is malformed, external or does not resolve
+	ErrorCodeInvalidReference = ErrorCode(internal.CodeInvalidReference)
+	// ErrorCodeInvalidExtension means an x-proto-* extension other than x-proto-number,
+	// or an x-db-* extension, has an invalid value
+	ErrorCodeInvalidExtension = ErrorCode(internal.CodeInvalidExtension)
+	// ErrorCodeInvalidExample means a schema example cannot be used for Examples
+	ErrorCodeInvalidExample = ErrorCode(internal.CodeInvalidExample)
+	// ErrorCodeInvalidFixture means a test fixture cannot be built from a schema
+	ErrorCodeInvalidFixture = ErrorCode(internal.CodeInvalidFixture)
+	// ErrorCodeMissingDescription means DescriptionOptions.Require is RequireDescriptionsError
+	// and a schema or property has no description
+	ErrorCodeMissingDescription = ErrorCode(internal.CodeMissingDescription)
+)
+
+// Error is the error returned by Convert and ConvertUntrusted. Its message is that of
+// the underlying error unless ConvertOptions.FormatError rewrote it; use errors.As to
+// read the code:
+//
+//	var convErr *conv.Error
+//	if errors.As(err, &convErr) && convErr.Code == conv.ErrorCodePluralInlineName {
+//		...
+//	}
+type Error struct {
+	Code ErrorCode
+	Err  error
+	// Message replaces the message of Err when set by ConvertOptions.FormatError
+	Message string
+	// Fixes is a suggested patch to the spec that resolves the error, set for plural
+	// inline names, mixed x-proto-number usage and property names that cannot start a
+	// proto field name
+	Fixes []Fix
+}
+
+// Fix is a JSON Patch (RFC 6902) operation on the OpenAPI spec, so tooling can offer
+// an automatic fix. Pointers address the spec as parsed and apply to YAML and JSON
+// alike. Marshaled to JSON, a []Fix is a valid JSON Patch document.
+type Fix struct {
+	// Op is "add", "replace" or "move"
+	Op string `json:"op"`
+	// Path is the JSON pointer of the location changed, e.g.
+	// "/components/schemas/User/properties/name/x-proto-number"
+	Path string `json:"path"`
+	// From is the JSON pointer of the source location, for "move"
+	From string `json:"from,omitempty"`
+	// Value is the new value, for "add" and "replace"
+	Value any `json:"value,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// withErrorCode returns err as an *Error, taking the code from the most specific
+// category found in its chain. Errors without one are ErrorCodeInternal.
+func withErrorCode(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var convErr *Error
+	if errors.As(err, &convErr) {
+		return err
+	}
+
+	var optsErr *OptionsError
+	if errors.As(err, &optsErr) {
+		return &Error{Code: ErrorCodeInvalidOptions, Err: err}
+	}
+
+	var coded *internal.CodedError
+	if errors.As(err, &coded) {
+		return &Error{Code: ErrorCode(coded.Code), Err: err}
+	}
+
+	return &Error{Code: ErrorCodeInternal, Err: err}
+}
+
+// formatError applies opts.FormatError to the *Error in err, if both are present
+func formatError(err error, opts ConvertOptions) error {
+	if opts.FormatError == nil {
+		return err
+	}
+
+	var convErr *Error
+	if errors.As(err, &convErr) && convErr.Message == "" {
+		convErr.Message = opts.FormatError(convErr)
+	}
+	return err
+}
+
+// withFixes returns err as an *Error carrying the fixes suggested for it, if any
+func withFixes(err error, schemas []*parser.SchemaEntry) error {
+	fixes := buildFixes(internal.SuggestFixes(schemas, err))
+	if fixes == nil {
+		return err
+	}
+
+	var coded *internal.CodedError
+	if !errors.As(err, &coded) {
+		return err
+	}
+	return &Error{Code: ErrorCode(coded.Code), Err: err, Fixes: fixes}
+}
+
+// buildFixes converts suggested fixes from the internal representation
+func buildFixes(fixes []internal.Fix) []Fix {
+	if len(fixes) == 0 {
+		return nil
+	}
+
+	result := make([]Fix, 0, len(fixes))
+	for _, fix := range fixes {
+		result = append(result, Fix{Op: fix.Op, Path: fix.Path, From: fix.From, Value: fix.Value})
+	}
+	return result
+}
@@ -0,0 +1,302 @@
+package conv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertErrorCodes(t *testing.T) {
+	header := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+`
+
+	for _, test := range []struct {
+		name    string
+		given   string
+		opts    conv.ConvertOptions
+		code    conv.ErrorCode
+		wantErr string
+	}{
+		{
+			name:    "empty input",
+			code:    conv.ErrorCodeInvalidInput,
+			wantErr: "openapi input cannot be empty",
+		},
+		{
+			name:    "invalid options",
+			given:   header,
+			opts:    conv.ConvertOptions{PackageName: "testpkg"},
+			code:    conv.ErrorCodeInvalidOptions,
+			wantErr: "package path cannot be empty",
+		},
+		{
+			name:    "not openapi",
+			given:   "swagger: '2.0'\n",
+			code:    conv.ErrorCodeParse,
+			wantErr: "failed to build OpenAPI model",
+		},
+		{
+			name: "allOf",
+			given: header + `    Pet:
+      allOf:
+        - type: object
+`,
+			code:    conv.ErrorCodeUnsupportedAllOf,
+			wantErr: "schema 'Pet': uses 'allOf' which is not supported",
+		},
+		{
+			name: "plural inline name",
+			given: header + `    User:
+      type: object
+      properties:
+        addresses:
+          type: object
+          properties:
+            street:
+              type: string
+`,
+			code:    conv.ErrorCodePluralInlineName,
+			wantErr: "cannot derive message name from property 'addresses'",
+		},
+		{
+			name: "reserved field number",
+			given: header + `    User:
+      type: object
+      properties:
+        name:
+          type: string
+          x-proto-number: 19500
+`,
+			code:    conv.ErrorCodeReservedFieldNumber,
+			wantErr: "x-proto-number 19500 is in reserved range 19000-19999",
+		},
+		{
+			name: "mixed numbering",
+			given: header + `    User:
+      type: object
+      properties:
+        name:
+          type: string
+          x-proto-number: 1
+        email:
+          type: string
+`,
+			code:    conv.ErrorCodeMixedNumbering,
+			wantErr: "x-proto-number must be specified on all fields or none",
+		},
+		{
+			name: "nested property keeps code",
+			given: header + `    User:
+      type: object
+      properties:
+        profile:
+          type: object
+          properties:
+            _secret:
+              type: string
+`,
+			code:    conv.ErrorCodeInvalidFieldName,
+			wantErr: "field name cannot start with underscore",
+		},
+		{
+			name: "invalid extension",
+			given: header + `    User:
+      type: object
+      properties:
+        name:
+          type: string
+          x-proto-field-behavior: [SOMETIMES]
+`,
+			code:    conv.ErrorCodeInvalidExtension,
+			wantErr: "x-proto-field-behavior has unknown value: SOMETIMES",
+		},
+		{
+			name: "limit exceeded",
+			given: header + `    User:
+      type: object
+      properties:
+        profile:
+          type: object
+          properties:
+            location:
+              type: object
+              properties:
+                city:
+                  type: string
+`,
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				Limits:      conv.Limits{MaxDepth: 1},
+			},
+			code:    conv.ErrorCodeLimitExceeded,
+			wantErr: "nesting depth exceeds limit of 1",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.opts
+			if opts.PackageName == "" && opts.PackagePath == "" {
+				opts.PackageName = "testpkg"
+				opts.PackagePath = "github.com/example/proto/v1"
+			}
+
+			_, err := conv.Convert([]byte(test.given), opts)
+			require.ErrorContains(t, err, test.wantErr)
+
+			var convErr *conv.Error
+			require.True(t, errors.As(err, &convErr))
+			assert.Equal(t, test.code, convErr.Code)
+		})
+	}
+}
+
+func TestConvertUntrustedErrorCodes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := conv.ConvertUntrusted(ctx, []byte("openapi: 3.0.0\n"), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	var convErr *conv.Error
+	require.True(t, errors.As(err, &convErr))
+	assert.Equal(t, conv.ErrorCodeCanceled, convErr.Code)
+}
+
+func TestConvertFormatError(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        addresses:
+          type: object
+`
+	messages := map[conv.ErrorCode]string{
+		conv.ErrorCodePluralInlineName: "Bitte verwenden Sie einen Namen im Singular",
+	}
+
+	for _, test := range []struct {
+		name     string
+		format   func(*conv.Error) string
+		expected string
+	}{
+		{
+			name:     "no formatter",
+			expected: "schema 'User': cannot derive message name from property 'addresses'; use singular form or $ref",
+		},
+		{
+			name: "localized",
+			format: func(err *conv.Error) string {
+				return messages[err.Code]
+			},
+			expected: "Bitte verwenden Sie einen Namen im Singular",
+		},
+		{
+			name: "empty keeps original",
+			format: func(err *conv.Error) string {
+				return ""
+			},
+			expected: "schema 'User': cannot derive message name from property 'addresses'; use singular form or $ref",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				FormatError: test.format,
+			})
+			require.Error(t, err)
+			assert.Equal(t, test.expected, err.Error())
+
+			var convErr *conv.Error
+			require.True(t, errors.As(err, &convErr))
+			assert.Equal(t, conv.ErrorCodePluralInlineName, convErr.Code)
+			assert.Contains(t, convErr.Err.Error(), "use singular form or $ref")
+		})
+	}
+}
+
+// panickingObserver panics when the given phase completes
+type panickingObserver struct {
+	phase conv.Phase
+}
+
+func (o panickingObserver) PhaseDone(phase conv.Phase, _ time.Duration) {
+	if phase == o.phase {
+		panic("unexpected state")
+	}
+}
+
+func (panickingObserver) ConversionDone(conv.ConversionStats, error) {}
+
+func TestConvertRecoversPanics(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+	for _, test := range []struct {
+		name  string
+		phase conv.Phase
+	}{
+		{name: "parse", phase: conv.PhaseParse},
+		{name: "build", phase: conv.PhaseBuild},
+		{name: "proto generation", phase: conv.PhaseProto},
+		{name: "go generation", phase: conv.PhaseGo},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				GoPackagePath: "github.com/example/go/v1",
+				Observer:      panickingObserver{phase: test.phase},
+			})
+			require.ErrorContains(t, err, "conversion panicked: unexpected state")
+
+			var convErr *conv.Error
+			require.True(t, errors.As(err, &convErr))
+			assert.Equal(t, conv.ErrorCodePanic, convErr.Code)
+		})
+	}
+}
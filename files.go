@@ -0,0 +1,22 @@
+package conv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ConvertFiles converts a spec split across files, reading the document at entrypoint and
+// following its $refs into other files to emit one proto package. Relative references
+// resolve against the directory of entrypoint unless opts.BaseDir is set, and must stay
+// inside that directory unless opts.RootDir names a wider one.
+func ConvertFiles(entrypoint string, opts ConvertOptions) (*ConvertResult, error) {
+	openapi, err := os.ReadFile(entrypoint)
+	if err != nil {
+		return nil, formatError(&Error{Code: ErrorCodeInvalidInput, Err: fmt.Errorf("failed to read entrypoint: %w", err)}, opts)
+	}
+	if opts.BaseDir == "" {
+		opts.BaseDir = filepath.Dir(entrypoint)
+	}
+	return Convert(openapi, opts)
+}
@@ -0,0 +1,128 @@
+package conv_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"api/openapi.yaml": `openapi: 3.0.0
+info:
+  title: Orders
+  version: 1.0.0
+paths:
+  /orders:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: 'schemas/orders.yaml#/Order'
+      responses:
+        '200':
+          description: OK
+components:
+  schemas:
+    Refund:
+      type: object
+      properties:
+        amount:
+          $ref: '../common/money.yaml#/Money'
+`,
+		"api/schemas/orders.yaml": `Order:
+  type: object
+  properties:
+    items:
+      type: array
+      items:
+        $ref: '#/LineItem'
+    total:
+      $ref: '../../common/money.yaml#/Money'
+LineItem:
+  type: object
+  properties:
+    sku:
+      type: string
+`,
+		"common/money.yaml": `Money:
+  type: object
+  properties:
+    cents:
+      type: integer
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	expected := `syntax = "proto3";
+
+package orders.v1;
+
+option go_package = "github.com/example/orders/v1";
+
+message Refund {
+  Money amount = 1 [json_name = "amount"];
+}
+
+message Order {
+  repeated LineItem items = 1 [json_name = "items"];
+  Money total = 2 [json_name = "total"];
+}
+
+message LineItem {
+  string sku = 1 [json_name = "sku"];
+}
+
+message Money {
+  int32 cents = 1 [json_name = "cents"];
+}
+
+`
+
+	for _, test := range []struct {
+		name       string
+		entrypoint string
+		rootDir    string
+		err        string
+	}{
+		{
+			name:       "reference graph",
+			entrypoint: filepath.Join(dir, "api", "openapi.yaml"),
+			rootDir:    dir,
+		},
+		{
+			name:       "reference outside the entrypoint directory",
+			entrypoint: filepath.Join(dir, "api", "openapi.yaml"),
+			err:        "'" + filepath.Join(dir, "common", "money.yaml") + "' is outside the directory '" + filepath.Join(dir, "api") + "'",
+		},
+		{
+			name:       "missing entrypoint",
+			entrypoint: filepath.Join(dir, "api", "missing.yaml"),
+			err:        "failed to read entrypoint",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.ConvertFiles(test.entrypoint, conv.ConvertOptions{
+				PackageName: "orders.v1",
+				PackagePath: "github.com/example/orders/v1",
+				RootDir:     test.rootDir,
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, expected, string(result.Protobuf))
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package conv_test
+
+import (
+	"errors"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSuggestedFixes(t *testing.T) {
+	header := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+`
+
+	for _, test := range []struct {
+		name     string
+		given    string
+		code     conv.ErrorCode
+		expected []conv.Fix
+	}{
+		{
+			name: "plural array items",
+			given: header + `    User:
+      type: object
+      properties:
+        addresses:
+          type: array
+          items:
+            type: object
+            properties:
+              street:
+                type: string
+`,
+			code: conv.ErrorCodePluralInlineName,
+			expected: []conv.Fix{
+				{
+					Op:   "add",
+					Path: "/components/schemas/Address",
+					Value: map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"street": map[string]any{"type": "string"}},
+					},
+				},
+				{
+					Op:    "replace",
+					Path:  "/components/schemas/User/properties/addresses/items",
+					Value: map[string]any{"$ref": "#/components/schemas/Address"},
+				},
+			},
+		},
+		{
+			name: "plural nested object with taken name",
+			given: header + `    Setting:
+      type: object
+      properties:
+        name:
+          type: string
+    User:
+      type: object
+      properties:
+        profile:
+          type: object
+          properties:
+            settings:
+              type: object
+              properties:
+                theme:
+                  type: string
+`,
+			code: conv.ErrorCodePluralInlineName,
+			expected: []conv.Fix{
+				{
+					Op:   "add",
+					Path: "/components/schemas/UserSetting",
+					Value: map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"theme": map[string]any{"type": "string"}},
+					},
+				},
+				{
+					Op:    "replace",
+					Path:  "/components/schemas/User/properties/profile/properties/settings",
+					Value: map[string]any{"$ref": "#/components/schemas/UserSetting"},
+				},
+			},
+		},
+		{
+			name: "mixed numbering",
+			given: header + `    User:
+      type: object
+      properties:
+        name:
+          type: string
+        id:
+          type: string
+          x-proto-number: 1
+        email:
+          type: string
+          x-proto-number: 3
+`,
+			code: conv.ErrorCodeMixedNumbering,
+			expected: []conv.Fix{
+				{Op: "add", Path: "/components/schemas/User/properties/name/x-proto-number", Value: 2},
+			},
+		},
+		{
+			name: "digit leading name",
+			given: header + `    User:
+      type: object
+      required: [name, 2faEnabled]
+      properties:
+        name:
+          type: string
+        2faEnabled:
+          type: boolean
+`,
+			code: conv.ErrorCodeInvalidFieldName,
+			expected: []conv.Fix{
+				{
+					Op:   "move",
+					From: "/components/schemas/User/properties/2faEnabled",
+					Path: "/components/schemas/User/properties/field_2faEnabled",
+				},
+				{Op: "replace", Path: "/components/schemas/User/required/1", Value: "field_2faEnabled"},
+			},
+		},
+		{
+			name: "no fix known",
+			given: header + `    Pet:
+      allOf:
+        - type: object
+`,
+			code: conv.ErrorCodeUnsupportedAllOf,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := conv.Convert([]byte(test.given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			require.Error(t, err)
+
+			var convErr *conv.Error
+			require.True(t, errors.As(err, &convErr))
+			assert.Equal(t, test.code, convErr.Code)
+			assert.Equal(t, test.expected, convErr.Fixes)
+		})
+	}
+}
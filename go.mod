@@ -6,6 +6,8 @@ require (
 	github.com/pb33f/libopenapi v0.28.2
 	github.com/stretchr/testify v1.11.1
 	go.yaml.in/yaml/v4 v4.0.0-rc.2
+	golang.org/x/sync v0.16.0
+	google.golang.org/protobuf v1.36.11
 )
 
 require (
@@ -0,0 +1,78 @@
+package conv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// goImportPathProblem describes why path cannot be imported by Go code, or returns an
+// empty string if it can. The rules follow those the go command applies to import paths.
+func goImportPathProblem(path string) string {
+	if strings.HasPrefix(path, "/") || strings.HasSuffix(path, "/") || strings.Contains(path, "//") {
+		return "has an empty path element"
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "." || elem == ".." {
+			return "cannot contain '.' or '..' elements"
+		}
+		if strings.HasPrefix(elem, ".") || strings.HasSuffix(elem, ".") {
+			return fmt.Sprintf("has element '%s' starting or ending with a dot", elem)
+		}
+		for _, r := range elem {
+			if !isImportPathChar(r) {
+				return fmt.Sprintf("contains %q, which is not allowed in a Go import path", r)
+			}
+		}
+	}
+	return ""
+}
+
+// isImportPathChar reports whether r may appear in an element of a Go import path
+func isImportPathChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		strings.ContainsRune("-._~+", r)
+}
+
+// goModulePath returns the module path declared by the go.mod file at path
+func goModulePath(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		module := fields[1]
+		if unquoted, err := strconv.Unquote(module); err == nil {
+			module = unquoted
+		}
+		return module, nil
+	}
+	return "", fmt.Errorf("no module directive")
+}
+
+// goModuleProblem describes why goPackagePath cannot be imported from within the module
+// declared by the go.mod file at modFile, or returns an empty string if it can
+func goModuleProblem(goPackagePath, modFile string) string {
+	module, err := goModulePath(modFile)
+	if err != nil {
+		return fmt.Sprintf("cannot read module path from '%s': %v", modFile, err)
+	}
+	if goPackagePath == module || strings.HasPrefix(goPackagePath, module+"/") {
+		return ""
+	}
+
+	elems := strings.Split(goPackagePath, "/")
+	return fmt.Sprintf("go package path '%s' is outside module '%s' declared in '%s', so the module "+
+		"cannot import the generated code; set GoPackagePath to a path inside the module, e.g. '%s/%s'",
+		goPackagePath, module, modFile, module, elems[len(elems)-1])
+}
@@ -0,0 +1,51 @@
+package conv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// HeaderData is the data ProtoHeader and GoHeader templates are executed with
+type HeaderData struct {
+	// Title, Version and Description come from the info object of the spec
+	Title       string
+	Version     string
+	Description string
+	// Source is Provenance.Source
+	Source string
+	// SHA256 is the hex encoded SHA-256 of the spec as given to Convert
+	SHA256 string
+	// Package is the proto package for ProtoHeader and the Go package name for GoHeader
+	Package string
+}
+
+// renderHeader executes a header template and returns the result ending in a blank line,
+// or an empty string if text is empty. Every non-blank line of the result must be a //
+// comment, so a header can never change the meaning of the file it starts.
+func renderHeader(name, text string, data HeaderData) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a valid template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s failed to render: %w", name, err)
+	}
+
+	header := strings.TrimRight(buf.String(), "\n")
+	for _, line := range strings.Split(header, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "//") {
+			return "", fmt.Errorf("%s line '%s' is not a // comment", name, line)
+		}
+	}
+	if header == "" {
+		return "", nil
+	}
+	return header + "\n\n", nil
+}
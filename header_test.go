@@ -0,0 +1,89 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertHeaders(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Pets
+  version: 2.1.0
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	for _, test := range []struct {
+		name        string
+		protoHeader string
+		goHeader    string
+		proto       string
+		golang      string
+		err         string
+	}{
+		{
+			name:   "none",
+			proto:  "syntax = \"proto3\";\n",
+			golang: "package types\n",
+		},
+		{
+			name:        "per output kind",
+			protoHeader: "// Copyright Example Inc.\n// {{.Title}} {{.Version}} ({{.Package}})\n",
+			goHeader:    "//go:build !legacy\n// Package {{.Package}} holds the {{.Title}} types",
+			proto:       "// Copyright Example Inc.\n// Pets 2.1.0 (api.v1)\n\nsyntax = \"proto3\";\n",
+			golang:      "//go:build !legacy\n// Package types holds the Pets types\n\npackage types\n",
+		},
+		{
+			name:        "unknown variable",
+			protoHeader: "// {{.Owner}}",
+			err:         "proto header failed to render",
+		},
+		{
+			name:     "not a comment",
+			goHeader: "{{.Title}}",
+			err:      "go header line 'Pets' is not a // comment",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:   "api.v1",
+				PackagePath:   "github.com/example/proto/v1",
+				GoPackagePath: "github.com/example/types/v1",
+				ProtoHeader:   test.protoHeader,
+				GoHeader:      test.goHeader,
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.proto, string(result.Protobuf[:len(test.proto)]))
+			assert.Equal(t, test.golang, string(result.Golang[:len(test.golang)]))
+		})
+	}
+}
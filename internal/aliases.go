@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+// findAliases returns the top-level schemas that are only a $ref to another component
+// schema, mapped to the schema the chain of references ends at. Aliases of oneOf schemas
+// and cyclic chains are left out, so they are built like other schemas.
+func findAliases(entries []*parser.SchemaEntry) map[string]string {
+	refs := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.Proxy.IsReference() {
+			continue
+		}
+		if target, err := extractReferenceName(entry.Proxy.GetReference()); err == nil {
+			refs[entry.Name] = target
+		}
+	}
+
+	aliases := make(map[string]string)
+	for _, entry := range entries {
+		target, ok := refs[entry.Name]
+		if !ok {
+			continue
+		}
+		seen := map[string]bool{entry.Name: true}
+		for next, ok := refs[target]; ok; next, ok = refs[target] {
+			if seen[next] {
+				target = ""
+				break
+			}
+			seen[target] = true
+			target = next
+		}
+		if target == "" {
+			continue
+		}
+		if schema := entry.Proxy.Schema(); schema == nil || len(schema.OneOf) > 0 {
+			continue
+		}
+		aliases[entry.Name] = target
+	}
+	return aliases
+}
+
+// commentAliases lists the aliases of each top-level message and enum in its description
+func commentAliases(ctx *Context) {
+	byTarget := make(map[string][]string)
+	for alias, target := range ctx.Aliases {
+		byTarget[target] = append(byTarget[target], alias)
+	}
+	for _, aliases := range byTarget {
+		slices.Sort(aliases)
+	}
+
+	note := func(description string, aliases []string) string {
+		line := "Aliases: " + strings.Join(aliases, ", ") + "."
+		if description == "" {
+			return line
+		}
+		return strings.TrimRight(description, "\n") + "\n\n" + line
+	}
+	for _, msg := range ctx.Messages {
+		if aliases, ok := byTarget[msg.OriginalSchema]; ok {
+			msg.Description = note(msg.Description, aliases)
+			delete(byTarget, msg.OriginalSchema)
+		}
+	}
+	for target, aliases := range byTarget {
+		for _, enum := range ctx.Enums {
+			if enum.Name == ToPascalCase(target) {
+				enum.Description = note(enum.Description, aliases)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const aliasSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Customer:
+      $ref: '#/components/schemas/Buyer'
+    Buyer:
+      $ref: '#/components/schemas/User'
+    User:
+      description: A registered user
+      type: object
+      properties:
+        name:
+          type: string
+    Order:
+      type: object
+      properties:
+        buyer:
+          $ref: '#/components/schemas/Customer'
+        sellers:
+          type: array
+          items:
+            $ref: '#/components/schemas/Buyer'
+`
+
+func TestConvertSchemaAliases(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		style    conv.SchemaAliases
+		expected string
+		aliasOf  string
+	}{
+		{
+			name:  "copy",
+			style: conv.SchemaAliasesCopy,
+			expected: `// A registered user
+message Customer {
+  string name = 1 [json_name = "name"];
+}
+
+// A registered user
+message Buyer {
+  string name = 1 [json_name = "name"];
+}
+
+// A registered user
+message User {
+  string name = 1 [json_name = "name"];
+}
+
+message Order {
+  Customer buyer = 1 [json_name = "buyer"];
+  repeated Buyer sellers = 2 [json_name = "sellers"];
+}
+`,
+		},
+		{
+			name:  "skip",
+			style: conv.SchemaAliasesSkip,
+			expected: `// A registered user
+message User {
+  string name = 1 [json_name = "name"];
+}
+
+message Order {
+  User buyer = 1 [json_name = "buyer"];
+  repeated User sellers = 2 [json_name = "sellers"];
+}
+`,
+			aliasOf: "testpkg.User",
+		},
+		{
+			name:  "comment",
+			style: conv.SchemaAliasesComment,
+			expected: `// A registered user
+//
+// Aliases: Buyer, Customer.
+message User {
+  string name = 1 [json_name = "name"];
+}
+
+message Order {
+  User buyer = 1 [json_name = "buyer"];
+  repeated User sellers = 2 [json_name = "sellers"];
+}
+`,
+			aliasOf: "testpkg.User",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(aliasSpec), conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				SchemaAliases: test.style,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+			for _, schema := range []string{"Customer", "Buyer"} {
+				assert.Equal(t, conv.TypeLocationProto, result.TypeMap[schema].Location)
+				assert.Equal(t, test.aliasOf, result.TypeMap[schema].AliasOf)
+			}
+
+			name, ok := result.ProtoTypeFor("Customer")
+			require.True(t, ok)
+			if test.aliasOf != "" {
+				assert.Equal(t, test.aliasOf, name)
+			}
+		})
+	}
+}
+
+func TestConvertSchemaAliasesEnum(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Code:
+      $ref: '#/components/schemas/Status'
+    Status:
+      type: integer
+      enum: [1, 2]
+    Reply:
+      type: object
+      properties:
+        code:
+          $ref: '#/components/schemas/Code'
+`
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		SchemaAliases: conv.SchemaAliasesComment,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Protobuf), `// Aliases: Code.
+enum Status {`)
+	assert.Contains(t, string(result.Protobuf), `Status code = 1 [json_name = "code"];`)
+	assert.NotContains(t, string(result.Protobuf), "enum Code")
+	assert.Equal(t, "testpkg.Status", result.TypeMap["Code"].AliasOf)
+}
+
+func TestConvertSchemaAliasesUnknown(t *testing.T) {
+	_, err := conv.Convert([]byte(aliasSpec), conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		SchemaAliases: "typedef",
+	})
+	require.ErrorContains(t, err, "unknown schema aliases style: typedef")
+}
@@ -0,0 +1,204 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertFlattenAllOf(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		schemas  string
+		expected string
+		err      string
+	}{
+		{
+			name: "base and extension",
+			schemas: `
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        age:
+          type: integer
+    Dog:
+      description: A dog
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          required: [breed]
+          properties:
+            breed:
+              type: string
+      properties:
+        barks:
+          type: boolean
+`,
+			expected: `message Pet {
+  string name = 1 [json_name = "name"];
+  int32 age = 2 [json_name = "age"];
+}
+
+// A dog
+message Dog {
+  string name = 1 [json_name = "name"];
+  int32 age = 2 [json_name = "age"];
+  string breed = 3 [json_name = "breed"];
+  bool barks = 4 [json_name = "barks"];
+}
+`,
+		},
+		{
+			name: "chained and repeated property",
+			schemas: `
+    Named:
+      type: object
+      properties:
+        name:
+          type: string
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - properties:
+            age:
+              type: integer
+    Dog:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - $ref: '#/components/schemas/Named'
+`,
+			expected: `message Dog {
+  string name = 1 [json_name = "name"];
+  int32 age = 2 [json_name = "age"];
+}
+`,
+		},
+		{
+			name: "wrapped reference",
+			schemas: `
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+    Post:
+      type: object
+      properties:
+        owner:
+          description: The owner of the post
+          nullable: true
+          allOf:
+            - $ref: '#/components/schemas/User'
+`,
+			expected: `message Post {
+  User owner = 1 [json_name = "owner"];
+}
+`,
+		},
+		{
+			name: "nullable object member",
+			schemas: `
+    Dog:
+      allOf:
+        - type: [object, "null"]
+          properties:
+            name:
+              type: string
+`,
+			expected: `message Dog {
+  string name = 1 [json_name = "name"];
+}
+`,
+		},
+		{
+			name: "allOf in an example",
+			schemas: `
+    Dog:
+      type: object
+      example:
+        allOf: [not a schema]
+      properties:
+        name:
+          type: string
+`,
+			expected: `message Dog {
+  string name = 1 [json_name = "name"];
+}
+`,
+		},
+		{
+			name: "conflicting property",
+			schemas: `
+    Dog:
+      allOf:
+        - properties:
+            id:
+              type: string
+        - properties:
+            id:
+              type: integer
+`,
+			err: "allOf at /components/schemas/Dog: member 0 and member 1 define property 'id' differently",
+		},
+		{
+			name: "non-object member",
+			schemas: `
+    Dog:
+      allOf:
+        - type: string
+`,
+			err: "allOf member at /components/schemas/Dog/allOf/0 has type 'string'; only object members can be merged",
+		},
+		{
+			name: "cycle",
+			schemas: `
+    Dog:
+      allOf:
+        - $ref: '#/components/schemas/Dog'
+`,
+			err: "allOf at /components/schemas/Dog references itself through /components/schemas/Dog",
+		},
+		{
+			name: "wrapped reference cycle",
+			schemas: `
+    Cat:
+      allOf:
+        - $ref: '#/components/schemas/Dog'
+    Dog:
+      description: A dog
+      allOf:
+        - $ref: '#/components/schemas/Cat'
+`,
+			err: "allOf at /components/schemas/Cat references itself through /components/schemas/Cat",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:` + test.schemas
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:  "testpkg",
+				PackagePath:  "github.com/example/proto/v1",
+				FlattenAllOf: true,
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				var convErr *conv.Error
+				require.ErrorAs(t, err, &convErr)
+				assert.Equal(t, conv.ErrorCodeUnsupportedAllOf, convErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
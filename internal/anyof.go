@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// AnyOfStrategy controls how properties using anyOf are converted. Empty rejects them.
+type AnyOfStrategy string
+
+const (
+	// AnyOfWidest collapses scalar members to the widest type accepting all of them
+	AnyOfWidest AnyOfStrategy = "widest"
+	// AnyOfAny maps the property to google.protobuf.Any
+	AnyOfAny AnyOfStrategy = "any"
+)
+
+// anyType is the well-known message holding anyOf properties with AnyOfAny
+const anyType = "google.protobuf.Any"
+
+// anyImport declares google.protobuf.Any
+const anyImport = "google/protobuf/any.proto"
+
+// anyOfProtoType returns the proto type of a property using anyOf according to
+// ctx.AnyOf, recording a warning when members are collapsed or mapped to
+// google.protobuf.Any
+func anyOfProtoType(schema *base.Schema, propertyName string, ctx *Context, parentMsg *ProtoMessage) (string, error) {
+	location := propertyName
+	if parentMsg != nil {
+		location = parentMsg.Name + "." + propertyName
+	}
+
+	if ctx.AnyOf == AnyOfAny {
+		ctx.AddImport(anyImport)
+		ctx.Warnings = append(ctx.Warnings, fmt.Sprintf("%s: anyOf mapped to %s, whose protobuf JSON wraps the value "+
+			"in an object with an @type field, unlike the OpenAPI JSON", location, anyType))
+		return anyType, nil
+	}
+
+	typ, format, members, collapsed, err := widestAnyOfType(schema, propertyName)
+	if err != nil {
+		return "", err
+	}
+	protoType, err := MapScalarType(ctx, typ, format)
+	if err != nil {
+		return "", err
+	}
+
+	if collapsed {
+		ctx.Warnings = append(ctx.Warnings, fmt.Sprintf("%s: anyOf of %s collapsed to %s",
+			location, strings.Join(members, ", "), protoType))
+	}
+	return protoType, nil
+}
+
+// widestAnyOfType returns the OpenAPI type and format accepting every member of an
+// anyOf, the member types in order and whether more than one member was collapsed.
+// Booleans and numbers only widen among themselves, anything else mixed with them
+// becomes a string. Members that only allow null are skipped; they make the property
+// nullable instead, see isNullable.
+func widestAnyOfType(schema *base.Schema, propertyName string) (string, string, []string, bool, error) {
+	var members []string
+	seen := make(map[string]bool)
+	int64Member := false
+	count := 0
+	var last *base.Schema
+	for i, proxy := range schema.AnyOf {
+		member := proxy.Schema()
+		if isNullSchema(member) {
+			continue
+		}
+		count++
+		last = member
+		typ := ""
+		if member != nil {
+			typ = nonNullType(member.Type)
+		}
+		switch typ {
+		case "string", "integer", "number", "boolean":
+		default:
+			return "", "", nil, false, Errorf(CodeUnsupportedAnyOf,
+				"property '%s' uses 'anyOf' with non-scalar member %d, which cannot be collapsed", propertyName, i)
+		}
+		if typ == "integer" && member.Format == "int64" {
+			int64Member = true
+		}
+		if !seen[typ] {
+			seen[typ] = true
+			members = append(members, typ)
+		}
+	}
+
+	switch {
+	case count == 0:
+		return "", "", nil, false, Errorf(CodeUnsupportedAnyOf,
+			"property '%s' uses 'anyOf' whose members only allow null", propertyName)
+	case count == 1:
+		// Only null was added to the member, so it keeps its own type and format
+		return members[0], last.Format, members, false, nil
+	case len(seen) == 1 && seen["boolean"]:
+		return "boolean", "", members, true, nil
+	case len(seen) == 1 && seen["integer"] && int64Member:
+		return "integer", "int64", members, true, nil
+	case len(seen) == 1 && seen["integer"]:
+		return "integer", "", members, true, nil
+	case !seen["string"] && !seen["boolean"]:
+		return "number", "double", members, true, nil
+	}
+	return "string", "", members, true, nil
+}
+
+// isNullSchema reports whether schema only allows null, e.g. {type: "null"}
+func isNullSchema(schema *base.Schema) bool {
+	if schema == nil || len(schema.Type) == 0 {
+		return false
+	}
+	for _, typ := range schema.Type {
+		if !strings.EqualFold(typ, "null") {
+			return false
+		}
+	}
+	return true
+}
+
+// nonNullType returns the single non-null entry of a type list, or an empty string
+func nonNullType(types []string) string {
+	typ := ""
+	for _, t := range types {
+		if strings.EqualFold(t, "null") {
+			continue
+		}
+		if typ != "" {
+			return ""
+		}
+		typ = t
+	}
+	return typ
+}
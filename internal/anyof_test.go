@@ -0,0 +1,176 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAnyOfStrategy(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		strategy conv.AnyOfStrategy
+		nullable conv.NullableFields
+		members  string
+		field    string
+		warnings []string
+		err      string
+	}{
+		{
+			name: "rejected by default",
+			members: `
+            - type: string
+            - type: integer`,
+			err: "property 'value' uses 'anyOf' which is not supported",
+		},
+		{
+			name:     "widest string",
+			strategy: conv.AnyOfStrategyWidest,
+			members: `
+            - type: string
+            - type: integer`,
+			field:    `string value = 1 [json_name = "value"];`,
+			warnings: []string{"Setting.value: anyOf of string, integer collapsed to string"},
+		},
+		{
+			name:     "widest integer",
+			strategy: conv.AnyOfStrategyWidest,
+			members: `
+            - type: integer
+            - type: integer
+              format: int64`,
+			field:    `int64 value = 1 [json_name = "value"];`,
+			warnings: []string{"Setting.value: anyOf of integer collapsed to int64"},
+		},
+		{
+			name:     "widest number",
+			strategy: conv.AnyOfStrategyWidest,
+			members: `
+            - type: integer
+            - type: number
+              format: float`,
+			field:    `double value = 1 [json_name = "value"];`,
+			warnings: []string{"Setting.value: anyOf of integer, number collapsed to double"},
+		},
+		{
+			name:     "widest with object member",
+			strategy: conv.AnyOfStrategyWidest,
+			members: `
+            - type: string
+            - $ref: '#/components/schemas/Other'`,
+			err: "property 'value' uses 'anyOf' with non-scalar member 1, which cannot be collapsed",
+		},
+		{
+			name:     "any",
+			strategy: conv.AnyOfStrategyAny,
+			members: `
+            - type: string
+            - $ref: '#/components/schemas/Other'`,
+			field: `google.protobuf.Any value = 1 [json_name = "value"];`,
+			warnings: []string{"Setting.value: anyOf mapped to google.protobuf.Any, whose protobuf JSON wraps " +
+				"the value in an object with an @type field, unlike the OpenAPI JSON"},
+		},
+		{
+			name:     "widest nullable",
+			strategy: conv.AnyOfStrategyWidest,
+			nullable: conv.NullableFieldsOptional,
+			members: `
+            - type: string
+            - type: "null"`,
+			field: `optional string value = 1 [json_name = "value"];`,
+		},
+		{
+			name:     "widest nullable keeps format",
+			strategy: conv.AnyOfStrategyWidest,
+			nullable: conv.NullableFieldsWrappers,
+			members: `
+            - type: "null"
+            - type: integer
+              format: int64`,
+			field: `google.protobuf.Int64Value value = 1 [json_name = "value"];`,
+		},
+		{
+			name:     "widest nullable collapsed",
+			strategy: conv.AnyOfStrategyWidest,
+			members: `
+            - type: string
+            - type: integer
+            - type: "null"`,
+			field:    `string value = 1 [json_name = "value"];`,
+			warnings: []string{"Setting.value: anyOf of string, integer collapsed to string"},
+		},
+		{
+			name:     "widest only null",
+			strategy: conv.AnyOfStrategyWidest,
+			members: `
+            - type: "null"`,
+			err: "property 'value' uses 'anyOf' whose members only allow null",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.1.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Setting:
+      type: object
+      properties:
+        value:
+          anyOf:` + test.members + `
+    Other:
+      type: object
+      properties:
+        name:
+          type: string
+`
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:    "testpkg",
+				PackagePath:    "github.com/example/proto/v1",
+				AnyOfStrategy:  test.strategy,
+				NullableFields: test.nullable,
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				var convErr *conv.Error
+				require.True(t, errors.As(err, &convErr))
+				assert.Equal(t, conv.ErrorCodeUnsupportedAnyOf, convErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.field)
+			assert.Equal(t, test.warnings, result.Warnings)
+
+			_, err = result.FileDescriptor()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConvertAnyOfAnyImport(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Setting:
+      type: object
+      properties:
+        value:
+          anyOf:
+            - type: string
+            - type: boolean
+`
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		AnyOfStrategy: conv.AnyOfStrategyAny,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Protobuf), `import "google/protobuf/any.proto";`)
+}
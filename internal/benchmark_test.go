@@ -0,0 +1,117 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/duh-rpc/openapi-proto.go/benchmarks"
+	"github.com/duh-rpc/openapi-proto.go/internal"
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+func BenchmarkUniqueName(b *testing.B) {
+	names := make([]string, 1000)
+	for i := range names {
+		names[i] = fmt.Sprintf("field%d", i)
+	}
+
+	for _, test := range []struct {
+		name    string
+		repeats int
+	}{
+		{name: "distinct", repeats: 1},
+		{name: "conflicting", repeats: 4},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tracker := internal.NewNameTracker()
+				for r := 0; r < test.repeats; r++ {
+					for _, name := range names {
+						tracker.UniqueName(name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkConvertWideSchemas(b *testing.B) {
+	for _, test := range []struct {
+		name    string
+		schemas int
+		fields  int
+	}{
+		{name: "many small messages", schemas: 2000, fields: 10},
+		{name: "few wide messages", schemas: 20, fields: 1000},
+	} {
+		given := wideSpec(test.schemas, test.fields)
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := conv.Convert(given, conv.ConvertOptions{
+					PackageName: "testpkg",
+					PackagePath: "github.com/example/proto/v1",
+				}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// wideSpec builds schemas object schemas with fields properties each, every fourth
+// property holding a small inline object
+func wideSpec(schemas, fields int) []byte {
+	var spec strings.Builder
+	spec.WriteString(`openapi: 3.0.0
+info:
+  title: Wide API
+  version: 1.0.0
+components:
+  schemas:
+`)
+	for i := 0; i < schemas; i++ {
+		spec.WriteString(fmt.Sprintf("    Schema%d:\n      type: object\n      properties:\n", i))
+		for j := 0; j < fields; j++ {
+			if j%4 == 0 {
+				spec.WriteString(fmt.Sprintf("        detail%d:\n          type: object\n          properties:\n            note:\n              type: string\n", j))
+				continue
+			}
+			spec.WriteString(fmt.Sprintf("        field%d:\n          type: string\n", j))
+		}
+	}
+	return []byte(spec.String())
+}
+
+// BenchmarkBuildAndGenerate measures the builder and proto generator alone. The spec is
+// parsed once and converted before the timer starts, so libopenapi has built every schema
+// and the allocations reported are those of BuildMessages and Generate.
+func BenchmarkBuildAndGenerate(b *testing.B) {
+	doc, err := parser.ParseDocument(benchmarks.Medium())
+	if err != nil {
+		b.Fatal(err)
+	}
+	schemas, err := doc.Schemas()
+	if err != nil {
+		b.Fatal(err)
+	}
+	build := func() {
+		ctx := internal.NewContext()
+		if _, err := internal.BuildMessages(schemas, ctx); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := internal.Generate("benchpkg", "github.com/example/proto/v1", ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+	build()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		build()
+	}
+}
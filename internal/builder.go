@@ -2,22 +2,115 @@ package internal
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/duh-rpc/openapi-proto.go/internal/parser"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
 )
 
 // Context holds state during conversion
 type Context struct {
-	Tracker       *NameTracker
-	Messages      []*ProtoMessage
-	Enums         []*ProtoEnum
-	Definitions   []interface{} // Mixed enums and messages in processing order
-	UsesTimestamp bool
+	Tracker                *NameTracker
+	Messages               []*ProtoMessage
+	Enums                  []*ProtoEnum
+	Definitions            []interface{} // Mixed enums and messages in processing order
+	UsesTimestamp          bool
+	Servers                []*parser.ServerEntry        // Rendered as a file comment
+	Callbacks              []*parser.CallbackEntry      // Rendered as a commented service after the definitions
+	CallbackTypes          map[string]string            // Proto messages of callback request schemas, by schema name
+	Format                 Format                       // Layout of the generated proto file
+	Imports                []string                     // Additional imports required by field options
+	FileOptions            []FileOption                 // Rendered after go_package
+	ImportRewrites         map[string]string            // Replacement import paths keyed by path or "prefix/*"
+	ImportKinds            map[string]string            // "public" or "weak" modifiers keyed by rewritten import path
+	InlineEnums            map[*ProtoEnum]*ProtoMessage // Enums built from inline properties and the message declaring them
+	Limits                 Limits                       // Bounds on the messages built from a spec
+	ProtoOneof             bool                         // Build oneOf schemas as messages with a oneof instead of Go
+	ExampleOption          string                       // Custom field option carrying property examples, empty if disabled
+	ExampleImport          string                       // Proto file declaring ExampleOption
+	AnyOf                  AnyOfStrategy                // How properties using anyOf are converted, empty rejects them
+	NullableOptional       bool                         // Declare nullable scalar fields optional
+	NullableWrappers       bool                         // Use wrapper messages for nullable scalar fields
+	OptionalUnlessRequired bool                         // Declare scalar fields not listed in required optional
+	UnsignedFromMinimum    bool                         // Map integers with a non-negative minimum to unsigned types
+	SkipAliases            bool                         // Build no definitions for schemas that are only a $ref
+	CommentAliases         bool                         // List skipped aliases in the comment of their target
+	Aliases                map[string]string            // Skipped aliases mapped to the schema they reference
+	Warnings               []string                     // Adjustments callers should review, e.g. collapsed anyOf members
+	Pointer                string                       // JSON pointer of the schema or property being built
+
+	depth    int                     // Current inline object nesting depth
+	messages int                     // Messages built so far, including nested ones
+	refs     map[string]*resolvedRef // Resolved $ref targets by reference string
+	names    map[string]string       // Names reserved for component definitions, by schema name
+	enums    map[string]*ProtoEnum   // Component integer enums built so far, by schema name
+
+	components map[string]*base.SchemaProxy // Component schema entries by name
 }
 
+// Limits bounds the messages built from a spec. Zero values mean no limit.
+type Limits struct {
+	MaxDepth    int // Maximum inline object nesting depth below a top-level message
+	MaxMessages int // Maximum number of messages, including nested ones
+}
+
+// countMessage records a new message and fails once the MaxMessages limit is exceeded
+func (c *Context) countMessage() error {
+	c.messages++
+	if c.Limits.MaxMessages > 0 && c.messages > c.Limits.MaxMessages {
+		return Errorf(CodeLimitExceeded, "generated message count exceeds limit of %d", c.Limits.MaxMessages)
+	}
+	return nil
+}
+
+// reserveName returns the proto name of the definition for a component schema,
+// claiming a unique one on first use
+func (c *Context) reserveName(schemaName string) string {
+	if name, ok := c.names[schemaName]; ok {
+		return name
+	}
+	name := c.Tracker.UniqueName(ToPascalCase(schemaName))
+	c.names[schemaName] = name
+	return name
+}
+
+// AddImport records an import required by the generated proto, ignoring duplicates
+func (c *Context) AddImport(path string) {
+	for _, existing := range c.Imports {
+		if existing == path {
+			return
+		}
+	}
+	c.Imports = append(c.Imports, path)
+}
+
+// Format controls the layout of the generated proto file
+type Format struct {
+	FieldOrder             FieldOrder
+	IndentWidth            int // Spaces per nesting level, 0 means 2
+	BlankLineBetweenFields bool
+	SingleTrailingNewline  bool // End the file with one newline instead of a blank line
+	MaxCommentWidth        int  // Wrap comment lines longer than this, 0 disables wrapping
+	BufFormat              bool // Post-process into the canonical buf format style
+	Anchors                bool // Wrap top-level definitions in begin/end marker comments
+}
+
+// FieldOrder controls the order fields are rendered within a message. Field numbers
+// are assigned before ordering and never change. The values match conv.FieldOrder.
+type FieldOrder string
+
+const (
+	// FieldOrderSpec renders fields in the order the properties appear in the spec
+	FieldOrderSpec FieldOrder = "spec"
+	// FieldOrderByNumber renders fields sorted by field number
+	FieldOrderByNumber FieldOrder = "number"
+	// FieldOrderAlphabetical renders fields sorted by field name
+	FieldOrderAlphabetical FieldOrder = "alphabetical"
+)
+
 // NewContext creates a new conversion context
 func NewContext() *Context {
 	return &Context{
@@ -26,6 +119,10 @@ func NewContext() *Context {
 		Enums:         []*ProtoEnum{},
 		Definitions:   []interface{}{},
 		UsesTimestamp: false,
+		InlineEnums:   make(map[*ProtoEnum]*ProtoMessage),
+		refs:          make(map[string]*resolvedRef),
+		names:         make(map[string]string),
+		enums:         make(map[string]*ProtoEnum),
 	}
 }
 
@@ -35,18 +132,29 @@ type ProtoMessage struct {
 	Description    string
 	Fields         []*ProtoField
 	Nested         []*ProtoMessage
-	OriginalSchema string // Original schema name before name tracker renaming
+	Enums          []*ProtoEnum // Enums declared inside this message
+	OriginalSchema string       // Original schema name before name tracker renaming
+	// ReservedNumbers and ReservedNames hold fields removed since a previous conversion
+	ReservedNumbers []int
+	ReservedNames   []string
+	Notes           []string // Rendered as NOTE comments before the message
+	Todo            string   // Rendered as a TODO comment before the message
+	Oneof           string   // Name of a oneof block enclosing every field, empty if none
 }
 
 // ProtoField represents a proto3 field
 type ProtoField struct {
 	Name        string
-	Type        string
+	Type        string // Value type for map fields
+	MapKey      string // Key type of a map field, empty for other fields
 	Number      int
 	JSONName    string
 	Description string
 	Repeated    bool
+	Optional    bool // Rendered with the proto3 optional label
 	EnumValues  []string
+	Options     []string // Field options rendered after json_name, e.g. (google.api.field_behavior) = REQUIRED
+	Notes       []string // Rendered as NOTE comments before the field
 }
 
 // ProtoEnum represents a proto3 enum definition
@@ -65,9 +173,17 @@ type ProtoEnumValue struct {
 // BuildMessages processes all schemas and returns messages and dependency graph
 func BuildMessages(entries []*parser.SchemaEntry, ctx *Context) (*DependencyGraph, error) {
 	graph := NewDependencyGraph()
+	ctx.components = make(map[string]*base.SchemaProxy, len(entries))
+	for _, entry := range entries {
+		ctx.components[entry.Name] = entry.Proxy
+	}
+	if ctx.SkipAliases {
+		ctx.Aliases = findAliases(entries)
+	}
 
 	// First pass: Add all schemas to graph and detect unions
 	for _, entry := range entries {
+		ctx.Pointer = schemaPointer(entry.Name)
 		if err := graph.AddSchema(entry.Name, entry.Proxy); err != nil {
 			return nil, err
 		}
@@ -83,21 +199,40 @@ func BuildMessages(entries []*parser.SchemaEntry, ctx *Context) (*DependencyGrap
 		}
 
 		// Detect oneOf and mark as union
-		if len(schema.OneOf) > 0 {
+		if len(schema.OneOf) > 0 && !ctx.ProtoOneof {
 			variants := extractVariantNames(schema.OneOf)
 			graph.MarkUnion(entry.Name, "contains oneOf", variants)
 		}
 	}
 
+	// Reserve the names of component definitions in spec order before any is built, so
+	// hoisted inline enums cannot take them and references resolve to them
+	for _, entry := range entries {
+		if definesTopLevelType(entry, ctx) {
+			ctx.reserveName(entry.Name)
+		}
+	}
+
 	// Second pass: Build messages and track dependencies
 	for _, entry := range entries {
+		ctx.Pointer = schemaPointer(entry.Name)
 		schema := entry.Proxy.Schema()
 		if schema == nil {
 			continue
 		}
 
-		// Skip oneOf schemas for now (will be handled as Go code in later phases)
+		// References to aliases resolve to their target
+		if _, ok := ctx.Aliases[entry.Name]; ok {
+			continue
+		}
+
+		// oneOf schemas are generated as Go code unless built as proto oneofs
 		if len(schema.OneOf) > 0 {
+			if ctx.ProtoOneof {
+				if _, err := buildOneofMessage(entry.Name, schema, ctx, graph); err != nil {
+					return nil, err
+				}
+			}
 			continue
 		}
 
@@ -113,7 +248,7 @@ func BuildMessages(entries []*parser.SchemaEntry, ctx *Context) (*DependencyGrap
 				continue
 			}
 			// Only build enum for integer enums
-			_, err := buildEnum(entry.Name, entry.Proxy, ctx)
+			_, err := buildComponentEnum(entry.Name, entry.Proxy, ctx)
 			if err != nil {
 				return nil, err
 			}
@@ -125,22 +260,42 @@ func BuildMessages(entries []*parser.SchemaEntry, ctx *Context) (*DependencyGrap
 			return nil, err
 		}
 	}
+
+	if ctx.CommentAliases {
+		commentAliases(ctx)
+	}
 	return graph, nil
 }
 
+// definesTopLevelType reports whether the second pass of BuildMessages builds a
+// top-level message or enum for entry
+func definesTopLevelType(entry *parser.SchemaEntry, ctx *Context) bool {
+	schema := entry.Proxy.Schema()
+	if schema == nil {
+		return false
+	}
+	if _, ok := ctx.Aliases[entry.Name]; ok {
+		return false
+	}
+	if len(schema.OneOf) > 0 {
+		return ctx.ProtoOneof
+	}
+	return !isStringEnum(schema)
+}
+
 // buildMessage creates a protoMessage from an OpenAPI schema
 func buildMessage(name string, proxy *base.SchemaProxy, ctx *Context, graph *DependencyGraph) (*ProtoMessage, error) {
 	schema := proxy.Schema()
 	if schema == nil {
 		if err := proxy.GetBuildError(); err != nil {
-			return nil, SchemaError(name, fmt.Sprintf("failed to resolve schema: %v", err))
+			return nil, WithCode(CodeInvalidReference, SchemaError(name, fmt.Sprintf("failed to resolve schema: %v", err)))
 		}
 		return nil, SchemaError(name, "schema is nil")
 	}
 
 	// Check if it's an object type
 	if len(schema.Type) == 0 || !contains(schema.Type, "object") {
-		return nil, SchemaError(name, "only objects and enums supported at top level")
+		return nil, WithCode(CodeUnsupportedType, SchemaError(name, "only objects and enums supported at top level"))
 	}
 
 	// Validate field numbers before processing
@@ -148,34 +303,45 @@ func buildMessage(name string, proxy *base.SchemaProxy, ctx *Context, graph *Dep
 		return nil, err
 	}
 
+	if err := ctx.countMessage(); err != nil {
+		return nil, WrapSchemaError(name, err)
+	}
+
 	msg := &ProtoMessage{
-		Name:           ctx.Tracker.UniqueName(ToPascalCase(name)),
+		Name:           ctx.reserveName(name),
 		Description:    schema.Description,
-		Fields:         []*ProtoField{},
+		Fields:         make([]*ProtoField, 0, propertyCount(schema)),
 		Nested:         []*ProtoMessage{},
 		OriginalSchema: name,
 	}
 
-	fieldTracker := NewNameTracker()
+	fieldTracker := acquireNameTracker()
+	defer releaseNameTracker(fieldTracker)
 
 	// Process properties in YAML order
 	if schema.Properties != nil {
-		fieldNumber := 1
+		// Validated with the field numbers above
+		fieldNumber, stride, _ := fieldNumbering(schema)
+		fields := make([]ProtoField, 0, propertyCount(schema))
+		propertyPointer := schemaPointer(name) + "/properties/"
 		for propName, propProxy := range schema.Properties.FromOldest() {
-			propSchema := propProxy.Schema()
+			ctx.Pointer = propertyPointer + escapePointer(propName)
+			propSchema := ctx.schemaOf(propProxy)
 			if propSchema == nil {
 				return nil, PropertyError(name, propName, "has nil schema")
 			}
 
 			// Track dependency if property references another schema
 			if propProxy.IsReference() {
-				ref := propProxy.GetReference()
-				parts := strings.Split(ref, "/")
-				if len(parts) > 0 {
-					refName := parts[len(parts)-1]
-					if refName != "" {
-						graph.AddDependency(name, refName)
-					}
+				if refName := ctx.refName(propProxy.GetReference()); refName != "" {
+					graph.AddDependency(name, refName)
+				}
+			}
+
+			// Track dependencies in map values
+			if isMapSchema(propSchema) && propSchema.AdditionalProperties.A.IsReference() {
+				if refName := ctx.refName(propSchema.AdditionalProperties.A.GetReference()); refName != "" {
+					graph.AddDependency(name, refName)
 				}
 			}
 
@@ -184,13 +350,8 @@ func buildMessage(name string, proxy *base.SchemaProxy, ctx *Context, graph *Dep
 				if propSchema.Items != nil && propSchema.Items.A != nil {
 					itemProxy := propSchema.Items.A
 					if itemProxy.IsReference() {
-						ref := itemProxy.GetReference()
-						parts := strings.Split(ref, "/")
-						if len(parts) > 0 {
-							refName := parts[len(parts)-1]
-							if refName != "" {
-								graph.AddDependency(name, refName)
-							}
+						if refName := ctx.refName(itemProxy.GetReference()); refName != "" {
+							graph.AddDependency(name, refName)
 						}
 					}
 				}
@@ -198,7 +359,7 @@ func buildMessage(name string, proxy *base.SchemaProxy, ctx *Context, graph *Dep
 
 			sanitizedName, err := SanitizeFieldName(propName)
 			if err != nil {
-				return nil, PropertyError(name, propName, err.Error())
+				return nil, WrapPropertyError(name, propName, err)
 			}
 			protoFieldName := fieldTracker.UniqueName(sanitizedName)
 			protoType, repeated, enumValues, err := ProtoType(propSchema, propName, propProxy, ctx, msg)
@@ -207,13 +368,13 @@ func buildMessage(name string, proxy *base.SchemaProxy, ctx *Context, graph *Dep
 				if strings.Contains(err.Error(), fmt.Sprintf("property '%s'", propName)) {
 					return nil, fmt.Errorf("schema '%s': %w", name, err)
 				}
-				return nil, PropertyError(name, propName, err.Error())
+				return nil, WrapPropertyError(name, propName, err)
 			}
 
 			// For inline objects and integer enums, description goes to the nested type, not the field
 			// For string enums, keep description on field (not hoisted)
 			fieldDescription := propSchema.Description
-			if len(propSchema.Type) > 0 && contains(propSchema.Type, "object") {
+			if len(propSchema.Type) > 0 && contains(propSchema.Type, "object") && !isMapSchema(propSchema) {
 				fieldDescription = ""
 			}
 			if isIntegerEnum(propSchema) {
@@ -227,21 +388,31 @@ func buildMessage(name string, proxy *base.SchemaProxy, ctx *Context, graph *Dep
 				actualFieldNumber = customFieldNum
 			}
 
-			field := &ProtoField{
+			options, err := fieldOptions(propProxy, ctx)
+			if err != nil {
+				return nil, WrapPropertyError(name, propName, err)
+			}
+
+			fieldType := nullableType(ctx, propProxy, propSchema, protoType, repeated)
+			fields = append(fields, ProtoField{
 				Name:        protoFieldName,
-				Type:        protoType,
+				Type:        fieldType,
+				MapKey:      mapKey(propSchema),
 				Number:      actualFieldNumber,
 				Description: fieldDescription,
 				Repeated:    repeated,
+				Optional:    optionalField(ctx, propProxy, propSchema, fieldType, repeated, slices.Contains(schema.Required, propName)),
 				JSONName:    propName,
 				EnumValues:  enumValues,
-			}
+				Options:     options,
+			})
 
-			msg.Fields = append(msg.Fields, field)
+			// The fields share one allocation, sized so appending never moves them
+			msg.Fields = append(msg.Fields, &fields[len(fields)-1])
 
 			// Only increment auto-counter if we didn't use a custom number
 			if !hasCustomNum {
-				fieldNumber++
+				fieldNumber += stride
 			}
 		}
 	}
@@ -251,6 +422,14 @@ func buildMessage(name string, proxy *base.SchemaProxy, ctx *Context, graph *Dep
 	return msg, nil
 }
 
+// propertyCount returns the number of properties of a schema, to size field slices
+func propertyCount(schema *base.Schema) int {
+	if schema.Properties == nil {
+		return 0
+	}
+	return schema.Properties.Len()
+}
+
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -305,18 +484,18 @@ func validateEnumSchema(schema *base.Schema, schemaName string) error {
 
 	// Check for explicit type field
 	if len(schema.Type) == 0 {
-		return fmt.Errorf("schema '%s': enum must have explicit type field", schemaName)
+		return Errorf(CodeInvalidEnum, "schema '%s': enum must have explicit type field", schemaName)
 	}
 
 	// Check for null values and mixed types
 	var hasString, hasInteger bool
 	for _, value := range schema.Enum {
 		if value == nil || value.Value == "" {
-			return fmt.Errorf("schema '%s': enum cannot contain null values", schemaName)
+			return Errorf(CodeInvalidEnum, "schema '%s': enum cannot contain null values", schemaName)
 		}
 
 		// Check if value looks like an integer
-		if _, err := fmt.Sscanf(value.Value, "%d", new(int)); err == nil {
+		if startsWithInteger(value.Value) {
 			hasInteger = true
 		} else {
 			hasString = true
@@ -325,24 +504,31 @@ func validateEnumSchema(schema *base.Schema, schemaName string) error {
 
 	// Check for mixed types
 	if hasString && hasInteger {
-		return fmt.Errorf("schema '%s': enum contains mixed types (string and integer)", schemaName)
+		return Errorf(CodeInvalidEnum, "schema '%s': enum contains mixed types (string and integer)", schemaName)
 	}
 
 	return nil
 }
 
-// extractFieldNumber extracts x-proto-number from schema proxy extensions
+// startsWithInteger reports whether text begins with a decimal integer after optional
+// spaces and sign, the values fmt.Sscanf accepts for %d
+func startsWithInteger(text string) bool {
+	text = strings.TrimLeft(text, " \t\r\n")
+	if text != "" && (text[0] == '+' || text[0] == '-') {
+		text = text[1:]
+	}
+	return text != "" && text[0] >= '0' && text[0] <= '9'
+}
+
+// extractFieldNumber extracts x-proto-number from a property. The extension is read from
+// the property itself, so it may sit next to a $ref and is never taken from the schema
+// the $ref points to.
 // Returns (number, true, nil) if found and valid
 // Returns (0, false, nil) if not present
 // Returns (0, false, error) if present but invalid format
 func extractFieldNumber(proxy *base.SchemaProxy) (int, bool, error) {
-	schema := proxy.Schema()
-	if schema == nil || schema.Extensions == nil {
-		return 0, false, nil
-	}
-
-	node, found := schema.Extensions.Get("x-proto-number")
-	if !found || node == nil {
+	node := fieldNumberNode(proxy)
+	if node == nil {
 		return 0, false, nil
 	}
 
@@ -350,42 +536,117 @@ func extractFieldNumber(proxy *base.SchemaProxy) (int, bool, error) {
 	// This properly rejects decimals like "3.14" unlike fmt.Sscanf
 	num, err := strconv.Atoi(node.Value)
 	if err != nil {
-		return 0, false, fmt.Errorf("x-proto-number must be a valid integer, got: %s", node.Value)
+		return 0, false, Errorf(CodeInvalidFieldNumber, "x-proto-number must be a valid integer, got: %s", node.Value)
 	}
 
 	return num, true, nil
 }
 
-// validateFieldNumbers validates x-proto-number extensions on schema properties
-// Returns error if:
-// - Field numbers are duplicated
-// - Field numbers are out of valid range (1 to 536,870,911)
-// - Field numbers use reserved range (19000-19999)
-// - Field number is 0 (invalid)
-// - Some but not all fields have x-proto-number (all-or-nothing violation)
+// fieldNumberNode returns the x-proto-number value of a property, or nil if it has none
+func fieldNumberNode(proxy *base.SchemaProxy) *yaml.Node {
+	if proxy.IsReference() {
+		// The value node is the resolved target; the reference node holds the siblings
+		node := proxy.GetReferenceNode()
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "x-proto-number" {
+				return node.Content[i+1]
+			}
+		}
+		return nil
+	}
+
+	schema := proxy.Schema()
+	if schema == nil || schema.Extensions == nil {
+		return nil
+	}
+	node, found := schema.Extensions.Get("x-proto-number")
+	if !found {
+		return nil
+	}
+	return node
+}
+
+// nestedNumberingScope explains in errors that the all-or-nothing rule is per message
+const nestedNumberingScope = "inline objects and array items are numbered separately from their parent"
+
+// validateFieldNumbers validates x-proto-number extensions on the properties of a
+// top-level schema. The same rules apply to every message, nested or not; see
+// validateNestedFieldNumbers. Returns error if:
+//   - Field numbers are duplicated
+//   - Field numbers are out of valid range (1 to 536,870,911)
+//   - Field numbers use reserved range (19000-19999)
+//   - Field number is 0 (invalid)
+//   - Some but not all fields have x-proto-number (all-or-nothing violation)
+//   - x-proto-number-start or x-proto-number-stride is invalid, is combined with
+//     x-proto-number, or assigns a number out of range or in the reserved range
 func validateFieldNumbers(schema *base.Schema, schemaName string) error {
-	if schema == nil || schema.Properties == nil {
+	propName, err := checkFieldNumbers(schema)
+	if err == nil {
 		return nil
 	}
+	if propName != "" {
+		return WrapPropertyError(schemaName, propName, err)
+	}
+	return WrapSchemaError(schemaName, err)
+}
+
+// validateNestedFieldNumbers validates x-proto-number extensions on the properties of
+// an inline object or array item schema. Each nested message is checked on its own, so
+// its parent may number its fields while it does not, or the other way around. Errors
+// carry no schema context; the caller adds the property path.
+func validateNestedFieldNumbers(schema *base.Schema) error {
+	propName, err := checkFieldNumbers(schema)
+	if err == nil {
+		return nil
+	}
+	if propName != "" {
+		return fmt.Errorf("property '%s': %w", propName, err)
+	}
+	if codeOf(err) == CodeMixedNumbering {
+		return fmt.Errorf("%w; %s", err, nestedNumberingScope)
+	}
+	return err
+}
+
+// checkFieldNumbers applies the x-proto-number rules to the properties of one message
+// and returns the first violation, with the property it concerns or "" if it concerns
+// the message as a whole
+func checkFieldNumbers(schema *base.Schema) (string, error) {
+	if schema == nil || schema.Properties == nil {
+		return "", nil
+	}
 
 	// Return nil if schema has 0 properties
 	if schema.Properties.Len() == 0 {
-		return nil
+		return "", nil
 	}
 
 	// First pass: check all-or-nothing rule
 	totalProps := schema.Properties.Len()
 	annotatedCount := 0
 	for _, propProxy := range schema.Properties.FromOldest() {
-		_, found, _ := extractFieldNumber(propProxy)
-		if found {
+		if fieldNumberNode(propProxy) != nil {
 			annotatedCount++
 		}
 	}
 
 	// Enforce all-or-nothing: if any field has x-proto-number, all must have it
 	if annotatedCount > 0 && annotatedCount < totalProps {
-		return SchemaError(schemaName, fmt.Sprintf("x-proto-number must be specified on all fields or none (found on %d of %d fields)", annotatedCount, totalProps))
+		return "", Errorf(CodeMixedNumbering, "x-proto-number must be specified on all fields or none (found on %d of %d fields)", annotatedCount, totalProps)
+	}
+
+	start, stride, err := fieldNumbering(schema)
+	if err != nil {
+		return "", err
+	}
+	if annotatedCount > 0 && (start != 1 || stride != 1) {
+		return "", Errorf(CodeMixedNumbering, "x-proto-number-start and x-proto-number-stride cannot be combined with x-proto-number on fields")
+	}
+	if annotatedCount == 0 {
+		return checkAutoNumbers(schema, start, stride)
 	}
 
 	// Track seen field numbers to detect duplicates
@@ -396,7 +657,7 @@ func validateFieldNumbers(schema *base.Schema, schemaName string) error {
 		// Extract field number
 		fieldNum, found, err := extractFieldNumber(propProxy)
 		if err != nil {
-			return PropertyError(schemaName, propName, err.Error())
+			return propName, err
 		}
 
 		// Skip properties without x-proto-number (all fields have none if we reach here)
@@ -405,42 +666,104 @@ func validateFieldNumbers(schema *base.Schema, schemaName string) error {
 		}
 
 		// Validate field number constraints
-		if fieldNum < 1 {
-			return PropertyError(schemaName, propName, "x-proto-number must be between 1 and 536870911")
-		}
-
-		if fieldNum > 536870911 {
-			return PropertyError(schemaName, propName, "x-proto-number must be between 1 and 536870911")
+		if fieldNum < 1 || fieldNum > 536870911 {
+			return propName, Errorf(CodeInvalidFieldNumber, "x-proto-number must be between 1 and 536870911")
 		}
 
 		// Check reserved range (19000-19999)
 		if fieldNum >= 19000 && fieldNum <= 19999 {
-			return PropertyError(schemaName, propName, fmt.Sprintf("x-proto-number %d is in reserved range 19000-19999", fieldNum))
+			return propName, Errorf(CodeReservedFieldNumber, "x-proto-number %d is in reserved range 19000-19999", fieldNum)
 		}
 
 		// Check for duplicates
 		if existingProp, exists := seen[fieldNum]; exists {
-			return SchemaError(schemaName, fmt.Sprintf("duplicate x-proto-number %d used by properties '%s' and '%s'", fieldNum, existingProp, propName))
+			return "", Errorf(CodeDuplicateFieldNumber, "duplicate x-proto-number %d used by properties '%s' and '%s'", fieldNum, existingProp, propName)
 		}
 
 		seen[fieldNum] = propName
 	}
 
-	return nil
+	return "", nil
+}
+
+// fieldNumbering returns the number given to the first auto-numbered field of a message
+// and the step to the next, from x-proto-number-start and x-proto-number-stride on the
+// schema. Both default to 1.
+func fieldNumbering(schema *base.Schema) (int, int, error) {
+	start, err := numberingExtension(schema, "x-proto-number-start")
+	if err != nil {
+		return 0, 0, err
+	}
+	stride, err := numberingExtension(schema, "x-proto-number-stride")
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, stride, nil
+}
+
+// numberingExtension reads a positive integer schema extension, defaulting to 1
+func numberingExtension(schema *base.Schema, name string) (int, error) {
+	if schema == nil || schema.Extensions == nil {
+		return 1, nil
+	}
+	node, found := schema.Extensions.Get(name)
+	if !found || node == nil {
+		return 1, nil
+	}
+
+	num, err := strconv.Atoi(node.Value)
+	if err != nil || num < 1 || num > 536870911 {
+		return 0, Errorf(CodeInvalidFieldNumber, "%s must be an integer between 1 and 536870911, got: %s", name, node.Value)
+	}
+	return num, nil
+}
+
+// checkAutoNumbers fails if numbering the properties of schema from start in steps of
+// stride gives a field number outside the valid range or in the reserved range
+func checkAutoNumbers(schema *base.Schema, start, stride int) (string, error) {
+	num := start
+	for propName := range schema.Properties.FromOldest() {
+		if num > 536870911 {
+			return propName, Errorf(CodeInvalidFieldNumber, "x-proto-number-start and x-proto-number-stride assign %d, which exceeds 536870911", num)
+		}
+		if num >= 19000 && num <= 19999 {
+			return propName, Errorf(CodeReservedFieldNumber, "x-proto-number-start and x-proto-number-stride assign %d, which is in reserved range 19000-19999", num)
+		}
+		num += stride
+	}
+	return "", nil
 }
 
 // buildEnum creates a protoEnum from an OpenAPI schema
 func buildEnum(name string, proxy *base.SchemaProxy, ctx *Context) (*ProtoEnum, error) {
+	return buildNamedEnum(name, ctx.Tracker.UniqueName(ToPascalCase(name)), proxy, ctx)
+}
+
+// buildComponentEnum creates the protoEnum of a component integer enum schema under the
+// name reserved for it. Every reference to the schema uses this enum, so it is built
+// once and later calls return it.
+func buildComponentEnum(name string, proxy *base.SchemaProxy, ctx *Context) (*ProtoEnum, error) {
+	if enum, ok := ctx.enums[name]; ok {
+		return enum, nil
+	}
+	enum, err := buildNamedEnum(name, ctx.reserveName(name), proxy, ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx.enums[name] = enum
+	return enum, nil
+}
+
+// buildNamedEnum creates a protoEnum named enumName from the OpenAPI schema name
+func buildNamedEnum(name, enumName string, proxy *base.SchemaProxy, ctx *Context) (*ProtoEnum, error) {
 	schema := proxy.Schema()
 	if schema == nil {
 		if err := proxy.GetBuildError(); err != nil {
-			return nil, SchemaError(name, fmt.Sprintf("failed to resolve schema: %v", err))
+			return nil, WithCode(CodeInvalidReference, SchemaError(name, fmt.Sprintf("failed to resolve schema: %v", err)))
 		}
 		return nil, SchemaError(name, "schema is nil")
 	}
 
-	enumName := ctx.Tracker.UniqueName(ToPascalCase(name))
-
 	enum := &ProtoEnum{
 		Name:        enumName,
 		Description: schema.Description,
@@ -448,7 +771,7 @@ func buildEnum(name string, proxy *base.SchemaProxy, ctx *Context) (*ProtoEnum,
 	}
 
 	// Add UNSPECIFIED value at 0
-	unspecifiedName := fmt.Sprintf("%s_UNSPECIFIED", strings.ToUpper(ToSnakeCase(enumName)))
+	unspecifiedName := ToUnspecifiedValueName(enumName)
 	enum.Values = append(enum.Values, &ProtoEnumValue{
 		Name:   unspecifiedName,
 		Number: 0,
@@ -479,18 +802,14 @@ func buildNestedMessage(propertyName string, proxy *base.SchemaProxy, ctx *Conte
 	schema := proxy.Schema()
 	if schema == nil {
 		if err := proxy.GetBuildError(); err != nil {
-			return nil, fmt.Errorf("failed to resolve nested object: %w", err)
+			return nil, Errorf(CodeInvalidReference, "failed to resolve nested object: %w", err)
 		}
 		return nil, fmt.Errorf("nested object schema is nil")
 	}
 
 	// Validate property name is not plural
-	// Simple check: error if ends with 's' or 'es' (no intelligent singularization)
-	if strings.HasSuffix(propertyName, "es") {
-		return nil, fmt.Errorf("cannot derive message name from property '%s'; use singular form or $ref", propertyName)
-	}
-	if strings.HasSuffix(propertyName, "s") {
-		return nil, fmt.Errorf("cannot derive message name from property '%s'; use singular form or $ref", propertyName)
+	if err := checkSingular(propertyName, "message", "property"); err != nil {
+		return nil, err
 	}
 
 	// Derive nested message name via PascalCase
@@ -498,25 +817,43 @@ func buildNestedMessage(propertyName string, proxy *base.SchemaProxy, ctx *Conte
 	msgName = ctx.Tracker.UniqueName(msgName)
 
 	// Validate field numbers before processing
-	if err := validateFieldNumbers(schema, propertyName); err != nil {
+	if err := validateNestedFieldNumbers(schema); err != nil {
+		return nil, err
+	}
+
+	ctx.depth++
+	defer func() { ctx.depth-- }()
+	if ctx.Limits.MaxDepth > 0 && ctx.depth > ctx.Limits.MaxDepth {
+		return nil, Errorf(CodeLimitExceeded, "nesting depth exceeds limit of %d", ctx.Limits.MaxDepth)
+	}
+	if err := ctx.countMessage(); err != nil {
 		return nil, err
 	}
 
 	msg := &ProtoMessage{
 		Name:           msgName,
 		Description:    schema.Description,
-		Fields:         []*ProtoField{},
+		Fields:         make([]*ProtoField, 0, propertyCount(schema)),
 		Nested:         []*ProtoMessage{},
 		OriginalSchema: propertyName, // For nested messages, use property name
 	}
 
-	fieldTracker := NewNameTracker()
+	fieldTracker := acquireNameTracker()
+	defer releaseNameTracker(fieldTracker)
+
+	// Properties of the nested object extend the pointer of the property declaring it.
+	// It is restored on return rather than deferred, so a panic reports the innermost.
+	pointer := ctx.Pointer
 
 	// Process properties in YAML order
 	if schema.Properties != nil {
-		fieldNumber := 1
+		// Validated with the field numbers above
+		fieldNumber, stride, _ := fieldNumbering(schema)
+		fields := make([]ProtoField, 0, propertyCount(schema))
+		propertyPointer := pointer + "/properties/"
 		for propName, propProxy := range schema.Properties.FromOldest() {
-			propSchema := propProxy.Schema()
+			ctx.Pointer = propertyPointer + escapePointer(propName)
+			propSchema := ctx.schemaOf(propProxy)
 			if propSchema == nil {
 				return nil, fmt.Errorf("property '%s': has nil schema", propName)
 			}
@@ -538,7 +875,7 @@ func buildNestedMessage(propertyName string, proxy *base.SchemaProxy, ctx *Conte
 			// For inline objects and integer enums, description goes to the nested type, not the field
 			// For string enums, keep description on field (not hoisted)
 			fieldDescription := propSchema.Description
-			if len(propSchema.Type) > 0 && contains(propSchema.Type, "object") {
+			if len(propSchema.Type) > 0 && contains(propSchema.Type, "object") && !isMapSchema(propSchema) {
 				fieldDescription = ""
 			}
 			if isIntegerEnum(propSchema) {
@@ -552,21 +889,31 @@ func buildNestedMessage(propertyName string, proxy *base.SchemaProxy, ctx *Conte
 				actualFieldNumber = customFieldNum
 			}
 
-			field := &ProtoField{
+			options, err := fieldOptions(propProxy, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("property '%s': %w", propName, err)
+			}
+
+			fieldType := nullableType(ctx, propProxy, propSchema, protoType, repeated)
+			fields = append(fields, ProtoField{
 				Name:        protoFieldName,
-				Type:        protoType,
+				Type:        fieldType,
+				MapKey:      mapKey(propSchema),
 				Number:      actualFieldNumber,
 				Description: fieldDescription,
 				Repeated:    repeated,
+				Optional:    optionalField(ctx, propProxy, propSchema, fieldType, repeated, slices.Contains(schema.Required, propName)),
 				JSONName:    propName,
 				EnumValues:  enumValues,
-			}
+				Options:     options,
+			})
 
-			msg.Fields = append(msg.Fields, field)
+			// The fields share one allocation, sized so appending never moves them
+			msg.Fields = append(msg.Fields, &fields[len(fields)-1])
 
 			// Only increment auto-counter if we didn't use a custom number
 			if !hasCustomNum {
-				fieldNumber++
+				fieldNumber += stride
 			}
 		}
 	}
@@ -576,6 +923,7 @@ func buildNestedMessage(propertyName string, proxy *base.SchemaProxy, ctx *Conte
 		parentMsg.Nested = append(parentMsg.Nested, msg)
 	}
 
+	ctx.Pointer = pointer
 	return msg, nil
 }
 
@@ -597,18 +945,18 @@ func validateTopLevelSchema(schema *base.Schema, schemaName string) error {
 	if len(schema.OneOf) > 0 {
 		// Require at least 2 variants
 		if len(schema.OneOf) < 2 {
-			return fmt.Errorf("schema '%s': oneOf must have at least 2 variants", schemaName)
+			return Errorf(CodeInvalidOneOf, "schema '%s': oneOf must have at least 2 variants", schemaName)
 		}
 
 		// Require discriminator
 		if schema.Discriminator == nil || schema.Discriminator.PropertyName == "" {
-			return fmt.Errorf("schema '%s': oneOf requires discriminator", schemaName)
+			return Errorf(CodeInvalidOneOf, "schema '%s': oneOf requires discriminator", schemaName)
 		}
 
 		// Require all variants to be $ref (no inline schemas)
 		for i, variant := range schema.OneOf {
 			if !variant.IsReference() {
-				return fmt.Errorf("schema '%s': oneOf variant %d must use $ref, inline schemas not supported", schemaName, i)
+				return Errorf(CodeInvalidOneOf, "schema '%s': oneOf variant %d must use $ref, inline schemas not supported", schemaName, i)
 			}
 		}
 
@@ -0,0 +1,212 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbacksService(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /subscriptions:
+    post:
+      operationId: subscribe
+      responses:
+        '201':
+          description: Subscribed
+      callbacks:
+        onEvent:
+          '{$request.body#/callbackUrl}':
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      $ref: '#/components/schemas/Event'
+              responses:
+                '200':
+                  description: Received
+        onCancel:
+          '{$request.body#/callbackUrl}':
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      type: object
+                      properties:
+                        reason:
+                          type: string
+              responses:
+                '200':
+                  description: Received
+  /jobs:
+    put:
+      responses:
+        '202':
+          description: Accepted
+      callbacks:
+        onDone:
+          '{$request.query.url}':
+            delete:
+              responses:
+                '200':
+                  description: Received
+components:
+  schemas:
+    Event:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+	expected := `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+message Event {
+  string name = 1 [json_name = "name"];
+}
+
+message SubscribeOnCancelRequest {
+  string reason = 1 [json_name = "reason"];
+}
+
+// Callbacks sent to clients, which implement this service:
+//
+// service Callbacks {
+//   // onEvent from subscribe: POST {$request.body#/callbackUrl}
+//   rpc SubscribeOnEvent(Event) returns (google.protobuf.Empty);
+//
+//   // onCancel from subscribe: POST {$request.body#/callbackUrl}
+//   rpc SubscribeOnCancel(SubscribeOnCancelRequest) returns (google.protobuf.Empty);
+//
+//   // onDone from PUT /jobs: DELETE {$request.query.url}
+//   rpc PutJobsOnDone(google.protobuf.Empty) returns (google.protobuf.Empty);
+// }
+
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(result.Protobuf))
+
+	assert.Equal(t, []conv.Callback{
+		{
+			Operation:  "subscribe",
+			Name:       "onEvent",
+			Expression: "{$request.body#/callbackUrl}",
+			Method:     "POST",
+			Request:    "Event",
+		},
+		{
+			Operation:  "subscribe",
+			Name:       "onCancel",
+			Expression: "{$request.body#/callbackUrl}",
+			Method:     "POST",
+			Request:    "SubscribeOnCancelRequest",
+		},
+		{
+			Operation:  "PUT /jobs",
+			Name:       "onDone",
+			Expression: "{$request.query.url}",
+			Method:     "DELETE",
+		},
+	}, result.Callbacks)
+}
+
+func TestCallbacksServiceRequestTypes(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /subscriptions:
+    post:
+      operationId: subscribe
+      responses:
+        '201':
+          description: Subscribed
+      callbacks:
+        onEvent:
+          '{$request.body#/callbackUrl}':
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      type: object
+                      properties:
+                        id:
+                          type: string
+              responses:
+                '200':
+                  description: Received
+        onPet:
+          '{$request.body#/callbackUrl}':
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      $ref: '#/components/schemas/Pet'
+              responses:
+                '200':
+                  description: Received
+components:
+  schemas:
+    SubscribeOnEventRequest:
+      type: object
+      properties:
+        name:
+          type: string
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(result.Protobuf), "message SubscribeOnEventRequest2 {\n  string id = 1 [json_name = \"id\"];\n}\n")
+	assert.Contains(t, string(result.Protobuf), `// service Callbacks {
+//   // onEvent from subscribe: POST {$request.body#/callbackUrl}
+//   rpc SubscribeOnEvent(SubscribeOnEventRequest2) returns (google.protobuf.Empty);
+//
+//   // onPet from subscribe: POST {$request.body#/callbackUrl}, sends Pet
+//   rpc SubscribeOnPet(google.protobuf.Any) returns (google.protobuf.Empty);
+// }
+`)
+	assert.Equal(t, "SubscribeOnEventRequest_2", result.Callbacks[0].Request)
+	message, ok := result.ProtoTypeFor(result.Callbacks[0].Request)
+	require.True(t, ok)
+	assert.Equal(t, "testpkg.SubscribeOnEventRequest2", message)
+}
@@ -200,3 +200,65 @@ message User {
 	require.NotNil(t, result)
 	assert.Equal(t, expected, string(result.Protobuf))
 }
+
+func TestAdversarialDescriptions(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{
+			name:        "block comment terminator",
+			description: `"Ends here */ message Evil {}"`,
+			expected:    "  // Ends here * / message Evil {}\n",
+		},
+		{
+			name:        "leading slashes",
+			description: `"// copied code comment\n/// doc marker\n  / single"`,
+			expected:    "  // copied code comment\n  // doc marker\n  // single\n",
+		},
+		{
+			name:        "carriage returns",
+			description: `"first\r\nsecond\rthird"`,
+			expected:    "  // first\n  // second\n  // third\n",
+		},
+		{
+			name:        "control characters",
+			description: `"bell\a null\0 escape\e form\ffeed"`,
+			expected:    "  // bell null escape formfeed\n",
+		},
+		{
+			name:        "unicode line separators",
+			description: `"one\Ltwo\Pthree"`,
+			expected:    "  // one\n  // two\n  // three\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+          description: ` + test.description + `
+          enum:
+            - "a\rb"
+            - "/path"
+`
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected+
+				"  // enum: [a b, /path]\n"+
+				"  string name = 1 [json_name = \"name\"];\n")
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Well-known types for the string formats date, time and duration, and the files
+// declaring them
+const (
+	dateType      = "google.type.Date"
+	timeOfDayType = "google.type.TimeOfDay"
+	durationType  = "google.protobuf.Duration"
+
+	dateImport      = "google/type/date.proto"
+	timeOfDayImport = "google/type/timeofday.proto"
+	durationImport  = "google/protobuf/duration.proto"
+)
+
+// timeOfDayLayouts are the accepted forms of a time example, as in RFC 3339 partial-time
+var timeOfDayLayouts = []string{"15:04:05.999999999Z07:00", "15:04:05.999999999", "15:04"}
+
+// isoDuration matches ISO 8601 durations made of days, hours, minutes and seconds
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseTimeOfDay parses a time example such as "13:45:30" or "13:45:30.5Z"
+func parseTimeOfDay(text string) (time.Time, error) {
+	for _, layout := range timeOfDayLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time example '%s'", text)
+}
+
+// parseDuration parses a duration example in ISO 8601 form such as "PT1H30M", or in the
+// form accepted by time.ParseDuration such as "90m"
+func parseDuration(text string) (time.Duration, error) {
+	match := isoDuration.FindStringSubmatch(text)
+	if match == nil || text == "P" || strings.HasSuffix(text, "T") {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration example '%s'", text)
+		}
+		return d, nil
+	}
+
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if match[i+1] == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration example '%s'", text)
+		}
+		d += time.Duration(value * float64(unit))
+	}
+	return d, nil
+}
+
+// durationJSON formats d as the protobuf JSON form of google.protobuf.Duration, e.g. "5400s"
+func durationJSON(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
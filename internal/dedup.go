@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sharedErrorName is the message name used for collapsed error schemas when it is free
+const sharedErrorName = "Error"
+
+// DedupErrorMessages collapses top-level error messages (names containing "Error") that
+// have the same structure into the first one in spec order. Structure covers field names,
+// types, numbers, json names and options of the message and its nested messages, but not
+// descriptions. The kept message is renamed to Error when no other message uses that name.
+// References to collapsed messages are rewritten and the collapsed messages are removed from
+// messages and definitions. The returned map links each collapsed or renamed schema to the
+// shared message name.
+func DedupErrorMessages(ctx *Context) map[string]string {
+	groups := make(map[string][]*ProtoMessage)
+	var order []string
+	for _, msg := range ctx.Messages {
+		if !strings.Contains(msg.Name, "Error") {
+			continue
+		}
+		key := messageShape(msg)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], msg)
+	}
+
+	aliases := make(map[string]string)
+	renames := make(map[string]string)
+	removed := make(map[*ProtoMessage]bool)
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+
+		shared := group[0]
+		if shared.Name != sharedErrorName && !nameInUse(ctx, sharedErrorName) {
+			renames[shared.Name] = sharedErrorName
+			shared.Name = sharedErrorName
+			aliases[shared.OriginalSchema] = sharedErrorName
+		}
+
+		for _, msg := range group[1:] {
+			renames[msg.Name] = shared.Name
+			aliases[msg.OriginalSchema] = shared.Name
+			removed[msg] = true
+		}
+	}
+
+	if len(renames) == 0 {
+		return nil
+	}
+
+	messages := make([]*ProtoMessage, 0, len(ctx.Messages))
+	for _, msg := range ctx.Messages {
+		if !removed[msg] {
+			messages = append(messages, msg)
+			renameFieldTypes(msg, renames)
+		}
+	}
+	ctx.Messages = messages
+
+	definitions := make([]interface{}, 0, len(ctx.Definitions))
+	for _, def := range ctx.Definitions {
+		if msg, ok := def.(*ProtoMessage); ok && removed[msg] {
+			continue
+		}
+		definitions = append(definitions, def)
+	}
+	ctx.Definitions = definitions
+
+	return aliases
+}
+
+// messageShape renders the structure of a message, ignoring its name and descriptions
+func messageShape(msg *ProtoMessage) string {
+	var result strings.Builder
+	result.WriteString(msg.Oneof)
+	for _, field := range msg.Fields {
+		result.WriteString(fmt.Sprintf("%t %t %s %s %s = %d %s %q %q;", field.Repeated, field.Optional, field.MapKey,
+			field.Type, field.Name, field.Number, field.JSONName, field.EnumValues, field.Options))
+	}
+	for _, nested := range msg.Nested {
+		result.WriteString(fmt.Sprintf("%s {%s}", nested.Name, messageShape(nested)))
+	}
+	return result.String()
+}
+
+// renameFieldTypes rewrites field types in msg and its nested messages
+func renameFieldTypes(msg *ProtoMessage, renames map[string]string) {
+	for _, field := range msg.Fields {
+		if name, ok := renames[field.Type]; ok {
+			field.Type = name
+		}
+	}
+	for _, nested := range msg.Nested {
+		renameFieldTypes(nested, renames)
+	}
+}
+
+// nameInUse reports whether a top-level message or enum has the given name
+func nameInUse(ctx *Context, name string) bool {
+	for _, msg := range ctx.Messages {
+		if msg.Name == name {
+			return true
+		}
+	}
+	for _, enum := range ctx.Enums {
+		if enum.Name == name {
+			return true
+		}
+	}
+	return false
+}
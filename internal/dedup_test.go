@@ -0,0 +1,176 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupErrors(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    NotFoundError:
+      type: object
+      description: Returned when the user does not exist
+      properties:
+        code:
+          type: integer
+        message:
+          type: string
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        lastError:
+          $ref: '#/components/schemas/ValidationError'
+    ValidationError:
+      type: object
+      properties:
+        code:
+          type: integer
+        message:
+          type: string
+    RateLimitError:
+      type: object
+      properties:
+        code:
+          type: integer
+        retryAfter:
+          type: integer
+`
+
+	for _, test := range []struct {
+		name     string
+		dedup    bool
+		expected string
+		aliases  map[string]string
+	}{
+		{
+			name: "disabled",
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+// Returned when the user does not exist
+message NotFoundError {
+  int32 code = 1 [json_name = "code"];
+  string message = 2 [json_name = "message"];
+}
+
+message User {
+  string name = 1 [json_name = "name"];
+  ValidationError lastError = 2 [json_name = "lastError"];
+}
+
+message ValidationError {
+  int32 code = 1 [json_name = "code"];
+  string message = 2 [json_name = "message"];
+}
+
+message RateLimitError {
+  int32 code = 1 [json_name = "code"];
+  int32 retryAfter = 2 [json_name = "retryAfter"];
+}
+
+`,
+			aliases: map[string]string{},
+		},
+		{
+			name:  "enabled",
+			dedup: true,
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+// Returned when the user does not exist
+message Error {
+  int32 code = 1 [json_name = "code"];
+  string message = 2 [json_name = "message"];
+}
+
+message User {
+  string name = 1 [json_name = "name"];
+  Error lastError = 2 [json_name = "lastError"];
+}
+
+message RateLimitError {
+  int32 code = 1 [json_name = "code"];
+  int32 retryAfter = 2 [json_name = "retryAfter"];
+}
+
+`,
+			aliases: map[string]string{
+				"NotFoundError":   "Error",
+				"ValidationError": "Error",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				DedupErrors: test.dedup,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(result.Protobuf))
+
+			aliases := make(map[string]string)
+			for name, info := range result.TypeMap {
+				if info.AliasOf != "" {
+					aliases[name] = info.AliasOf
+				}
+			}
+			assert.Equal(t, test.aliases, aliases)
+		})
+	}
+}
+
+func TestDedupErrorsNameInUse(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Error:
+      type: object
+      properties:
+        reason:
+          type: string
+    AuthError:
+      type: object
+      properties:
+        code:
+          type: integer
+    QuotaError:
+      type: object
+      properties:
+        code:
+          type: integer
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		DedupErrors: true,
+	})
+	require.NoError(t, err)
+
+	proto := string(result.Protobuf)
+	assert.Contains(t, proto, "message Error {\n  string reason = 1")
+	assert.Contains(t, proto, "message AuthError {")
+	assert.NotContains(t, proto, "message QuotaError")
+	assert.Equal(t, "AuthError", result.TypeMap["QuotaError"].AliasOf)
+	assert.Empty(t, result.TypeMap["AuthError"].AliasOf)
+}
@@ -9,6 +9,8 @@ import (
 // DependencyGraph tracks schema dependencies and union types for transitive closure computation
 type DependencyGraph struct {
 	schemas       map[string]*base.SchemaProxy
+	unions        []string            // union names in the order they were marked
+	sources       []string            // edge sources in the order they were first seen
 	edges         map[string][]string // from -> []to dependencies
 	hasUnion      map[string]bool
 	unionReasons  map[string]string
@@ -19,6 +21,8 @@ type DependencyGraph struct {
 func NewDependencyGraph() *DependencyGraph {
 	return &DependencyGraph{
 		schemas:       make(map[string]*base.SchemaProxy),
+		unions:        make([]string, 0),
+		sources:       make([]string, 0),
 		edges:         make(map[string][]string),
 		hasUnion:      make(map[string]bool),
 		unionReasons:  make(map[string]string),
@@ -36,57 +40,61 @@ func (g *DependencyGraph) AddSchema(name string, proxy *base.SchemaProxy) error
 func (g *DependencyGraph) AddDependency(from, to string) {
 	if g.edges[from] == nil {
 		g.edges[from] = make([]string, 0)
+		g.sources = append(g.sources, from)
 	}
 	g.edges[from] = append(g.edges[from], to)
 }
 
 // MarkUnion marks a schema as containing a union with the given reason and variant names
 func (g *DependencyGraph) MarkUnion(schemaName, reason string, variants []string) {
+	if !g.hasUnion[schemaName] {
+		g.unions = append(g.unions, schemaName)
+	}
 	g.hasUnion[schemaName] = true
 	g.unionReasons[schemaName] = reason
 	g.unionVariants[schemaName] = variants
 }
 
 // ComputeTransitiveClosure performs BFS to find all schemas that should be Go-only
-// Returns goTypes (Go-only schemas), protoTypes (proto schemas), and reasons
+// Returns goTypes (Go-only schemas), protoTypes (proto schemas), and reasons.
+// Schemas are visited in the order they were added so reasons are identical across runs.
 func (g *DependencyGraph) ComputeTransitiveClosure() (goTypes, protoTypes map[string]bool, reasons map[string]string) {
 	goTypes = make(map[string]bool)
 	reasons = make(map[string]string)
 	rootCause := make(map[string]string) // tracks root union type for each Go-only type
 	visited := make(map[string]bool)
+	queue := make([]string, 0)
 
 	// Mark direct union types
-	for name, reason := range g.unionReasons {
+	for _, name := range g.unions {
 		goTypes[name] = true
-		reasons[name] = reason
+		reasons[name] = g.unionReasons[name]
 		rootCause[name] = name // union types are their own root cause
 		visited[name] = true
+		queue = append(queue, name)
 	}
 
 	// Mark union variants
-	for unionName, variants := range g.unionVariants {
-		for _, variant := range variants {
+	for _, unionName := range g.unions {
+		for _, variant := range g.unionVariants[unionName] {
 			if !goTypes[variant] {
 				goTypes[variant] = true
 				reasons[variant] = fmt.Sprintf("variant of union type %s", unionName)
 				rootCause[variant] = unionName // root cause is the union containing this variant
 				visited[variant] = true
+				queue = append(queue, variant)
 			}
 		}
 	}
 
 	// BFS to find all types referencing Go-only types
-	queue := make([]string, 0)
-	for name := range goTypes {
-		queue = append(queue, name)
-	}
-
 	for len(queue) > 0 {
 		current := queue[0]
 		queue = queue[1:]
 
 		// Find all types that depend on (reference) current
-		for from, deps := range g.edges {
+		for _, from := range g.sources {
+			deps := g.edges[from]
 			if visited[from] {
 				continue
 			}
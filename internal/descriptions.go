@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// DescriptionOptions controls how schema descriptions are carried into generated comments
+type DescriptionOptions struct {
+	MaxLength     int  // Truncate descriptions longer than this many characters, 0 means no limit
+	StripMarkdown bool // Remove markdown formatting, keeping the text
+	Omit          bool // Drop descriptions entirely
+}
+
+var (
+	markdownImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink       = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	markdownBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownItalic     = regexp.MustCompile(`\*([^*\s][^*]*)\*|(^|[^\w])_([^_\s][^_]*)_([^\w]|$)`)
+	markdownCode       = regexp.MustCompile("`([^`]+)`")
+	markdownHeading    = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	markdownBlockquote = regexp.MustCompile(`^\s{0,3}>\s?`)
+	markdownHTML       = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// Apply returns the description as it should appear in a generated comment
+func (o DescriptionOptions) Apply(description string) string {
+	if o.Omit {
+		return ""
+	}
+
+	if o.StripMarkdown {
+		description = stripMarkdown(description)
+	}
+
+	if o.MaxLength > 0 {
+		description = truncateDescription(description, o.MaxLength)
+	}
+
+	return description
+}
+
+// stripMarkdown removes common markdown syntax (headings, blockquotes, code fences,
+// emphasis, inline code, links, images and inline HTML) while keeping the text
+func stripMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	result := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+
+		line = markdownHeading.ReplaceAllString(line, "")
+		line = markdownBlockquote.ReplaceAllString(line, "")
+		line = markdownImage.ReplaceAllString(line, "$1")
+		line = markdownLink.ReplaceAllString(line, "$1")
+		line = markdownCode.ReplaceAllString(line, "$1")
+		line = markdownBold.ReplaceAllString(line, "$1$2")
+		line = markdownItalic.ReplaceAllString(line, "$1$2$3$4")
+		line = markdownHTML.ReplaceAllString(line, "")
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// truncateDescription shortens text to at most max characters, preferring a word
+// boundary, and marks the cut with "..."
+func truncateDescription(text string, max int) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	cut := string(runes[:max])
+	if idx := strings.LastIndexAny(cut, " \t\n"); idx > 0 {
+		cut = cut[:idx]
+	}
+
+	return strings.TrimRight(cut, " \t\n.,;:") + "..."
+}
+
+// ApplyProtoDescriptions rewrites the descriptions of proto definitions in place
+func ApplyProtoDescriptions(definitions []interface{}, opts DescriptionOptions) {
+	for _, def := range definitions {
+		switch d := def.(type) {
+		case *ProtoEnum:
+			d.Description = opts.Apply(d.Description)
+		case *ProtoMessage:
+			applyMessageDescriptions(d, opts)
+		}
+	}
+}
+
+// applyMessageDescriptions rewrites the descriptions of a message, its fields, nested enums and nested messages
+func applyMessageDescriptions(msg *ProtoMessage, opts DescriptionOptions) {
+	msg.Description = opts.Apply(msg.Description)
+	for _, field := range msg.Fields {
+		field.Description = opts.Apply(field.Description)
+	}
+	for _, enum := range msg.Enums {
+		enum.Description = opts.Apply(enum.Description)
+	}
+	for _, nested := range msg.Nested {
+		applyMessageDescriptions(nested, opts)
+	}
+}
+
+// ApplyGoDescriptions rewrites the descriptions of Go structs, fields and enums in place
+func ApplyGoDescriptions(ctx *GoContext, opts DescriptionOptions) {
+	for _, s := range ctx.Structs {
+		s.Description = opts.Apply(s.Description)
+		for _, field := range s.Fields {
+			field.Description = opts.Apply(field.Description)
+		}
+	}
+	for _, e := range ctx.Enums {
+		e.Description = opts.Apply(e.Description)
+	}
+}
+
+// MissingDescriptions returns the dotted paths of the schemas and properties without a
+// description, in spec order, e.g. "User" or "User.profile.name". Inline objects and
+// array items are checked below their property; referenced schemas are checked under
+// their own name. Schemas named in exempt are skipped along with their properties.
+func MissingDescriptions(entries []*parser.SchemaEntry, exempt map[string]bool) []string {
+	var missing []string
+	for _, entry := range entries {
+		schema := entry.Proxy.Schema()
+		if schema == nil || exempt[entry.Name] {
+			continue
+		}
+		if strings.TrimSpace(schema.Description) == "" {
+			missing = append(missing, entry.Name)
+		}
+		missing = appendMissingProperties(missing, entry.Name, schema)
+	}
+	return missing
+}
+
+// appendMissingProperties adds the properties of schema, at path, lacking a description
+func appendMissingProperties(missing []string, path string, schema *base.Schema) []string {
+	if schema.Properties == nil {
+		return missing
+	}
+	for name, proxy := range schema.Properties.FromOldest() {
+		if proxy.IsReference() {
+			continue
+		}
+		prop := proxy.Schema()
+		if prop == nil {
+			continue
+		}
+		propPath := path + "." + name
+		if strings.TrimSpace(prop.Description) == "" {
+			missing = append(missing, propPath)
+		}
+
+		missing = appendMissingProperties(missing, propPath, prop)
+		if prop.Items != nil && prop.Items.IsA() && !prop.Items.A.IsReference() {
+			if items := prop.Items.A.Schema(); items != nil {
+				missing = appendMissingProperties(missing, propPath, items)
+			}
+		}
+	}
+	return missing
+}
@@ -0,0 +1,248 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescriptionOptionsProto(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      description: |-
+        ## User account
+
+        A **registered** user of the _storefront_, see [docs](https://example.com/users).
+        Identified by ` + "`user_id`" + `.
+      properties:
+        user_id:
+          type: string
+          description: Unique identifier assigned by the account service when the user signs up
+`
+
+	for _, test := range []struct {
+		name     string
+		opts     conv.DescriptionOptions
+		expected string
+	}{
+		{
+			name: "unchanged by default",
+			opts: conv.DescriptionOptions{},
+			expected: `// ## User account
+//
+// A **registered** user of the _storefront_, see [docs](https://example.com/users).
+// Identified by ` + "`user_id`" + `.
+message User {
+  // Unique identifier assigned by the account service when the user signs up
+  string user_id = 1 [json_name = "user_id"];
+}
+`,
+		},
+		{
+			name: "strip markdown",
+			opts: conv.DescriptionOptions{StripMarkdown: true},
+			expected: `// User account
+//
+// A registered user of the storefront, see docs.
+// Identified by user_id.
+message User {
+  // Unique identifier assigned by the account service when the user signs up
+  string user_id = 1 [json_name = "user_id"];
+}
+`,
+		},
+		{
+			name: "truncate at word boundary",
+			opts: conv.DescriptionOptions{StripMarkdown: true, MaxLength: 40},
+			expected: `// User account
+//
+// A registered user of the...
+message User {
+  // Unique identifier assigned by the...
+  string user_id = 1 [json_name = "user_id"];
+}
+`,
+		},
+		{
+			name: "omit from proto",
+			opts: conv.DescriptionOptions{OmitFromProto: true},
+			expected: `message User {
+  string user_id = 1 [json_name = "user_id"];
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:  "testpkg",
+				PackagePath:  "github.com/example/proto/v1",
+				Descriptions: test.opts,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), "go_package = \"github.com/example/proto/v1\";\n\n"+test.expected)
+		})
+	}
+}
+
+func TestDescriptionOptionsPerOutput(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      description: A pet in the store
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: kind
+    Dog:
+      type: object
+      properties:
+        kind:
+          type: string
+    Cat:
+      type: object
+      properties:
+        kind:
+          type: string
+    Address:
+      type: object
+      description: A postal address
+      properties:
+        city:
+          type: string
+`
+
+	for _, test := range []struct {
+		name      string
+		opts      conv.DescriptionOptions
+		wantProto bool
+		wantGo    bool
+	}{
+		{
+			name:      "both outputs by default",
+			wantProto: true,
+			wantGo:    true,
+		},
+		{
+			name:      "omit from proto keeps Go doc comments",
+			opts:      conv.DescriptionOptions{OmitFromProto: true},
+			wantProto: false,
+			wantGo:    true,
+		},
+		{
+			name:      "omit from Go keeps proto comments",
+			opts:      conv.DescriptionOptions{OmitFromGo: true},
+			wantProto: true,
+			wantGo:    false,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:  "testpkg",
+				PackagePath:  "github.com/example/proto/v1",
+				Descriptions: test.opts,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.wantProto, strings.Contains(string(result.Protobuf), "// A postal address\n"))
+			assert.Equal(t, test.wantGo, strings.Contains(string(result.Golang), "// A pet in the store\n"))
+		})
+	}
+}
+
+func TestDescriptionOptionsRequire(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      description: A registered user
+      properties:
+        email:
+          type: string
+        address:
+          $ref: '#/components/schemas/Address'
+        profile:
+          type: object
+          description: Public profile
+          properties:
+            bio:
+              type: string
+              description: Free text
+            link:
+              type: array
+              items:
+                type: object
+                properties:
+                  url:
+                    type: string
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+          description: City name
+`
+
+	for _, test := range []struct {
+		name     string
+		opts     conv.DescriptionOptions
+		warnings []string
+		err      string
+	}{
+		{
+			name: "not required",
+		},
+		{
+			name: "warn",
+			opts: conv.DescriptionOptions{Require: conv.RequireDescriptionsWarn},
+			warnings: []string{
+				"missing description: User.email",
+				"missing description: User.profile.link",
+				"missing description: User.profile.link.url",
+				"missing description: Address",
+			},
+		},
+		{
+			name: "error",
+			opts: conv.DescriptionOptions{Require: conv.RequireDescriptionsError},
+			err:  "missing descriptions: User.email, User.profile.link, User.profile.link.url, Address",
+		},
+		{
+			name: "exempt",
+			opts: conv.DescriptionOptions{Require: conv.RequireDescriptionsError, Exempt: []string{"User", "Address"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:  "testpkg",
+				PackagePath:  "github.com/example/proto/v1",
+				Descriptions: test.opts,
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				var convErr *conv.Error
+				require.ErrorAs(t, err, &convErr)
+				assert.Equal(t, conv.ErrorCodeMissingDescription, convErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.warnings, result.Warnings)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deterministicSpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+servers:
+  - url: https://{region}.example.com/{version}
+    variables:
+      region:
+        default: eu
+      version:
+        default: v1
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Bird'
+        - $ref: '#/components/schemas/Fish'
+      discriminator:
+        propertyName: kind
+    Animal:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+      discriminator:
+        propertyName: kind
+    Dog:
+      type: object
+      properties:
+        kind:
+          type: string
+    Cat:
+      type: object
+      properties:
+        kind:
+          type: string
+    Bird:
+      type: object
+      properties:
+        kind:
+          type: string
+    Fish:
+      type: object
+      properties:
+        kind:
+          type: string
+    Owner:
+      type: object
+      properties:
+        pet:
+          $ref: '#/components/schemas/Pet'
+        animal:
+          $ref: '#/components/schemas/Animal'
+    Home:
+      type: object
+      properties:
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+          example: Paris
+`
+
+func TestDeterministicOutput(t *testing.T) {
+	opts := conv.ConvertOptions{
+		PackageName:  "testpkg",
+		PackagePath:  "github.com/example/proto/v1",
+		EmitExamples: true,
+	}
+
+	first, err := conv.Convert([]byte(deterministicSpec), opts)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		result, err := conv.Convert([]byte(deterministicSpec), opts)
+		require.NoError(t, err)
+		assert.Equal(t, string(first.Protobuf), string(result.Protobuf))
+		assert.Equal(t, string(first.Golang), string(result.Golang))
+		assert.Equal(t, first.TypeMap, result.TypeMap)
+		assert.Equal(t, first.Servers, result.Servers)
+		assert.Equal(t, first.Examples, result.Examples)
+	}
+
+	// Variants and referencing types are attributed to the first union declared in the spec
+	assert.Equal(t, "variant of union type Pet", first.TypeMap["Cat"].Reason)
+	assert.Equal(t, "references union type Pet", first.TypeMap["Owner"].Reason)
+	assert.Equal(t, "https://eu.example.com/v1", first.Servers[0].BaseURL)
+}
+
+func TestDeterministicDiscriminatorOrder(t *testing.T) {
+	result, err := conv.Convert([]byte(deterministicSpec), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	golang := string(result.Golang)
+	start := strings.Index(golang, "func (u *Pet) UnmarshalJSON")
+	require.NotEqual(t, -1, start)
+	body := golang[start:]
+
+	dog := strings.Index(body, `case "dog":`)
+	cat := strings.Index(body, `case "cat":`)
+	bird := strings.Index(body, `case "bird":`)
+	fish := strings.Index(body, `case "fish":`)
+	require.NotEqual(t, -1, dog)
+	assert.Less(t, dog, cat)
+	assert.Less(t, cat, bird)
+	assert.Less(t, bird, fish)
+}
+
+func TestDeterministicMode(t *testing.T) {
+	result, err := conv.Convert([]byte(deterministicSpec), conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		EmitExamples:  true,
+		Deterministic: true,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Protobuf)
+	assert.NotEmpty(t, result.Golang)
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// mappedFormats are the formats that change the proto type of each OpenAPI type; any
+// other format is dropped
+var mappedFormats = map[string]map[string]bool{
+	"integer": {"int32": true, "int64": true},
+	"number":  {"float": true, "double": true},
+	"string":  {"date": true, "date-time": true, "time": true, "duration": true, "byte": true, "binary": true},
+}
+
+// NoteDropped records a note on each message and field whose schema uses a construct
+// the proto output cannot represent and silently ignores, so the loss is visible in code
+// review. Notes name the construct and the JSON pointer of the schema using it. Dropped
+// constructs are formats that do not affect the proto type, such as uuid or email, and
+// additionalProperties on objects that also declare properties or allow any value, since
+// only objects with nothing but an additionalProperties schema become map fields.
+func NoteDropped(entries []*parser.SchemaEntry, messages []*ProtoMessage) {
+	proxies := make(map[string]*base.SchemaProxy, len(entries))
+	for _, entry := range entries {
+		proxies[entry.Name] = entry.Proxy
+	}
+
+	for _, msg := range messages {
+		proxy, ok := proxies[msg.OriginalSchema]
+		if !ok {
+			continue
+		}
+		noteMessage(msg, proxy.Schema(), schemaPointer(msg.OriginalSchema))
+	}
+}
+
+// noteMessage records dropped constructs of msg and its fields, recursing into nested
+// messages. pointer is the JSON pointer of schema.
+func noteMessage(msg *ProtoMessage, schema *base.Schema, pointer string) {
+	if schema == nil {
+		return
+	}
+
+	if additional := schema.AdditionalProperties; additional != nil && (additional.IsA() || additional.B) {
+		msg.Notes = append(msg.Notes, fmt.Sprintf("dropped additionalProperties from %s", pointer))
+	}
+	if schema.Properties == nil {
+		return
+	}
+
+	for _, field := range msg.Fields {
+		proxy, ok := schema.Properties.Get(field.JSONName)
+		if !ok || proxy.IsReference() || proxy.Schema() == nil {
+			continue
+		}
+		fieldPointer := pointer + "/properties/" + escapePointer(field.JSONName)
+
+		if field.Repeated {
+			items := proxy.Schema().Items
+			if items == nil || items.A == nil || items.A.IsReference() {
+				continue
+			}
+			proxy = items.A
+			fieldPointer += "/items"
+		}
+		fieldSchema := proxy.Schema()
+		if fieldSchema == nil {
+			continue
+		}
+
+		if note := droppedFormat(fieldSchema, fieldPointer); note != "" {
+			field.Notes = append(field.Notes, note)
+		}
+
+		for _, nested := range msg.Nested {
+			if nested.Name == field.Type {
+				noteMessage(nested, fieldSchema, fieldPointer)
+			}
+		}
+	}
+}
+
+// droppedFormat returns a note if the format of a scalar schema does not affect its
+// proto type, or an empty string
+func droppedFormat(schema *base.Schema, pointer string) string {
+	// password is kept as the debug_redact field option
+	if schema.Format == "" || schema.Format == "password" || isEnumSchema(schema) {
+		return ""
+	}
+
+	for _, typ := range schema.Type {
+		if typ == "null" {
+			continue
+		}
+		if mappedFormats[typ][schema.Format] {
+			return ""
+		}
+	}
+	return fmt.Sprintf("dropped format '%s' from %s", schema.Format, pointer)
+}
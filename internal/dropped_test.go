@@ -0,0 +1,122 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoteDropped(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      description: A user
+      additionalProperties:
+        type: string
+      properties:
+        id:
+          type: string
+          format: uuid
+          description: Unique identifier
+        age:
+          type: integer
+          format: int64
+        emails:
+          type: array
+          items:
+            type: string
+            format: email
+        createdAt:
+          type: string
+          format: date-time
+        setting:
+          type: object
+          additionalProperties: true
+          properties:
+            ratio:
+              type: number
+              format: decimal
+`
+
+	for _, test := range []struct {
+		name     string
+		note     bool
+		expected string
+	}{
+		{
+			name: "notes",
+			note: true,
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/example/proto/v1";
+
+// A user
+// NOTE: dropped additionalProperties from /components/schemas/User
+message User {
+  // NOTE: dropped additionalProperties from /components/schemas/User/properties/setting
+  message Setting {
+    // NOTE: dropped format 'decimal' from /components/schemas/User/properties/setting/properties/ratio
+    double ratio = 1 [json_name = "ratio"];
+  }
+
+  // Unique identifier
+  // NOTE: dropped format 'uuid' from /components/schemas/User/properties/id
+  string id = 1 [json_name = "id"];
+  int64 age = 2 [json_name = "age"];
+  // NOTE: dropped format 'email' from /components/schemas/User/properties/emails/items
+  repeated string emails = 3 [json_name = "emails"];
+  google.protobuf.Timestamp createdAt = 4 [json_name = "createdAt"];
+  Setting setting = 5 [json_name = "setting"];
+}
+
+`,
+		},
+		{
+			name: "disabled",
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/example/proto/v1";
+
+// A user
+message User {
+  message Setting {
+    double ratio = 1 [json_name = "ratio"];
+  }
+
+  // Unique identifier
+  string id = 1 [json_name = "id"];
+  int64 age = 2 [json_name = "age"];
+  repeated string emails = 3 [json_name = "emails"];
+  google.protobuf.Timestamp createdAt = 4 [json_name = "createdAt"];
+  Setting setting = 5 [json_name = "setting"];
+}
+
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				NoteDropped: test.note,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(result.Protobuf))
+		})
+	}
+}
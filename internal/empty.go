@@ -0,0 +1,101 @@
+package internal
+
+// EmptyMessages controls how messages for schemas without properties are emitted
+type EmptyMessages string
+
+const (
+	// EmptyMessagesKeep emits them as empty messages
+	EmptyMessagesKeep EmptyMessages = "keep"
+	// EmptyMessagesOmit drops the ones no field references
+	EmptyMessagesOmit EmptyMessages = "omit"
+	// EmptyMessagesWellKnown drops them all and references google.protobuf.Empty instead
+	EmptyMessagesWellKnown EmptyMessages = "well-known"
+	// EmptyMessagesTODO emits them with a TODO comment
+	EmptyMessagesTODO EmptyMessages = "todo"
+)
+
+// emptyType is the well-known message replacing empty messages
+const emptyType = "google.protobuf.Empty"
+
+// emptyImport declares google.protobuf.Empty
+const emptyImport = "google/protobuf/empty.proto"
+
+// ApplyEmptyMessages handles top-level messages with no fields and no nested types
+// according to mode. Returns the schemas whose message was replaced by
+// google.protobuf.Empty, mapped to that name.
+func ApplyEmptyMessages(ctx *Context, mode EmptyMessages) map[string]string {
+	var empty []*ProtoMessage
+	for _, msg := range ctx.Messages {
+		if len(msg.Fields) == 0 && len(msg.Nested) == 0 && len(msg.Enums) == 0 {
+			empty = append(empty, msg)
+		}
+	}
+	if len(empty) == 0 {
+		return nil
+	}
+
+	removed := make(map[*ProtoMessage]bool)
+	aliases := make(map[string]string)
+	switch mode {
+	case EmptyMessagesTODO:
+		for _, msg := range empty {
+			msg.Todo = "schema " + msg.OriginalSchema + " has no properties; add fields or remove it"
+		}
+		return nil
+
+	case EmptyMessagesOmit:
+		referenced := make(map[string]bool)
+		for _, msg := range ctx.Messages {
+			collectFieldTypes(msg, referenced)
+		}
+		for _, msg := range empty {
+			if !referenced[msg.Name] {
+				removed[msg] = true
+			}
+		}
+
+	case EmptyMessagesWellKnown:
+		renames := make(map[string]string, len(empty))
+		for _, msg := range empty {
+			removed[msg] = true
+			renames[msg.Name] = emptyType
+			aliases[msg.OriginalSchema] = emptyType
+		}
+		for _, msg := range ctx.Messages {
+			renameFieldTypes(msg, renames)
+		}
+		ctx.AddImport(emptyImport)
+
+	default:
+		return nil
+	}
+
+	messages := make([]*ProtoMessage, 0, len(ctx.Messages))
+	for _, msg := range ctx.Messages {
+		if !removed[msg] {
+			messages = append(messages, msg)
+		}
+	}
+	ctx.Messages = messages
+
+	definitions := make([]interface{}, 0, len(ctx.Definitions))
+	for _, def := range ctx.Definitions {
+		if msg, ok := def.(*ProtoMessage); ok && removed[msg] {
+			continue
+		}
+		definitions = append(definitions, def)
+	}
+	ctx.Definitions = definitions
+
+	return aliases
+}
+
+// collectFieldTypes records the type of every field of msg and its nested messages
+func collectFieldTypes(msg *ProtoMessage, types map[string]bool) {
+	for _, field := range msg.Fields {
+		types[field.Type] = true
+	}
+	for _, nested := range msg.Nested {
+		collectFieldTypes(nested, types)
+	}
+}
@@ -0,0 +1,84 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmptyMessages(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Ping:
+      type: object
+    Ack:
+      type: object
+    Status:
+      type: object
+      properties:
+        ack:
+          $ref: '#/components/schemas/Ack'
+`
+
+	for _, test := range []struct {
+		name        string
+		mode        conv.EmptyMessages
+		contains    []string
+		notContains []string
+		aliasOf     string
+	}{
+		{
+			name:     "keep",
+			contains: []string{"message Ping {\n}", "message Ack {\n}", "Ack ack = 1"},
+		},
+		{
+			name:        "omit",
+			mode:        conv.EmptyMessagesOmit,
+			contains:    []string{"message Ack {\n}", "Ack ack = 1"},
+			notContains: []string{"Ping"},
+		},
+		{
+			name:        "well known",
+			mode:        conv.EmptyMessagesWellKnown,
+			contains:    []string{`import "google/protobuf/empty.proto";`, "google.protobuf.Empty ack = 1"},
+			notContains: []string{"Ping", "message Ack"},
+			aliasOf:     "google.protobuf.Empty",
+		},
+		{
+			name: "todo",
+			mode: conv.EmptyMessagesTODO,
+			contains: []string{
+				"// TODO: schema Ping has no properties; add fields or remove it\nmessage Ping {\n}",
+				"// TODO: schema Ack has no properties; add fields or remove it\nmessage Ack {\n}",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				EmptyMessages: test.mode,
+			})
+			require.NoError(t, err)
+
+			for _, want := range test.contains {
+				assert.Contains(t, string(result.Protobuf), want)
+			}
+			for _, unwanted := range test.notContains {
+				assert.NotContains(t, string(result.Protobuf), unwanted)
+			}
+			assert.Equal(t, test.aliasOf, result.TypeMap["Ack"].AliasOf)
+
+			// The descriptor resolves google.protobuf.Empty like protoc would
+			msg, err := result.NewMessage("Status")
+			require.NoError(t, err)
+			assert.Equal(t, 1, msg.Descriptor().Fields().Len())
+		})
+	}
+}
@@ -599,3 +599,141 @@ message Task {
 	require.NotNil(t, result)
 	assert.Equal(t, expected, string(result.Protobuf))
 }
+
+func TestEnumReferenceReuse(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		given    string
+		expected string
+	}{
+		{
+			name: "direct, array item and map value references",
+			given: `
+    Order:
+      type: object
+      properties:
+        status:
+          $ref: '#/components/schemas/Status'
+        history:
+          type: array
+          items:
+            $ref: '#/components/schemas/Status'
+        byRegion:
+          type: object
+          additionalProperties:
+            $ref: '#/components/schemas/Status'
+        line:
+          type: object
+          properties:
+            status:
+              $ref: '#/components/schemas/Status'
+    Status:
+      type: integer
+      enum: [1, 2]
+`,
+			expected: `message Order {
+  message Line {
+    Status status = 1 [json_name = "status"];
+  }
+
+  Status status = 1 [json_name = "status"];
+  repeated Status history = 2 [json_name = "history"];
+  map<string, Status> byRegion = 3 [json_name = "byRegion"];
+  Line line = 4 [json_name = "line"];
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_1 = 1;
+  STATUS_2 = 2;
+}
+`,
+		},
+		{
+			name: "inline enum declared before the component enum",
+			given: `
+    Order:
+      type: object
+      properties:
+        status:
+          type: integer
+          enum: [5, 6]
+        current:
+          $ref: '#/components/schemas/Status'
+        history:
+          type: array
+          items:
+            $ref: '#/components/schemas/Status'
+    Status:
+      type: integer
+      enum: [1, 2]
+`,
+			expected: `enum Status_2 {
+  STATUS_2_UNSPECIFIED = 0;
+  STATUS_2_5 = 1;
+  STATUS_2_6 = 2;
+}
+
+message Order {
+  Status_2 status = 1 [json_name = "status"];
+  Status current = 2 [json_name = "current"];
+  repeated Status history = 3 [json_name = "history"];
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_1 = 1;
+  STATUS_2 = 2;
+}
+`,
+		},
+		{
+			name: "schema name that is not PascalCase",
+			given: `
+    Line:
+      type: object
+      properties:
+        kind:
+          $ref: '#/components/schemas/order_kind'
+        kinds:
+          type: array
+          items:
+            $ref: '#/components/schemas/order_kind'
+    order_kind:
+      type: integer
+      enum: [1, 2]
+`,
+			expected: `message Line {
+  OrderKind kind = 1 [json_name = "kind"];
+  repeated OrderKind kinds = 2 [json_name = "kinds"];
+}
+
+enum OrderKind {
+  ORDER_KIND_UNSPECIFIED = 0;
+  ORDER_KIND_1 = 1;
+  ORDER_KIND_2 = 2;
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:` + test.given
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+
+			// The output compiles, so every reference names a declared enum
+			_, err = result.Files()
+			require.NoError(t, err)
+		})
+	}
+}
@@ -1,6 +1,72 @@
 package internal
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode classifies a conversion failure so callers can branch on it without
+// matching error text
+type ErrorCode string
+
+const (
+	CodeInternal             ErrorCode = "internal"
+	CodeParse                ErrorCode = "parse"
+	CodeLimitExceeded        ErrorCode = "limit_exceeded"
+	CodeUnsupportedAllOf     ErrorCode = "unsupported_all_of"
+	CodeUnsupportedAnyOf     ErrorCode = "unsupported_any_of"
+	CodeUnsupportedNot       ErrorCode = "unsupported_not"
+	CodeInvalidOneOf         ErrorCode = "invalid_one_of"
+	CodeInvalidDiscriminator ErrorCode = "invalid_discriminator"
+	CodeUnsupportedType      ErrorCode = "unsupported_type"
+	CodePluralInlineName     ErrorCode = "plural_inline_name"
+	CodeInvalidFieldName     ErrorCode = "invalid_field_name"
+	CodeInvalidFieldNumber   ErrorCode = "invalid_field_number"
+	CodeReservedFieldNumber  ErrorCode = "reserved_field_number"
+	CodeDuplicateFieldNumber ErrorCode = "duplicate_field_number"
+	CodeDuplicateJSONName    ErrorCode = "duplicate_json_name"
+	CodeMixedNumbering       ErrorCode = "mixed_numbering"
+	CodeInvalidEnum          ErrorCode = "invalid_enum"
+	CodeInvalidReference     ErrorCode = "invalid_reference"
+	CodeInvalidExtension     ErrorCode = "invalid_extension"
+	CodeInvalidExample       ErrorCode = "invalid_example"
+	CodeInvalidFixture       ErrorCode = "invalid_fixture"
+	CodeMissingDescription   ErrorCode = "missing_description"
+)
+
+// CodedError attaches an ErrorCode to an error without changing its message. Context
+// added by wrapping with %w keeps the code reachable through errors.As.
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// WithCode attaches code to err
+func WithCode(code ErrorCode, err error) error {
+	return &CodedError{Code: code, Err: err}
+}
+
+// codeOf returns the code attached to err, or CodeInternal if there is none
+func codeOf(err error) ErrorCode {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return CodeInternal
+}
+
+// Errorf formats an error like fmt.Errorf and attaches code to it
+func Errorf(code ErrorCode, format string, args ...any) error {
+	return &CodedError{Code: code, Err: fmt.Errorf(format, args...)}
+}
 
 // SchemaError creates an error with schema context.
 // Format: schema '<name>': <message>
@@ -8,20 +74,45 @@ func SchemaError(schemaName, message string) error {
 	return fmt.Errorf("schema '%s': %s", schemaName, message)
 }
 
+// WrapSchemaError adds schema context to err, keeping its code.
+// Format: schema '<name>': <err>
+func WrapSchemaError(schemaName string, err error) error {
+	return fmt.Errorf("schema '%s': %w", schemaName, err)
+}
+
 // PropertyError creates an error with schema and property context.
 // Format: schema '<schema>': property '<prop>' <message>
 func PropertyError(schemaName, propertyName, message string) error {
 	return fmt.Errorf("schema '%s': property '%s' %s", schemaName, propertyName, message)
 }
 
+// WrapPropertyError adds schema and property context to err, keeping its code.
+// Format: schema '<schema>': property '<prop>' <err>
+func WrapPropertyError(schemaName, propertyName string, err error) error {
+	return fmt.Errorf("schema '%s': property '%s' %w", schemaName, propertyName, err)
+}
+
 // UnsupportedError creates an error for unsupported features.
 // Format: schema '<schema>': property '<prop>' uses '<feature>' which is not supported
 func UnsupportedError(schemaName, propertyName, feature string) error {
-	return fmt.Errorf("schema '%s': property '%s' uses '%s' which is not supported", schemaName, propertyName, feature)
+	return Errorf(unsupportedCode(feature), "schema '%s': property '%s' uses '%s' which is not supported", schemaName, propertyName, feature)
 }
 
 // UnsupportedSchemaError creates an error for unsupported features at the schema level.
 // Format: schema '<name>': uses '<feature>' which is not supported
 func UnsupportedSchemaError(schemaName, feature string) error {
-	return fmt.Errorf("schema '%s': uses '%s' which is not supported", schemaName, feature)
+	return Errorf(unsupportedCode(feature), "schema '%s': uses '%s' which is not supported", schemaName, feature)
+}
+
+// unsupportedCode returns the code for an unsupported schema keyword
+func unsupportedCode(feature string) ErrorCode {
+	switch feature {
+	case "allOf":
+		return CodeUnsupportedAllOf
+	case "anyOf":
+		return CodeUnsupportedAnyOf
+	case "not":
+		return CodeUnsupportedNot
+	}
+	return CodeUnsupportedType
 }
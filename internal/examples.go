@@ -0,0 +1,329 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// exampleBuilder converts OpenAPI example values to the protobuf JSON form of the
+// messages built from them
+type exampleBuilder struct {
+	messages map[string]*ProtoMessage
+	enums    map[string]*ProtoEnum
+}
+
+// BuildExamples creates a sample protobuf JSON document for each top-level message using
+// the OpenAPI example/examples values. A schema-level example is used as the document,
+// otherwise the document is composed from property examples. Either way the document is
+// then converted field by field to protobuf JSON, so nested enums, dates and durations
+// take the form of the fields they populate. Messages without any examples are omitted.
+func BuildExamples(entries []*parser.SchemaEntry, messages []*ProtoMessage, enums []*ProtoEnum) (map[string][]byte, error) {
+	proxies := make(map[string]*base.SchemaProxy, len(entries))
+	for _, entry := range entries {
+		proxies[entry.Name] = entry.Proxy
+	}
+	b := &exampleBuilder{
+		messages: make(map[string]*ProtoMessage, len(messages)),
+		enums:    make(map[string]*ProtoEnum, len(enums)),
+	}
+	for _, msg := range messages {
+		b.messages[msg.Name] = msg
+	}
+	for _, enum := range enums {
+		b.enums[enum.Name] = enum
+	}
+
+	examples := make(map[string][]byte)
+	for _, msg := range messages {
+		proxy, ok := proxies[msg.OriginalSchema]
+		if !ok {
+			continue
+		}
+
+		value, found, err := exampleValue(proxy, map[string]bool{msg.OriginalSchema: true})
+		if err != nil {
+			return nil, WithCode(CodeInvalidExample, SchemaError(msg.OriginalSchema, fmt.Sprintf("invalid example: %v", err)))
+		}
+		if !found {
+			continue
+		}
+
+		doc, err := json.MarshalIndent(b.messageValue(msg, value), "", "  ")
+		if err != nil {
+			return nil, WithCode(CodeInvalidExample, SchemaError(msg.OriginalSchema, fmt.Sprintf("failed to encode example: %v", err)))
+		}
+		examples[msg.Name] = append(doc, '\n')
+	}
+
+	return examples, nil
+}
+
+// exampleValue returns the example for a schema, composing object and array examples
+// from their members when the schema has no example of its own.
+// visiting guards against recursive references.
+func exampleValue(proxy *base.SchemaProxy, visiting map[string]bool) (interface{}, bool, error) {
+	schema := proxy.Schema()
+	if schema == nil {
+		return nil, false, nil
+	}
+
+	if proxy.IsReference() {
+		refName, err := extractReferenceName(proxy.GetReference())
+		if err != nil {
+			return nil, false, nil
+		}
+		if visiting[refName] {
+			return nil, false, nil
+		}
+		visiting[refName] = true
+		defer delete(visiting, refName)
+	}
+
+	node := schema.Example
+	if node == nil && len(schema.Examples) > 0 {
+		node = schema.Examples[0]
+	}
+	if node != nil {
+		value, err := nodeValue(node)
+		if err != nil {
+			return nil, false, err
+		}
+		return value, true, nil
+	}
+
+	if len(schema.Type) > 0 && contains(schema.Type, "array") {
+		if schema.Items == nil || schema.Items.A == nil {
+			return nil, false, nil
+		}
+		item, found, err := exampleValue(schema.Items.A, visiting)
+		if err != nil || !found {
+			return nil, false, err
+		}
+		return []interface{}{item}, true, nil
+	}
+
+	if schema.Properties == nil {
+		return nil, false, nil
+	}
+
+	object := &orderedObject{}
+	for propName, propProxy := range schema.Properties.FromOldest() {
+		value, found, err := exampleValue(propProxy, visiting)
+		if err != nil {
+			return nil, false, fmt.Errorf("property '%s': %w", propName, err)
+		}
+		if found {
+			object.keys = append(object.keys, propName)
+			object.values = append(object.values, value)
+		}
+	}
+
+	if len(object.keys) == 0 {
+		return nil, false, nil
+	}
+	return object, true, nil
+}
+
+// messageValue converts an example object to the protobuf JSON form of msg. Properties
+// without a field in msg are dropped, as protobuf JSON parsers reject unknown fields.
+func (b *exampleBuilder) messageValue(msg *ProtoMessage, value interface{}) interface{} {
+	object, ok := value.(*orderedObject)
+	if !ok {
+		return value
+	}
+
+	fields := make(map[string]*ProtoField, len(msg.Fields))
+	for _, field := range msg.Fields {
+		fields[field.JSONName] = field
+	}
+
+	result := &orderedObject{}
+	for i, key := range object.keys {
+		field, ok := fields[key]
+		if !ok {
+			continue
+		}
+		result.keys = append(result.keys, key)
+		result.values = append(result.values, b.fieldValue(msg, field, object.values[i]))
+	}
+	return result
+}
+
+// fieldValue converts the example value of a field, converting each element of repeated
+// fields and each value of map fields
+func (b *exampleBuilder) fieldValue(msg *ProtoMessage, field *ProtoField, value interface{}) interface{} {
+	if field.MapKey != "" {
+		object, ok := value.(*orderedObject)
+		if !ok {
+			return value
+		}
+		result := &orderedObject{keys: object.keys}
+		for _, item := range object.values {
+			result.values = append(result.values, b.typeValue(msg, field.Type, item))
+		}
+		return result
+	}
+
+	if field.Repeated {
+		items, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		result := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			result = append(result, b.typeValue(msg, field.Type, item))
+		}
+		return result
+	}
+
+	return b.typeValue(msg, field.Type, value)
+}
+
+// typeValue adjusts a single value to the protobuf JSON form of the proto type typ:
+// integer enums use the name of the generated value, dates and times become
+// google.type.Date and google.type.TimeOfDay objects, durations are written in seconds
+// and message values are converted field by field. msg is the message declaring the
+// field, which holds nested types.
+func (b *exampleBuilder) typeValue(msg *ProtoMessage, typ string, value interface{}) interface{} {
+	switch typ {
+	case dateType:
+		if t, ok := value.(time.Time); ok {
+			value = t.Format("2006-01-02")
+		}
+		if t, err := time.Parse("2006-01-02", fmt.Sprint(value)); err == nil {
+			return &orderedObject{
+				keys:   []string{"year", "month", "day"},
+				values: []interface{}{t.Year(), int(t.Month()), t.Day()},
+			}
+		}
+		return value
+	case timeOfDayType:
+		if t, err := parseTimeOfDay(fmt.Sprint(value)); err == nil {
+			object := &orderedObject{
+				keys:   []string{"hours", "minutes", "seconds"},
+				values: []interface{}{t.Hour(), t.Minute(), t.Second()},
+			}
+			if t.Nanosecond() != 0 {
+				object.keys = append(object.keys, "nanos")
+				object.values = append(object.values, t.Nanosecond())
+			}
+			return object
+		}
+		return value
+	case durationType:
+		if d, err := parseDuration(fmt.Sprint(value)); err == nil {
+			return durationJSON(d)
+		}
+		return value
+	}
+
+	for _, nested := range msg.Nested {
+		if nested.Name == typ {
+			return b.messageValue(nested, value)
+		}
+	}
+	if ref, ok := b.messages[typ]; ok {
+		return b.messageValue(ref, value)
+	}
+
+	enum, ok := b.enums[typ]
+	for _, nested := range msg.Enums {
+		if nested.Name == typ {
+			enum, ok = nested, true
+		}
+	}
+	if ok {
+		name := ToEnumValueName(enum.Name, fmt.Sprint(value))
+		for _, enumValue := range enum.Values {
+			if enumValue.Name == name {
+				return name
+			}
+		}
+	}
+
+	return value
+}
+
+// nodeValue converts a YAML node into a JSON-encodable value, preserving mapping key order
+func nodeValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return nodeValue(node.Content[0])
+
+	case yaml.MappingNode:
+		object := &orderedObject{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := nodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, node.Content[i].Value)
+			object.values = append(object.values, value)
+		}
+		return object, nil
+
+	case yaml.SequenceNode:
+		values := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := nodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+
+	case yaml.AliasNode:
+		return nodeValue(node.Alias)
+
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// orderedObject is a JSON object that marshals its keys in insertion order
+type orderedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+// MarshalJSON writes the object keys in insertion order
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+
+		encodedValue, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encodedValue)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,312 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExamplesOutput(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		given    string
+		expected map[string]string
+	}{
+		{
+			name: "schema level example used as-is",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      example:
+        name: Alice
+        age: 30
+      properties:
+        name:
+          type: string
+        age:
+          type: integer
+`,
+			expected: map[string]string{
+				"User": "{\n  \"name\": \"Alice\",\n  \"age\": 30\n}\n",
+			},
+		},
+		{
+			name: "composed from property examples in spec order",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        orderId:
+          type: string
+          example: ord-123
+        notes:
+          type: string
+        tags:
+          type: array
+          items:
+            type: string
+            example: rush
+        total:
+          type: number
+          examples:
+            - 19.99
+            - 5
+`,
+			expected: map[string]string{
+				"Order": "{\n  \"orderId\": \"ord-123\",\n  \"tags\": [\n    \"rush\"\n  ],\n  \"total\": 19.99\n}\n",
+			},
+		},
+		{
+			name: "references and nested objects are composed",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+          example: Paris
+    Customer:
+      type: object
+      properties:
+        home:
+          $ref: '#/components/schemas/Address'
+        contact:
+          type: object
+          properties:
+            email:
+              type: string
+              example: a@example.com
+`,
+			expected: map[string]string{
+				"Address":  "{\n  \"city\": \"Paris\"\n}\n",
+				"Customer": "{\n  \"home\": {\n    \"city\": \"Paris\"\n  },\n  \"contact\": {\n    \"email\": \"a@example.com\"\n  }\n}\n",
+			},
+		},
+		{
+			name: "protobuf JSON forms for enums and dates",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Code:
+      type: integer
+      example: 404
+      enum:
+        - 200
+        - 404
+    Response:
+      type: object
+      properties:
+        code:
+          $ref: '#/components/schemas/Code'
+        day:
+          type: string
+          format: date
+          example: '2024-03-01'
+`,
+			expected: map[string]string{
+				"Response": "{\n  \"code\": \"CODE_404\",\n  \"day\": {\n    \"year\": 2024,\n    \"month\": 3,\n    \"day\": 1\n  }\n}\n",
+			},
+		},
+		{
+			name: "schema level example converted through field schemas",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Level:
+      type: integer
+      enum:
+        - 1
+        - 2
+    Alert:
+      type: object
+      properties:
+        level:
+          $ref: '#/components/schemas/Level'
+        until:
+          type: string
+          format: date
+    Incident:
+      type: object
+      example:
+        alert:
+          level: 2
+          until: '2024-03-01'
+        history:
+          - level: 1
+        severity: 404
+        extra: dropped
+      properties:
+        alert:
+          $ref: '#/components/schemas/Alert'
+        history:
+          type: array
+          items:
+            $ref: '#/components/schemas/Alert'
+        severity:
+          type: integer
+          enum:
+            - 500
+            - 404
+`,
+			expected: map[string]string{
+				"Incident": "{\n  \"alert\": {\n    \"level\": \"LEVEL_2\",\n    \"until\": {\n      \"year\": 2024,\n      \"month\": 3,\n      \"day\": 1\n    }\n  },\n  \"history\": [\n    {\n      \"level\": \"LEVEL_1\"\n    }\n  ],\n  \"severity\": \"SEVERITY_404\"\n}\n",
+			},
+		},
+		{
+			name: "recursive references terminate",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Node:
+      type: object
+      properties:
+        label:
+          type: string
+          example: root
+        child:
+          $ref: '#/components/schemas/Node'
+`,
+			expected: map[string]string{
+				"Node": "{\n  \"label\": \"root\"\n}\n",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(test.given), conv.ConvertOptions{
+				PackageName:  "testpkg",
+				PackagePath:  "github.com/example/proto/v1",
+				EmitExamples: true,
+			})
+			require.NoError(t, err)
+
+			actual := make(map[string]string, len(result.Examples))
+			for name, doc := range result.Examples {
+				actual[name] = string(doc)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestExamplesDisabledByDefault(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Alice
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, result.Examples)
+}
+
+func TestExampleOption(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Address:
+      type: object
+      example:
+        city: Paris
+      properties:
+        city:
+          type: string
+    User:
+      type: object
+      properties:
+        email:
+          type: string
+          example: "jane \"j\" doe@example.com"
+        age:
+          type: integer
+          example: 42
+        tags:
+          type: array
+          items:
+            type: string
+          example: [admin, ops]
+        nickname:
+          type: string
+        address:
+          $ref: '#/components/schemas/Address'
+`
+
+	for _, test := range []struct {
+		name     string
+		option   conv.ExampleOption
+		expected []string
+		absent   []string
+	}{
+		{
+			name:   "disabled",
+			absent: []string{"(api.example)", "import \"api/annotations.proto\";"},
+		},
+		{
+			name:   "enabled",
+			option: conv.ExampleOption{Name: "api.example", Import: "api/annotations.proto"},
+			expected: []string{
+				"import \"api/annotations.proto\";",
+				`string email = 1 [json_name = "email", (api.example) = "jane \"j\" doe@example.com"];`,
+				`int32 age = 2 [json_name = "age", (api.example) = "42"];`,
+				`repeated string tags = 3 [json_name = "tags", (api.example) = "[\"admin\",\"ops\"]"];`,
+				`string nickname = 4 [json_name = "nickname"];`,
+				`Address address = 5 [json_name = "address"];`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				ExampleOption: test.option,
+			})
+			require.NoError(t, err)
+			for _, expected := range test.expected {
+				assert.Contains(t, string(result.Protobuf), expected)
+			}
+			for _, absent := range test.absent {
+				assert.NotContains(t, string(result.Protobuf), absent)
+			}
+		})
+	}
+}
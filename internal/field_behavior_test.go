@@ -0,0 +1,124 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldBehavior(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Book:
+      type: object
+      properties:
+        name:
+          type: string
+          x-proto-field-behavior: [IDENTIFIER]
+        title:
+          type: string
+          x-proto-field-behavior: [REQUIRED, IMMUTABLE]
+        createdAt:
+          type: string
+          format: date-time
+          x-proto-field-behavior: [OUTPUT_ONLY]
+        author:
+          type: object
+          properties:
+            id:
+              type: string
+              x-proto-field-behavior: [REQUIRED]
+        notes:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `syntax = "proto3";
+
+package testpkg;
+
+import "google/api/field_behavior.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/example/proto/v1";
+
+message Book {
+  message Author {
+    string id = 1 [json_name = "id", (google.api.field_behavior) = REQUIRED];
+  }
+
+  string name = 1 [json_name = "name", (google.api.field_behavior) = IDENTIFIER];
+  string title = 2 [json_name = "title", (google.api.field_behavior) = REQUIRED, (google.api.field_behavior) = IMMUTABLE];
+  google.protobuf.Timestamp createdAt = 3 [json_name = "createdAt", (google.api.field_behavior) = OUTPUT_ONLY];
+  Author author = 4 [json_name = "author"];
+  string notes = 5 [json_name = "notes"];
+}
+
+`, string(result.Protobuf))
+}
+
+func TestFieldBehaviorErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		property string
+		wantErr  string
+	}{
+		{
+			name: "unknown value",
+			property: `        title:
+          type: string
+          x-proto-field-behavior: [REQUIRED, MANDATORY]
+`,
+			wantErr: "schema 'Book': property 'title' x-proto-field-behavior has unknown value: MANDATORY",
+		},
+		{
+			name: "not a list",
+			property: `        title:
+          type: string
+          x-proto-field-behavior: REQUIRED
+`,
+			wantErr: "x-proto-field-behavior must be a list, got: REQUIRED",
+		},
+		{
+			name: "nested property",
+			property: `        author:
+          type: object
+          properties:
+            id:
+              type: string
+              x-proto-field-behavior: [required]
+`,
+			wantErr: "property 'id': x-proto-field-behavior has unknown value: required",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Book:
+      type: object
+      properties:
+` + test.property
+
+			_, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			require.ErrorContains(t, err, test.wantErr)
+		})
+	}
+}
@@ -1515,3 +1515,297 @@ message Product {
 		})
 	}
 }
+
+func TestFieldNumberScoping(t *testing.T) {
+	header := `openapi: 3.1.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+`
+
+	for _, test := range []struct {
+		name     string
+		given    string
+		expected string
+		wantErr  string
+	}{
+		{
+			name: "mixed numbering in top-level schema",
+			given: header + `    User:
+      type: object
+      properties:
+        id:
+          type: string
+          x-proto-number: 1
+        name:
+          type: string
+`,
+			wantErr: "schema 'User': x-proto-number must be specified on all fields or none (found on 1 of 2 fields)",
+		},
+		{
+			name: "mixed numbering in inline object",
+			given: header + `    User:
+      type: object
+      properties:
+        profile:
+          type: object
+          properties:
+            bio:
+              type: string
+              x-proto-number: 1
+            photo:
+              type: string
+`,
+			wantErr: "schema 'User': property 'profile' x-proto-number must be specified on all fields or none " +
+				"(found on 1 of 2 fields); inline objects and array items are numbered separately from their parent",
+		},
+		{
+			name: "mixed numbering in array item object",
+			given: header + `    User:
+      type: object
+      properties:
+        entry:
+          type: array
+          items:
+            type: object
+            properties:
+              bio:
+                type: string
+                x-proto-number: 1
+              photo:
+                type: string
+`,
+			wantErr: "schema 'User': property 'entry' x-proto-number must be specified on all fields or none " +
+				"(found on 1 of 2 fields); inline objects and array items are numbered separately from their parent",
+		},
+		{
+			name: "reserved number in array item object",
+			given: header + `    User:
+      type: object
+      properties:
+        entry:
+          type: array
+          items:
+            type: object
+            properties:
+              bio:
+                type: string
+                x-proto-number: 19001
+`,
+			wantErr: "schema 'User': property 'entry' property 'bio': x-proto-number 19001 is in reserved range 19000-19999",
+		},
+		{
+			name: "x-proto-number next to $ref",
+			given: header + `    User:
+      type: object
+      properties:
+        id:
+          type: string
+          x-proto-number: 1
+        address:
+          $ref: '#/components/schemas/Address'
+          x-proto-number: 5
+    Address:
+      type: object
+      properties:
+        street:
+          type: string
+`,
+			expected: `message User {
+  string id = 1 [json_name = "id"];
+  Address address = 5 [json_name = "address"];
+}
+`,
+		},
+		{
+			name: "x-proto-number on $ref target is not used by referencing property",
+			given: header + `    User:
+      type: object
+      properties:
+        id:
+          type: string
+        address:
+          $ref: '#/components/schemas/Address'
+    Address:
+      type: object
+      x-proto-number: 7
+      properties:
+        street:
+          type: string
+`,
+			expected: `message User {
+  string id = 1 [json_name = "id"];
+  Address address = 2 [json_name = "address"];
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(test.given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
+
+func TestFieldNumberStartStride(t *testing.T) {
+	header := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+`
+
+	for _, test := range []struct {
+		name     string
+		given    string
+		expected string
+		wantErr  string
+	}{
+		{
+			name: "start and stride",
+			given: header + `    User:
+      type: object
+      x-proto-number-start: 100
+      x-proto-number-stride: 10
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+        email:
+          type: string
+`,
+			expected: `message User {
+  string id = 100 [json_name = "id"];
+  string name = 110 [json_name = "name"];
+  string email = 120 [json_name = "email"];
+}
+`,
+		},
+		{
+			name: "start only on inline object",
+			given: header + `    User:
+      type: object
+      properties:
+        id:
+          type: string
+        profile:
+          type: object
+          x-proto-number-start: 50
+          properties:
+            bio:
+              type: string
+            photo:
+              type: string
+`,
+			expected: `message User {
+  message Profile {
+    string bio = 50 [json_name = "bio"];
+    string photo = 51 [json_name = "photo"];
+  }
+
+  string id = 1 [json_name = "id"];
+  Profile profile = 2 [json_name = "profile"];
+}
+`,
+		},
+		{
+			name: "stride only on array items",
+			given: header + `    User:
+      type: object
+      properties:
+        entry:
+          type: array
+          items:
+            type: object
+            x-proto-number-stride: 5
+            properties:
+              key:
+                type: string
+              value:
+                type: string
+`,
+			expected: `  message Entry {
+    string key = 1 [json_name = "key"];
+    string value = 6 [json_name = "value"];
+  }
+`,
+		},
+		{
+			name: "invalid start",
+			given: header + `    User:
+      type: object
+      x-proto-number-start: 0
+      properties:
+        id:
+          type: string
+`,
+			wantErr: "schema 'User': x-proto-number-start must be an integer between 1 and 536870911, got: 0",
+		},
+		{
+			name: "invalid stride",
+			given: header + `    User:
+      type: object
+      x-proto-number-stride: two
+      properties:
+        id:
+          type: string
+`,
+			wantErr: "schema 'User': x-proto-number-stride must be an integer between 1 and 536870911, got: two",
+		},
+		{
+			name: "combined with x-proto-number",
+			given: header + `    User:
+      type: object
+      x-proto-number-start: 100
+      properties:
+        id:
+          type: string
+          x-proto-number: 1
+`,
+			wantErr: "schema 'User': x-proto-number-start and x-proto-number-stride cannot be combined with x-proto-number on fields",
+		},
+		{
+			name: "reaches reserved range",
+			given: header + `    User:
+      type: object
+      x-proto-number-start: 18000
+      x-proto-number-stride: 1000
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+`,
+			wantErr: "schema 'User': property 'name' x-proto-number-start and x-proto-number-stride assign 19000, which is in reserved range 19000-19999",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(test.given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
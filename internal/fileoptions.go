@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"strconv"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// FileOption is a file-level option rendered after go_package, e.g. java_package
+type FileOption struct {
+	Name  string
+	Value string // Unquoted value, "true" or "false" for boolean options
+	Bool  bool
+}
+
+// Literal returns the value as written in the proto file
+func (o FileOption) Literal() string {
+	if o.Bool {
+		return o.Value
+	}
+	return strconv.Quote(o.Value)
+}
+
+// languageOptions maps extensions of the info object to the file options they set, in
+// output order
+var languageOptions = []struct {
+	extension string
+	option    string
+	boolean   bool
+}{
+	{extension: "x-java-package", option: "java_package"},
+	{extension: "x-java-outer-classname", option: "java_outer_classname"},
+	{extension: "x-java-multiple-files", option: "java_multiple_files", boolean: true},
+	{extension: "x-csharp-namespace", option: "csharp_namespace"},
+	{extension: "x-objc-class-prefix", option: "objc_class_prefix"},
+	{extension: "x-php-namespace", option: "php_namespace"},
+	{extension: "x-ruby-package", option: "ruby_package"},
+	{extension: "x-swift-prefix", option: "swift_prefix"},
+}
+
+// BuildFileOptions returns the file options requested by x-java-package style extensions
+// of the info object, so consumers in other languages get their own namespaces
+func BuildFileOptions(extensions map[string]*yaml.Node) ([]FileOption, error) {
+	var options []FileOption
+	for _, lang := range languageOptions {
+		node, ok := extensions[lang.extension]
+		if !ok || node == nil {
+			continue
+		}
+		if lang.boolean {
+			var value bool
+			if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" || node.Decode(&value) != nil {
+				return nil, Errorf(CodeInvalidExtension, "%s must be a boolean, got: %s", lang.extension, node.Value)
+			}
+			// YAML also spells booleans True or TRUE, which protoc rejects
+			options = append(options, FileOption{Name: lang.option, Value: strconv.FormatBool(value), Bool: true})
+			continue
+		}
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!str" || node.Value == "" {
+			return nil, Errorf(CodeInvalidExtension, "%s must be a non-empty string, got: %s", lang.extension, node.Value)
+		}
+		options = append(options, FileOption{Name: lang.option, Value: node.Value})
+	}
+	return options, nil
+}
@@ -0,0 +1,113 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertLanguageFileOptions(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		info     string
+		expected string
+		err      string
+	}{
+		{
+			name: "none",
+			expected: `option go_package = "github.com/example/proto/v1";
+
+message User {`,
+		},
+		{
+			name: "java and csharp",
+			info: `
+  x-csharp-namespace: Example.Api.V1
+  x-java-multiple-files: true
+  x-java-package: com.example.api.v1
+  x-java-outer-classname: ApiProto`,
+			expected: `option go_package = "github.com/example/proto/v1";
+option java_package = "com.example.api.v1";
+option java_outer_classname = "ApiProto";
+option java_multiple_files = true;
+option csharp_namespace = "Example.Api.V1";
+
+message User {`,
+		},
+		{
+			name: "capitalized boolean",
+			info: `
+  x-java-multiple-files: True`,
+			expected: `option go_package = "github.com/example/proto/v1";
+option java_multiple_files = true;
+
+message User {`,
+		},
+		{
+			name: "non-boolean multiple files",
+			info: `
+  x-java-multiple-files: "yes"`,
+			err: "x-java-multiple-files must be a boolean, got: yes",
+		},
+		{
+			name: "non-string package",
+			info: `
+  x-java-package: 42`,
+			err: "x-java-package must be a non-empty string, got: 42",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0` + test.info + `
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
+
+func TestFileDescriptorLanguageOptions(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+  x-java-package: com.example.api.v1
+  x-java-multiple-files: true
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	fd, err := result.FileDescriptor()
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.api.v1", fd.GetOptions().GetJavaPackage())
+	assert.True(t, fd.GetOptions().GetJavaMultipleFiles())
+}
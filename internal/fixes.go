@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Fix is a JSON Patch (RFC 6902) operation on the OpenAPI document. Pointers address
+// the document as parsed, so they apply equally to YAML and JSON specs.
+type Fix struct {
+	Op    string // "add", "replace" or "move"
+	Path  string // JSON pointer of the location changed
+	From  string // JSON pointer of the source location, for "move"
+	Value any    // New value, for "add" and "replace"
+}
+
+// schemaPointer returns the JSON pointer of a component schema
+func schemaPointer(name string) string {
+	return "/components/schemas/" + escapePointer(name)
+}
+
+// escapePointer escapes a JSON pointer reference token
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// extractFixes moves the inline schema at pointer, named after a plural property, into
+// a component schema and references it instead. Unlike renaming the property this keeps
+// the JSON wire format unchanged.
+func (l *linter) extractFixes(schemaName, propName, pointer string, proxy *base.SchemaProxy) []Fix {
+	node := proxy.GetValueNode()
+	if node == nil {
+		return nil
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil
+	}
+
+	taken := func(name string) bool { return l.schemas[name] || l.added[name] }
+	name := ToPascalCase(singular(propName))
+	if taken(name) {
+		name = ToPascalCase(schemaName) + name
+	}
+	for i, prefixed := 2, name; taken(name); i++ {
+		name = prefixed + strconv.Itoa(i)
+	}
+	l.added[name] = true
+
+	return []Fix{
+		{Op: "add", Path: schemaPointer(name), Value: value},
+		{Op: "replace", Path: pointer, Value: map[string]any{"$ref": "#/components/schemas/" + name}},
+	}
+}
+
+// fieldNumberFixes adds x-proto-number to the properties of schema that lack one when
+// err reports mixed numbering. Numbers are assigned in property order, skipping those
+// already used and the reserved range.
+func fieldNumberFixes(schema *base.Schema, pointer string, err error) []Fix {
+	if codeOf(err) != CodeMixedNumbering {
+		return nil
+	}
+
+	used := make(map[int]bool)
+	for _, propProxy := range schema.Properties.FromOldest() {
+		if num, found, _ := extractFieldNumber(propProxy); found {
+			used[num] = true
+		}
+	}
+
+	var fixes []Fix
+	next := 1
+	for propName, propProxy := range schema.Properties.FromOldest() {
+		if _, found, _ := extractFieldNumber(propProxy); found {
+			continue
+		}
+		for used[next] || (next >= 19000 && next <= 19999) {
+			next++
+		}
+		used[next] = true
+		fixes = append(fixes, Fix{
+			Op:    "add",
+			Path:  pointer + "/properties/" + escapePointer(propName) + "/x-proto-number",
+			Value: next,
+		})
+	}
+	return fixes
+}
+
+// renameFixes renames a property whose name cannot start a proto field name, dropping
+// leading underscores or prefixing "field_" to a leading digit, and updates required.
+// This changes the JSON name of the property, so it is only a suggestion.
+func renameFixes(schema *base.Schema, pointer, propName string) []Fix {
+	name := strings.TrimLeft(propName, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "field_" + name
+	}
+	if _, err := SanitizeFieldName(name); err != nil {
+		return nil
+	}
+	if _, exists := schema.Properties.Get(name); exists {
+		return nil
+	}
+
+	fixes := []Fix{{
+		Op:   "move",
+		From: pointer + "/properties/" + escapePointer(propName),
+		Path: pointer + "/properties/" + escapePointer(name),
+	}}
+	for i, required := range schema.Required {
+		if required == propName {
+			fixes = append(fixes, Fix{Op: "replace", Path: fmt.Sprintf("%s/required/%d", pointer, i), Value: name})
+		}
+	}
+	return fixes
+}
+
+// singular guesses the singular form of a plural property name
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"),
+		strings.HasSuffix(name, "shes"), strings.HasSuffix(name, "zes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "ss"):
+		return name
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name
+}
@@ -0,0 +1,358 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// defaultFixtureTime is used for timestamp fields without an example
+var defaultFixtureTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fixtureBuilder renders populated text format instances of proto messages
+type fixtureBuilder struct {
+	proxies  map[string]*base.SchemaProxy
+	messages map[string]*ProtoMessage
+	enums    map[string]*ProtoEnum
+}
+
+// BuildFixtures creates a populated protobuf text format instance of each top-level message.
+// Values come from OpenAPI examples when present, otherwise from minimum/maximum boundaries,
+// the first enum member or a placeholder. Repeated fields get a single element and
+// recursive references are left unset.
+func BuildFixtures(entries []*parser.SchemaEntry, messages []*ProtoMessage, enums []*ProtoEnum) (map[string][]byte, error) {
+	b := &fixtureBuilder{
+		proxies:  make(map[string]*base.SchemaProxy, len(entries)),
+		messages: make(map[string]*ProtoMessage, len(messages)),
+		enums:    make(map[string]*ProtoEnum, len(enums)),
+	}
+	for _, entry := range entries {
+		b.proxies[entry.Name] = entry.Proxy
+	}
+	for _, msg := range messages {
+		b.messages[msg.Name] = msg
+	}
+	for _, enum := range enums {
+		b.enums[enum.Name] = enum
+	}
+
+	fixtures := make(map[string][]byte, len(messages))
+	for _, msg := range messages {
+		proxy, ok := b.proxies[msg.OriginalSchema]
+		if !ok {
+			continue
+		}
+
+		var result strings.Builder
+		if err := b.writeMessage(&result, msg, proxy.Schema(), "", map[string]bool{msg.Name: true}); err != nil {
+			return nil, WithCode(CodeInvalidFixture, SchemaError(msg.OriginalSchema, fmt.Sprintf("failed to build fixture: %v", err)))
+		}
+		fixtures[msg.Name] = []byte(result.String())
+	}
+
+	return fixtures, nil
+}
+
+// writeMessage writes the fields of msg using schema for example and boundary values
+func (b *fixtureBuilder) writeMessage(result *strings.Builder, msg *ProtoMessage, schema *base.Schema, indent string, visiting map[string]bool) error {
+	if schema == nil || schema.Properties == nil {
+		return nil
+	}
+
+	for _, field := range msg.Fields {
+		proxy, ok := schema.Properties.Get(field.JSONName)
+		if !ok || proxy.Schema() == nil {
+			continue
+		}
+
+		if field.Repeated {
+			items := proxy.Schema().Items
+			if items == nil || items.A == nil {
+				continue
+			}
+			proxy = items.A
+		}
+
+		if err := b.writeField(result, msg, field, proxy.Schema(), indent, visiting); err != nil {
+			return fmt.Errorf("property '%s': %w", field.JSONName, err)
+		}
+	}
+
+	return nil
+}
+
+// writeField writes a single field value. schema describes the value (the item schema for repeated fields).
+func (b *fixtureBuilder) writeField(result *strings.Builder, msg *ProtoMessage, field *ProtoField, schema *base.Schema, indent string, visiting map[string]bool) error {
+	// Map keys are not known from the schema, so maps are left empty
+	if schema == nil || field.MapKey != "" {
+		return nil
+	}
+
+	if field.Type == "google.protobuf.Timestamp" {
+		t, err := fixtureTime(schema)
+		if err != nil {
+			return err
+		}
+		result.WriteString(fmt.Sprintf("%s%s {\n%s  seconds: %d\n%s}\n", indent, field.Name, indent, t.Unix(), indent))
+		return nil
+	}
+
+	switch field.Type {
+	case dateType:
+		t, err := fixtureTime(schema)
+		if err != nil {
+			return err
+		}
+		result.WriteString(fmt.Sprintf("%s%s {\n%s  year: %d\n%s  month: %d\n%s  day: %d\n%s}\n",
+			indent, field.Name, indent, t.Year(), indent, int(t.Month()), indent, t.Day(), indent))
+		return nil
+	case timeOfDayType:
+		t, err := fixtureTimeOfDay(schema)
+		if err != nil {
+			return err
+		}
+		result.WriteString(fmt.Sprintf("%s%s {\n%s  hours: %d\n%s  minutes: %d\n%s  seconds: %d\n%s  nanos: %d\n%s}\n",
+			indent, field.Name, indent, t.Hour(), indent, t.Minute(), indent, t.Second(), indent, t.Nanosecond(), indent))
+		return nil
+	case durationType:
+		d, err := fixtureDuration(schema)
+		if err != nil {
+			return err
+		}
+		result.WriteString(fmt.Sprintf("%s%s {\n%s  seconds: %d\n%s  nanos: %d\n%s}\n",
+			indent, field.Name, indent, int64(d/time.Second), indent, int32(d%time.Second), indent))
+		return nil
+	}
+
+	if scalar, ok := unwrapType(field.Type); ok {
+		wrapped := *field
+		wrapped.Type = scalar
+		value, err := fixtureScalar(&wrapped, schema)
+		if err != nil {
+			return err
+		}
+		result.WriteString(fmt.Sprintf("%s%s {\n%s  value: %s\n%s}\n", indent, field.Name, indent, value, indent))
+		return nil
+	}
+
+	if enum, ok := b.enums[field.Type]; ok {
+		value, err := b.enumValue(enum, schema)
+		if err != nil {
+			return err
+		}
+		result.WriteString(fmt.Sprintf("%s%s: %s\n", indent, field.Name, value))
+		return nil
+	}
+
+	for _, nested := range msg.Nested {
+		if nested.Name == field.Type {
+			result.WriteString(fmt.Sprintf("%s%s {\n", indent, field.Name))
+			if err := b.writeMessage(result, nested, schema, indent+"  ", visiting); err != nil {
+				return err
+			}
+			result.WriteString(fmt.Sprintf("%s}\n", indent))
+			return nil
+		}
+	}
+
+	if ref, ok := b.messages[field.Type]; ok {
+		if visiting[ref.Name] {
+			return nil
+		}
+		visiting[ref.Name] = true
+		defer delete(visiting, ref.Name)
+
+		result.WriteString(fmt.Sprintf("%s%s {\n", indent, field.Name))
+		if err := b.writeMessage(result, ref, b.proxies[ref.OriginalSchema].Schema(), indent+"  ", visiting); err != nil {
+			return err
+		}
+		result.WriteString(fmt.Sprintf("%s}\n", indent))
+		return nil
+	}
+
+	value, err := fixtureScalar(field, schema)
+	if err != nil {
+		return err
+	}
+	result.WriteString(fmt.Sprintf("%s%s: %s\n", indent, field.Name, value))
+	return nil
+}
+
+// enumValue returns the enum value name for the schema example, or the first declared value
+func (b *fixtureBuilder) enumValue(enum *ProtoEnum, schema *base.Schema) (string, error) {
+	example, found, err := fixtureExample(schema)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		name := ToEnumValueName(enum.Name, fmt.Sprint(example))
+		for _, value := range enum.Values {
+			if value.Name == name {
+				return name, nil
+			}
+		}
+	}
+
+	// Skip the UNSPECIFIED zero value so the field is populated
+	if len(enum.Values) > 1 {
+		return enum.Values[1].Name, nil
+	}
+	return enum.Values[0].Name, nil
+}
+
+// fixtureScalar returns the text format literal for a scalar field
+func fixtureScalar(field *ProtoField, schema *base.Schema) (string, error) {
+	example, found, err := fixtureExample(schema)
+	if err != nil {
+		return "", err
+	}
+
+	switch field.Type {
+	case "string", "bytes":
+		if found {
+			return strconv.Quote(fmt.Sprint(example)), nil
+		}
+		if len(field.EnumValues) > 0 {
+			return strconv.Quote(field.EnumValues[0]), nil
+		}
+		if schema.MinLength != nil && *schema.MinLength > int64(len(field.Name)) {
+			return strconv.Quote(strings.Repeat("a", int(*schema.MinLength))), nil
+		}
+		return strconv.Quote(field.Name), nil
+
+	case "bool":
+		if found {
+			return fmt.Sprint(example), nil
+		}
+		return "true", nil
+
+	case "int32", "int64", "uint32", "uint64", "sint32", "sint64", "fixed32", "fixed64", "sfixed32", "sfixed64":
+		if found {
+			return fmt.Sprint(example), nil
+		}
+		return strconv.FormatFloat(fixtureBoundary(schema, 1, true), 'f', -1, 64), nil
+
+	case "double", "float":
+		if found {
+			return fmt.Sprint(example), nil
+		}
+		return strconv.FormatFloat(fixtureBoundary(schema, 1.5, false), 'f', -1, 64), nil
+
+	default:
+		return "", fmt.Errorf("unsupported fixture type '%s'", field.Type)
+	}
+}
+
+// fixtureBoundary returns the inclusive minimum, or the inclusive maximum when only a
+// maximum is set, or fallback when the schema has no bounds
+func fixtureBoundary(schema *base.Schema, fallback float64, integer bool) float64 {
+	step := 0.5
+	if integer {
+		step = 1
+	}
+
+	if schema.Minimum != nil {
+		value := *schema.Minimum
+		if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsA() && schema.ExclusiveMinimum.A {
+			value += step
+		}
+		if integer {
+			value = math.Ceil(value)
+		}
+		return value
+	}
+
+	if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsB() {
+		value := schema.ExclusiveMinimum.B + step
+		if integer {
+			value = math.Floor(schema.ExclusiveMinimum.B) + 1
+		}
+		return value
+	}
+
+	if schema.Maximum != nil {
+		value := *schema.Maximum
+		if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsA() && schema.ExclusiveMaximum.A {
+			value -= step
+		}
+		if integer {
+			value = math.Floor(value)
+		}
+		return value
+	}
+
+	if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsB() {
+		value := schema.ExclusiveMaximum.B - step
+		if integer {
+			value = math.Ceil(schema.ExclusiveMaximum.B) - 1
+		}
+		return value
+	}
+
+	return fallback
+}
+
+// fixtureTime returns the schema's date or date-time example, or a fixed default
+func fixtureTime(schema *base.Schema) (time.Time, error) {
+	example, found, err := fixtureExample(schema)
+	if err != nil || !found {
+		return defaultFixtureTime, err
+	}
+
+	if t, ok := example.(time.Time); ok {
+		return t, nil
+	}
+
+	text := fmt.Sprint(example)
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", text); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date example '%s'", text)
+}
+
+// fixtureTimeOfDay returns the schema's time example, or midnight
+func fixtureTimeOfDay(schema *base.Schema) (time.Time, error) {
+	example, found, err := fixtureExample(schema)
+	if err != nil || !found {
+		return time.Time{}, err
+	}
+	return parseTimeOfDay(fmt.Sprint(example))
+}
+
+// fixtureDuration returns the schema's duration example, or zero
+func fixtureDuration(schema *base.Schema) (time.Duration, error) {
+	example, found, err := fixtureExample(schema)
+	if err != nil || !found {
+		return 0, err
+	}
+	return parseDuration(fmt.Sprint(example))
+}
+
+// fixtureExample returns the scalar example or first of examples for a schema
+func fixtureExample(schema *base.Schema) (interface{}, bool, error) {
+	node := schema.Example
+	if node == nil && len(schema.Examples) > 0 {
+		node = schema.Examples[0]
+	}
+	if node == nil {
+		return nil, false, nil
+	}
+
+	value, err := nodeValue(node)
+	if err != nil {
+		return nil, false, err
+	}
+	switch value.(type) {
+	case *orderedObject, []interface{}, nil:
+		return nil, false, nil
+	}
+	return value, true, nil
+}
@@ -0,0 +1,140 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+func TestFixturesOutput(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Priority:
+      type: integer
+      enum: [1, 2, 3]
+      example: 2
+    Order:
+      type: object
+      properties:
+        orderId:
+          type: string
+          example: ord-123
+        status:
+          type: string
+          enum: [open, closed]
+        quantity:
+          type: integer
+          minimum: 5
+        discount:
+          type: number
+          maximum: 0.5
+          exclusiveMaximum: true
+        total:
+          type: number
+          format: float
+          example: 19.99
+        express:
+          type: boolean
+        tags:
+          type: array
+          items:
+            type: string
+            example: rush
+        priority:
+          $ref: '#/components/schemas/Priority'
+        placedAt:
+          type: string
+          format: date-time
+          example: '2024-03-01T10:00:00Z'
+        item:
+          type: object
+          properties:
+            sku:
+              type: string
+              minLength: 8
+        parent:
+          $ref: '#/components/schemas/Order'
+        customer:
+          $ref: '#/components/schemas/Customer'
+    Customer:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Alice
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:  "testpkg",
+		PackagePath:  "github.com/example/proto/v1",
+		EmitFixtures: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"Order": `orderId: "ord-123"
+status: "open"
+quantity: 5
+discount: 0
+total: 19.99
+express: true
+tags: "rush"
+priority: PRIORITY_2
+placedAt {
+  seconds: 1709287200
+}
+item {
+  sku: "aaaaaaaa"
+}
+customer {
+  name: "Alice"
+}
+`,
+		"Customer": `name: "Alice"
+`,
+	}, fixtureStrings(result.Fixtures))
+
+	// Every fixture parses against the generated schema
+	for name, fixture := range result.Fixtures {
+		msg, err := result.NewMessage(name)
+		require.NoError(t, err)
+		require.NoError(t, prototext.Unmarshal(fixture, msg))
+	}
+}
+
+func TestFixturesDisabledByDefault(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, result.Fixtures)
+}
+
+func fixtureStrings(fixtures map[string][]byte) map[string]string {
+	result := make(map[string]string, len(fixtures))
+	for name, fixture := range fixtures {
+		result[name] = string(fixture)
+	}
+	return result
+}
@@ -0,0 +1,309 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatOptions(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Level:
+      type: integer
+      enum: [1, 2]
+    Order:
+      type: object
+      description: An order placed by a customer through the storefront checkout flow
+      properties:
+        id:
+          type: string
+        item:
+          type: object
+          properties:
+            sku:
+              type: string
+        level:
+          $ref: '#/components/schemas/Level'
+`
+
+	for _, test := range []struct {
+		name     string
+		format   conv.FormatOptions
+		expected string
+	}{
+		{
+			name:   "default layout",
+			format: conv.FormatOptions{},
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+enum Level {
+  LEVEL_UNSPECIFIED = 0;
+  LEVEL_1 = 1;
+  LEVEL_2 = 2;
+}
+
+// An order placed by a customer through the storefront checkout flow
+message Order {
+  message Item {
+    string sku = 1 [json_name = "sku"];
+  }
+
+  string id = 1 [json_name = "id"];
+  Item item = 2 [json_name = "item"];
+  Level level = 3 [json_name = "level"];
+}
+
+`,
+		},
+		{
+			name: "four space indent and blank lines between fields",
+			format: conv.FormatOptions{
+				IndentWidth:            4,
+				BlankLineBetweenFields: true,
+			},
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+enum Level {
+    LEVEL_UNSPECIFIED = 0;
+    LEVEL_1 = 1;
+    LEVEL_2 = 2;
+}
+
+// An order placed by a customer through the storefront checkout flow
+message Order {
+    message Item {
+        string sku = 1 [json_name = "sku"];
+    }
+
+    string id = 1 [json_name = "id"];
+
+    Item item = 2 [json_name = "item"];
+
+    Level level = 3 [json_name = "level"];
+}
+
+`,
+		},
+		{
+			name: "single trailing newline and wrapped comments",
+			format: conv.FormatOptions{
+				SingleTrailingNewline: true,
+				MaxCommentWidth:       40,
+			},
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+enum Level {
+  LEVEL_UNSPECIFIED = 0;
+  LEVEL_1 = 1;
+  LEVEL_2 = 2;
+}
+
+// An order placed by a customer through
+// the storefront checkout flow
+message Order {
+  message Item {
+    string sku = 1 [json_name = "sku"];
+  }
+
+  string id = 1 [json_name = "id"];
+  Item item = 2 [json_name = "item"];
+  Level level = 3 [json_name = "level"];
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				Format:      test.format,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(result.Protobuf))
+		})
+	}
+}
+
+func TestFormatCommentWrapping(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+          description: |-
+            The display name shown to other users
+            See https://example.com/a/very/long/documentation/link/for/names
+`
+
+	expected := `message User {
+  // The display name shown to other
+  // users
+  // See
+  // https://example.com/a/very/long/documentation/link/for/names
+  string name = 1 [json_name = "name"];
+}
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		Format:      conv.FormatOptions{MaxCommentWidth: 40},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Protobuf), expected)
+}
+
+func TestFormatBufFormat(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Empty:
+      type: object
+    Wrapper:
+      type: object
+      description: Holds a nested value
+      properties:
+        inner:
+          type: object
+          properties:
+            meta:
+              type: object
+            createdAt:
+              type: string
+              format: date-time
+`
+
+	expected := `syntax = "proto3";
+
+package testpkg;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/example/proto/v1";
+
+message Empty {}
+
+// Holds a nested value
+message Wrapper {
+  message Inner {
+    message Meta {}
+
+    Meta meta = 1 [json_name = "meta"];
+    google.protobuf.Timestamp createdAt = 2 [json_name = "createdAt"];
+  }
+
+  Inner inner = 1 [json_name = "inner"];
+}
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		Format: conv.FormatOptions{
+			IndentWidth: 4,
+			BufFormat:   true,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(result.Protobuf))
+}
+
+func TestFormatAnchorComments(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Status:
+      type: integer
+      enum: [1, 2]
+    User:
+      type: object
+      description: A user
+      properties:
+        profile:
+          type: object
+          properties:
+            name:
+              type: string
+`
+
+	expected := `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+// openapi-proto:begin enum Status
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_1 = 1;
+  STATUS_2 = 2;
+}
+// openapi-proto:end enum Status
+
+// openapi-proto:begin message User
+// A user
+message User {
+  message Profile {
+    string name = 1 [json_name = "name"];
+  }
+
+  Profile profile = 1 [json_name = "profile"];
+}
+// openapi-proto:end message User
+`
+
+	for _, test := range []struct {
+		name   string
+		format conv.FormatOptions
+	}{
+		{
+			name:   "default layout",
+			format: conv.FormatOptions{AnchorComments: true, SingleTrailingNewline: true},
+		},
+		{
+			name:   "buf format",
+			format: conv.FormatOptions{AnchorComments: true, BufFormat: true},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				Format:      test.format,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, expected, string(result.Protobuf))
+		})
+	}
+}
@@ -3,34 +3,48 @@ package internal
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
 )
 
-const protoTemplate = `syntax = "proto3";
+const protoTemplate = `{{formatServers .Servers}}syntax = "proto3";
 
 package {{.PackageName}};
-{{if .UsesTimestamp}}
-import "google/protobuf/timestamp.proto";
-{{end}}
+{{formatImports .UsesTimestamp .Imports .ImportRewrites .ImportKinds}}
 option go_package = "{{.GoPackage}}";
-{{range .Definitions}}{{renderDefinition .}}{{end}}
+{{range .FileOptions}}option {{.Name}} = {{.Literal}};
+{{end}}{{.Body}}{{formatCallbacks .Callbacks .CallbackTypes}}
 `
 
 type templateData struct {
-	PackageName   string
-	Messages      []*ProtoMessage
-	Enums         []*ProtoEnum
-	Definitions   []interface{}
-	UsesTimestamp bool
-	GoPackage     string
+	PackageName    string
+	Messages       []*ProtoMessage
+	Enums          []*ProtoEnum
+	Definitions    []interface{}
+	Body           string // Rendered definitions
+	UsesTimestamp  bool
+	Imports        []string
+	ImportRewrites map[string]string
+	ImportKinds    map[string]string
+	GoPackage      string
+	FileOptions    []FileOption
+	Callbacks      []*parser.CallbackEntry
+	CallbackTypes  map[string]string
+	Servers        []*parser.ServerEntry
 }
 
 // Generate creates proto3 output from messages and enums in order
 func Generate(packageName string, packagePath string, ctx *Context) ([]byte, error) {
 	funcMap := template.FuncMap{
-		"formatComment":    formatCommentForTemplate,
-		"renderDefinition": renderDefinition,
+		"formatComment":   formatCommentForTemplate,
+		"formatServers":   formatServers,
+		"formatCallbacks": formatCallbacks,
+		"formatImports":   formatImports,
 	}
 
 	tmpl, err := template.New("proto").Funcs(funcMap).Parse(protoTemplate)
@@ -39,120 +53,489 @@ func Generate(packageName string, packagePath string, ctx *Context) ([]byte, err
 	}
 
 	data := templateData{
-		PackageName:   packageName,
-		Messages:      ctx.Messages,
-		Enums:         ctx.Enums,
-		Definitions:   ctx.Definitions,
-		UsesTimestamp: ctx.UsesTimestamp,
-		GoPackage:     packagePath,
+		PackageName:    packageName,
+		Messages:       ctx.Messages,
+		Enums:          ctx.Enums,
+		Definitions:    ctx.Definitions,
+		Body:           renderDefinitions(ctx.Definitions, ctx.Format),
+		UsesTimestamp:  ctx.UsesTimestamp,
+		Imports:        ctx.Imports,
+		ImportRewrites: ctx.ImportRewrites,
+		ImportKinds:    ctx.ImportKinds,
+		GoPackage:      packagePath,
+		FileOptions:    ctx.FileOptions,
+		Servers:        ctx.Servers,
+		Callbacks:      ctx.Callbacks,
+		CallbackTypes:  ctx.CallbackTypes,
 	}
 
 	var buf bytes.Buffer
+	buf.Grow(len(data.Body) + 1024)
 	if err := tmpl.Execute(&buf, data); err != nil {
 		return nil, fmt.Errorf("failed to execute template: %w", err)
 	}
 
+	if ctx.Format.BufFormat {
+		return bufFormat(buf.Bytes()), nil
+	}
+
+	if ctx.Format.SingleTrailingNewline {
+		return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n'), nil
+	}
+
 	return buf.Bytes(), nil
 }
 
-// renderDefinition renders either an enum or message definition
-func renderDefinition(def interface{}) string {
+// renderDefinitions renders every definition into one buffer, sized up front from the
+// number of definitions and fields so large files are not copied as the buffer grows
+func renderDefinitions(defs []interface{}, format Format) string {
+	size := 0
+	for _, def := range defs {
+		size += definitionSize(def)
+	}
+
+	var result strings.Builder
+	result.Grow(size)
+	for _, def := range defs {
+		writeDefinition(&result, def, format)
+	}
+	return result.String()
+}
+
+// definitionSize estimates the rendered length of a definition, without comments
+func definitionSize(def interface{}) int {
+	const lineSize = 48
 	switch d := def.(type) {
 	case *ProtoEnum:
-		return renderEnum(d)
+		return (len(d.Values) + 3) * lineSize
 	case *ProtoMessage:
-		return renderMessage(d)
-	default:
+		size := (len(d.Fields) + 3) * lineSize
+		for _, nested := range d.Nested {
+			size += definitionSize(nested)
+		}
+		for _, enum := range d.Enums {
+			size += definitionSize(enum)
+		}
+		return size
+	}
+	return 0
+}
+
+// bufFormat rewrites generated proto text into the canonical style enforced by
+// `buf format`: two space indentation by nesting depth, no trailing whitespace,
+// no blank lines at the start or end of a block, at most one consecutive blank
+// line, empty bodies collapsed to {} and a single trailing newline.
+func bufFormat(proto []byte) []byte {
+	var lines []string
+	depth := 0
+	blank := false
+
+	for _, line := range strings.Split(string(proto), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			blank = len(lines) > 0
+			continue
+		}
+
+		isComment := strings.HasPrefix(trimmed, "//")
+		if !isComment && trimmed == "}" {
+			depth--
+			last := len(lines) - 1
+			if last >= 0 && strings.HasSuffix(lines[last], " {") {
+				// Collapse an empty body onto its opening line
+				lines[last] += "}"
+				blank = false
+				continue
+			}
+			blank = false
+		}
+
+		if blank && !strings.HasSuffix(lines[len(lines)-1], " {") {
+			lines = append(lines, "")
+		}
+		blank = false
+
+		lines = append(lines, strings.Repeat("  ", depth)+trimmed)
+		if !isComment && strings.HasSuffix(trimmed, " {") {
+			depth++
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// formatImports renders the import block, rewritten and sorted by path, with a blank
+// line after it. kinds adds a public or weak modifier to the rewritten paths.
+func formatImports(usesTimestamp bool, extra []string, rewrites, kinds map[string]string) string {
+	imports := append([]string(nil), extra...)
+	if usesTimestamp {
+		imports = append(imports, "google/protobuf/timestamp.proto")
+	}
+	if len(imports) == 0 {
 		return ""
 	}
+
+	seen := make(map[string]bool, len(imports))
+	rewritten := make([]string, 0, len(imports))
+	for _, path := range imports {
+		path = rewriteImport(path, rewrites)
+		if !seen[path] {
+			seen[path] = true
+			rewritten = append(rewritten, path)
+		}
+	}
+	sort.Strings(rewritten)
+
+	var result strings.Builder
+	result.WriteString("\n")
+	for _, path := range rewritten {
+		if kind := kinds[path]; kind != "" {
+			result.WriteString(fmt.Sprintf("import %s \"%s\";\n", kind, path))
+			continue
+		}
+		result.WriteString(fmt.Sprintf("import \"%s\";\n", path))
+	}
+	return result.String()
 }
 
-// renderEnum renders an enum definition
-func renderEnum(enum *ProtoEnum) string {
+// rewriteImport returns the replacement for path from rewrites, or path unchanged. An
+// exact key wins; otherwise the longest key ending in "*" whose prefix matches is used,
+// with a trailing "*" in its replacement standing for the rest of the path.
+func rewriteImport(path string, rewrites map[string]string) string {
+	if replacement, found := rewrites[path]; found {
+		return replacement
+	}
+
+	var prefix, replacement string
+	var matched bool
+	for from, to := range rewrites {
+		p, wildcard := strings.CutSuffix(from, "*")
+		if !wildcard || !strings.HasPrefix(path, p) || (matched && len(p) <= len(prefix)) {
+			continue
+		}
+		prefix, replacement, matched = p, to, true
+	}
+	if !matched {
+		return path
+	}
+
+	if base, wildcard := strings.CutSuffix(replacement, "*"); wildcard {
+		return base + strings.TrimPrefix(path, prefix)
+	}
+	return replacement
+}
+
+// formatServers renders the spec's servers as a file comment followed by a blank line
+func formatServers(servers []*parser.ServerEntry) string {
+	if len(servers) == 0 {
+		return ""
+	}
+
 	var result strings.Builder
+	result.WriteString("// Servers:\n")
+	for _, server := range servers {
+		result.WriteString("//   ")
+		result.WriteString(sanitizeCommentLine(server.URL))
+		if description := strings.TrimSpace(server.Description); description != "" {
+			// Keep each server on a single comment line
+			result.WriteString(" - ")
+			result.WriteString(sanitizeCommentLine(description))
+		}
+		result.WriteString("\n")
+	}
 	result.WriteString("\n")
 
-	if enum.Description != "" {
-		result.WriteString(formatCommentForTemplate(enum.Description))
+	return result.String()
+}
+
+// formatCallbacks renders the operations' callbacks as a commented out service, preceded
+// by a blank line, so async notification contracts stay visible in the proto output. The
+// service is implemented by clients rather than the API, so it is not compiled. Each rpc
+// takes the message of the callback request body from types, google.protobuf.Empty when
+// there is none, or google.protobuf.Any when the body is not a proto message.
+func formatCallbacks(callbacks []*parser.CallbackEntry, types map[string]string) string {
+	if len(callbacks) == 0 {
+		return ""
 	}
 
-	result.WriteString(fmt.Sprintf("enum %s {\n", enum.Name))
-	for _, value := range enum.Values {
-		result.WriteString(fmt.Sprintf("  %s = %d;\n", value.Name, value.Number))
+	var result strings.Builder
+	result.WriteString("\n// Callbacks sent to clients, which implement this service:\n//\n// service Callbacks {\n")
+	rpcs := NewNameTracker()
+	for i, callback := range callbacks {
+		if i > 0 {
+			result.WriteString("//\n")
+		}
+		result.WriteString("//   // ")
+		result.WriteString(sanitizeCommentLine(fmt.Sprintf("%s from %s: %s %s",
+			callback.Name, callback.Operation, callback.Method, callback.Expression)))
+		request := "google.protobuf.Empty"
+		if callback.Request != "" {
+			request = "google.protobuf.Any"
+			if message, ok := types[callback.Request]; ok {
+				request = message
+			} else {
+				result.WriteString(", sends ")
+				result.WriteString(sanitizeCommentLine(callback.Request))
+			}
+		}
+		result.WriteString("\n")
+		result.WriteString(fmt.Sprintf("//   rpc %s(%s) returns (google.protobuf.Empty);\n",
+			rpcs.UniqueName(CallbackRPC(callback)), request))
 	}
-	result.WriteString("}\n")
+	result.WriteString("// }\n")
 
 	return result.String()
 }
 
-// renderMessage renders a message definition
-func renderMessage(msg *ProtoMessage) string {
-	return renderMessageWithIndent(msg, "")
+// CallbackRPC returns the name of a callback's rpc, the PascalCase operationId, or method
+// and path, of its operation followed by the callback name, e.g. SubscribeOnEvent
+func CallbackRPC(callback *parser.CallbackEntry) string {
+	operation := callback.OperationID
+	if operation == "" {
+		operation = strings.ToLower(callback.OperationMethod) + " " + callback.OperationPath
+	}
+	return WordsToPascalCase(operation) + WordsToPascalCase(callback.Name)
 }
 
-// renderMessageWithIndent renders a message definition with custom indentation
-func renderMessageWithIndent(msg *ProtoMessage, indent string) string {
-	var result strings.Builder
+// anchorBegin and anchorEnd start the marker comments Format.Anchors places around each
+// top-level definition, followed by its kind and name, e.g. "message User"
+const (
+	anchorBegin = "// openapi-proto:begin "
+	anchorEnd   = "// openapi-proto:end "
+)
+
+// writeDefinition writes an enum or message definition, preceded by a blank line
+func writeDefinition(result *strings.Builder, def interface{}, format Format) {
+	var kind, name string
+	switch d := def.(type) {
+	case *ProtoEnum:
+		kind, name = "enum", d.Name
+	case *ProtoMessage:
+		kind, name = "message", d.Name
+	default:
+		return
+	}
+
 	result.WriteString("\n")
+	// The begin marker goes before the leading comments, which belong to the definition
+	if format.Anchors {
+		writeAnchor(result, anchorBegin, kind, name)
+	}
+	switch d := def.(type) {
+	case *ProtoEnum:
+		writeEnum(result, d, "", format)
+	case *ProtoMessage:
+		writeMessage(result, d, "", format)
+	}
+	if format.Anchors {
+		writeAnchor(result, anchorEnd, kind, name)
+	}
+}
+
+// writeAnchor writes a marker comment line such as "// openapi-proto:begin message User"
+func writeAnchor(result *strings.Builder, marker, kind, name string) {
+	result.WriteString(marker)
+	result.WriteString(kind)
+	result.WriteString(" ")
+	result.WriteString(name)
+	result.WriteString("\n")
+}
 
+// writeEnum writes an enum definition at indent
+func writeEnum(result *strings.Builder, enum *ProtoEnum, indent string, format Format) {
+	if enum.Description != "" {
+		result.WriteString(formatComment(enum.Description, indent, format.MaxCommentWidth))
+	}
+
+	valueIndent := indent + format.indent()
+	result.WriteString(indent)
+	result.WriteString("enum ")
+	result.WriteString(enum.Name)
+	result.WriteString(" {\n")
+	for _, value := range enum.Values {
+		result.WriteString(valueIndent)
+		result.WriteString(value.Name)
+		result.WriteString(" = ")
+		result.WriteString(strconv.Itoa(value.Number))
+		result.WriteString(";\n")
+	}
+	result.WriteString(indent)
+	result.WriteString("}\n")
+}
+
+// writeMessage writes a message definition at indent, nested definitions first
+func writeMessage(result *strings.Builder, msg *ProtoMessage, indent string, format Format) {
 	if msg.Description != "" {
-		result.WriteString(formatComment(msg.Description, indent))
+		result.WriteString(formatComment(msg.Description, indent, format.MaxCommentWidth))
+	}
+	result.WriteString(formatNotes(msg.Notes, indent))
+	if msg.Todo != "" {
+		result.WriteString(fmt.Sprintf("%s// TODO: %s\n", indent, sanitizeCommentLine(msg.Todo)))
 	}
+	fieldIndent := indent + format.indent()
 
 	result.WriteString(indent)
-	result.WriteString(fmt.Sprintf("message %s {\n", msg.Name))
+	result.WriteString("message ")
+	result.WriteString(msg.Name)
+	result.WriteString(" {\n")
+	result.WriteString(renderReserved(msg, fieldIndent))
 
-	// Render nested messages first (with proper indentation)
+	// Nested enums and messages are each followed by a blank line
+	for _, enum := range msg.Enums {
+		writeEnum(result, enum, fieldIndent, format)
+		result.WriteString("\n")
+	}
 	for _, nested := range msg.Nested {
-		nestedContent := renderMessageWithIndent(nested, indent+"  ")
-		// Remove the leading newline from nested message since we're inside parent
-		result.WriteString(strings.TrimPrefix(nestedContent, "\n"))
+		writeMessage(result, nested, fieldIndent, format)
 		result.WriteString("\n")
 	}
 
-	// Render fields
-	for _, field := range msg.Fields {
-		if field.Description != "" {
-			result.WriteString(formatComment(field.Description, indent+"  "))
+	// Render fields, inside the oneof block if the message has one
+	oneofIndent := fieldIndent
+	if msg.Oneof != "" {
+		result.WriteString(fmt.Sprintf("%soneof %s {\n", oneofIndent, msg.Oneof))
+		fieldIndent += format.indent()
+	}
+	for i, field := range orderFields(msg.Fields, format.FieldOrder) {
+		if i > 0 && format.BlankLineBetweenFields {
+			result.WriteString("\n")
 		}
+		writeField(result, field, fieldIndent, format)
+	}
+	if msg.Oneof != "" {
+		result.WriteString(oneofIndent)
+		result.WriteString("}\n")
+	}
 
-		if len(field.EnumValues) > 0 {
-			result.WriteString(formatEnumComment(field.EnumValues, indent+"  "))
-		}
+	result.WriteString(indent)
+	result.WriteString("}\n")
+}
 
-		result.WriteString(indent)
-		result.WriteString("  ")
-		if field.Repeated {
-			result.WriteString("repeated ")
-		}
-		result.WriteString(fmt.Sprintf("%s %s = %d", field.Type, field.Name, field.Number))
-		if field.JSONName != "" {
-			result.WriteString(fmt.Sprintf(" [json_name = \"%s\"]", field.JSONName))
-		}
-		result.WriteString(";\n")
+// writeField writes a field declaration at indent, preceded by its comments
+func writeField(result *strings.Builder, field *ProtoField, indent string, format Format) {
+	if field.Description != "" {
+		result.WriteString(formatComment(field.Description, indent, format.MaxCommentWidth))
+	}
+	if len(field.EnumValues) > 0 {
+		result.WriteString(formatEnumComment(field.EnumValues, indent))
 	}
+	result.WriteString(formatNotes(field.Notes, indent))
 
 	result.WriteString(indent)
-	result.WriteString("}\n")
+	if field.Repeated {
+		result.WriteString("repeated ")
+	}
+	if field.Optional {
+		result.WriteString("optional ")
+	}
+	if field.MapKey != "" {
+		result.WriteString("map<")
+		result.WriteString(field.MapKey)
+		result.WriteString(", ")
+		result.WriteString(field.Type)
+		result.WriteString(">")
+	} else {
+		result.WriteString(field.Type)
+	}
+	result.WriteString(" ")
+	result.WriteString(field.Name)
+	result.WriteString(" = ")
+	result.WriteString(strconv.Itoa(field.Number))
+
+	// Options follow json_name, separated by commas
+	separator := " ["
+	if field.JSONName != "" {
+		result.WriteString(separator)
+		result.WriteString(`json_name = "`)
+		result.WriteString(field.JSONName)
+		result.WriteString(`"`)
+		separator = ", "
+	}
+	for _, option := range field.Options {
+		result.WriteString(separator)
+		result.WriteString(option)
+		separator = ", "
+	}
+	if separator == ", " {
+		result.WriteString("]")
+	}
+	result.WriteString(";\n")
+}
+
+// formatNotes renders notes as NOTE comment lines
+func formatNotes(notes []string, indent string) string {
+	var result strings.Builder
+	for _, note := range notes {
+		result.WriteString(fmt.Sprintf("%s// NOTE: %s\n", indent, sanitizeCommentLine(note)))
+	}
+	return result.String()
+}
 
+// renderReserved renders the reserved statements of a message, numbers before names
+func renderReserved(msg *ProtoMessage, indent string) string {
+	var result strings.Builder
+	if len(msg.ReservedNumbers) > 0 {
+		numbers := make([]string, 0, len(msg.ReservedNumbers))
+		for _, number := range msg.ReservedNumbers {
+			numbers = append(numbers, strconv.Itoa(number))
+		}
+		result.WriteString(fmt.Sprintf("%sreserved %s;\n", indent, strings.Join(numbers, ", ")))
+	}
+	if len(msg.ReservedNames) > 0 {
+		names := make([]string, 0, len(msg.ReservedNames))
+		for _, name := range msg.ReservedNames {
+			names = append(names, fmt.Sprintf("\"%s\"", name))
+		}
+		result.WriteString(fmt.Sprintf("%sreserved %s;\n", indent, strings.Join(names, ", ")))
+	}
 	return result.String()
 }
 
+// orderFields returns the fields in render order without modifying the message
+func orderFields(fields []*ProtoField, order FieldOrder) []*ProtoField {
+	switch order {
+	case FieldOrderByNumber:
+		sorted := append([]*ProtoField(nil), fields...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Number < sorted[j].Number
+		})
+		return sorted
+	case FieldOrderAlphabetical:
+		sorted := append([]*ProtoField(nil), fields...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Name < sorted[j].Name
+		})
+		return sorted
+	default:
+		return fields
+	}
+}
+
+// indent returns the whitespace for one nesting level
+func (f Format) indent() string {
+	if f.IndentWidth <= 0 {
+		return "  "
+	}
+	return strings.Repeat(" ", f.IndentWidth)
+}
+
 // formatCommentForTemplate formats a description as a proto3 comment for use in templates
 func formatCommentForTemplate(description string) string {
-	return formatComment(description, "")
+	return formatComment(description, "", 0)
 }
 
-// formatComment formats a description as a proto3 comment with indentation
-func formatComment(description, indent string) string {
+// formatComment formats a description as a proto3 comment with indentation.
+// When width is positive, lines are wrapped at word boundaries so the
+// comment including indentation does not exceed width where possible.
+func formatComment(description, indent string, width int) string {
 	if strings.TrimSpace(description) == "" {
 		return ""
 	}
 
-	lines := strings.Split(description, "\n")
+	lines := strings.Split(sanitizeComment(description), "\n")
 	var result strings.Builder
 
-	for _, line := range lines {
+	for _, line := range wrapLines(lines, width-len(indent)-len("// ")) {
 		trimmed := strings.TrimRight(line, " \t")
 		result.WriteString(indent)
 		if trimmed == "" {
@@ -167,6 +550,78 @@ func formatComment(description, indent string) string {
 	return result.String()
 }
 
+// sanitizeComment normalizes a description so it cannot corrupt a generated
+// comment. Leading slashes are removed from each line so it cannot read as a
+// nested comment marker. See sanitizeCommentText for character handling.
+func sanitizeComment(text string) string {
+	lines := strings.Split(sanitizeCommentText(text), "\n")
+	for i, line := range lines {
+		rest := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(rest, "/") {
+			lines[i] = strings.TrimLeft(strings.TrimLeft(rest, "/"), " \t")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// sanitizeCommentLine sanitizes text that must stay on a single comment line,
+// collapsing all whitespace including line breaks into single spaces
+func sanitizeCommentLine(text string) string {
+	return strings.Join(strings.Fields(sanitizeCommentText(text)), " ")
+}
+
+// commentReplacer normalizes line breaks and breaks up "*/" in comment text
+var commentReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\u2028", "\n", "\u2029", "\n", "*/", "* /")
+
+// sanitizeCommentText normalizes line endings to \n, turns Unicode line separators
+// into line breaks, drops other control characters and invalid UTF-8, and breaks
+// up "*/" so the text stays safe inside block comments
+func sanitizeCommentText(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	text = commentReplacer.Replace(text)
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) || r == '\uFEFF' {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// wrapLines splits lines longer than width at word boundaries. Words longer than
+// width are kept whole. A width of zero or less disables wrapping.
+func wrapLines(lines []string, width int) []string {
+	if width <= 0 {
+		return lines
+	}
+
+	wrapped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if len(line) <= width {
+			wrapped = append(wrapped, line)
+			continue
+		}
+
+		current := ""
+		for _, word := range strings.Fields(line) {
+			if current != "" && len(current)+1+len(word) > width {
+				wrapped = append(wrapped, current)
+				current = ""
+			}
+			if current != "" {
+				current += " "
+			}
+			current += word
+		}
+		wrapped = append(wrapped, current)
+	}
+
+	return wrapped
+}
+
 // formatEnumComment formats enum values as a proto3 comment
 func formatEnumComment(values []string, indent string) string {
 	if len(values) == 0 {
@@ -180,7 +635,7 @@ func formatEnumComment(values []string, indent string) string {
 		if i > 0 {
 			result.WriteString(", ")
 		}
-		result.WriteString(value)
+		result.WriteString(sanitizeCommentLine(value))
 	}
 	result.WriteString("]\n")
 	return result.String()
@@ -100,3 +100,53 @@ plugins:
 	_, err = os.Stat(genFile)
 	require.NoError(t, err, "expected generated Go file at %s", genFile)
 }
+
+func TestBufFormatDiff(t *testing.T) {
+	if _, err := exec.LookPath("buf"); err != nil {
+		t.Skip("buf not found in PATH, skipping integration test")
+	}
+
+	const openapi = `
+openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Empty:
+      type: object
+    User:
+      type: object
+      description: A user account
+      properties:
+        address:
+          type: object
+          properties:
+            city:
+              type: string
+        code:
+          type: integer
+          enum: [1, 2]
+        createdAt:
+          type: string
+          format: date-time
+`
+
+	result, err := conv.Convert([]byte(openapi), conv.ConvertOptions{
+		PackageName: "testapi",
+		PackagePath: "github.com/example/proto/v1/testapi",
+		Format:      conv.FormatOptions{BufFormat: true},
+	})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "test.proto"), result.Protobuf, 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "buf.yaml"), []byte("version: v2\n"), 0644)
+	require.NoError(t, err)
+
+	cmd := exec.Command("buf", "format", "--diff", "--exit-code")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "buf format reported differences: %s", string(output))
+}
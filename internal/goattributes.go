@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// attributeImport is the import path of the OpenTelemetry attribute package used by the
+// generated Attributes methods
+const attributeImport = "go.opentelemetry.io/otel/attribute"
+
+// attributeConstructors maps the Go types that convert to an attribute without a guard to
+// the attribute constructor and the conversion of the field value
+var attributeConstructors = map[string][2]string{
+	"string":    {"String", "%s"},
+	"bool":      {"Bool", "%s"},
+	"int8":      {"Int64", "int64(%s)"},
+	"int16":     {"Int64", "int64(%s)"},
+	"int32":     {"Int64", "int64(%s)"},
+	"int64":     {"Int64", "%s"},
+	"uint8":     {"Int64", "int64(%s)"},
+	"uint16":    {"Int64", "int64(%s)"},
+	"uint32":    {"Int64", "int64(%s)"},
+	"float32":   {"Float64", "float64(%s)"},
+	"float64":   {"Float64", "%s"},
+	"time.Time": {"String", "%s.Format(time.RFC3339Nano)"},
+	"[]string":  {"StringSlice", "%s"},
+	"[]bool":    {"BoolSlice", "%s"},
+	"[]int64":   {"Int64Slice", "%s"},
+	"[]float64": {"Float64Slice", "%s"},
+}
+
+// observabilityKey reads the x-observability extension of a property: true exports the
+// property under its own name and a string exports it under that attribute key. Returns
+// an empty string if the property is not exported. Extensions next to a $ref are not
+// visible, so only inline property schemas can be exported.
+func observabilityKey(schema *base.Schema, propName string) (string, error) {
+	if schema.Extensions == nil {
+		return "", nil
+	}
+	node, found := schema.Extensions.Get("x-observability")
+	if !found || node == nil {
+		return "", nil
+	}
+	if node.Kind != yaml.ScalarNode || node.Value == "" || strings.ContainsAny(node.Value, "\"\\\n") {
+		return "", Errorf(CodeInvalidExtension, "x-observability must be true or an attribute key")
+	}
+
+	switch {
+	case node.Tag == "!!bool" && node.Value == "true":
+		return propName, nil
+	case node.Tag == "!!bool":
+		return "", nil
+	}
+	return node.Value, nil
+}
+
+// supportsAttribute reports whether a field of typeName can be exported as an attribute
+func supportsAttribute(typeName string) bool {
+	if value, ok := strings.CutPrefix(typeName, "Optional["); ok {
+		return supportsAttribute(strings.TrimSuffix(value, "]"))
+	}
+	_, ok := attributeConstructors[typeName]
+	return ok
+}
+
+// renderAttributes renders an Attributes method returning the fields of s marked with
+// x-observability as OpenTelemetry attributes, or an empty string if none are marked.
+// Optional fields that are not Valid are left out.
+func renderAttributes(s *GoStruct) string {
+	var body strings.Builder
+	count := 0
+	for _, field := range s.Fields {
+		if field.Attribute == "" {
+			continue
+		}
+		count++
+		guard, attr := attributeExpr(field.Attribute, field.Type, "x."+field.Name)
+		if guard == "" {
+			body.WriteString(fmt.Sprintf("\tattrs = append(attrs, %s)\n", attr))
+			continue
+		}
+		body.WriteString(fmt.Sprintf("\tif %s {\n\t\tattrs = append(attrs, %s)\n\t}\n", guard, attr))
+	}
+	if count == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("\n// Attributes returns the %s fields marked with x-observability as OpenTelemetry attributes\n", s.Name))
+	result.WriteString(fmt.Sprintf("func (x *%s) Attributes() []attribute.KeyValue {\n", s.Name))
+	result.WriteString("\tif x == nil {\n\t\treturn nil\n\t}\n")
+	result.WriteString(fmt.Sprintf("\tattrs := make([]attribute.KeyValue, 0, %d)\n", count))
+	result.WriteString(body.String())
+	result.WriteString("\treturn attrs\n}\n")
+	return result.String()
+}
+
+// attributeExpr returns the attribute for value of typeName and the condition under
+// which it is set, empty if it is always set
+func attributeExpr(key, typeName, value string) (string, string) {
+	if inner, ok := strings.CutPrefix(typeName, "Optional["); ok {
+		_, attr := attributeExpr(key, strings.TrimSuffix(inner, "]"), value+".Value")
+		return value + ".Valid", attr
+	}
+	constructor := attributeConstructors[typeName]
+	return "", fmt.Sprintf("attribute.%s(%q, %s)", constructor[0], key, fmt.Sprintf(constructor[1], value))
+}
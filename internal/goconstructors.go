@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// constructorValue returns the Go expression a NewX constructor assigns to a field of
+// typeName: the schema default if it has one, an empty slice for arrays, or an empty
+// string to keep the zero value
+func constructorValue(schema *base.Schema, typeName string) string {
+	if schema.Default != nil {
+		if literal := goLiteral(typeName, schema.Default); literal != "" {
+			return literal
+		}
+	}
+	if strings.HasPrefix(typeName, "[]") && typeName != "[]byte" {
+		return typeName + "{}"
+	}
+	return ""
+}
+
+// goLiteral returns value as a Go literal of typeName, or an empty string if the value
+// does not fit the type or the type has no literal form, e.g. time.Time
+func goLiteral(typeName string, value *yaml.Node) string {
+	if elem, ok := strings.CutPrefix(typeName, "[]"); ok && elem != "byte" {
+		if value.Kind != yaml.SequenceNode {
+			return ""
+		}
+		items := make([]string, 0, len(value.Content))
+		for _, item := range value.Content {
+			literal := goLiteral(elem, item)
+			if literal == "" {
+				return ""
+			}
+			items = append(items, literal)
+		}
+		return typeName + "{" + strings.Join(items, ", ") + "}"
+	}
+
+	if value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	switch typeName {
+	case "string":
+		return strconv.Quote(value.Value)
+	case "bool":
+		if b, err := strconv.ParseBool(value.Value); err == nil {
+			return strconv.FormatBool(b)
+		}
+	case "int8", "int16", "int32", "int64":
+		if _, err := strconv.ParseInt(value.Value, 10, 64); err == nil {
+			return value.Value
+		}
+	case "uint8", "uint16", "uint32", "uint64":
+		if _, err := strconv.ParseUint(value.Value, 10, 64); err == nil {
+			return value.Value
+		}
+	case "float32", "float64":
+		if _, err := strconv.ParseFloat(value.Value, 64); err == nil {
+			return value.Value
+		}
+	}
+	return ""
+}
+
+// linkConstructors initializes the nested struct fields assigned by NewX constructors.
+// Fields referencing another generated struct call its constructor and fields referencing
+// a proto message get an empty one. A constructor call that would lead back to the struct
+// being built is left nil so self and circular references terminate. Union fields are
+// left nil since no variant is chosen yet.
+func linkConstructors(ctx *GoContext) {
+	structs := make(map[string]*GoStruct, len(ctx.Structs))
+	for _, s := range ctx.Structs {
+		structs[s.Name] = s
+	}
+
+	calls := make(map[string][]string)
+	var reaches func(from, to string, seen map[string]bool) bool
+	reaches = func(from, to string, seen map[string]bool) bool {
+		if from == to {
+			return true
+		}
+		if seen[from] {
+			return false
+		}
+		seen[from] = true
+		for _, next := range calls[from] {
+			if reaches(next, to, seen) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, s := range ctx.Structs {
+		if s.IsUnion {
+			continue
+		}
+		for _, field := range s.Fields {
+			if field.ref == "" {
+				continue
+			}
+			target, ok := structs[field.ref]
+			switch {
+			case !ok:
+				field.Init = "&" + field.ref + "{}"
+			case target.IsUnion || reaches(field.ref, s.Name, make(map[string]bool)):
+				continue
+			default:
+				calls[s.Name] = append(calls[s.Name], field.ref)
+				field.Init = "New" + field.ref + "()"
+			}
+		}
+	}
+}
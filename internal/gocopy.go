@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// protoImport is the import path of the protobuf runtime used to copy and compare proto
+// messages referenced from Go structs
+const protoImport = "google.golang.org/protobuf/proto"
+
+// copyHelpers are generic functions the Clone and Equal methods call, emitted once when used
+var copyHelpers = map[string]string{
+	"cloneSlice": `func cloneSlice[T any](s []T, clone func(T) T) []T {
+	if s == nil {
+		return nil
+	}
+	c := make([]T, len(s))
+	for i, v := range s {
+		c[i] = clone(v)
+	}
+	return c
+}
+`,
+	"cloneMap": `func cloneMap[T any](m map[string]T, clone func(T) T) map[string]T {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]T, len(m))
+	for k, v := range m {
+		c[k] = clone(v)
+	}
+	return c
+}
+`,
+	"clonePointer": `func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+`,
+	"equalPointer": `func equalPointer[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+`,
+}
+
+// copyRenderer renders Clone and Equal methods, recording the imports and helpers they use
+type copyRenderer struct {
+	equal   bool // Generated structs have Equal methods
+	structs map[string]bool
+	enums   map[string]bool
+	imports map[string]bool
+	helpers map[string]bool
+}
+
+func newCopyRenderer(ctx *GoContext) *copyRenderer {
+	r := &copyRenderer{
+		equal:   ctx.CloneEqual,
+		structs: make(map[string]bool, len(ctx.Structs)),
+		enums:   ctx.enumNames,
+		imports: make(map[string]bool),
+		helpers: make(map[string]bool),
+	}
+	for _, s := range ctx.Structs {
+		r.structs[s.Name] = true
+	}
+	return r
+}
+
+// render returns the Clone and Equal methods of s. Generated structs are copied and
+// compared with their own methods and proto messages with proto.Clone and proto.Equal,
+// so mixed object graphs behave the same throughout.
+func (r *copyRenderer) render(s *GoStruct) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("\n// Clone returns a deep copy of x\nfunc (x *%s) Clone() *%s {\n", s.Name, s.Name))
+	result.WriteString("\tif x == nil {\n\t\treturn nil\n\t}\n")
+	result.WriteString("\tc := *x\n")
+	for _, field := range s.Fields {
+		src := "x." + field.Name
+		if expr := r.cloneExpr(field.Type, src, "Clone"); expr != src {
+			result.WriteString(fmt.Sprintf("\tc.%s = %s\n", field.Name, expr))
+		}
+	}
+	result.WriteString("\treturn &c\n}\n")
+
+	result.WriteString(fmt.Sprintf("\n// Equal reports whether x and y hold the same values\nfunc (x *%s) Equal(y *%s) bool {\n", s.Name, s.Name))
+	result.WriteString("\tif x == nil || y == nil {\n\t\treturn x == y\n\t}\n")
+	if len(s.Fields) == 0 {
+		result.WriteString("\treturn true\n}\n")
+		return result.String()
+	}
+	for i, field := range s.Fields {
+		prefix, suffix := "\t\t", " &&\n"
+		if i == 0 {
+			prefix = "\treturn "
+		}
+		if i == len(s.Fields)-1 {
+			suffix = "\n"
+		}
+		result.WriteString(prefix + r.equalExpr(field.Type, "x."+field.Name, "y."+field.Name) + suffix)
+	}
+	result.WriteString("}\n")
+
+	return result.String()
+}
+
+// renderDeepCopy returns DeepCopyInto and DeepCopy methods of s in the form generated by
+// Kubernetes deepcopy-gen, so the struct can be embedded in custom resource types
+func (r *copyRenderer) renderDeepCopy(s *GoStruct) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("\n// DeepCopyInto copies the receiver into out. in must be non-nil.\nfunc (in *%s) DeepCopyInto(out *%s) {\n", s.Name, s.Name))
+	result.WriteString("\t*out = *in\n")
+	for _, field := range s.Fields {
+		src := "in." + field.Name
+		if expr := r.cloneExpr(field.Type, src, "DeepCopy"); expr != src {
+			result.WriteString(fmt.Sprintf("\tout.%s = %s\n", field.Name, expr))
+		}
+	}
+	result.WriteString("}\n")
+
+	result.WriteString(fmt.Sprintf("\n// DeepCopy returns a deep copy of the receiver\nfunc (in *%s) DeepCopy() *%s {\n", s.Name, s.Name))
+	result.WriteString("\tif in == nil {\n\t\treturn nil\n\t}\n")
+	result.WriteString(fmt.Sprintf("\tout := new(%s)\n", s.Name))
+	result.WriteString("\tin.DeepCopyInto(out)\n")
+	result.WriteString("\treturn out\n}\n")
+
+	return result.String()
+}
+
+// cloneExpr returns an expression deep copying src of type typ, copying generated
+// structs with their method named method
+func (r *copyRenderer) cloneExpr(typ, src, method string) string {
+	if typ == "[]byte" {
+		r.imports["bytes"] = true
+		return fmt.Sprintf("bytes.Clone(%s)", src)
+	}
+	if elem, ok := strings.CutPrefix(typ, "[]"); ok {
+		r.imports["slices"] = true
+		inner := r.cloneExpr(elem, "v", method)
+		if inner == "v" {
+			return fmt.Sprintf("slices.Clone(%s)", src)
+		}
+		r.helpers["cloneSlice"] = true
+		return fmt.Sprintf("cloneSlice(%s, func(v %s) %s { return %s })", src, elem, elem, inner)
+	}
+	if value, ok := strings.CutPrefix(typ, "map[string]"); ok {
+		r.imports["maps"] = true
+		inner := r.cloneExpr(value, "v", method)
+		if inner == "v" {
+			return fmt.Sprintf("maps.Clone(%s)", src)
+		}
+		r.helpers["cloneMap"] = true
+		return fmt.Sprintf("cloneMap(%s, func(v %s) %s { return %s })", src, value, value, inner)
+	}
+	if name, ok := strings.CutPrefix(typ, "*"); ok {
+		switch {
+		case r.structs[name]:
+			return src + "." + method + "()"
+		case r.enums[name]:
+			r.helpers["clonePointer"] = true
+			return fmt.Sprintf("clonePointer(%s)", src)
+		}
+		r.imports[protoImport] = true
+		return fmt.Sprintf("proto.Clone(%s).(*%s)", src, name)
+	}
+	return src
+}
+
+// equalExpr returns an expression reporting whether a and b of type typ are equal
+func (r *copyRenderer) equalExpr(typ, a, b string) string {
+	switch typ {
+	case "[]byte":
+		r.imports["bytes"] = true
+		return fmt.Sprintf("bytes.Equal(%s, %s)", a, b)
+	case "time.Time":
+		return fmt.Sprintf("%s.Equal(%s)", a, b)
+	case "any":
+		// Decoded JSON values hold maps and slices, which panic when compared with ==
+		r.imports["reflect"] = true
+		return fmt.Sprintf("reflect.DeepEqual(%s, %s)", a, b)
+	}
+	if value, ok := strings.CutPrefix(typ, "Optional["); ok {
+		value = strings.TrimSuffix(value, "]")
+		return fmt.Sprintf("%s.Valid == %s.Valid && %s", a, b, r.equalExpr(value, a+".Value", b+".Value"))
+	}
+	if elem, ok := strings.CutPrefix(typ, "[]"); ok {
+		r.imports["slices"] = true
+		inner := r.equalExpr(elem, "v", "w")
+		if inner == "v == w" {
+			return fmt.Sprintf("slices.Equal(%s, %s)", a, b)
+		}
+		return fmt.Sprintf("slices.EqualFunc(%s, %s, func(v, w %s) bool { return %s })", a, b, elem, inner)
+	}
+	if value, ok := strings.CutPrefix(typ, "map[string]"); ok {
+		r.imports["maps"] = true
+		inner := r.equalExpr(value, "v", "w")
+		if inner == "v == w" {
+			return fmt.Sprintf("maps.Equal(%s, %s)", a, b)
+		}
+		return fmt.Sprintf("maps.EqualFunc(%s, %s, func(v, w %s) bool { return %s })", a, b, value, inner)
+	}
+	if name, ok := strings.CutPrefix(typ, "*"); ok {
+		switch {
+		case r.structs[name] && r.equal:
+			return fmt.Sprintf("%s.Equal(%s)", a, b)
+		case r.structs[name]:
+			r.imports["reflect"] = true
+			return fmt.Sprintf("reflect.DeepEqual(%s, %s)", a, b)
+		case r.enums[name]:
+			r.helpers["equalPointer"] = true
+			return fmt.Sprintf("equalPointer(%s, %s)", a, b)
+		}
+		r.imports[protoImport] = true
+		return fmt.Sprintf("proto.Equal(%s, %s)", a, b)
+	}
+	return fmt.Sprintf("%s == %s", a, b)
+}
+
+// renderHelpers returns the generic helpers used by the rendered methods in name order
+func (r *copyRenderer) renderHelpers() string {
+	names := make([]string, 0, len(r.helpers))
+	for name := range r.helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	for _, name := range names {
+		result.WriteString("\n")
+		result.WriteString(copyHelpers[name])
+	}
+	return result.String()
+}
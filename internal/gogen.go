@@ -3,6 +3,8 @@ package internal
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 )
@@ -10,7 +12,10 @@ import (
 // GenerateGo produces Go source code from GoStruct IR with custom JSON marshaling
 func GenerateGo(ctx *GoContext) ([]byte, error) {
 	funcMap := template.FuncMap{
-		"renderStruct": renderStruct,
+		"renderStruct":      renderStruct,
+		"renderConstructor": renderConstructor,
+		"renderVisitor":     renderVisitor,
+		"renderEnum":        renderGoEnum,
 	}
 
 	tmpl, err := template.New("go").Funcs(funcMap).Parse(goTemplate)
@@ -19,10 +24,57 @@ func GenerateGo(ctx *GoContext) ([]byte, error) {
 	}
 
 	data := goTemplateData{
-		PackageName: ctx.PackageName,
-		Structs:     ctx.Structs,
-		NeedsTime:   ctx.NeedsTime,
+		PackageName:  ctx.PackageName,
+		Structs:      ctx.Structs,
+		Enums:        ctx.Enums,
+		Imports:      []string{"encoding/json", "fmt", "strings"},
+		Header:       ctx.Header,
+		Constructors: ctx.Constructors,
+		Visitors:     ctx.UnionVisitors,
 	}
+	if ctx.NeedsOptional {
+		data.Optional = goOptionalType
+	}
+	if ctx.NeedsTime {
+		data.Imports = append(data.Imports, "time")
+	}
+	copies := newCopyRenderer(ctx)
+	data.Methods = make(map[string]string, len(ctx.Structs))
+	for _, s := range ctx.Structs {
+		if ctx.CloneEqual {
+			data.Methods[s.Name] += copies.render(s)
+		}
+		if ctx.Kubernetes {
+			data.Methods[s.Name] += copies.renderDeepCopy(s)
+		}
+		if s.Patch {
+			data.Methods[s.Name] += copies.renderFieldMask(s)
+		}
+		if attributes := renderAttributes(s); attributes != "" {
+			copies.imports[attributeImport] = true
+			data.Methods[s.Name] += attributes
+		}
+		if redaction := renderRedaction(s); redaction != "" {
+			copies.imports["log/slog"] = true
+			data.Methods[s.Name] += redaction
+		}
+	}
+	for _, e := range ctx.Enums {
+		if e.PreserveUnknown && !e.IsString {
+			copies.imports["strconv"] = true
+		}
+	}
+	data.Helpers = copies.renderHelpers()
+	for path := range copies.imports {
+		// Standard library paths have no dot in their first element
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			data.ExternalImports = append(data.ExternalImports, path)
+		} else {
+			data.Imports = append(data.Imports, path)
+		}
+	}
+	sort.Strings(data.Imports)
+	sort.Strings(data.ExternalImports)
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -32,24 +84,65 @@ func GenerateGo(ctx *GoContext) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-const goTemplate = `package {{.PackageName}}
+const goTemplate = `{{.Header}}package {{.PackageName}}
 
 import (
-	"encoding/json"
-	"fmt"
-{{if .NeedsTime}}	"strings"
-	"time"
-{{else}}	"strings"
-{{end}}
+{{range .Imports}}	"{{.}}"
+{{end}}{{if .ExternalImports}}
+{{range .ExternalImports}}	"{{.}}"
+{{end}}{{end}}
 )
 {{range .Structs}}
-{{renderStruct .}}{{end}}
+{{renderStruct .}}{{if and $.Visitors .IsUnion}}{{renderVisitor .}}{{end}}{{if $.Constructors}}{{renderConstructor .}}{{end}}{{index $.Methods .Name}}{{end}}{{range .Enums}}
+{{renderEnum .}}{{end}}{{.Optional}}{{.Helpers}}
+`
+
+// goOptionalType declares the generic type used for nullable scalars. A null or absent
+// JSON value decodes to an Optional that is not Valid.
+const goOptionalType = `
+// Optional holds a value that may be null in JSON
+type Optional[T any] struct {
+	Value T
+	Valid bool // false if the value is null or absent
+}
+
+// Some returns a valid Optional holding v
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Valid: true}
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = Optional[T]{}
+		return nil
+	}
+	if err := json.Unmarshal(data, &o.Value); err != nil {
+		return err
+	}
+	o.Valid = true
+	return nil
+}
 `
 
 type goTemplateData struct {
-	PackageName string
-	Structs     []*GoStruct
-	NeedsTime   bool
+	PackageName     string
+	Structs         []*GoStruct
+	Enums           []*GoEnum
+	Imports         []string // Standard library imports in sorted order
+	ExternalImports []string
+	Header          string
+	Constructors    bool
+	Visitors        bool
+	Optional        string            // Declaration of the Optional type, empty if unused
+	Methods         map[string]string // Optional methods by struct name, e.g. Clone and Attributes
+	Helpers         string            // Generic helpers used by Methods
 }
 
 // renderStruct renders struct definition with fields, add MarshalJSON/UnmarshalJSON for unions
@@ -57,9 +150,7 @@ func renderStruct(s *GoStruct) string {
 	var result strings.Builder
 
 	// Add struct comment if present
-	if s.Description != "" {
-		result.WriteString(formatGoComment(s.Description, ""))
-	}
+	result.WriteString(formatGoDoc(s.Description, s.Annotations, ""))
 
 	// Struct definition
 	result.WriteString(fmt.Sprintf("type %s struct {\n", s.Name))
@@ -71,6 +162,11 @@ func renderStruct(s *GoStruct) string {
 
 	result.WriteString("}\n")
 
+	if s.Table != "" {
+		result.WriteString(fmt.Sprintf("\n// %sTable is the database table storing %s\n", s.Name, s.Name))
+		result.WriteString(fmt.Sprintf("const %sTable = %s\n", s.Name, strconv.Quote(s.Table)))
+	}
+
 	// Add custom marshaling for union types
 	if s.IsUnion {
 		result.WriteString("\n")
@@ -82,21 +178,80 @@ func renderStruct(s *GoStruct) string {
 	return result.String()
 }
 
+// renderConstructor renders a NewX function returning the struct with its defaults
+// applied and nested structs and slices initialized. Unions get no constructor since the
+// variant is the caller's choice.
+func renderConstructor(s *GoStruct) string {
+	if s.IsUnion {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("\n// New%s returns a new %s with schema defaults applied\n", s.Name, s.Name))
+	result.WriteString(fmt.Sprintf("func New%s() *%s {\n", s.Name, s.Name))
+	var values strings.Builder
+	for _, field := range s.Fields {
+		if field.Init != "" {
+			values.WriteString(fmt.Sprintf("\t\t%s: %s,\n", field.Name, field.Init))
+		}
+	}
+	if values.Len() == 0 {
+		result.WriteString(fmt.Sprintf("\treturn &%s{}\n", s.Name))
+	} else {
+		result.WriteString(fmt.Sprintf("\treturn &%s{\n%s\t}\n", s.Name, values.String()))
+	}
+	result.WriteString("}\n")
+
+	return result.String()
+}
+
+// renderVisitor renders a visitor interface with a method per union variant and a Visit
+// method dispatching to the variant that is set. Adding a variant to the spec adds an
+// interface method, so the compiler reports every visitor that does not handle it.
+func renderVisitor(s *GoStruct) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("\n// %sVisitor handles each variant of %s\n", s.Name, s.Name))
+	result.WriteString(fmt.Sprintf("type %sVisitor interface {\n", s.Name))
+	for _, field := range s.Fields {
+		result.WriteString(fmt.Sprintf("\tVisit%s(%s) error\n", field.Name, field.Type))
+	}
+	result.WriteString("}\n")
+
+	result.WriteString("\n// Visit calls the method of v for the variant that is set\n")
+	result.WriteString(fmt.Sprintf("func (u *%s) Visit(v %sVisitor) error {\n", s.Name, s.Name))
+	result.WriteString("\tswitch {\n")
+	for _, field := range s.Fields {
+		result.WriteString(fmt.Sprintf("\tcase u.%s != nil:\n", field.Name))
+		result.WriteString(fmt.Sprintf("\t\treturn v.Visit%s(u.%s)\n", field.Name, field.Name))
+	}
+	result.WriteString("\t}\n")
+	result.WriteString(fmt.Sprintf("\treturn fmt.Errorf(\"%s: no variant set\")\n", s.Name))
+	result.WriteString("}\n")
+
+	return result.String()
+}
+
 // renderField renders individual field with JSON tag and pointer notation
 func renderField(f *GoField, indent string) string {
 	var result strings.Builder
 
 	// Add field comment if present
-	if f.Description != "" {
-		result.WriteString(formatGoComment(f.Description, indent))
-	}
+	result.WriteString(formatGoDoc(f.Description, f.Annotations, indent))
 
 	result.WriteString(indent)
 	result.WriteString(fmt.Sprintf("%s %s", f.Name, f.Type))
 
-	// Add JSON tag
+	// Add JSON tag, followed by the YAML tag in Kubernetes mode and the x-db-column tag
 	if f.JSONName != "" {
-		result.WriteString(fmt.Sprintf(" `json:\"%s\"`", f.JSONName))
+		result.WriteString(fmt.Sprintf(" `json:\"%s\"", f.JSONName))
+		if f.YAMLName != "" {
+			result.WriteString(fmt.Sprintf(" yaml:\"%s\"", f.YAMLName))
+		}
+		if f.DBName != "" {
+			result.WriteString(fmt.Sprintf(" db:\"%s\"", f.DBName))
+		}
+		result.WriteString("`")
 	}
 
 	result.WriteString("\n")
@@ -161,7 +316,8 @@ func renderUnionUnmarshal(s *GoStruct) string {
 	result.WriteString(fmt.Sprintf("\tswitch strings.ToLower(discriminator.%s) {\n", discriminatorFieldName))
 
 	// Generate case for each discriminator value
-	for discValue, typeName := range s.DiscriminatorMap {
+	for _, discValue := range s.DiscriminatorKeys {
+		typeName := s.DiscriminatorMap[discValue]
 		result.WriteString(fmt.Sprintf("\tcase \"%s\":\n", discValue))
 		result.WriteString(fmt.Sprintf("\t\tu.%s = &%s{}\n", typeName, typeName))
 		result.WriteString(fmt.Sprintf("\t\treturn json.Unmarshal(data, u.%s)\n", typeName))
@@ -177,13 +333,164 @@ func renderUnionUnmarshal(s *GoStruct) string {
 	return result.String()
 }
 
+// renderGoEnum renders lookup maps and MarshalJSON/UnmarshalJSON shims that serialize an enum
+// using its original OpenAPI values. String enums also get a type and constant declarations.
+func renderGoEnum(e *GoEnum) string {
+	var result strings.Builder
+
+	if e.IsString {
+		if e.Description != "" {
+			result.WriteString(formatGoComment(e.Description, ""))
+		}
+		result.WriteString(fmt.Sprintf("type %s string\n\n", e.Name))
+		result.WriteString("const (\n")
+		for _, value := range e.Values {
+			result.WriteString(fmt.Sprintf("\t%s %s = %s\n", value.Name, e.Name, strconv.Quote(value.Value)))
+		}
+		result.WriteString(")\n\n")
+	}
+
+	// Lookup maps between enum constants and original OpenAPI values
+	result.WriteString(fmt.Sprintf("// %s_OpenAPI maps %s values to their original OpenAPI enum values\n", e.Name, e.Name))
+	result.WriteString(fmt.Sprintf("var %s_OpenAPI = map[%s]string{\n", e.Name, e.Name))
+	for _, value := range e.Values {
+		result.WriteString(fmt.Sprintf("\t%s: %s,\n", value.Name, strconv.Quote(value.Value)))
+	}
+	result.WriteString("}\n\n")
+
+	result.WriteString(fmt.Sprintf("// %s_OpenAPIValue maps original OpenAPI enum values to %s values\n", e.Name, e.Name))
+	result.WriteString(fmt.Sprintf("var %s_OpenAPIValue = map[string]%s{\n", e.Name, e.Name))
+	for _, value := range e.Values {
+		result.WriteString(fmt.Sprintf("\t%s: %s,\n", strconv.Quote(value.Value), value.Name))
+	}
+	result.WriteString("}\n\n")
+
+	result.WriteString(renderEnumMarshal(e))
+	result.WriteString("\n")
+	result.WriteString(renderEnumUnmarshal(e))
+
+	// Callers need a way to detect values that were passed through
+	if e.PreserveUnknown {
+		result.WriteString("\n")
+		result.WriteString(fmt.Sprintf("// IsKnown reports whether x is one of the %s values declared in the OpenAPI spec\n", e.Name))
+		result.WriteString(fmt.Sprintf("func (x %s) IsKnown() bool {\n", e.Name))
+		result.WriteString(fmt.Sprintf("\t_, ok := %s_OpenAPI[x]\n", e.Name))
+		result.WriteString("\treturn ok\n")
+		result.WriteString("}\n")
+	}
+
+	return result.String()
+}
+
+// renderEnumMarshal generates MarshalJSON that writes the original OpenAPI value
+func renderEnumMarshal(e *GoEnum) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("func (x %s) MarshalJSON() ([]byte, error) {\n", e.Name))
+	result.WriteString(fmt.Sprintf("\tvalue, ok := %s_OpenAPI[x]\n", e.Name))
+	result.WriteString("\tif !ok {\n")
+	switch {
+	case e.PreserveUnknown && e.IsString:
+		result.WriteString("\t\t// Unrecognized values are passed through as-is for forward compatibility\n")
+		result.WriteString("\t\treturn json.Marshal(string(x))\n")
+	case e.PreserveUnknown:
+		result.WriteString("\t\t// Unrecognized values are passed through as-is for forward compatibility\n")
+		result.WriteString("\t\treturn []byte(strconv.FormatInt(int64(x), 10)), nil\n")
+	default:
+		result.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s: unknown value %%v\", x)\n", e.Name))
+	}
+	result.WriteString("\t}\n")
+	if e.IsString {
+		result.WriteString("\treturn json.Marshal(value)\n")
+	} else {
+		// Integer enum values are written as JSON numbers
+		result.WriteString("\treturn []byte(value), nil\n")
+	}
+	result.WriteString("}\n")
+
+	return result.String()
+}
+
+// renderEnumUnmarshal generates UnmarshalJSON that maps an original OpenAPI value to the enum constant
+func renderEnumUnmarshal(e *GoEnum) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("func (x *%s) UnmarshalJSON(data []byte) error {\n", e.Name))
+	if e.IsString {
+		result.WriteString("\tvar value string\n")
+	} else {
+		result.WriteString("\tvar value json.Number\n")
+	}
+	result.WriteString("\tif err := json.Unmarshal(data, &value); err != nil {\n")
+	result.WriteString("\t\treturn err\n")
+	result.WriteString("\t}\n")
+	if e.IsString {
+		result.WriteString(fmt.Sprintf("\tv, ok := %s_OpenAPIValue[value]\n", e.Name))
+	} else {
+		result.WriteString(fmt.Sprintf("\tv, ok := %s_OpenAPIValue[value.String()]\n", e.Name))
+	}
+	result.WriteString("\tif !ok {\n")
+	switch {
+	case e.PreserveUnknown && e.IsString:
+		result.WriteString("\t\t// Keep the raw value so newer upstream values survive decoding\n")
+		result.WriteString(fmt.Sprintf("\t\t*x = %s(value)\n", e.Name))
+		result.WriteString("\t\treturn nil\n")
+	case e.PreserveUnknown:
+		// A raw value equal to the number of a declared constant would read back as that
+		// constant, so it is rejected rather than silently renamed
+		result.WriteString("\t\t// Keep the raw value so newer upstream values survive decoding\n")
+		result.WriteString("\t\traw, err := strconv.ParseInt(value.String(), 10, 32)\n")
+		result.WriteString(fmt.Sprintf("\t\tif _, declared := %s_OpenAPI[%s(raw)]; err != nil || declared {\n", e.Name, e.Name))
+		result.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"%s: cannot keep unknown value %%s\", data)\n", e.Name))
+		result.WriteString("\t\t}\n")
+		result.WriteString(fmt.Sprintf("\t\t*x = %s(raw)\n", e.Name))
+		result.WriteString("\t\treturn nil\n")
+	default:
+		result.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: unknown value %%s\", data)\n", e.Name))
+	}
+	result.WriteString("\t}\n")
+	result.WriteString("\t*x = v\n")
+	result.WriteString("\treturn nil\n")
+	result.WriteString("}\n")
+
+	return result.String()
+}
+
+// formatGoDoc renders a description followed by annotations as a doc comment. The
+// annotations form their own paragraph, and a deprecation notice its own paragraph
+// after them, as godoc expects.
+func formatGoDoc(description string, annotations []string, indent string) string {
+	var paragraphs []string
+	if strings.TrimSpace(description) != "" {
+		paragraphs = append(paragraphs, description)
+	}
+
+	var notes []string
+	for _, annotation := range annotations {
+		if strings.HasPrefix(annotation, "Deprecated: ") {
+			continue
+		}
+		notes = append(notes, annotation)
+	}
+	if len(notes) > 0 {
+		paragraphs = append(paragraphs, strings.Join(notes, "\n"))
+	}
+	for _, annotation := range annotations {
+		if strings.HasPrefix(annotation, "Deprecated: ") {
+			paragraphs = append(paragraphs, annotation)
+		}
+	}
+
+	return formatGoComment(strings.Join(paragraphs, "\n\n"), indent)
+}
+
 // formatGoComment formats a description as a Go comment with indentation
 func formatGoComment(description, indent string) string {
 	if strings.TrimSpace(description) == "" {
 		return ""
 	}
 
-	lines := strings.Split(description, "\n")
+	lines := strings.Split(sanitizeComment(description), "\n")
 	var result strings.Builder
 
 	for _, line := range lines {
@@ -1,23 +1,29 @@
 package internal
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/duh-rpc/openapi-proto.go/internal/parser"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
 )
 
 // GoStruct represents a Go struct definition with union metadata
 type GoStruct struct {
-	Name             string
-	Description      string
-	Fields           []*GoField
-	IsUnion          bool
-	UnionVariants    []string
-	Discriminator    string
-	DiscriminatorMap map[string]string // discriminator value -> type name (lowercase keys)
+	Name              string
+	Description       string
+	Annotations       []string // Comment lines after Description, e.g. "Deprecated: ..."
+	Fields            []*GoField
+	IsUnion           bool
+	UnionVariants     []string
+	Discriminator     string
+	DiscriminatorMap  map[string]string // discriminator value -> type name (lowercase keys)
+	DiscriminatorKeys []string          // DiscriminatorMap keys in declaration order
+	Table             string            // Table name from x-db-table, empty for no constant
+	Patch             bool              // Request body of a PATCH operation, gets field mask helpers
 }
 
 // GoField represents a struct field with Go type, JSON tag, pointer flag
@@ -25,16 +31,54 @@ type GoField struct {
 	Name        string
 	Type        string
 	JSONName    string
+	ProtoName   string // Name of the property as a proto field, used as its field mask path
 	Description string
+	Annotations []string // Comment lines after Description: example, constraints, deprecation
 	IsPointer   bool
+	Init        string // Value assigned by the NewX constructor, empty to keep the zero value
+	YAMLName    string // YAML tag name, empty for no yaml tag
+	DBName      string // Column name from x-db-column, empty for no db tag
+	Attribute   string // OpenTelemetry attribute key from x-observability, empty if not exported
+	Sensitive   bool   // Redacted by String and LogValue, from x-sensitive or format: password
+	ref         string // Referenced object schema, initialized by linkConstructors
+}
+
+// GoEnum represents an enum referenced from Go structs along with the original
+// OpenAPI values used for JSON marshaling
+type GoEnum struct {
+	Name            string
+	Description     string
+	Values          []*GoEnumValue
+	IsString        bool // String enums are declared in Go; integer enums are generated by protoc
+	PreserveUnknown bool // Decode unrecognized values instead of returning an error
+}
+
+// GoEnumValue maps a Go enum constant to its original OpenAPI value
+type GoEnumValue struct {
+	Name  string
+	Value string
 }
 
 // GoContext holds state during Go code generation including package name
 type GoContext struct {
-	Tracker     *NameTracker
-	Structs     []*GoStruct
-	PackageName string
-	NeedsTime   bool // Flag for time.Time import
+	Tracker              *NameTracker
+	Structs              []*GoStruct
+	Enums                []*GoEnum
+	PackageName          string
+	NeedsTime            bool              // Flag for time.Time import
+	PreserveUnknownEnums bool              // Keep unrecognized enum values instead of failing decode
+	Header               string            // Comment lines rendered before the package clause
+	Constructors         bool              // Generate NewX constructors applying defaults
+	CloneEqual           bool              // Generate Clone and Equal methods
+	UnionVisitors        bool              // Generate visitor interfaces and Visit methods for unions
+	Optional             bool              // Wrap nullable scalars in the generic Optional type
+	NeedsOptional        bool              // Flag for the Optional type declaration
+	Kubernetes           bool              // Add yaml tags and deepcopy-gen style DeepCopy methods
+	PatchTypes           map[string]bool   // Schemas used as PATCH request bodies
+	AnyOf                AnyOfStrategy     // How properties using anyOf are converted, empty rejects them
+	Pointer              string            // JSON pointer of the schema or property being built
+	Aliases              map[string]string // Skipped aliases mapped to the schema they reference
+	enumNames            map[string]bool
 }
 
 // NewGoContext initializes empty context with package name
@@ -42,8 +86,10 @@ func NewGoContext(packageName string) *GoContext {
 	return &GoContext{
 		Tracker:     NewNameTracker(),
 		Structs:     []*GoStruct{},
+		Enums:       []*GoEnum{},
 		PackageName: packageName,
 		NeedsTime:   false,
+		enumNames:   make(map[string]bool),
 	}
 }
 
@@ -56,14 +102,26 @@ func BuildGoStructs(entries []*parser.SchemaEntry, goTypes map[string]bool, grap
 			continue
 		}
 
+		ctx.Pointer = schemaPointer(entry.Name)
 		goStruct, err := buildGoStruct(entry.Name, entry.Proxy, graph, ctx)
 		if err != nil {
 			return err
 		}
 
+		goStruct.Patch = ctx.PatchTypes[entry.Name] && !goStruct.IsUnion
 		ctx.Structs = append(ctx.Structs, goStruct)
 	}
 
+	if ctx.Constructors {
+		linkConstructors(ctx)
+	}
+	if ctx.Kubernetes {
+		for _, s := range ctx.Structs {
+			for _, field := range s.Fields {
+				field.YAMLName = field.JSONName
+			}
+		}
+	}
 	return nil
 }
 
@@ -74,10 +132,17 @@ func buildGoStruct(name string, proxy *base.SchemaProxy, graph *DependencyGraph,
 		return nil, fmt.Errorf("schema for '%s' is nil", name)
 	}
 
+	table, err := dbExtension(schema, "x-db-table")
+	if err != nil {
+		return nil, fmt.Errorf("schema '%s': %w", name, err)
+	}
+
 	goStruct := &GoStruct{
 		Name:        name,
 		Description: schema.Description,
+		Annotations: deprecationAnnotation(schema),
 		Fields:      make([]*GoField, 0),
+		Table:       table,
 	}
 
 	// Check if this is a union type (schema-level oneOf)
@@ -90,11 +155,12 @@ func buildGoStruct(name string, proxy *base.SchemaProxy, graph *DependencyGraph,
 		goStruct.UnionVariants = variants
 
 		// Build discriminator map with validation
-		discriminatorMap, err := buildDiscriminatorMap(schema, variants, graph.schemas)
+		discriminatorMap, discriminatorKeys, err := buildDiscriminatorMap(schema, variants, graph.schemas)
 		if err != nil {
 			return nil, err
 		}
 		goStruct.DiscriminatorMap = discriminatorMap
+		goStruct.DiscriminatorKeys = discriminatorKeys
 
 		// Create pointer field for each variant
 		for _, variantName := range variants {
@@ -115,7 +181,13 @@ func buildGoStruct(name string, proxy *base.SchemaProxy, graph *DependencyGraph,
 		return goStruct, nil
 	}
 
+	// Proto field names are tracked as the proto builder does, so field mask paths match
+	// the names the properties have in proto messages
+	fieldTracker := acquireNameTracker()
+	defer releaseNameTracker(fieldTracker)
+
 	for propName, propProxy := range schema.Properties.FromOldest() {
+		ctx.Pointer = schemaPointer(name) + "/properties/" + escapePointer(propName)
 		// Get Go type for this property
 		propSchema := propProxy.Schema()
 		if propSchema == nil {
@@ -129,22 +201,189 @@ func buildGoStruct(name string, proxy *base.SchemaProxy, graph *DependencyGraph,
 
 		// Convert property name to Go field name (PascalCase)
 		fieldName := ToPascalCase(propName)
+		protoName, err := SanitizeFieldName(propName)
+		if err != nil {
+			return nil, WrapPropertyError(name, propName, err)
+		}
+
+		column, err := dbExtension(propSchema, "x-db-column")
+		if err != nil {
+			return nil, fmt.Errorf("property '%s' in schema '%s': %w", propName, name, err)
+		}
+
+		attribute, err := observabilityKey(propSchema, propName)
+		if err != nil {
+			return nil, fmt.Errorf("property '%s' in schema '%s': %w", propName, name, err)
+		}
+
+		sensitive, err := isSensitive(propSchema)
+		if err != nil {
+			return nil, fmt.Errorf("property '%s' in schema '%s': %w", propName, name, err)
+		}
+
+		initial := constructorValue(propSchema, typeName)
+		if ctx.Optional && isNullable(propSchema) && !propProxy.IsReference() && isGoScalar(typeName) {
+			if initial != "" {
+				initial = fmt.Sprintf("Some[%s](%s)", typeName, initial)
+			}
+			typeName = "Optional[" + typeName + "]"
+			ctx.NeedsOptional = true
+		}
 
-		goStruct.Fields = append(goStruct.Fields, &GoField{
+		if attribute != "" && !supportsAttribute(typeName) {
+			return nil, Errorf(CodeInvalidExtension, "property '%s' in schema '%s': x-observability is not supported on fields of type %s", propName, name, typeName)
+		}
+
+		field := &GoField{
 			Name:        fieldName,
 			Type:        typeName,
 			JSONName:    propName, // Original OpenAPI property name
+			ProtoName:   fieldTracker.UniqueName(protoName),
 			Description: propSchema.Description,
+			Annotations: fieldAnnotations(propSchema),
 			IsPointer:   isPointer, // Not used if Type already has *
-		})
+			Init:        initial,
+			DBName:      column,
+			Attribute:   attribute,
+			Sensitive:   sensitive,
+		}
+		if propProxy.IsReference() && len(propSchema.OneOf) == 0 &&
+			(contains(propSchema.Type, "object") || propSchema.Properties != nil) {
+			field.ref = strings.TrimPrefix(typeName, "*")
+		}
+		goStruct.Fields = append(goStruct.Fields, field)
 	}
 
 	return goStruct, nil
 }
 
-// buildDiscriminatorMap builds map from discriminator values to type names
-func buildDiscriminatorMap(schema *base.Schema, variants []string, schemas map[string]*base.SchemaProxy) (map[string]string, error) {
+// dbExtension reads the table or column name of an x-db-table or x-db-column extension,
+// or an empty string if schema has none
+func dbExtension(schema *base.Schema, name string) (string, error) {
+	if schema.Extensions == nil {
+		return "", nil
+	}
+	node, found := schema.Extensions.Get(name)
+	if !found || node == nil {
+		return "", nil
+	}
+	if node.Kind != yaml.ScalarNode || node.Value == "" || strings.ContainsAny(node.Value, "\"` \t\n") {
+		return "", Errorf(CodeInvalidExtension, "%s must be a name without quotes or whitespace", name)
+	}
+	return node.Value, nil
+}
+
+// isNullable reports whether schema allows null, through nullable in OpenAPI 3.0 or a
+// "null" type in OpenAPI 3.1
+func isNullable(schema *base.Schema) bool {
+	if schema.Nullable != nil && *schema.Nullable {
+		return true
+	}
+	for _, typ := range schema.Type {
+		if strings.EqualFold(typ, "null") {
+			return true
+		}
+	}
+	for _, member := range schema.AnyOf {
+		if isNullSchema(member.Schema()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGoScalar reports whether typeName is a scalar Go type rather than a pointer or slice
+func isGoScalar(typeName string) bool {
+	return !strings.HasPrefix(typeName, "*") && !strings.HasPrefix(typeName, "[]")
+}
+
+// fieldAnnotations describes the example, validation constraints and deprecation of a
+// property for its Go doc comment
+func fieldAnnotations(schema *base.Schema) []string {
+	var annotations []string
+	if example := scalarExample(schema); example != "" {
+		annotations = append(annotations, "Example: "+example)
+	}
+	if constraints := schemaConstraints(schema); len(constraints) > 0 {
+		annotations = append(annotations, "Constraints: "+strings.Join(constraints, ", "))
+	}
+	return append(annotations, deprecationAnnotation(schema)...)
+}
+
+// deprecationAnnotation returns a godoc deprecation notice if schema is deprecated
+func deprecationAnnotation(schema *base.Schema) []string {
+	if schema.Deprecated == nil || !*schema.Deprecated {
+		return nil
+	}
+	return []string{"Deprecated: marked deprecated in the OpenAPI spec."}
+}
+
+// scalarExample returns the example of schema as JSON if it is a scalar, or an empty
+// string. Object and array examples are left to ConvertOptions.EmitExamples.
+func scalarExample(schema *base.Schema) string {
+	node := schema.Example
+	if node == nil && len(schema.Examples) > 0 {
+		node = schema.Examples[0]
+	}
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	value, err := nodeValue(node)
+	if err != nil {
+		return ""
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// schemaConstraints lists the validation keywords of schema, e.g. "minLength 1"
+func schemaConstraints(schema *base.Schema) []string {
+	var constraints []string
+	number := func(keyword string, value *float64) {
+		if value != nil {
+			constraints = append(constraints, keyword+" "+strconv.FormatFloat(*value, 'g', -1, 64))
+		}
+	}
+	integer := func(keyword string, value *int64) {
+		if value != nil {
+			constraints = append(constraints, keyword+" "+strconv.FormatInt(*value, 10))
+		}
+	}
+	bound := func(keyword, exclusiveKeyword string, value *float64, exclusive *base.DynamicValue[bool, float64]) {
+		switch {
+		case exclusive != nil && exclusive.IsB():
+			number(keyword, value)
+			number(exclusiveKeyword, &exclusive.B)
+		case exclusive != nil && exclusive.A:
+			// OpenAPI 3.0 makes the minimum or maximum itself exclusive
+			number(exclusiveKeyword, value)
+		default:
+			number(keyword, value)
+		}
+	}
+
+	bound("minimum", "exclusiveMinimum", schema.Minimum, schema.ExclusiveMinimum)
+	bound("maximum", "exclusiveMaximum", schema.Maximum, schema.ExclusiveMaximum)
+	number("multipleOf", schema.MultipleOf)
+	integer("minLength", schema.MinLength)
+	integer("maxLength", schema.MaxLength)
+	if schema.Pattern != "" {
+		constraints = append(constraints, "pattern "+schema.Pattern)
+	}
+	integer("minItems", schema.MinItems)
+	integer("maxItems", schema.MaxItems)
+	return constraints
+}
+
+// buildDiscriminatorMap builds map from discriminator values to type names.
+// The returned keys preserve declaration order so generated code is stable across runs.
+func buildDiscriminatorMap(schema *base.Schema, variants []string, schemas map[string]*base.SchemaProxy) (map[string]string, []string, error) {
 	mapping := make(map[string]string)
+	keys := make([]string, 0, len(variants))
 	discriminatorProp := schema.Discriminator.PropertyName
 
 	// If explicit mapping exists, use it
@@ -153,16 +392,19 @@ func buildDiscriminatorMap(schema *base.Schema, variants []string, schemas map[s
 			// Extract "Dog" from "#/components/schemas/Dog"
 			typeName, err := extractReferenceName(ref)
 			if err != nil {
-				return nil, fmt.Errorf("failed to extract type name from discriminator mapping value '%s': %w", value, err)
+				return nil, nil, Errorf(CodeInvalidDiscriminator, "failed to extract type name from discriminator mapping value '%s': %w", value, err)
 			}
 
 			// Check for conflicts (case-insensitive)
 			lowerValue := strings.ToLower(value)
 			if existing, exists := mapping[lowerValue]; exists && existing != typeName {
-				return nil, fmt.Errorf("discriminator conflict: values '%s' and '%s' both map to lowercase '%s'",
+				return nil, nil, Errorf(CodeInvalidDiscriminator, "discriminator conflict: values '%s' and '%s' both map to lowercase '%s'",
 					existing, value, lowerValue)
 			}
 
+			if _, exists := mapping[lowerValue]; !exists {
+				keys = append(keys, lowerValue)
+			}
 			mapping[lowerValue] = typeName // Store lowercase for case-insensitive lookup
 		}
 
@@ -176,11 +418,11 @@ func buildDiscriminatorMap(schema *base.Schema, variants []string, schemas map[s
 				}
 			}
 			if !found {
-				return nil, fmt.Errorf("variant '%s' not covered by discriminator mapping", variant)
+				return nil, nil, Errorf(CodeInvalidDiscriminator, "variant '%s' not covered by discriminator mapping", variant)
 			}
 		}
 
-		return mapping, nil
+		return mapping, keys, nil
 	}
 
 	// Otherwise, build case-insensitive mapping from variant names
@@ -189,10 +431,13 @@ func buildDiscriminatorMap(schema *base.Schema, variants []string, schemas map[s
 
 		// Check for conflicts (e.g., "Dog" and "dog" both exist)
 		if existing, exists := mapping[lowerVariant]; exists && existing != variant {
-			return nil, fmt.Errorf("discriminator conflict: variants '%s' and '%s' both map to lowercase '%s'",
+			return nil, nil, Errorf(CodeInvalidDiscriminator, "discriminator conflict: variants '%s' and '%s' both map to lowercase '%s'",
 				existing, variant, lowerVariant)
 		}
 
+		if _, exists := mapping[lowerVariant]; !exists {
+			keys = append(keys, lowerVariant)
+		}
 		mapping[lowerVariant] = variant // "dog" -> "Dog"
 	}
 
@@ -200,17 +445,17 @@ func buildDiscriminatorMap(schema *base.Schema, variants []string, schemas map[s
 	for _, variant := range variants {
 		variantProxy, exists := schemas[variant]
 		if !exists {
-			return nil, fmt.Errorf("variant '%s' not found in schemas", variant)
+			return nil, nil, Errorf(CodeInvalidReference, "variant '%s' not found in schemas", variant)
 		}
 
 		variantSchema := variantProxy.Schema()
 		if variantSchema == nil {
-			return nil, fmt.Errorf("variant '%s' has nil schema", variant)
+			return nil, nil, fmt.Errorf("variant '%s' has nil schema", variant)
 		}
 
 		// Check if discriminator property exists
 		if variantSchema.Properties == nil {
-			return nil, fmt.Errorf("discriminator property '%s' missing in variant '%s' (no properties)",
+			return nil, nil, Errorf(CodeInvalidDiscriminator, "discriminator property '%s' missing in variant '%s' (no properties)",
 				discriminatorProp, variant)
 		}
 
@@ -223,16 +468,29 @@ func buildDiscriminatorMap(schema *base.Schema, variants []string, schemas map[s
 		}
 
 		if !hasDiscriminator {
-			return nil, fmt.Errorf("discriminator property '%s' missing in variant '%s'",
+			return nil, nil, Errorf(CodeInvalidDiscriminator, "discriminator property '%s' missing in variant '%s'",
 				discriminatorProp, variant)
 		}
 	}
 
-	return mapping, nil
+	return mapping, keys, nil
 }
 
 // goType maps OpenAPI type to Go type using type mapping table
 func goType(schema *base.Schema, propertyName string, propProxy *base.SchemaProxy, ctx *GoContext) (string, bool, error) {
+	// anyOf holds any JSON value, or the widest scalar type of its members
+	if len(schema.AnyOf) > 0 && ctx.AnyOf != "" {
+		if ctx.AnyOf == AnyOfAny {
+			return "any", false, nil
+		}
+		typ, format, _, _, err := widestAnyOfType(schema, propertyName)
+		if err != nil {
+			return "", false, err
+		}
+		scalarType, err := mapGoScalarType(typ, format, ctx)
+		return scalarType, false, err
+	}
+
 	// Check if it's a reference first
 	if propProxy.IsReference() {
 		ref := propProxy.GetReference()
@@ -240,6 +498,13 @@ func goType(schema *base.Schema, propertyName string, propProxy *base.SchemaProx
 		if err != nil {
 			return "", false, fmt.Errorf("property '%s': %w", propertyName, err)
 		}
+		if target, ok := ctx.Aliases[typeName]; ok {
+			typeName = target
+		}
+		// Referenced enums need JSON shims that use the original OpenAPI values
+		if isEnumSchema(schema) {
+			addGoEnum(typeName, schema, ctx)
+		}
 		// Objects/refs are always pointers in Go
 		return "*" + typeName, false, nil
 	}
@@ -253,6 +518,19 @@ func goType(schema *base.Schema, propertyName string, propProxy *base.SchemaProx
 		return arrayType, false, nil
 	}
 
+	// Objects with only additionalProperties are maps keyed by the JSON object keys
+	if isMapSchema(schema) {
+		value := schema.AdditionalProperties.A
+		if value.Schema() == nil {
+			return "", false, Errorf(CodeInvalidReference, "property '%s' has unresolved additionalProperties", propertyName)
+		}
+		valueType, _, err := goType(value.Schema(), propertyName, value, ctx)
+		if err != nil {
+			return "", false, err
+		}
+		return "map[string]" + valueType, false, nil
+	}
+
 	// Check if it's an inline object
 	if len(schema.Type) > 0 && contains(schema.Type, "object") {
 		// For inline objects, derive type name from property name
@@ -262,7 +540,7 @@ func goType(schema *base.Schema, propertyName string, propProxy *base.SchemaProx
 
 	// It's a scalar type
 	if len(schema.Type) == 0 {
-		return "", false, fmt.Errorf("property '%s' must have type or $ref", propertyName)
+		return "", false, Errorf(CodeUnsupportedType, "property '%s' must have type or $ref", propertyName)
 	}
 
 	var typ string
@@ -275,7 +553,7 @@ func goType(schema *base.Schema, propertyName string, propProxy *base.SchemaProx
 		}
 
 		if len(nonNullTypes) != 1 {
-			return "", false, fmt.Errorf("property '%s' has multi-type which is not supported (only nullable variants allowed)", propertyName)
+			return "", false, Errorf(CodeUnsupportedType, "property '%s' has multi-type which is not supported (only nullable variants allowed)", propertyName)
 		}
 
 		typ = nonNullTypes[0]
@@ -292,6 +570,50 @@ func goType(schema *base.Schema, propertyName string, propProxy *base.SchemaProx
 	return scalarType, false, nil
 }
 
+// addGoEnum records an enum referenced from a Go struct so its JSON shims are generated once.
+// Integer enums reuse the constants protoc-gen-go generates for the proto enum, while
+// string enums have no proto counterpart and are declared as Go string types.
+func addGoEnum(typeName string, schema *base.Schema, ctx *GoContext) {
+	if ctx.enumNames[typeName] {
+		return
+	}
+	ctx.enumNames[typeName] = true
+
+	enum := &GoEnum{
+		Name:            typeName,
+		Description:     schema.Description,
+		Values:          []*GoEnumValue{},
+		IsString:        isStringEnum(schema),
+		PreserveUnknown: ctx.PreserveUnknownEnums,
+	}
+
+	protoName := ToPascalCase(typeName)
+	for _, value := range extractEnumValues(schema) {
+		enum.Values = append(enum.Values, &GoEnumValue{
+			Name:  typeName + "_" + toGoIdentifier(ToEnumValueName(protoName, value)),
+			Value: value,
+		})
+	}
+
+	ctx.Enums = append(ctx.Enums, enum)
+}
+
+// toGoIdentifier replaces characters that are not valid in a Go identifier with underscores
+func toGoIdentifier(s string) string {
+	var result strings.Builder
+	result.Grow(len(s))
+
+	for _, r := range s {
+		if isValidProtoFieldChar(r) {
+			result.WriteRune(r)
+		} else {
+			result.WriteRune('_')
+		}
+	}
+
+	return result.String()
+}
+
 // mapGoScalarType maps OpenAPI scalars using type table
 func mapGoScalarType(typ, format string, ctx *GoContext) (string, error) {
 	switch typ {
@@ -316,7 +638,7 @@ func mapGoScalarType(typ, format string, ctx *GoContext) (string, error) {
 		case "int", "":
 			return "int32", nil // Default to int32 for proto3 consistency
 		default:
-			return "", fmt.Errorf("unsupported integer format: %s", format)
+			return "", Errorf(CodeUnsupportedType, "unsupported integer format: %s", format)
 		}
 
 	case "number":
@@ -326,7 +648,7 @@ func mapGoScalarType(typ, format string, ctx *GoContext) (string, error) {
 		case "double", "":
 			return "float64", nil // Default to float64 (double precision)
 		default:
-			return "", fmt.Errorf("unsupported number format: %s", format)
+			return "", Errorf(CodeUnsupportedType, "unsupported number format: %s", format)
 		}
 
 	case "string":
@@ -348,7 +670,7 @@ func mapGoScalarType(typ, format string, ctx *GoContext) (string, error) {
 		return "bool", nil
 
 	default:
-		return "", fmt.Errorf("unsupported type: %s", typ)
+		return "", Errorf(CodeUnsupportedType, "unsupported type: %s", typ)
 	}
 }
 
@@ -356,14 +678,14 @@ func mapGoScalarType(typ, format string, ctx *GoContext) (string, error) {
 func mapGoArrayType(schema *base.Schema, propProxy *base.SchemaProxy, ctx *GoContext) (string, error) {
 	// Check if Items is defined
 	if schema.Items == nil || schema.Items.A == nil {
-		return "", fmt.Errorf("array must have items defined")
+		return "", Errorf(CodeUnsupportedType, "array must have items defined")
 	}
 
 	itemsProxy := schema.Items.A
 	itemsSchema := itemsProxy.Schema()
 	if itemsSchema == nil {
 		if err := itemsProxy.GetBuildError(); err != nil {
-			return "", fmt.Errorf("failed to resolve array items: %w", err)
+			return "", Errorf(CodeInvalidReference, "failed to resolve array items: %w", err)
 		}
 		return "", fmt.Errorf("array items schema is nil")
 	}
@@ -0,0 +1,82 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoDocAnnotations(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      description: A dog
+      deprecated: true
+      properties:
+        petType:
+          type: string
+        name:
+          type: string
+          description: Name of the dog
+          example: Rex
+          minLength: 1
+          maxLength: 64
+          pattern: ^[A-Za-z]+$
+        age:
+          type: integer
+          minimum: 0
+          maximum: 30
+          exclusiveMaximum: true
+        tags:
+          type: array
+          maxItems: 5
+          items:
+            type: string
+        nickname:
+          type: string
+          deprecated: true
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		GoPackagePath: "github.com/example/types/v1",
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(result.Golang), `// A dog
+//
+// Deprecated: marked deprecated in the OpenAPI spec.
+type Dog struct {
+	PetType string `+"`json:\"petType\"`"+`
+	// Name of the dog
+	//
+	// Example: "Rex"
+	// Constraints: minLength 1, maxLength 64, pattern ^[A-Za-z]+$
+	Name string `+"`json:\"name\"`"+`
+	// Constraints: minimum 0, exclusiveMaximum 30
+	Age int32 `+"`json:\"age\"`"+`
+	// Constraints: maxItems 5
+	Tags []string `+"`json:\"tags\"`"+`
+	// Deprecated: marked deprecated in the OpenAPI spec.
+	Nickname string `+"`json:\"nickname\"`"+`
+}`)
+}
@@ -0,0 +1,118 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const attributesSpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+          x-observability: order.id
+        quantity:
+          type: integer
+          x-observability: true
+        status:
+          type: string
+          enum: [open, closed]
+          x-observability: order.status
+        shipped:
+          type: string
+          format: date-time
+          nullable: true
+          x-observability: order.shipped
+        note:
+          type: string
+          x-observability: false
+        receipt:
+          type: string
+          format: byte
+          x-observability: RECEIPT
+        payment:
+          $ref: '#/components/schemas/Payment'
+    Payment:
+      oneOf:
+        - $ref: '#/components/schemas/Card'
+        - $ref: '#/components/schemas/Cash'
+      discriminator:
+        propertyName: kind
+    Card:
+      type: object
+      properties:
+        kind:
+          type: string
+    Cash:
+      type: object
+      properties:
+        kind:
+          type: string
+`
+
+func TestGoAttributes(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		receipt string
+		wantErr string
+	}{
+		{
+			name:    "marked fields",
+			receipt: "false",
+		},
+		{
+			name:    "unsupported type",
+			receipt: "true",
+			wantErr: "property 'receipt' in schema 'Order': x-observability is not supported on fields of type []byte",
+		},
+		{
+			name:    "invalid value",
+			receipt: "[receipt]",
+			wantErr: "x-observability must be true or an attribute key",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := strings.ReplaceAll(attributesSpec, "RECEIPT", test.receipt)
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				GoPackagePath: "github.com/example/types/v1",
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				GoOptional:    true,
+			})
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			golang := string(result.Golang)
+
+			assert.Contains(t, golang, "\n\t\"go.opentelemetry.io/otel/attribute\"\n")
+			assert.Contains(t, golang, `// Attributes returns the Order fields marked with x-observability as OpenTelemetry attributes
+func (x *Order) Attributes() []attribute.KeyValue {
+	if x == nil {
+		return nil
+	}
+	attrs := make([]attribute.KeyValue, 0, 4)
+	attrs = append(attrs, attribute.String("order.id", x.Id))
+	attrs = append(attrs, attribute.Int64("quantity", int64(x.Quantity)))
+	attrs = append(attrs, attribute.String("order.status", x.Status))
+	if x.Shipped.Valid {
+		attrs = append(attrs, attribute.String("order.shipped", x.Shipped.Value.Format(time.RFC3339Nano)))
+	}
+	return attrs
+}
+`)
+			assert.NotContains(t, golang, "func (x *Card) Attributes")
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoConstructors(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+          default: Jane
+        pet:
+          $ref: '#/components/schemas/Pet'
+        favorite:
+          $ref: '#/components/schemas/Dog'
+        location:
+          $ref: '#/components/schemas/Location'
+        manager:
+          $ref: '#/components/schemas/Owner'
+        tags:
+          type: array
+          default: [new, "quoted \"tag\""]
+          items:
+            type: string
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+          default: dog
+        age:
+          type: integer
+          default: 3
+        weight:
+          type: number
+          default: 12.5
+        trained:
+          type: boolean
+          default: true
+        owner:
+          $ref: '#/components/schemas/Owner'
+        scores:
+          type: array
+          items:
+            type: integer
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+    Location:
+      type: object
+      properties:
+        city:
+          type: string
+`
+
+	for _, test := range []struct {
+		name         string
+		constructors bool
+		contains     []string
+		notContains  []string
+	}{
+		{
+			name:         "enabled",
+			constructors: true,
+			contains: []string{
+				`// NewOwner returns a new Owner with schema defaults applied
+func NewOwner() *Owner {
+	return &Owner{
+		Name: "Jane",
+		Favorite: NewDog(),
+		Location: &Location{},
+		Tags: []string{"new", "quoted \"tag\""},
+	}
+}
+`,
+				`func NewDog() *Dog {
+	return &Dog{
+		PetType: "dog",
+		Age: 3,
+		Weight: 12.5,
+		Trained: true,
+		Scores: []int32{},
+	}
+}
+`,
+				`func NewCat() *Cat {
+	return &Cat{}
+}
+`,
+			},
+			notContains: []string{"func NewPet"},
+		},
+		{
+			name:        "disabled",
+			notContains: []string{"func NewOwner", "func NewDog"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				GoPackagePath:  "github.com/example/types/v1",
+				PackageName:    "testpkg",
+				PackagePath:    "github.com/example/proto/v1",
+				GoConstructors: test.constructors,
+			})
+			require.NoError(t, err)
+
+			for _, want := range test.contains {
+				assert.Contains(t, string(result.Golang), want)
+			}
+			for _, unwanted := range test.notContains {
+				assert.NotContains(t, string(result.Golang), unwanted)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoCloneEqual(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+        pets:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+        location:
+          $ref: '#/components/schemas/Location'
+        status:
+          $ref: '#/components/schemas/Status'
+        born:
+          type: string
+          format: date-time
+        avatar:
+          type: string
+          format: byte
+        tags:
+          type: array
+          items:
+            type: string
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+    Location:
+      type: object
+      properties:
+        city:
+          type: string
+    Status:
+      type: string
+      enum: [active, inactive]
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		GoPackagePath: "github.com/example/types/v1",
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		GoCloneEqual:  true,
+	})
+	require.NoError(t, err)
+	golang := string(result.Golang)
+
+	for _, want := range []string{
+		`import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+)`,
+		`// Clone returns a deep copy of x
+func (x *Owner) Clone() *Owner {
+	if x == nil {
+		return nil
+	}
+	c := *x
+	c.Pets = cloneSlice(x.Pets, func(v *Pet) *Pet { return v.Clone() })
+	c.Location = proto.Clone(x.Location).(*Location)
+	c.Status = clonePointer(x.Status)
+	c.Avatar = bytes.Clone(x.Avatar)
+	c.Tags = slices.Clone(x.Tags)
+	return &c
+}`,
+		`// Equal reports whether x and y hold the same values
+func (x *Owner) Equal(y *Owner) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return x.Name == y.Name &&
+		slices.EqualFunc(x.Pets, y.Pets, func(v, w *Pet) bool { return v.Equal(w) }) &&
+		proto.Equal(x.Location, y.Location) &&
+		equalPointer(x.Status, y.Status) &&
+		x.Born.Equal(y.Born) &&
+		bytes.Equal(x.Avatar, y.Avatar) &&
+		slices.Equal(x.Tags, y.Tags)
+}`,
+		`func (x *Pet) Clone() *Pet {
+	if x == nil {
+		return nil
+	}
+	c := *x
+	c.Dog = x.Dog.Clone()
+	c.Cat = x.Cat.Clone()
+	return &c
+}`,
+		`func cloneSlice[T any](s []T, clone func(T) T) []T {`,
+		`func equalPointer[T comparable](a, b *T) bool {`,
+	} {
+		assert.Contains(t, golang, want)
+	}
+}
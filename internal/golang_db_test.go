@@ -0,0 +1,93 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dbSpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Owner:
+      type: object
+      x-db-table: owners
+      properties:
+        id:
+          type: string
+          x-db-column: owner_id
+        pet:
+          $ref: '#/components/schemas/Pet'
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+          x-db-column: COLUMN
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+func TestGoDBTags(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		column  string
+		wantErr string
+	}{
+		{
+			name:   "column",
+			column: "pet_type",
+		},
+		{
+			name:    "column with whitespace",
+			column:  "'pet type'",
+			wantErr: "property 'petType' in schema 'Dog': x-db-column must be a name without quotes or whitespace",
+		},
+		{
+			name:    "column list",
+			column:  "[pet_type]",
+			wantErr: "x-db-column must be a name without quotes or whitespace",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := strings.ReplaceAll(dbSpec, "COLUMN", test.column)
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				GoPackagePath: "github.com/example/types/v1",
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+			})
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			golang := string(result.Golang)
+
+			assert.Contains(t, golang, `type Owner struct {
+	Id string `+"`json:\"id\" db:\"owner_id\"`"+`
+	Pet *Pet `+"`json:\"pet\"`"+`
+}
+
+// OwnerTable is the database table storing Owner
+const OwnerTable = "owners"
+`)
+			assert.Contains(t, golang, "\tPetType string `json:\"petType\" db:\"pet_type\"`\n")
+			assert.NotContains(t, golang, "DogTable")
+		})
+	}
+}
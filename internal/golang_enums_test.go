@@ -0,0 +1,274 @@
+package internal_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const enumUnionSpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Task:
+      oneOf:
+        - $ref: '#/components/schemas/Bug'
+        - $ref: '#/components/schemas/Story'
+      discriminator:
+        propertyName: kind
+    Bug:
+      type: object
+      properties:
+        kind:
+          type: string
+        status:
+          $ref: '#/components/schemas/Status'
+        code:
+          $ref: '#/components/schemas/Code'
+        history:
+          type: array
+          items:
+            $ref: '#/components/schemas/Status'
+    Story:
+      type: object
+      properties:
+        kind:
+          type: string
+        status:
+          $ref: '#/components/schemas/Status'
+    Status:
+      type: string
+      description: Task status
+      enum:
+        - pending
+        - in-progress
+    Code:
+      type: integer
+      enum:
+        - 200
+        - 404
+`
+
+// TestGoStringEnumJSONShims validates string enums referenced from Go structs get a Go type and JSON shims
+func TestGoStringEnumJSONShims(t *testing.T) {
+	result, err := conv.Convert([]byte(enumUnionSpec), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	goCode := string(result.Golang)
+
+	assert.Contains(t, goCode, "type Status string")
+	assert.Contains(t, goCode, "\tStatus_STATUS_IN_PROGRESS Status = \"in-progress\"\n")
+	assert.Contains(t, goCode, "var Status_OpenAPI = map[Status]string{\n")
+	assert.Contains(t, goCode, "\tStatus_STATUS_PENDING: \"pending\",\n")
+	assert.Contains(t, goCode, "var Status_OpenAPIValue = map[string]Status{\n")
+	assert.Contains(t, goCode, "\t\"in-progress\": Status_STATUS_IN_PROGRESS,\n")
+	assert.Contains(t, goCode, "func (x Status) MarshalJSON() ([]byte, error) {")
+	assert.Contains(t, goCode, "func (x *Status) UnmarshalJSON(data []byte) error {")
+
+	// Referenced from two structs and an array but declared once
+	assert.Equal(t, 1, strings.Count(goCode, "type Status string"))
+}
+
+// TestGoIntegerEnumJSONShims validates proto-generated enums get JSON shims without redeclaring the type
+func TestGoIntegerEnumJSONShims(t *testing.T) {
+	result, err := conv.Convert([]byte(enumUnionSpec), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	goCode := string(result.Golang)
+
+	// The Code type and its constants come from protoc-gen-go
+	assert.NotContains(t, goCode, "type Code ")
+	assert.Contains(t, goCode, "\tCode_CODE_404: \"404\",\n")
+	assert.Contains(t, goCode, "\t\"200\": Code_CODE_200,\n")
+	assert.Contains(t, goCode, "\treturn []byte(value), nil\n")
+	assert.Contains(t, goCode, "\tvar value json.Number\n")
+
+	assert.Contains(t, string(result.Protobuf), "enum Code {")
+}
+
+// TestGoEnumJSONRoundTrip validates enums marshal using their original OpenAPI values
+func TestGoEnumJSONRoundTrip(t *testing.T) {
+	result, err := conv.Convert([]byte(enumUnionSpec), conv.ConvertOptions{
+		GoPackagePath: "test/types",
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto",
+	})
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	typesDir := filepath.Join(tmpDir, "types")
+	require.NoError(t, os.MkdirAll(typesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(typesDir, "types.go"), result.Golang, 0644))
+
+	// Stand-in for the enum protoc-gen-go would generate in the same package
+	protoStub := `package types
+
+type Code int32
+
+const (
+	Code_CODE_UNSPECIFIED Code = 0
+	Code_CODE_200         Code = 1
+	Code_CODE_404         Code = 2
+)
+`
+	require.NoError(t, os.WriteFile(filepath.Join(typesDir, "code.go"), []byte(protoStub), 0644))
+
+	testProg := `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"test/types"
+)
+
+func main() {
+	var task types.Task
+	if err := json.Unmarshal([]byte(` + "`" + `{"kind":"bug","status":"in-progress","code":404,"history":["pending"]}` + "`" + `), &task); err != nil {
+		fmt.Fprintf(os.Stderr, "unmarshal error: %v\n", err)
+		os.Exit(1)
+	}
+	if *task.Bug.Status != types.Status_STATUS_IN_PROGRESS {
+		fmt.Fprintf(os.Stderr, "expected in-progress status, got %v\n", *task.Bug.Status)
+		os.Exit(1)
+	}
+	if *task.Bug.Code != types.Code_CODE_404 {
+		fmt.Fprintf(os.Stderr, "expected CODE_404, got %v\n", *task.Bug.Code)
+		os.Exit(1)
+	}
+
+	out, err := json.Marshal(&task)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "marshal error: %v\n", err)
+		os.Exit(1)
+	}
+	if string(out) != ` + "`" + `{"kind":"bug","status":"in-progress","code":404,"history":["pending"]}` + "`" + ` {
+		fmt.Fprintf(os.Stderr, "unexpected marshal output: %s\n", out)
+		os.Exit(1)
+	}
+
+	err = json.Unmarshal([]byte(` + "`" + `{"kind":"bug","status":"done"}` + "`" + `), &task)
+	if err == nil || err.Error() != ` + "`" + `Status: unknown value "done"` + "`" + ` {
+		fmt.Fprintf(os.Stderr, "expected unknown value error, got %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("OK")
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(testProg), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module test\ngo 1.21\n"), 0644))
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = tmpDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "test program failed:\n%s", string(output))
+	assert.Contains(t, string(output), "OK")
+}
+
+// TestGoEnumPreserveUnknown validates unrecognized enum values survive a JSON round trip when enabled
+func TestGoEnumPreserveUnknown(t *testing.T) {
+	result, err := conv.Convert([]byte(enumUnionSpec), conv.ConvertOptions{
+		GoPackagePath:        "test/types",
+		PackageName:          "testpkg",
+		PackagePath:          "github.com/example/proto",
+		PreserveUnknownEnums: true,
+	})
+	require.NoError(t, err)
+
+	goCode := string(result.Golang)
+	assert.Contains(t, goCode, "func (x Status) IsKnown() bool {")
+	assert.Contains(t, goCode, "\t\t*x = Status(value)\n")
+	assert.Contains(t, goCode, "\t\t*x = Code(raw)\n")
+	assert.NotContains(t, goCode, "Status: unknown value")
+
+	tmpDir := t.TempDir()
+	typesDir := filepath.Join(tmpDir, "types")
+	require.NoError(t, os.MkdirAll(typesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(typesDir, "types.go"), result.Golang, 0644))
+
+	// Stand-in for the enum protoc-gen-go would generate in the same package
+	protoStub := `package types
+
+type Code int32
+
+const (
+	Code_CODE_UNSPECIFIED Code = 0
+	Code_CODE_200         Code = 1
+	Code_CODE_404         Code = 2
+)
+`
+	require.NoError(t, os.WriteFile(filepath.Join(typesDir, "code.go"), []byte(protoStub), 0644))
+
+	testProg := `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"test/types"
+)
+
+func main() {
+	for _, given := range []string{
+		` + "`" + `{"kind":"bug","status":"blocked","code":503}` + "`" + `,
+		` + "`" + `{"kind":"bug","status":"open","code":-5}` + "`" + `,
+		` + "`" + `{"kind":"bug","status":"blocked","code":200}` + "`" + `,
+	} {
+		var task types.Task
+		if err := json.Unmarshal([]byte(given), &task); err != nil {
+			fmt.Fprintf(os.Stderr, "unmarshal error: %v\n", err)
+			os.Exit(1)
+		}
+		out, err := json.Marshal(task.Bug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "marshal error: %v\n", err)
+			os.Exit(1)
+		}
+		var want, got map[string]any
+		_ = json.Unmarshal([]byte(given), &want)
+		_ = json.Unmarshal(out, &got)
+		if fmt.Sprint(want["status"], want["code"]) != fmt.Sprint(got["status"], got["code"]) {
+			fmt.Fprintf(os.Stderr, "round trip of %s gave %s\n", given, out)
+			os.Exit(1)
+		}
+	}
+
+	var task types.Task
+	_ = json.Unmarshal([]byte(` + "`" + `{"kind":"bug","status":"blocked","code":503}` + "`" + `), &task)
+	if task.Bug.Status.IsKnown() || task.Bug.Code.IsKnown() || *task.Bug.Code != 503 {
+		fmt.Fprintf(os.Stderr, "unknown values not kept as given: %v %v\n", *task.Bug.Status, *task.Bug.Code)
+		os.Exit(1)
+	}
+	// 1 is unknown upstream and also the number of the proto constant Code_CODE_200
+	if err := json.Unmarshal([]byte(` + "`" + `{"kind":"bug","code":1}` + "`" + `), &task); err == nil {
+		fmt.Fprintln(os.Stderr, "expected unknown code colliding with a constant to fail")
+		os.Exit(1)
+	}
+
+	fmt.Println("OK")
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(testProg), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module test\ngo 1.21\n"), 0644))
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = tmpDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "test program failed:\n%s", string(output))
+	assert.Contains(t, string(output), "OK")
+}
@@ -0,0 +1,83 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoKubernetes(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    BackupSpec:
+      type: object
+      properties:
+        schedule:
+          type: string
+        targets:
+          type: array
+          items:
+            $ref: '#/components/schemas/Target'
+    Target:
+      oneOf:
+        - $ref: '#/components/schemas/Bucket'
+        - $ref: '#/components/schemas/Volume'
+      discriminator:
+        propertyName: kind
+    Bucket:
+      type: object
+      properties:
+        kind:
+          type: string
+    Volume:
+      type: object
+      properties:
+        kind:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		GoPackagePath: "github.com/example/types/v1",
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		GoKubernetes:  true,
+	})
+	require.NoError(t, err)
+	golang := string(result.Golang)
+
+	for _, want := range []string{
+		"\tSchedule string `json:\"schedule\" yaml:\"schedule\"`\n",
+		"\tBucket *Bucket `json:\"-\" yaml:\"-\"`\n",
+		`// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
+	*out = *in
+	out.Targets = cloneSlice(in.Targets, func(v *Target) *Target { return v.DeepCopy() })
+}
+
+// DeepCopy returns a deep copy of the receiver
+func (in *BackupSpec) DeepCopy() *BackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+`,
+		`func (in *Target) DeepCopyInto(out *Target) {
+	*out = *in
+	out.Bucket = in.Bucket.DeepCopy()
+	out.Volume = in.Volume.DeepCopy()
+}
+`,
+	} {
+		assert.Contains(t, golang, want)
+	}
+	assert.NotContains(t, golang, "Clone()")
+}
@@ -0,0 +1,115 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoFieldMask(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /owners/{id}:
+    patch:
+      requestBody:
+        content:
+          application/merge-patch+json:
+            schema:
+              $ref: '#/components/schemas/Owner'
+      responses:
+        '200':
+          description: Updated
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+        pet:
+          $ref: '#/components/schemas/Pet'
+        tags:
+          type: array
+          items:
+            type: string
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		GoPackagePath: "github.com/example/types/v1",
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	golang := string(result.Golang)
+
+	for _, want := range []string{
+		"\t\"google.golang.org/protobuf/types/known/fieldmaskpb\"\n",
+		`// FieldMask returns a mask of the fields that differ between x and updated, the
+// fields a PATCH request changing x into updated must send
+func (x *Owner) FieldMask(updated *Owner) *fieldmaskpb.FieldMask {
+	if x == nil {
+		x = &Owner{}
+	}
+	if updated == nil {
+		updated = &Owner{}
+	}
+	mask := &fieldmaskpb.FieldMask{}
+	if !(x.Name == updated.Name) {
+		mask.Paths = append(mask.Paths, "name")
+	}
+	if !(reflect.DeepEqual(x.Pet, updated.Pet)) {
+		mask.Paths = append(mask.Paths, "pet")
+	}
+	if !(slices.Equal(x.Tags, updated.Tags)) {
+		mask.Paths = append(mask.Paths, "tags")
+	}
+	return mask
+}
+`,
+		`func (x *Owner) ApplyFieldMask(src *Owner, mask *fieldmaskpb.FieldMask) error {
+	for _, path := range mask.GetPaths() {
+		switch path {
+		case "name":
+			x.Name = src.Name
+		case "pet":
+			x.Pet = src.Pet
+		case "tags":
+			x.Tags = src.Tags
+		default:
+			return fmt.Errorf("Owner: unknown field mask path %q", path)
+		}
+	}
+	return nil
+}
+`,
+	} {
+		assert.Contains(t, golang, want)
+	}
+	assert.NotContains(t, golang, "func (x *Pet) FieldMask")
+	assert.NotContains(t, golang, "func (x *Dog) FieldMask")
+}
@@ -0,0 +1,116 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoOptional(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		version  string
+		nickname string
+	}{
+		{
+			name:    "openapi 3.0 nullable",
+			version: "3.0.0",
+			nickname: `type: string
+          nullable: true`,
+		},
+		{
+			name:     "openapi 3.1 null type",
+			version:  "3.1.0",
+			nickname: `type: [string, "null"]`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: ` + test.version + `
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+        nickname:
+          ` + test.nickname + `
+          default: Rex
+        age:
+          type: integer
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				GoPackagePath:  "github.com/example/types/v1",
+				PackageName:    "testpkg",
+				PackagePath:    "github.com/example/proto/v1",
+				GoOptional:     true,
+				GoConstructors: true,
+				GoCloneEqual:   true,
+			})
+			require.NoError(t, err)
+			golang := string(result.Golang)
+
+			assert.Contains(t, golang, "\tNickname Optional[string] `json:\"nickname\"`\n")
+			assert.Contains(t, golang, "\tAge int32 `json:\"age\"`\n")
+			assert.Contains(t, golang, "\t\tNickname: Some[string](\"Rex\"),\n")
+			assert.Contains(t, golang, "\t\tx.Nickname.Valid == y.Nickname.Valid && x.Nickname.Value == y.Nickname.Value &&\n")
+			assert.Contains(t, golang, "type Optional[T any] struct {")
+			assert.Contains(t, golang, "func (o *Optional[T]) UnmarshalJSON(data []byte) error {")
+		})
+	}
+}
+
+func TestGoOptionalDisabled(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+        nickname:
+          type: string
+          nullable: true
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		GoPackagePath: "github.com/example/types/v1",
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Golang), "\tNickname string `json:\"nickname\"`\n")
+	assert.NotContains(t, string(result.Golang), "Optional")
+}
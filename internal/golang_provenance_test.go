@@ -0,0 +1,84 @@
+package internal_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoProvenanceHeader(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(given)))
+
+	for _, test := range []struct {
+		name       string
+		provenance conv.ProvenanceOptions
+		expected   string
+	}{
+		{
+			name:     "none",
+			expected: "package types\n",
+		},
+		{
+			name:       "source",
+			provenance: conv.ProvenanceOptions{Source: "api/openapi.yaml"},
+			expected: "// Code generated by openapi-proto. DO NOT EDIT.\n" +
+				"// source: api/openapi.yaml@sha256:" + hash + "\n\npackage types\n",
+		},
+		{
+			name: "source and go generate",
+			provenance: conv.ProvenanceOptions{
+				Source:     "api/openapi.yaml",
+				GoGenerate: []string{"go run ./cmd/gen -spec api/openapi.yaml"},
+			},
+			expected: "// Code generated by openapi-proto. DO NOT EDIT.\n" +
+				"// source: api/openapi.yaml@sha256:" + hash + "\n\n" +
+				"//go:generate go run ./cmd/gen -spec api/openapi.yaml\n\npackage types\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				GoPackagePath: "github.com/example/types/v1",
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				Provenance:    test.provenance,
+			})
+			require.NoError(t, err)
+
+			goCode := string(result.Golang)
+			assert.Equal(t, test.expected, goCode[:len(test.expected)])
+
+			file, err := parser.ParseFile(token.NewFileSet(), "types.go", result.Golang, parser.ParseComments)
+			require.NoError(t, err)
+			assert.Nil(t, file.Doc)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoUnionVisitors(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    PaymentMethod:
+      oneOf:
+        - $ref: '#/components/schemas/Card'
+        - $ref: '#/components/schemas/BankTransfer'
+      discriminator:
+        propertyName: kind
+    Card:
+      type: object
+      properties:
+        kind:
+          type: string
+    BankTransfer:
+      type: object
+      properties:
+        kind:
+          type: string
+`
+
+	for _, test := range []struct {
+		name     string
+		visitors bool
+		expected bool
+	}{
+		{name: "enabled", visitors: true, expected: true},
+		{name: "disabled"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				GoPackagePath:   "github.com/example/types/v1",
+				PackageName:     "testpkg",
+				PackagePath:     "github.com/example/proto/v1",
+				GoUnionVisitors: test.visitors,
+			})
+			require.NoError(t, err)
+
+			visitor := `// PaymentMethodVisitor handles each variant of PaymentMethod
+type PaymentMethodVisitor interface {
+	VisitCard(*Card) error
+	VisitBankTransfer(*BankTransfer) error
+}
+
+// Visit calls the method of v for the variant that is set
+func (u *PaymentMethod) Visit(v PaymentMethodVisitor) error {
+	switch {
+	case u.Card != nil:
+		return v.VisitCard(u.Card)
+	case u.BankTransfer != nil:
+		return v.VisitBankTransfer(u.BankTransfer)
+	}
+	return fmt.Errorf("PaymentMethod: no variant set")
+}
+`
+			if test.expected {
+				assert.Contains(t, string(result.Golang), visitor)
+				assert.NotContains(t, string(result.Golang), "CardVisitor")
+				return
+			}
+			assert.NotContains(t, string(result.Golang), "Visitor")
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldMaskImport is the import path of the protobuf FieldMask type
+const fieldMaskImport = "google.golang.org/protobuf/types/known/fieldmaskpb"
+
+// renderFieldMask renders FieldMask and ApplyFieldMask methods for the request body of a
+// PATCH operation, bridging JSON merge patches and the proto FieldMask convention. Mask
+// paths are the top level proto field names, so masks interoperate with proto messages
+// and google.protobuf.FieldMask. Only Go structs get the methods: proto messages are
+// generated by protoc-gen-go, in a package this converter emits no code into.
+func (r *copyRenderer) renderFieldMask(s *GoStruct) string {
+	r.imports[fieldMaskImport] = true
+
+	var result strings.Builder
+	result.WriteString("\n// FieldMask returns a mask of the fields that differ between x and updated, the\n")
+	result.WriteString("// fields a PATCH request changing x into updated must send\n")
+	result.WriteString(fmt.Sprintf("func (x *%s) FieldMask(updated *%s) *fieldmaskpb.FieldMask {\n", s.Name, s.Name))
+	result.WriteString(fmt.Sprintf("\tif x == nil {\n\t\tx = &%s{}\n\t}\n", s.Name))
+	result.WriteString(fmt.Sprintf("\tif updated == nil {\n\t\tupdated = &%s{}\n\t}\n", s.Name))
+	result.WriteString("\tmask := &fieldmaskpb.FieldMask{}\n")
+	for _, field := range s.Fields {
+		if field.JSONName == "-" {
+			continue
+		}
+		equal := r.equalExpr(field.Type, "x."+field.Name, "updated."+field.Name)
+		result.WriteString(fmt.Sprintf("\tif !(%s) {\n", equal))
+		result.WriteString(fmt.Sprintf("\t\tmask.Paths = append(mask.Paths, %q)\n", field.ProtoName))
+		result.WriteString("\t}\n")
+	}
+	result.WriteString("\treturn mask\n}\n")
+
+	result.WriteString("\n// ApplyFieldMask copies the fields named by mask from src into x, as a PATCH request\n")
+	result.WriteString("// does. Returns an error for a path that names no field.\n")
+	result.WriteString(fmt.Sprintf("func (x *%s) ApplyFieldMask(src *%s, mask *fieldmaskpb.FieldMask) error {\n", s.Name, s.Name))
+	result.WriteString("\tfor _, path := range mask.GetPaths() {\n")
+	result.WriteString("\t\tswitch path {\n")
+	for _, field := range s.Fields {
+		if field.JSONName == "-" {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("\t\tcase %q:\n", field.ProtoName))
+		result.WriteString(fmt.Sprintf("\t\t\tx.%s = src.%s\n", field.Name, field.Name))
+	}
+	result.WriteString("\t\tdefault:\n")
+	result.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"%s: unknown field mask path %%q\", path)\n", s.Name))
+	result.WriteString("\t\t}\n\t}\n")
+	result.WriteString("\treturn nil\n}\n")
+
+	return result.String()
+}
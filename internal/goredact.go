@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// redacted replaces the value of sensitive string fields in String and LogValue output
+const redacted = "[REDACTED]"
+
+// renderRedaction renders String and LogValue methods for a struct with sensitive fields,
+// or an empty string if it has none. String formats the struct with %+v after replacing
+// sensitive values, and LogValue implements slog.LogValuer so structured logs keep the
+// redaction. Nested structs are logged through their own LogValue methods.
+func renderRedaction(s *GoStruct) string {
+	sensitive := false
+	for _, field := range s.Fields {
+		sensitive = sensitive || field.Sensitive
+	}
+	if !sensitive || s.IsUnion {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("\n// String formats %s with sensitive fields redacted\n", s.Name))
+	result.WriteString(fmt.Sprintf("func (x *%s) String() string {\n", s.Name))
+	result.WriteString("\tif x == nil {\n\t\treturn \"<nil>\"\n\t}\n")
+	result.WriteString(fmt.Sprintf("\ttype plain %s\n", s.Name))
+	result.WriteString("\tc := plain(*x)\n")
+	for _, field := range s.Fields {
+		if field.Sensitive {
+			result.WriteString(fmt.Sprintf("\tc.%s = %s\n", field.Name, redactedValue(field.Type)))
+		}
+	}
+	result.WriteString("\treturn fmt.Sprintf(\"%+v\", c)\n}\n")
+
+	result.WriteString("\n// LogValue implements slog.LogValuer with sensitive fields redacted\n")
+	result.WriteString(fmt.Sprintf("func (x *%s) LogValue() slog.Value {\n", s.Name))
+	result.WriteString("\tif x == nil {\n\t\treturn slog.AnyValue(nil)\n\t}\n")
+	result.WriteString("\treturn slog.GroupValue(\n")
+	for _, field := range s.Fields {
+		if field.Sensitive {
+			result.WriteString(fmt.Sprintf("\t\tslog.String(%q, %q),\n", field.JSONName, redacted))
+			continue
+		}
+		result.WriteString(fmt.Sprintf("\t\tslog.Any(%q, x.%s),\n", field.JSONName, field.Name))
+	}
+	result.WriteString("\t)\n}\n")
+
+	return result.String()
+}
+
+// redactedValue returns the value String shows for a sensitive field of typeName: the
+// redaction marker for strings and the zero value for anything else
+func redactedValue(typeName string) string {
+	switch {
+	case typeName == "string":
+		return fmt.Sprintf("%q", redacted)
+	case typeName == "bool":
+		return "false"
+	case strings.HasPrefix(typeName, "[]"), strings.HasPrefix(typeName, "*"):
+		return "nil"
+	case strings.HasPrefix(typeName, "int"), strings.HasPrefix(typeName, "uint"), strings.HasPrefix(typeName, "float"):
+		return "0"
+	}
+	return typeName + "{}"
+}
@@ -0,0 +1,51 @@
+package internal
+
+// HoistInlineObjects promotes nested messages built from inline objects to top-level
+// messages named after their parent and property (User.Profile becomes UserProfile).
+// Hoisted messages follow their parent in output order, keep the parent's
+// OriginalSchema, and take a numeric suffix from the context tracker if the name is
+// already used.
+func HoistInlineObjects(ctx *Context) {
+	hoisted := make(map[*ProtoMessage][]*ProtoMessage, len(ctx.Messages))
+	messages := make([]*ProtoMessage, 0, len(ctx.Messages))
+	for _, msg := range ctx.Messages {
+		hoisted[msg] = hoistMessage(msg, ctx.Tracker)
+		messages = append(messages, hoisted[msg]...)
+	}
+	ctx.Messages = messages
+
+	definitions := make([]interface{}, 0, len(ctx.Definitions))
+	for _, def := range ctx.Definitions {
+		if msg, ok := def.(*ProtoMessage); ok && hoisted[msg] != nil {
+			for _, m := range hoisted[msg] {
+				definitions = append(definitions, m)
+			}
+			continue
+		}
+		definitions = append(definitions, def)
+	}
+	ctx.Definitions = definitions
+}
+
+// hoistMessage returns msg followed by its nested messages, depth first, with field
+// types rewritten to the hoisted names
+func hoistMessage(msg *ProtoMessage, tracker *NameTracker) []*ProtoMessage {
+	result := []*ProtoMessage{msg}
+
+	nested := msg.Nested
+	msg.Nested = nil
+	for _, child := range nested {
+		name := tracker.UniqueName(msg.Name + child.Name)
+		for _, field := range msg.Fields {
+			if field.Type == child.Name {
+				field.Type = name
+			}
+		}
+
+		child.Name = name
+		child.OriginalSchema = msg.OriginalSchema
+		result = append(result, hoistMessage(child, tracker)...)
+	}
+
+	return result
+}
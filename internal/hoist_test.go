@@ -0,0 +1,91 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoistInlineObjects(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        profile:
+          type: object
+          description: Public profile
+          properties:
+            bio:
+              type: string
+            location:
+              type: object
+              properties:
+                city:
+                  type: string
+        tag:
+          type: array
+          items:
+            type: object
+            properties:
+              label:
+                type: string
+    UserProfile:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		InlineObjects: conv.InlineObjectsHoisted,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+message User {
+  string name = 1 [json_name = "name"];
+  UserProfile_2 profile = 2 [json_name = "profile"];
+  repeated UserTag tag = 3 [json_name = "tag"];
+}
+
+// Public profile
+message UserProfile_2 {
+  string bio = 1 [json_name = "bio"];
+  UserProfile_2Location location = 2 [json_name = "location"];
+}
+
+message UserProfile_2Location {
+  string city = 1 [json_name = "city"];
+}
+
+message UserTag {
+  string label = 1 [json_name = "label"];
+}
+
+message UserProfile {
+  string id = 1 [json_name = "id"];
+}
+
+`, string(result.Protobuf))
+
+	// UserProfile is taken by a schema, so the hoisted message takes the next free name.
+	// Hoisted messages resolve as top-level types
+	_, err = result.NewMessage("UserTag")
+	require.NoError(t, err)
+}
@@ -0,0 +1,121 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportRewrites(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Book:
+      type: object
+      properties:
+        name:
+          type: string
+          x-proto-field-behavior: [IDENTIFIER]
+        createdAt:
+          type: string
+          format: date-time
+`
+
+	for _, test := range []struct {
+		name     string
+		rewrites map[string]string
+		expected string
+	}{
+		{
+			name: "no rewrites",
+			expected: `import "google/api/field_behavior.proto";
+import "google/protobuf/timestamp.proto";
+`,
+		},
+		{
+			name: "exact path",
+			rewrites: map[string]string{
+				"google/api/field_behavior.proto": "third_party/google/api/field_behavior.proto",
+			},
+			expected: `import "google/protobuf/timestamp.proto";
+import "third_party/google/api/field_behavior.proto";
+`,
+		},
+		{
+			name: "prefix",
+			rewrites: map[string]string{
+				"google/*": "vendor/google/*",
+			},
+			expected: `import "vendor/google/api/field_behavior.proto";
+import "vendor/google/protobuf/timestamp.proto";
+`,
+		},
+		{
+			name: "longest prefix and exact path win",
+			rewrites: map[string]string{
+				"google/*":                        "vendor/google/*",
+				"google/api/*":                    "api/*",
+				"google/protobuf/timestamp.proto": "wkt/timestamp.proto",
+			},
+			expected: `import "api/field_behavior.proto";
+import "wkt/timestamp.proto";
+`,
+		},
+		{
+			name: "rewrites to the same path",
+			rewrites: map[string]string{
+				"google/*": "vendor/all.proto",
+			},
+			expected: `import "vendor/all.proto";
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:    "testpkg",
+				PackagePath:    "github.com/example/proto/v1",
+				ImportRewrites: test.rewrites,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), "package testpkg;\n\n"+test.expected+"\noption go_package")
+		})
+	}
+}
+
+func TestImportKinds(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Book:
+      type: object
+      properties:
+        name:
+          type: string
+          x-proto-field-behavior: [IDENTIFIER]
+        createdAt:
+          type: string
+          format: date-time
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:    "testpkg",
+		PackagePath:    "github.com/example/proto/v1",
+		ImportRewrites: map[string]string{"google/api/*": "third_party/google/api/*"},
+		ImportKinds: map[string]conv.ImportKind{
+			"third_party/google/api/field_behavior.proto": conv.ImportPublic,
+			"google/protobuf/timestamp.proto":             conv.ImportWeak,
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Protobuf), `import weak "google/protobuf/timestamp.proto";
+import public "third_party/google/api/field_behavior.proto";
+`)
+}
@@ -0,0 +1,50 @@
+package internal
+
+import "fmt"
+
+// CheckJSONNames reports the first message, nested ones included, with two fields sharing
+// a json_name, which protobuf JSON cannot tell apart. Parsers also accept the field name
+// of a field, so a field name equal to the json_name of another field makes documents
+// ambiguous too, e.g. property 'user-name' (field user_name) next to property
+// 'user_name' (field user_name_2). Those are returned as warnings, since the sanitized
+// names are the documented result for such properties.
+func CheckJSONNames(messages []*ProtoMessage) ([]string, error) {
+	var warnings []string
+	for _, msg := range messages {
+		if err := checkMessageJSONNames(msg.Name, msg, &warnings); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// checkMessageJSONNames checks msg, named path, and its nested messages
+func checkMessageJSONNames(path string, msg *ProtoMessage, warnings *[]string) error {
+	owners := make(map[string]*ProtoField, len(msg.Fields))
+	for _, field := range msg.Fields {
+		if field.JSONName == "" {
+			continue
+		}
+		if owner, ok := owners[field.JSONName]; ok {
+			return Errorf(CodeDuplicateJSONName,
+				"message '%s': fields '%s' (property '%s') and '%s' (property '%s') both have the json_name '%s'",
+				path, owner.Name, owner.JSONName, field.Name, field.JSONName, field.JSONName)
+		}
+		owners[field.JSONName] = field
+	}
+
+	for _, field := range msg.Fields {
+		if owner, ok := owners[field.Name]; ok && owner != field {
+			*warnings = append(*warnings, fmt.Sprintf(
+				"message '%s': field '%s' (property '%s') is named like the json_name of field '%s' (property '%s'), so protobuf JSON parsers accept '%s' for both",
+				path, field.Name, field.JSONName, owner.Name, owner.JSONName, field.Name))
+		}
+	}
+
+	for _, nested := range msg.Nested {
+		if err := checkMessageJSONNames(path+"."+nested.Name, nested, warnings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
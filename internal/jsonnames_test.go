@@ -0,0 +1,87 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/duh-rpc/openapi-proto.go/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertDuplicateJSONNames(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		properties string
+		warnings   []string
+	}{
+		{
+			name: "distinct names",
+			properties: `
+        user-name:
+          type: string
+        userName:
+          type: string`,
+		},
+		{
+			name: "sanitized name used as json name",
+			properties: `
+        user-name:
+          type: string
+        user_name:
+          type: string`,
+			warnings: []string{"message 'User': field 'user_name' (property 'user-name') is named like the json_name of field 'user_name_2' (property 'user_name'), so protobuf JSON parsers accept 'user_name' for both"},
+		},
+		{
+			name: "nested message",
+			properties: `
+        profile:
+          type: object
+          properties:
+            first.name:
+              type: string
+            first_name:
+              type: string`,
+			warnings: []string{"message 'User.Profile': field 'first_name' (property 'first.name') is named like the json_name of field 'first_name_2' (property 'first_name'), so protobuf JSON parsers accept 'first_name' for both"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:` + test.properties + "\n"
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.warnings, result.Warnings)
+		})
+	}
+}
+
+func TestCheckJSONNamesDuplicate(t *testing.T) {
+	messages := []*internal.ProtoMessage{{
+		Name: "User",
+		Nested: []*internal.ProtoMessage{{
+			Name: "Profile",
+			Fields: []*internal.ProtoField{
+				{Name: "name", Number: 1, JSONName: "name"},
+				{Name: "display_name", Number: 2, JSONName: "name"},
+			},
+		}},
+	}}
+
+	_, err := internal.CheckJSONNames(messages)
+	require.ErrorContains(t, err, "message 'User.Profile': fields 'name' (property 'name') and 'display_name' (property 'name') both have the json_name 'name'")
+	var codedErr *internal.CodedError
+	require.True(t, errors.As(err, &codedErr))
+	assert.Equal(t, internal.CodeDuplicateJSONName, codedErr.Code)
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"errors"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Finding is a problem that would stop a schema from converting
+type Finding struct {
+	Code     ErrorCode
+	Schema   string // Top-level schema name
+	Property string // Dotted path of the property below Schema, empty for the schema itself
+	Message  string // The error conversion would report
+	Fixes    []Fix  // Patch resolving the problem, empty if none is known
+
+	cause string // Message of the coded error, identifying the problem in conversion errors
+}
+
+// linter collects findings for the schemas of one document
+type linter struct {
+	schemas  map[string]bool // Names of all component schemas
+	added    map[string]bool // Component schemas added by fixes so far
+	findings []Finding
+}
+
+// Lint checks every schema, including inline objects and array items, for problems
+// conversion rejects. Unlike BuildMessages it keeps going after a problem, so all
+// schemas are reported at once. Referenced schemas are checked under their own name.
+func Lint(entries []*parser.SchemaEntry) []Finding {
+	l := &linter{
+		schemas: make(map[string]bool, len(entries)),
+		added:   make(map[string]bool),
+	}
+	for _, entry := range entries {
+		l.schemas[entry.Name] = true
+	}
+
+	for _, entry := range entries {
+		schema := entry.Proxy.Schema()
+		if schema == nil {
+			continue
+		}
+
+		if err := validateTopLevelSchema(schema, entry.Name); err != nil {
+			l.report(entry.Name, "", err, nil)
+			continue
+		}
+		if len(schema.OneOf) > 0 {
+			continue
+		}
+		if isEnumSchema(schema) {
+			if err := validateEnumSchema(schema, entry.Name); err != nil {
+				l.report(entry.Name, "", err, nil)
+			}
+			continue
+		}
+
+		l.lintObject(entry.Name, entry.Name, "", schemaPointer(entry.Name), schema)
+	}
+	return l.findings
+}
+
+// SuggestFixes returns the fixes for the problem reported by err, a conversion error
+// for entries, or nil if there are none
+func SuggestFixes(entries []*parser.SchemaEntry, err error) []Fix {
+	var coded *CodedError
+	if !errors.As(err, &coded) {
+		return nil
+	}
+
+	for _, finding := range Lint(entries) {
+		if finding.Code == coded.Code && finding.cause == coded.Err.Error() {
+			return finding.Fixes
+		}
+	}
+	return nil
+}
+
+// report records a finding for err under the top-level schema
+func (l *linter) report(schemaName, property string, err error, fixes []Fix) {
+	finding := Finding{
+		Code:     CodeInternal,
+		Schema:   schemaName,
+		Property: property,
+		Message:  err.Error(),
+		Fixes:    fixes,
+	}
+
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		finding.Code = coded.Code
+		finding.cause = coded.Err.Error()
+	}
+	l.findings = append(l.findings, finding)
+}
+
+// lintObject checks the field numbers and properties of an object schema. name is the
+// schema or property name used in conversion errors, path the dotted property path and
+// pointer the JSON pointer of schema.
+func (l *linter) lintObject(schemaName, name, path, pointer string, schema *base.Schema) {
+	var err error
+	if path == "" {
+		err = validateFieldNumbers(schema, schemaName)
+	} else if err = validateNestedFieldNumbers(schema); err != nil {
+		err = WrapPropertyError(schemaName, name, err)
+	}
+	if err != nil {
+		l.report(schemaName, path, err, fieldNumberFixes(schema, pointer, err))
+	}
+	if schema.Properties == nil {
+		return
+	}
+
+	for propName, propProxy := range schema.Properties.FromOldest() {
+		propPath := propName
+		if path != "" {
+			propPath = path + "." + propName
+		}
+		propPointer := pointer + "/properties/" + escapePointer(propName)
+
+		if _, err := SanitizeFieldName(propName); err != nil {
+			l.report(schemaName, propPath, WrapPropertyError(schemaName, propName, err),
+				renameFixes(schema, pointer, propName))
+		}
+
+		// Referenced schemas are linted on their own
+		if propProxy.IsReference() {
+			continue
+		}
+		propSchema := propProxy.Schema()
+		if propSchema == nil {
+			continue
+		}
+
+		if err := validateSchema(propSchema, propName); err != nil {
+			l.report(schemaName, propPath, WrapSchemaError(schemaName, err), nil)
+			continue
+		}
+
+		switch {
+		case contains(propSchema.Type, "object"):
+			if err := checkSingular(propName, "message", "property"); err != nil {
+				l.report(schemaName, propPath, WrapPropertyError(schemaName, propName, err),
+					l.extractFixes(schemaName, propName, propPointer, propProxy))
+				continue
+			}
+			l.lintObject(schemaName, propName, propPath, propPointer, propSchema)
+
+		case contains(propSchema.Type, "array") && propSchema.Items != nil && propSchema.Items.A != nil:
+			itemsProxy := propSchema.Items.A
+			itemsSchema := itemsProxy.Schema()
+			if itemsProxy.IsReference() || itemsSchema == nil {
+				continue
+			}
+			itemsPointer := propPointer + "/items"
+
+			switch {
+			case isIntegerEnum(itemsSchema):
+				if err := checkSingular(propName, "enum", "plural array property"); err != nil {
+					l.report(schemaName, propPath, WrapPropertyError(schemaName, propName, err),
+						l.extractFixes(schemaName, propName, itemsPointer, itemsProxy))
+				}
+			case contains(itemsSchema.Type, "object"):
+				if err := checkSingular(propName, "message", "plural array property"); err != nil {
+					l.report(schemaName, propPath, WrapPropertyError(schemaName, propName, err),
+						l.extractFixes(schemaName, propName, itemsPointer, itemsProxy))
+					continue
+				}
+				l.lintObject(schemaName, propName, propPath, itemsPointer, itemsSchema)
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
 )
 
 // ProtoType returns the proto3 type for an OpenAPI schema.
@@ -12,6 +13,11 @@ import (
 // For inline enums and objects, hoists them appropriately in the context.
 // parentMsg is used for nested messages (can be nil for top-level).
 func ProtoType(schema *base.Schema, propertyName string, propProxy *base.SchemaProxy, ctx *Context, parentMsg *ProtoMessage) (string, bool, []string, error) {
+	if schema != nil && len(schema.AnyOf) > 0 && ctx.AnyOf != "" {
+		typ, err := anyOfProtoType(schema, propertyName, ctx, parentMsg)
+		return typ, false, nil, err
+	}
+
 	// Validate schema for unsupported features
 	if err := validateSchema(schema, propertyName); err != nil {
 		return "", false, nil, err
@@ -22,27 +28,26 @@ func ProtoType(schema *base.Schema, propertyName string, propProxy *base.SchemaP
 		ref := propProxy.GetReference()
 
 		// Try to resolve the reference (libopenapi handles internal refs automatically)
-		resolvedSchema := propProxy.Schema()
+		resolvedSchema := ctx.schemaOf(propProxy)
 		if resolvedSchema == nil {
 			// Check if there's a build error (e.g., external reference)
 			if err := propProxy.GetBuildError(); err != nil {
-				return "", false, nil, fmt.Errorf("property '%s' references external file or unresolvable reference: %w", propertyName, err)
+				return "", false, nil, Errorf(CodeInvalidReference, "property '%s' references external file or unresolvable reference: %w", propertyName, err)
 			}
-			return "", false, nil, fmt.Errorf("property '%s' has unresolved reference", propertyName)
+			return "", false, nil, Errorf(CodeInvalidReference, "property '%s' has unresolved reference", propertyName)
 		}
 
 		// Check if referenced schema is a string enum
-		if isStringEnum(resolvedSchema) {
-			enumValues := extractEnumValues(resolvedSchema)
-			return "string", false, enumValues, nil
+		resolved := ctx.resolveRef(ref, resolvedSchema)
+		if resolved.stringEnum {
+			return "string", false, resolved.enumValues, nil
 		}
 
 		// Extract the schema name from the reference
-		typeName, err := extractReferenceName(ref)
-		if err != nil {
-			return "", false, nil, fmt.Errorf("property '%s': %w", propertyName, err)
+		if resolved.nameErr != nil {
+			return "", false, nil, fmt.Errorf("property '%s': %w", propertyName, resolved.nameErr)
 		}
-		return typeName, false, nil, nil
+		return resolved.typeName, false, nil, nil
 	}
 
 	// Check if it's an array first
@@ -54,6 +59,12 @@ func ProtoType(schema *base.Schema, propertyName string, propProxy *base.SchemaP
 		return itemType, true, enumValues, nil
 	}
 
+	// Objects with only additionalProperties become map fields holding the value type
+	if isMapSchema(schema) {
+		valueType, enumValues, err := mapValueType(schema, propertyName, ctx)
+		return valueType, false, enumValues, err
+	}
+
 	// Check if it's an inline object
 	if len(schema.Type) > 0 && contains(schema.Type, "object") {
 		// Build nested message
@@ -73,15 +84,16 @@ func ProtoType(schema *base.Schema, propertyName string, propProxy *base.SchemaP
 		}
 		// Integer enum - hoist to top-level
 		enumName := ToPascalCase(propertyName)
-		_, err := buildEnum(enumName, propProxy, ctx)
+		enum, err := buildEnum(enumName, propProxy, ctx)
 		if err != nil {
 			return "", false, nil, err
 		}
-		return enumName, false, nil, nil
+		ctx.InlineEnums[enum] = parentMsg
+		return enum.Name, false, nil, nil
 	}
 
 	if len(schema.Type) == 0 {
-		return "", false, nil, fmt.Errorf("property must have type or $ref")
+		return "", false, nil, Errorf(CodeUnsupportedType, "property must have type or $ref")
 	}
 
 	var typ string
@@ -94,25 +106,94 @@ func ProtoType(schema *base.Schema, propertyName string, propProxy *base.SchemaP
 		}
 
 		if len(nonNullTypes) != 1 {
-			return "", false, nil, fmt.Errorf("multi-type properties not supported (only nullable variants allowed)")
+			return "", false, nil, Errorf(CodeUnsupportedType, "multi-type properties not supported (only nullable variants allowed)")
 		}
 
 		typ = nonNullTypes[0]
 	} else {
 		typ = schema.Type[0]
 	}
-	format := schema.Format
+	protoType, err := scalarType(ctx, schema, typ)
+	return protoType, false, nil, err
+}
 
-	scalarType, err := MapScalarType(ctx, typ, format)
-	return scalarType, false, nil, err
+// scalarOverrides maps the proto3 scalars x-proto-type may name to the OpenAPI type
+// whose values they hold
+var scalarOverrides = map[string]string{
+	"int32":    "integer",
+	"int64":    "integer",
+	"uint32":   "integer",
+	"uint64":   "integer",
+	"sint32":   "integer",
+	"sint64":   "integer",
+	"fixed32":  "integer",
+	"fixed64":  "integer",
+	"sfixed32": "integer",
+	"sfixed64": "integer",
+	"float":    "number",
+	"double":   "number",
+	"bool":     "boolean",
+	"string":   "string",
+	"bytes":    "string",
+}
+
+// scalarType returns the proto3 scalar type for a schema of OpenAPI type typ: the type
+// named by its x-proto-type extension, or the one MapScalarType derives from typ and
+// the schema's format. The override must hold values of typ.
+func scalarType(ctx *Context, schema *base.Schema, typ string) (string, error) {
+	if schema.Extensions != nil {
+		if node, found := schema.Extensions.Get("x-proto-type"); found && node != nil {
+			declared, ok := scalarOverrides[node.Value]
+			if !ok || node.Kind != yaml.ScalarNode {
+				return "", Errorf(CodeInvalidExtension, "x-proto-type must be a proto3 scalar type, got: %s", node.Value)
+			}
+			if declared != typ {
+				return "", Errorf(CodeInvalidExtension, "x-proto-type %s cannot hold values of type %s", node.Value, typ)
+			}
+			return node.Value, nil
+		}
+	}
+	if typ == "integer" {
+		unsigned, err := unsignedInteger(ctx, schema)
+		if err != nil {
+			return "", err
+		}
+		if unsigned {
+			if schema.Format == "int64" || schema.Format == "uint64" {
+				return "uint64", nil
+			}
+			return "uint32", nil
+		}
+	} else if schema.Extensions != nil && schema.Extensions.GetOrZero("x-proto-unsigned") != nil {
+		return "", Errorf(CodeInvalidExtension, "x-proto-unsigned can only be set on integers, got type: %s", typ)
+	}
+	return MapScalarType(ctx, typ, schema.Format)
+}
+
+// unsignedInteger reports whether an integer schema maps to an unsigned type: when its
+// x-proto-unsigned extension is true, or with Context.UnsignedFromMinimum set, when it
+// declares a minimum of 0 or more and x-proto-unsigned is not false
+func unsignedInteger(ctx *Context, schema *base.Schema) (bool, error) {
+	if schema.Extensions != nil {
+		if node, found := schema.Extensions.Get("x-proto-unsigned"); found && node != nil {
+			if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+				return false, Errorf(CodeInvalidExtension, "x-proto-unsigned must be a boolean, got: %s", node.Value)
+			}
+			return node.Value == "true", nil
+		}
+	}
+	return ctx.UnsignedFromMinimum && schema.Minimum != nil && *schema.Minimum >= 0, nil
 }
 
 // MapScalarType maps OpenAPI type+format to proto3 scalar type.
 func MapScalarType(ctx *Context, typ, format string) (string, error) {
 	switch typ {
 	case "integer":
-		if format == "int64" {
+		switch format {
+		case "int64":
 			return "int64", nil
+		case "uint32", "uint64":
+			return format, nil
 		}
 		return "int32", nil
 
@@ -123,9 +204,19 @@ func MapScalarType(ctx *Context, typ, format string) (string, error) {
 		return "double", nil
 
 	case "string":
-		if format == "date" || format == "date-time" {
+		switch format {
+		case "date-time":
 			ctx.UsesTimestamp = true
 			return "google.protobuf.Timestamp", nil
+		case "date":
+			ctx.AddImport(dateImport)
+			return dateType, nil
+		case "time":
+			ctx.AddImport(timeOfDayImport)
+			return timeOfDayType, nil
+		case "duration":
+			ctx.AddImport(durationImport)
+			return durationType, nil
 		}
 		if format == "byte" || format == "binary" {
 			return "bytes", nil
@@ -136,7 +227,7 @@ func MapScalarType(ctx *Context, typ, format string) (string, error) {
 		return "bool", nil
 
 	default:
-		return "", fmt.Errorf("unsupported type: %s", typ)
+		return "", Errorf(CodeUnsupportedType, "unsupported type: %s", typ)
 	}
 }
 
@@ -146,39 +237,36 @@ func MapScalarType(ctx *Context, typ, format string) (string, error) {
 func ResolveArrayItemType(schema *base.Schema, propertyName string, propProxy *base.SchemaProxy, ctx *Context, parentMsg *ProtoMessage) (string, []string, error) {
 	// Check if Items is defined
 	if schema.Items == nil || schema.Items.A == nil {
-		return "", nil, fmt.Errorf("array must have items defined")
+		return "", nil, Errorf(CodeUnsupportedType, "array must have items defined")
 	}
 
 	itemsProxy := schema.Items.A
-	itemsSchema := itemsProxy.Schema()
+	itemsSchema := ctx.schemaOf(itemsProxy)
 	if itemsSchema == nil {
 		if err := itemsProxy.GetBuildError(); err != nil {
-			return "", nil, fmt.Errorf("failed to resolve array items: %w", err)
+			return "", nil, Errorf(CodeInvalidReference, "failed to resolve array items: %w", err)
 		}
 		return "", nil, fmt.Errorf("array items schema is nil")
 	}
 
 	// Check for nested arrays
 	if len(itemsSchema.Type) > 0 && contains(itemsSchema.Type, "array") {
-		return "", nil, fmt.Errorf("nested arrays not supported")
+		return "", nil, Errorf(CodeUnsupportedType, "nested arrays not supported")
 	}
 
 	// Check if it's a reference
 	if itemsProxy.IsReference() {
 		ref := itemsProxy.GetReference()
-		resolvedSchema := itemsProxy.Schema()
-		if resolvedSchema != nil && isStringEnum(resolvedSchema) {
-			enumValues := extractEnumValues(resolvedSchema)
-			return "string", enumValues, nil
-		}
 		if ref != "" {
-			// Extract the last segment of the reference path
-			parts := strings.Split(ref, "/")
-			if len(parts) > 0 {
-				return parts[len(parts)-1], nil, nil
+			// itemsSchema is the resolved target, checked for nil above
+			resolved := ctx.resolveRef(ref, itemsSchema)
+			if resolved.stringEnum {
+				return "string", resolved.enumValues, nil
 			}
+			// Extract the last segment of the reference path
+			return resolved.typeName, nil, nil
 		}
-		return "", nil, fmt.Errorf("invalid reference format")
+		return "", nil, Errorf(CodeInvalidReference, "invalid reference format")
 	}
 
 	// Check if it's an inline enum
@@ -189,30 +277,25 @@ func ResolveArrayItemType(schema *base.Schema, propertyName string, propProxy *b
 			return "string", enumValues, nil
 		}
 		// Integer enum - validate property name is not plural
-		if strings.HasSuffix(propertyName, "es") {
-			return "", nil, fmt.Errorf("cannot derive enum name from plural array property '%s'; use singular form or $ref", propertyName)
-		}
-		if strings.HasSuffix(propertyName, "s") {
-			return "", nil, fmt.Errorf("cannot derive enum name from plural array property '%s'; use singular form or $ref", propertyName)
+		if err := checkSingular(propertyName, "enum", "plural array property"); err != nil {
+			return "", nil, err
 		}
 
 		// Hoist inline integer enum to top-level
 		enumName := ToPascalCase(propertyName)
-		_, err := buildEnum(enumName, itemsProxy, ctx)
+		enum, err := buildEnum(enumName, itemsProxy, ctx)
 		if err != nil {
 			return "", nil, err
 		}
-		return enumName, nil, nil
+		ctx.InlineEnums[enum] = parentMsg
+		return enum.Name, nil, nil
 	}
 
 	// Check if it's an inline object
 	if len(itemsSchema.Type) > 0 && contains(itemsSchema.Type, "object") {
 		// Validate property name is not plural
-		if strings.HasSuffix(propertyName, "es") {
-			return "", nil, fmt.Errorf("cannot derive message name from plural array property '%s'; use singular form or $ref", propertyName)
-		}
-		if strings.HasSuffix(propertyName, "s") {
-			return "", nil, fmt.Errorf("cannot derive message name from plural array property '%s'; use singular form or $ref", propertyName)
+		if err := checkSingular(propertyName, "message", "plural array property"); err != nil {
+			return "", nil, err
 		}
 
 		// Build nested message for inline object in array
@@ -225,31 +308,29 @@ func ResolveArrayItemType(schema *base.Schema, propertyName string, propProxy *b
 
 	// It's a scalar type
 	if len(itemsSchema.Type) == 0 {
-		return "", nil, fmt.Errorf("array items must have a type")
+		return "", nil, Errorf(CodeUnsupportedType, "array items must have a type")
 	}
 
-	itemType := itemsSchema.Type[0]
-	format := itemsSchema.Format
-	scalarType, err := MapScalarType(ctx, itemType, format)
-	return scalarType, nil, err
+	protoType, err := scalarType(ctx, itemsSchema, itemsSchema.Type[0])
+	return protoType, nil, err
 }
 
 // extractReferenceName extracts the schema name from a reference string.
 // Example: "#/components/schemas/Address" → "Address"
 func extractReferenceName(ref string) (string, error) {
 	if ref == "" {
-		return "", fmt.Errorf("reference string is empty")
+		return "", Errorf(CodeInvalidReference, "reference string is empty")
 	}
 
 	// Split by '/' and validate standard format: "#/components/schemas/Name"
 	parts := strings.Split(ref, "/")
 	if len(parts) < 4 || parts[0] != "#" || parts[1] != "components" || parts[2] != "schemas" {
-		return "", fmt.Errorf("invalid reference format: %s (expected #/components/schemas/Name)", ref)
+		return "", Errorf(CodeInvalidReference, "invalid reference format: %s (expected #/components/schemas/Name)", ref)
 	}
 
 	name := parts[len(parts)-1]
 	if name == "" {
-		return "", fmt.Errorf("reference has empty name segment: %s", ref)
+		return "", Errorf(CodeInvalidReference, "reference has empty name segment: %s", ref)
 	}
 
 	return name, nil
@@ -263,23 +344,23 @@ func validateSchema(schema *base.Schema, propertyName string) error {
 
 	// Check for schema composition features
 	if len(schema.AllOf) > 0 {
-		return fmt.Errorf("property '%s' uses 'allOf' which is not supported", propertyName)
+		return Errorf(CodeUnsupportedAllOf, "property '%s' uses 'allOf' which is not supported", propertyName)
 	}
 
 	if len(schema.AnyOf) > 0 {
-		return fmt.Errorf("property '%s' uses 'anyOf' which is not supported", propertyName)
+		return Errorf(CodeUnsupportedAnyOf, "property '%s' uses 'anyOf' which is not supported", propertyName)
 	}
 
 	if len(schema.OneOf) > 0 {
 		// Require discriminator
 		if schema.Discriminator == nil || schema.Discriminator.PropertyName == "" {
-			return fmt.Errorf("oneOf in property '%s' requires discriminator", propertyName)
+			return Errorf(CodeInvalidOneOf, "oneOf in property '%s' requires discriminator", propertyName)
 		}
 
 		// Require all variants to be $ref (no inline schemas)
 		for i, variant := range schema.OneOf {
 			if !variant.IsReference() {
-				return fmt.Errorf("oneOf variant %d in property '%s' must use $ref, inline schemas not supported", i, propertyName)
+				return Errorf(CodeInvalidOneOf, "oneOf variant %d in property '%s' must use $ref, inline schemas not supported", i, propertyName)
 			}
 		}
 
@@ -288,7 +369,7 @@ func validateSchema(schema *base.Schema, propertyName string) error {
 	}
 
 	if schema.Not != nil {
-		return fmt.Errorf("property '%s' uses 'not' which is not supported", propertyName)
+		return Errorf(CodeUnsupportedNot, "property '%s' uses 'not' which is not supported", propertyName)
 	}
 
 	return nil
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// mapKeyType is the key type of map fields; JSON object keys are always strings
+const mapKeyType = "string"
+
+// isMapSchema reports whether schema is an object holding arbitrary keys, declared with
+// an additionalProperties schema and no properties, which maps to a proto map field
+func isMapSchema(schema *base.Schema) bool {
+	if schema == nil || !contains(schema.Type, "object") || orderedmap.Len(schema.Properties) > 0 {
+		return false
+	}
+	return schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() && schema.AdditionalProperties.A != nil
+}
+
+// mapKey returns the key type of the field for a property, empty unless it is a map
+func mapKey(schema *base.Schema) string {
+	if isMapSchema(schema) {
+		return mapKeyType
+	}
+	return ""
+}
+
+// mapValueType returns the proto type and string enum values of the additionalProperties
+// schema of a map. Values may be scalars, string enums or $refs; proto maps cannot hold
+// repeated values and inline objects have no name to declare a message under.
+func mapValueType(schema *base.Schema, propertyName string, ctx *Context) (string, []string, error) {
+	proxy := schema.AdditionalProperties.A
+	value := ctx.schemaOf(proxy)
+	if value == nil {
+		if err := proxy.GetBuildError(); err != nil {
+			return "", nil, Errorf(CodeInvalidReference, "property '%s' has unresolvable additionalProperties: %w", propertyName, err)
+		}
+		return "", nil, Errorf(CodeInvalidReference, "property '%s' has unresolved additionalProperties", propertyName)
+	}
+
+	if proxy.IsReference() {
+		resolved := ctx.resolveRef(proxy.GetReference(), value)
+		if resolved.stringEnum {
+			return "string", resolved.enumValues, nil
+		}
+		if resolved.nameErr != nil {
+			return "", nil, resolved.nameErr
+		}
+		return resolved.typeName, nil, nil
+	}
+
+	if isStringEnum(value) {
+		return "string", extractEnumValues(value), nil
+	}
+	typ := nonNullType(value.Type)
+	switch typ {
+	case "object", "array", "":
+		return "", nil, Errorf(CodeUnsupportedType,
+			"property '%s': map values must be scalars or $ref, not inline objects or arrays", propertyName)
+	}
+	protoType, err := scalarType(ctx, value, typ)
+	return protoType, nil, err
+}
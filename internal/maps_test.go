@@ -0,0 +1,176 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestConvertMapFields(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		property string
+		field    string
+		err      string
+	}{
+		{
+			name: "scalar values",
+			property: `
+          type: object
+          additionalProperties:
+            type: string`,
+			field: `map<string, string> label = 1 [json_name = "label"];`,
+		},
+		{
+			name: "message values",
+			property: `
+          type: object
+          additionalProperties:
+            $ref: '#/components/schemas/Address'`,
+			field: `map<string, Address> label = 1 [json_name = "label"];`,
+		},
+		{
+			name: "string enum values",
+			property: `
+          type: object
+          additionalProperties:
+            type: string
+            enum: [red, green]`,
+			field: "// enum: [red, green]\n  map<string, string> label = 1",
+		},
+		{
+			name: "timestamp values",
+			property: `
+          type: object
+          additionalProperties:
+            type: string
+            format: date-time`,
+			field: `map<string, google.protobuf.Timestamp> label = 1 [json_name = "label"];`,
+		},
+		{
+			name: "integer values with description",
+			property: `
+          type: object
+          description: Counts by name
+          additionalProperties:
+            type: integer
+            format: int64`,
+			field: "// Counts by name\n  map<string, int64> label = 1",
+		},
+		{
+			name: "inline object values",
+			property: `
+          type: object
+          additionalProperties:
+            type: object
+            properties:
+              name:
+                type: string`,
+			err: "property 'label': map values must be scalars or $ref, not inline objects or arrays",
+		},
+		{
+			name: "array values",
+			property: `
+          type: object
+          additionalProperties:
+            type: array
+            items:
+              type: string`,
+			err: "property 'label': map values must be scalars or $ref, not inline objects or arrays",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        label:` + test.property + `
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+`
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				var convErr *conv.Error
+				require.True(t, errors.As(err, &convErr))
+				assert.Equal(t, conv.ErrorCodeUnsupportedType, convErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.field)
+
+			// The descriptor declares the map entry message protoc would
+			files, err := result.Files()
+			require.NoError(t, err)
+			desc, err := files.FindDescriptorByName("testpkg.User")
+			require.NoError(t, err)
+			field := desc.(protoreflect.MessageDescriptor).Fields().ByName("label")
+			require.NotNil(t, field)
+			assert.True(t, field.IsMap())
+		})
+	}
+}
+
+func TestConvertMapFieldsGo(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+        label:
+          type: object
+          additionalProperties:
+            type: string
+        friend:
+          type: object
+          additionalProperties:
+            $ref: '#/components/schemas/Cat'
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:  "testpkg",
+		PackagePath:  "github.com/example/proto/v1",
+		GoCloneEqual: true,
+	})
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"Label map[string]string `json:\"label\"`",
+		"Friend map[string]*Cat `json:\"friend\"`",
+		"c.Label = maps.Clone(x.Label)",
+		"c.Friend = cloneMap(x.Friend, func(v *Cat) *Cat { return v.Clone() })",
+		"maps.EqualFunc(x.Friend, y.Friend, func(v, w *Cat) bool { return v.Equal(w) })",
+	} {
+		assert.Contains(t, string(result.Golang), expected)
+	}
+}
@@ -2,10 +2,63 @@ package internal
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
-	"unicode"
+	"sync"
 )
 
+// Case conversion only maps ASCII letters, so generated names never depend on Unicode
+// case tables, whose special cases differ between languages and tools. The non-ASCII
+// letters whose Unicode case mapping lands on an ASCII letter ('İ', 'ı', 'ſ' and the
+// Kelvin sign) are folded to that letter first; every other non-ASCII letter is copied
+// unchanged and neither starts nor ends a word.
+var asciiFolds = map[rune]rune{
+	'\u0130': 'I', // İ, Latin capital I with dot above
+	'\u0131': 'i', // ı, Latin small dotless i
+	'\u017F': 's', // ſ, Latin small long s
+	'\u212A': 'K', // K, Kelvin sign
+}
+
+// foldASCII maps the non-ASCII letters in asciiFolds to their ASCII letter
+func foldASCII(r rune) rune {
+	if folded, ok := asciiFolds[r]; ok {
+		return folded
+	}
+	return r
+}
+
+func isUpperASCII(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+func isLowerASCII(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
+
+func toUpperASCII(r rune) rune {
+	if isLowerASCII(r) {
+		return r - 'a' + 'A'
+	}
+	return r
+}
+
+func toLowerASCII(r rune) rune {
+	if isUpperASCII(r) {
+		return r - 'A' + 'a'
+	}
+	return r
+}
+
+// upperASCII upper cases the ASCII letters of s after folding
+func upperASCII(s string) string {
+	var result strings.Builder
+	result.Grow(len(s))
+	for _, r := range s {
+		result.WriteRune(toUpperASCII(foldASCII(r)))
+	}
+	return result.String()
+}
+
 // ToSnakeCase converts camelCase/PascalCase to snake_case.
 // Algorithm: Each uppercase letter becomes lowercase with underscore prefix (except first char).
 // Examples: userId → user_id, HTTPStatus → h_t_t_p_status, email → email
@@ -18,11 +71,12 @@ func ToSnakeCase(s string) string {
 	result.Grow(len(s) + 5)
 
 	for i, r := range s {
-		if unicode.IsUpper(r) {
+		r = foldASCII(r)
+		if isUpperASCII(r) {
 			if i > 0 {
 				result.WriteRune('_')
 			}
-			result.WriteRune(unicode.ToLower(r))
+			result.WriteRune(toLowerASCII(r))
 		} else {
 			result.WriteRune(r)
 		}
@@ -46,7 +100,7 @@ func ToPascalCase(s string) string {
 			hasUnderscore = true
 			continue
 		}
-		if unicode.IsLower(r) {
+		if isLowerASCII(foldASCII(r)) {
 			isAllCaps = false
 			break
 		}
@@ -61,15 +115,16 @@ func ToPascalCase(s string) string {
 			capitalizeNext = true
 			continue
 		}
+		r = foldASCII(r)
 
 		if capitalizeNext {
-			result.WriteRune(unicode.ToUpper(r))
+			result.WriteRune(toUpperASCII(r))
 			capitalizeNext = false
 		} else {
 			// Only lowercase if the entire string was all caps (like "USER")
 			// For camelCase (like "OrderStatus"), preserve the original casing
 			if isAllCaps && !hasUnderscore {
-				result.WriteRune(unicode.ToLower(r))
+				result.WriteRune(toLowerASCII(r))
 			} else {
 				result.WriteRune(r)
 			}
@@ -82,12 +137,18 @@ func ToPascalCase(s string) string {
 // ToEnumValueName converts a value to ENUM_PREFIX_VALUE_NAME format.
 // Examples: (Status, active) → STATUS_ACTIVE, (Status, in-progress) → STATUS_IN_PROGRESS, (SortBy, createdAt) → SORT_BY_CREATED_AT
 func ToEnumValueName(enumName, value string) string {
-	upperEnum := strings.ToUpper(ToSnakeCase(enumName))
-	upperValue := strings.ToUpper(ToSnakeCase(value))
+	upperEnum := upperASCII(ToSnakeCase(enumName))
+	upperValue := upperASCII(ToSnakeCase(value))
 	upperValue = strings.ReplaceAll(upperValue, "-", "_")
 	return fmt.Sprintf("%s_%s", upperEnum, upperValue)
 }
 
+// ToUnspecifiedValueName returns the name of the zero value every enum starts with.
+// Example: OrderStatus → ORDER_STATUS_UNSPECIFIED
+func ToUnspecifiedValueName(enumName string) string {
+	return upperASCII(ToSnakeCase(enumName)) + "_UNSPECIFIED"
+}
+
 // SanitizeFieldName sanitizes an OpenAPI field name for proto3 syntax.
 // Preserves the original name structure when valid, only modifying to meet
 // proto3 requirements:
@@ -98,19 +159,19 @@ func ToEnumValueName(enumName, value string) string {
 // Returns error if name cannot be sanitized (e.g., starts with digit).
 func SanitizeFieldName(name string) (string, error) {
 	if name == "" {
-		return "", fmt.Errorf("field name cannot be empty")
+		return "", Errorf(CodeInvalidFieldName, "field name cannot be empty")
 	}
 
 	// Check first character must be ASCII letter
 	firstChar := rune(name[0])
 	if (firstChar < 'a' || firstChar > 'z') && (firstChar < 'A' || firstChar > 'Z') {
 		if firstChar >= '0' && firstChar <= '9' {
-			return "", fmt.Errorf("field name must start with a letter, got '%s'", name)
+			return "", Errorf(CodeInvalidFieldName, "field name must start with a letter, got '%s'", name)
 		}
 		if firstChar == '_' {
-			return "", fmt.Errorf("field name cannot start with underscore, got '%s'", name)
+			return "", Errorf(CodeInvalidFieldName, "field name cannot start with underscore, got '%s'", name)
 		}
-		return "", fmt.Errorf("field name must start with a letter, got '%s'", name)
+		return "", Errorf(CodeInvalidFieldName, "field name must start with a letter, got '%s'", name)
 	}
 
 	var result strings.Builder
@@ -144,7 +205,7 @@ func SanitizeFieldName(name string) (string, error) {
 
 	sanitized := result.String()
 	if sanitized == "" {
-		return "", fmt.Errorf("field name contains no valid characters")
+		return "", Errorf(CodeInvalidFieldName, "field name contains no valid characters")
 	}
 
 	return sanitized, nil
@@ -160,6 +221,12 @@ type NameTracker struct {
 	used map[string]int
 }
 
+// nameTrackerPool reuses the per-message field trackers, which are short-lived and
+// otherwise allocate a map for every message in large specs
+var nameTrackerPool = sync.Pool{
+	New: func() any { return NewNameTracker() },
+}
+
 // NewNameTracker creates a new NameTracker.
 func NewNameTracker() *NameTracker {
 	return &NameTracker{
@@ -167,6 +234,18 @@ func NewNameTracker() *NameTracker {
 	}
 }
 
+// acquireNameTracker returns an empty NameTracker from the pool. Return it with
+// releaseNameTracker once the names it produced are no longer being checked.
+func acquireNameTracker() *NameTracker {
+	return nameTrackerPool.Get().(*NameTracker)
+}
+
+// releaseNameTracker clears a NameTracker and returns it to the pool
+func releaseNameTracker(nt *NameTracker) {
+	clear(nt.used)
+	nameTrackerPool.Put(nt)
+}
+
 // UniqueName returns a unique name, adding numeric suffix if needed (_2, _3, etc.).
 func (nt *NameTracker) UniqueName(name string) string {
 	count, exists := nt.used[name]
@@ -177,5 +256,15 @@ func (nt *NameTracker) UniqueName(name string) string {
 
 	count++
 	nt.used[name] = count
-	return fmt.Sprintf("%s_%d", name, count)
+	return name + "_" + strconv.Itoa(count)
+}
+
+// checkSingular fails if propertyName looks plural, since the name of a generated
+// message or enum is derived from it. Any trailing "s" counts as plural; there is no
+// real singularization.
+func checkSingular(propertyName, kind, describe string) error {
+	if strings.HasSuffix(propertyName, "s") {
+		return Errorf(CodePluralInlineName, "cannot derive %s name from %s '%s'; use singular form or $ref", kind, describe, propertyName)
+	}
+	return nil
 }
@@ -632,3 +632,47 @@ message Test {
 		})
 	}
 }
+
+func TestConvertLocaleStableNames(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		values   string
+		expected string
+	}{
+		{
+			name:     "dotted capital I folds to ASCII",
+			values:   "[İzmir]",
+			expected: "CITY_IZMIR = 1;",
+		},
+		{
+			name:     "dotless small i folds to ASCII",
+			values:   "[ıstanbul]",
+			expected: "CITY_ISTANBUL = 1;",
+		},
+		{
+			name:     "other non-ASCII letters are unchanged",
+			values:   "[straße]",
+			expected: "CITY_STRAßE = 1;",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    City:
+      type: integer
+      enum: ` + test.values + `
+`
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package internal
+
+// NestInlineEnums moves enums built from inline properties into the message that
+// declares the property (User.Status instead of a file-scope Status). inline maps each
+// inline enum to its declaring message, as recorded in Context.InlineEnums while building.
+func NestInlineEnums(ctx *Context, inline map[*ProtoEnum]*ProtoMessage) {
+	if len(inline) == 0 {
+		return
+	}
+
+	enums := make([]*ProtoEnum, 0, len(ctx.Enums))
+	for _, enum := range ctx.Enums {
+		parent, ok := inline[enum]
+		if !ok {
+			enums = append(enums, enum)
+			continue
+		}
+		parent.Enums = append(parent.Enums, enum)
+	}
+	ctx.Enums = enums
+
+	definitions := make([]interface{}, 0, len(ctx.Definitions))
+	for _, def := range ctx.Definitions {
+		if enum, ok := def.(*ProtoEnum); ok && inline[enum] != nil {
+			continue
+		}
+		definitions = append(definitions, def)
+	}
+	ctx.Definitions = definitions
+}
@@ -0,0 +1,86 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNestInlineEnums(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Priority:
+      type: integer
+      enum: [1, 2]
+    User:
+      type: object
+      properties:
+        status:
+          type: integer
+          description: Account status
+          enum: [1, 2]
+        priority:
+          $ref: '#/components/schemas/Priority'
+        location:
+          type: object
+          properties:
+            kind:
+              type: integer
+              enum: [10, 20]
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		InlineEnums: conv.InlineEnumsNested,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+enum Priority {
+  PRIORITY_UNSPECIFIED = 0;
+  PRIORITY_1 = 1;
+  PRIORITY_2 = 2;
+}
+
+message User {
+  // Account status
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_1 = 1;
+    STATUS_2 = 2;
+  }
+
+  message Location {
+    enum Kind {
+      KIND_UNSPECIFIED = 0;
+      KIND_10 = 1;
+      KIND_20 = 2;
+    }
+
+    Kind kind = 1 [json_name = "kind"];
+  }
+
+  Status status = 1 [json_name = "status"];
+  Priority priority = 2 [json_name = "priority"];
+  Location location = 3 [json_name = "location"];
+}
+
+`, string(result.Protobuf))
+
+	// The descriptors place the enums in the same scopes
+	msg, err := result.NewMessage("User.Location")
+	require.NoError(t, err)
+	assert.Equal(t, "testpkg.User.Location.Kind", string(msg.Descriptor().Fields().ByName("kind").Enum().FullName()))
+}
@@ -1,11 +1,13 @@
 package internal_test
 
 import (
+	"strings"
 	"testing"
 
 	conv "github.com/duh-rpc/openapi-proto.go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 func TestNullableTypeHandling(t *testing.T) {
@@ -458,3 +460,185 @@ components:
 		})
 	}
 }
+
+func TestNullableFieldsOptional(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		version  string
+		property string
+		expected string
+	}{
+		{
+			name:    "3.1 null type",
+			version: "3.1.0",
+			property: `
+          type: [string, "null"]`,
+			expected: `optional string value = 1 [json_name = "value"];`,
+		},
+		{
+			name:    "3.0 nullable",
+			version: "3.0.3",
+			property: `
+          type: integer
+          format: int64
+          nullable: true`,
+			expected: `optional int64 value = 1 [json_name = "value"];`,
+		},
+		{
+			name:    "nullable inline enum",
+			version: "3.0.3",
+			property: `
+          type: integer
+          nullable: true
+          enum: [1, 2]`,
+			expected: `optional Value value = 1 [json_name = "value"];`,
+		},
+		{
+			name:    "not nullable",
+			version: "3.1.0",
+			property: `
+          type: string`,
+			expected: `  string value = 1 [json_name = "value"];`,
+		},
+		{
+			name:    "nullable array",
+			version: "3.1.0",
+			property: `
+          type: [array, "null"]
+          items:
+            type: string`,
+			expected: `  repeated string value = 1 [json_name = "value"];`,
+		},
+		{
+			name:    "nullable object",
+			version: "3.1.0",
+			property: `
+          type: [object, "null"]
+          properties:
+            name:
+              type: string`,
+			expected: `  Value value = 1 [json_name = "value"];`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: ` + test.version + `
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Setting:
+      type: object
+      properties:
+        value:` + test.property + "\n"
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:    "testpkg",
+				PackagePath:    "github.com/example/proto/v1",
+				NullableFields: conv.NullableFieldsOptional,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+
+			// The descriptor declares the synthetic oneof protoc adds for optional fields
+			files, err := result.Files()
+			require.NoError(t, err)
+			desc, err := files.FindDescriptorByName("testpkg.Setting")
+			require.NoError(t, err)
+			field := desc.(protoreflect.MessageDescriptor).Fields().ByName("value")
+			assert.Equal(t, strings.HasPrefix(test.expected, "optional"), field.HasOptionalKeyword())
+		})
+	}
+}
+
+func TestNullableFieldsWrappers(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		property string
+		expected string
+		fixture  string
+		wrapped  bool
+	}{
+		{
+			name: "string",
+			property: `
+          type: [string, "null"]
+          example: dark`,
+			expected: `google.protobuf.StringValue value = 1 [json_name = "value"];`,
+			fixture:  "value {\n  value: \"dark\"\n}\n",
+			wrapped:  true,
+		},
+		{
+			name: "int64",
+			property: `
+          type: [integer, "null"]
+          format: int64`,
+			expected: `google.protobuf.Int64Value value = 1 [json_name = "value"];`,
+			fixture:  "value {\n  value: 1\n}\n",
+			wrapped:  true,
+		},
+		{
+			name: "boolean",
+			property: `
+          type: [boolean, "null"]`,
+			expected: `google.protobuf.BoolValue value = 1 [json_name = "value"];`,
+			fixture:  "value {\n  value: true\n}\n",
+			wrapped:  true,
+		},
+		{
+			name: "not nullable",
+			property: `
+          type: string`,
+			expected: `  string value = 1 [json_name = "value"];`,
+			fixture:  "value: \"value\"\n",
+		},
+		{
+			name: "nullable enum",
+			property: `
+          type: [integer, "null"]
+          enum: [1, 2]`,
+			expected: `  Value value = 1 [json_name = "value"];`,
+			fixture:  "value: VALUE_1\n",
+		},
+		{
+			name: "nullable array",
+			property: `
+          type: [array, "null"]
+          items:
+            type: string`,
+			expected: `  repeated string value = 1 [json_name = "value"];`,
+			fixture:  "value: \"value\"\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.1.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Setting:
+      type: object
+      properties:
+        value:` + test.property + "\n"
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:    "testpkg",
+				PackagePath:    "github.com/example/proto/v1",
+				NullableFields: conv.NullableFieldsWrappers,
+				EmitFixtures:   true,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+			assert.Equal(t, test.wrapped, strings.Contains(string(result.Protobuf), `import "google/protobuf/wrappers.proto";`))
+			assert.Equal(t, test.fixture, string(result.Fixtures["Setting"]))
+
+			files, err := result.Files()
+			require.NoError(t, err)
+			desc, err := files.FindDescriptorByName("testpkg.Setting")
+			require.NoError(t, err)
+			field := desc.(protoreflect.MessageDescriptor).Fields().ByName("value")
+			assert.Equal(t, test.wrapped, field.Kind() == protoreflect.MessageKind)
+		})
+	}
+}
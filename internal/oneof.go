@@ -0,0 +1,42 @@
+package internal
+
+import "github.com/pb33f/libopenapi/datamodel/high/base"
+
+// buildOneofMessage creates a message for a oneOf schema holding a oneof block with one
+// field per $ref variant, numbered in variant order. Used instead of Go code when
+// Context.ProtoOneof is set.
+func buildOneofMessage(name string, schema *base.Schema, ctx *Context, graph *DependencyGraph) (*ProtoMessage, error) {
+	if err := ctx.countMessage(); err != nil {
+		return nil, WrapSchemaError(name, err)
+	}
+
+	msg := &ProtoMessage{
+		Name:           ctx.reserveName(name),
+		Description:    schema.Description,
+		Fields:         []*ProtoField{},
+		Nested:         []*ProtoMessage{},
+		OriginalSchema: name,
+		Oneof:          ToSnakeCase(name),
+	}
+
+	fieldTracker := acquireNameTracker()
+	defer releaseNameTracker(fieldTracker)
+
+	// validateTopLevelSchema has checked every variant is a $ref
+	for i, variant := range schema.OneOf {
+		variantName := ctx.refName(variant.GetReference())
+		graph.AddDependency(name, variantName)
+
+		fieldName := fieldTracker.UniqueName(ToSnakeCase(variantName))
+		msg.Fields = append(msg.Fields, &ProtoField{
+			Name:     fieldName,
+			Type:     ctx.definitionName(variantName),
+			Number:   i + 1,
+			JSONName: fieldName,
+		})
+	}
+
+	ctx.Messages = append(ctx.Messages, msg)
+	ctx.Definitions = append(ctx.Definitions, msg)
+	return msg, nil
+}
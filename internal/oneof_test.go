@@ -0,0 +1,119 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oneOfSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      description: A pet of any kind
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+        bark:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+        meow:
+          type: string
+    Owner:
+      type: object
+      properties:
+        pet:
+          $ref: '#/components/schemas/Pet'
+`
+
+func TestConvertOneOfStrategy(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		strategy conv.OneOfStrategy
+		location conv.TypeLocation
+		contains string
+	}{
+		{
+			name:     "go",
+			strategy: conv.OneOfStrategyGo,
+			location: conv.TypeLocationGolang,
+		},
+		{
+			name:     "proto oneof",
+			strategy: conv.OneOfStrategyProtoOneof,
+			location: conv.TypeLocationProto,
+			contains: `// A pet of any kind
+message Pet {
+  oneof pet {
+    Dog dog = 1 [json_name = "dog"];
+    Cat cat = 2 [json_name = "cat"];
+  }
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(oneOfSpec), conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				OneOfStrategy: test.strategy,
+			})
+			require.NoError(t, err)
+			for _, schema := range []string{"Pet", "Dog", "Cat", "Owner"} {
+				assert.Equal(t, test.location, result.TypeMap[schema].Location)
+			}
+			if test.contains == "" {
+				assert.Empty(t, result.Protobuf)
+				return
+			}
+			assert.Contains(t, string(result.Protobuf), test.contains)
+			assert.Empty(t, result.Golang)
+
+			fd, err := result.FileDescriptor()
+			require.NoError(t, err)
+			var pet *conv.ProtoMessage
+			for _, def := range result.Definitions {
+				if msg, ok := def.(*conv.ProtoMessage); ok && msg.Name == "Pet" {
+					pet = msg
+				}
+			}
+			require.NotNil(t, pet)
+			assert.Equal(t, "pet", pet.Oneof)
+			for _, msg := range fd.GetMessageType() {
+				if msg.GetName() != "Pet" {
+					continue
+				}
+				require.Len(t, msg.GetOneofDecl(), 1)
+				assert.Equal(t, "pet", msg.GetOneofDecl()[0].GetName())
+				for _, field := range msg.GetField() {
+					assert.Equal(t, int32(0), field.GetOneofIndex())
+				}
+			}
+		})
+	}
+}
+
+func TestConvertOneOfStrategyUnknown(t *testing.T) {
+	_, err := conv.Convert([]byte(oneOfSpec), conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		OneOfStrategy: "flat",
+	})
+	require.ErrorContains(t, err, "unknown oneOf strategy: flat")
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// wrapperTypes maps scalar proto types to the well-known wrapper messages used for
+// nullable properties with Context.NullableWrappers
+var wrapperTypes = map[string]string{
+	"double": "google.protobuf.DoubleValue",
+	"float":  "google.protobuf.FloatValue",
+	"int64":  "google.protobuf.Int64Value",
+	"uint64": "google.protobuf.UInt64Value",
+	"int32":  "google.protobuf.Int32Value",
+	"uint32": "google.protobuf.UInt32Value",
+	"bool":   "google.protobuf.BoolValue",
+	"string": "google.protobuf.StringValue",
+	"bytes":  "google.protobuf.BytesValue",
+}
+
+// wrappersImport declares the wrapper messages
+const wrappersImport = "google/protobuf/wrappers.proto"
+
+// optionalField reports whether the field for a property is declared optional, so it
+// keeps its presence: with Context.NullableOptional set if the property is nullable,
+// and with Context.OptionalUnlessRequired set if required is false. Only scalar and enum
+// fields are declared optional, since message fields already track presence and
+// repeated and map fields cannot be optional.
+func optionalField(ctx *Context, proxy *base.SchemaProxy, schema *base.Schema, protoType string, repeated, required bool) bool {
+	if repeated || contains(schema.Type, "object") || contains(schema.Type, "array") || len(schema.OneOf) > 0 {
+		return false
+	}
+	// Well-known types such as wrappers, Timestamp and Date are messages
+	if strings.HasPrefix(protoType, "google.protobuf.") || strings.HasPrefix(protoType, "google.type.") {
+		return false
+	}
+	if ctx.NullableOptional && !proxy.IsReference() && isNullable(schema) {
+		return true
+	}
+	return ctx.OptionalUnlessRequired && !required
+}
+
+// nullableType returns the type of the field for a property: with Context.NullableWrappers
+// set, nullable scalar properties use the wrapper message of protoType, e.g.
+// google.protobuf.StringValue for string. Enums and map values have no wrapper and keep
+// protoType.
+func nullableType(ctx *Context, proxy *base.SchemaProxy, schema *base.Schema, protoType string, repeated bool) string {
+	if !ctx.NullableWrappers || repeated || proxy.IsReference() || !isNullable(schema) || contains(schema.Type, "object") {
+		return protoType
+	}
+	wrapper, ok := wrapperTypes[protoType]
+	if !ok {
+		return protoType
+	}
+	ctx.AddImport(wrappersImport)
+	return wrapper
+}
+
+// unwrapType returns the scalar type a wrapper message holds, and false for other types
+func unwrapType(typ string) (string, bool) {
+	for scalar, wrapper := range wrapperTypes {
+		if wrapper == typ {
+			return scalar, true
+		}
+	}
+	return "", false
+}
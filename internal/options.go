@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// fieldBehaviorImport declares the google.api.field_behavior extension
+const fieldBehaviorImport = "google/api/field_behavior.proto"
+
+// fieldBehaviors lists the google.api.FieldBehavior values accepted by x-proto-field-behavior
+var fieldBehaviors = map[string]bool{
+	"OPTIONAL":          true,
+	"REQUIRED":          true,
+	"OUTPUT_ONLY":       true,
+	"INPUT_ONLY":        true,
+	"IMMUTABLE":         true,
+	"UNORDERED_LIST":    true,
+	"NON_EMPTY_DEFAULT": true,
+	"IDENTIFIER":        true,
+}
+
+// fieldOptions returns the proto field options requested by a property's extensions
+// and records any imports they need on ctx
+func fieldOptions(proxy *base.SchemaProxy, ctx *Context) ([]string, error) {
+	behaviors, err := extractFieldBehavior(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []string
+	for _, behavior := range behaviors {
+		options = append(options, "(google.api.field_behavior) = "+behavior)
+	}
+
+	if len(behaviors) > 0 {
+		ctx.AddImport(fieldBehaviorImport)
+	}
+
+	sensitive, err := isSensitive(proxy.Schema())
+	if err != nil {
+		return nil, err
+	}
+	if sensitive {
+		options = append(options, "debug_redact = true")
+	}
+
+	if ctx.ExampleOption != "" && !proxy.IsReference() {
+		example, found, err := exampleText(proxy.Schema())
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			options = append(options, fmt.Sprintf("(%s) = %s", ctx.ExampleOption, strconv.Quote(example)))
+			ctx.AddImport(ctx.ExampleImport)
+		}
+	}
+
+	return options, nil
+}
+
+// exampleText returns the example of a property as option text: strings as written and
+// other values as JSON, e.g. 42 or ["a","b"]. The first entry of examples is used when
+// there is no example.
+func exampleText(schema *base.Schema) (string, bool, error) {
+	if schema == nil {
+		return "", false, nil
+	}
+	node := schema.Example
+	if node == nil && len(schema.Examples) > 0 {
+		node = schema.Examples[0]
+	}
+	if node == nil {
+		return "", false, nil
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		return node.Value, true, nil
+	}
+
+	value, err := nodeValue(node)
+	if err != nil {
+		return "", false, Errorf(CodeInvalidExample, "invalid example: %v", err)
+	}
+	text, err := json.Marshal(value)
+	if err != nil {
+		return "", false, Errorf(CodeInvalidExample, "failed to encode example: %v", err)
+	}
+	return string(text), true, nil
+}
+
+// isSensitive reports whether a property holds a secret that must not appear in logs,
+// marked with x-sensitive: true or format: password on the property or its array items
+func isSensitive(schema *base.Schema) (bool, error) {
+	if schema == nil {
+		return false, nil
+	}
+	if schema.Format == "password" {
+		return true, nil
+	}
+	if schema.Items != nil && schema.Items.IsA() && !schema.Items.A.IsReference() {
+		if items := schema.Items.A.Schema(); items != nil && items.Format == "password" {
+			return true, nil
+		}
+	}
+	if schema.Extensions == nil {
+		return false, nil
+	}
+
+	node, found := schema.Extensions.Get("x-sensitive")
+	if !found || node == nil {
+		return false, nil
+	}
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+		return false, Errorf(CodeInvalidExtension, "x-sensitive must be a boolean, got: %s", node.Value)
+	}
+	return node.Value == "true", nil
+}
+
+// extractFieldBehavior reads the x-proto-field-behavior extension, a list of
+// google.api.FieldBehavior values such as [REQUIRED, IMMUTABLE]
+func extractFieldBehavior(proxy *base.SchemaProxy) ([]string, error) {
+	schema := proxy.Schema()
+	if schema == nil || schema.Extensions == nil {
+		return nil, nil
+	}
+
+	node, found := schema.Extensions.Get("x-proto-field-behavior")
+	if !found || node == nil {
+		return nil, nil
+	}
+
+	if node.Kind != yaml.SequenceNode {
+		return nil, Errorf(CodeInvalidExtension, "x-proto-field-behavior must be a list, got: %s", node.Value)
+	}
+
+	behaviors := make([]string, 0, len(node.Content))
+	seen := make(map[string]bool, len(node.Content))
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode || !fieldBehaviors[item.Value] {
+			return nil, Errorf(CodeInvalidExtension, "x-proto-field-behavior has unknown value: %s", item.Value)
+		}
+		if seen[item.Value] {
+			continue
+		}
+		seen[item.Value] = true
+		behaviors = append(behaviors, item.Value)
+	}
+
+	return behaviors, nil
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PaginationStyle identifies the pagination convention a message follows
+type PaginationStyle string
+
+const (
+	PaginationPage   PaginationStyle = "page"   // page number and page size
+	PaginationCursor PaginationStyle = "cursor" // opaque cursor or page token
+)
+
+// paginationRenames maps normalized field names to their AIP-158 equivalents
+var paginationRenames = map[string]string{
+	"pagesize":      "page_size",
+	"perpage":       "page_size",
+	"limit":         "page_size",
+	"cursor":        "page_token",
+	"pagetoken":     "page_token",
+	"nextpagetoken": "next_page_token",
+	"nextcursor":    "next_page_token",
+}
+
+// DetectPagination finds top-level messages that follow a common pagination shape,
+// page/pageSize or cursor/nextPageToken. When normalize is set, matching fields are
+// renamed to the AIP-158 names (page_size, page_token, next_page_token) keeping their
+// json_name, and a warning describes each rename.
+func DetectPagination(messages []*ProtoMessage, normalize bool) (map[string]PaginationStyle, []string) {
+	detected := make(map[string]PaginationStyle)
+	var warnings []string
+
+	for _, msg := range messages {
+		style, ok := paginationStyle(msg)
+		if !ok {
+			continue
+		}
+		detected[msg.Name] = style
+
+		if !normalize {
+			continue
+		}
+
+		if style == PaginationPage {
+			warnings = append(warnings, fmt.Sprintf("%s: page number pagination has no AIP-158 equivalent, only the page size is renamed", msg.Name))
+		}
+
+		for _, field := range msg.Fields {
+			target, ok := paginationTarget(field)
+			if !ok || field.Name == target {
+				continue
+			}
+			if hasField(msg, target) {
+				warnings = append(warnings, fmt.Sprintf("%s: cannot rename pagination field %s to %s, the name is already used", msg.Name, field.Name, target))
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("%s: renamed pagination field %s to %s (AIP-158)", msg.Name, field.Name, target))
+			field.Name = target
+		}
+	}
+
+	return detected, warnings
+}
+
+// paginationStyle reports whether a message has a pagination shape
+func paginationStyle(msg *ProtoMessage) (PaginationStyle, bool) {
+	hasPage, hasSize := false, false
+	for _, field := range msg.Fields {
+		if field.Repeated {
+			continue
+		}
+		target, ok := paginationTarget(field)
+		if ok && target != "page_size" {
+			return PaginationCursor, true
+		}
+		if ok {
+			hasSize = true
+		}
+		if isIntegerType(field.Type) && isPageNumberName(normalizePaginationName(field.JSONName)) {
+			hasPage = true
+		}
+	}
+
+	if hasPage && hasSize {
+		return PaginationPage, true
+	}
+	return "", false
+}
+
+// paginationTarget returns the AIP-158 name for a pagination field of the expected type
+func paginationTarget(field *ProtoField) (string, bool) {
+	if field.Repeated {
+		return "", false
+	}
+
+	target, ok := paginationRenames[normalizePaginationName(field.JSONName)]
+	if !ok {
+		return "", false
+	}
+
+	if target == "page_size" {
+		return target, isIntegerType(field.Type)
+	}
+	return target, field.Type == "string"
+}
+
+// separatorReplacer drops the separators normalizePaginationName ignores
+var separatorReplacer = strings.NewReplacer("_", "", "-", "")
+
+// normalizePaginationName lowercases a name and drops separators so pageSize,
+// page_size and page-size compare equal
+func normalizePaginationName(name string) string {
+	return separatorReplacer.Replace(strings.ToLower(name))
+}
+
+// isPageNumberName reports whether a normalized name is a page number field
+func isPageNumberName(name string) bool {
+	return name == "page" || name == "pagenumber"
+}
+
+// isIntegerType reports whether a proto type is an integer scalar
+func isIntegerType(typ string) bool {
+	switch typ {
+	case "int32", "int64", "uint32", "uint64", "sint32", "sint64", "fixed32", "fixed64", "sfixed32", "sfixed64":
+		return true
+	}
+	return false
+}
+
+// hasField reports whether msg has a field with the given proto name
+func hasField(msg *ProtoMessage, name string) bool {
+	for _, field := range msg.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const paginationSpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    ListUsersRequest:
+      type: object
+      properties:
+        cursor:
+          type: string
+        limit:
+          type: integer
+    ListUsersResponse:
+      type: object
+      properties:
+        users:
+          type: array
+          items:
+            type: string
+        nextCursor:
+          type: string
+    SearchRequest:
+      type: object
+      properties:
+        page:
+          type: integer
+        pageSize:
+          type: integer
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        limit:
+          type: integer
+`
+
+func TestPaginationDetection(t *testing.T) {
+	result, err := conv.Convert([]byte(paginationSpec), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]conv.PaginationStyle{
+		"ListUsersRequest":  conv.PaginationCursor,
+		"ListUsersResponse": conv.PaginationCursor,
+		"SearchRequest":     conv.PaginationPage,
+	}, result.Pagination)
+	assert.Empty(t, result.Warnings)
+	assert.Contains(t, string(result.Protobuf), "  string cursor = 1 [json_name = \"cursor\"];\n")
+}
+
+func TestPaginationNormalize(t *testing.T) {
+	expected := `message ListUsersRequest {
+  string page_token = 1 [json_name = "cursor"];
+  int32 page_size = 2 [json_name = "limit"];
+}
+
+message ListUsersResponse {
+  repeated string users = 1 [json_name = "users"];
+  string next_page_token = 2 [json_name = "nextCursor"];
+}
+
+message SearchRequest {
+  int32 page = 1 [json_name = "page"];
+  int32 page_size = 2 [json_name = "pageSize"];
+}
+
+message User {
+  string name = 1 [json_name = "name"];
+  int32 limit = 2 [json_name = "limit"];
+}
+`
+
+	result, err := conv.Convert([]byte(paginationSpec), conv.ConvertOptions{
+		PackageName:         "testpkg",
+		PackagePath:         "github.com/example/proto/v1",
+		NormalizePagination: true,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Protobuf), expected)
+	assert.Equal(t, []string{
+		"ListUsersRequest: renamed pagination field cursor to page_token (AIP-158)",
+		"ListUsersRequest: renamed pagination field limit to page_size (AIP-158)",
+		"ListUsersResponse: renamed pagination field nextCursor to next_page_token (AIP-158)",
+		"SearchRequest: page number pagination has no AIP-158 equivalent, only the page size is renamed",
+		"SearchRequest: renamed pagination field pageSize to page_size (AIP-158)",
+	}, result.Warnings)
+}
+
+func TestPaginationNormalizeConflict(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    ListRequest:
+      type: object
+      properties:
+        page_token:
+          type: string
+        cursor:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:         "testpkg",
+		PackagePath:         "github.com/example/proto/v1",
+		NormalizePagination: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"ListRequest: cannot rename pagination field cursor to page_token, the name is already used",
+	}, result.Warnings)
+	assert.Contains(t, string(result.Protobuf), "  string cursor = 2 [json_name = \"cursor\"];\n")
+}
@@ -0,0 +1,288 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// FlattenAllOf returns openapi with every allOf replaced by a single object schema holding
+// the properties of its members, in member order, followed by the properties declared
+// next to the allOf. Members may be inline objects or references to component schemas,
+// which are flattened first, and an allOf of a single $ref with no properties next to it
+// becomes that $ref. The required lists are combined. A property declared by
+// more than one member must be identical in each, otherwise an error names the property
+// and the location of the allOf. openapi is returned unchanged if it uses no allOf.
+func FlattenAllOf(openapi []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(openapi, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return openapi, nil
+	}
+
+	f := &allOfFlattener{
+		schemas:  mappingValue(mappingValue(root.Content[0], "components"), "schemas"),
+		state:    make(map[*yaml.Node]flattenState),
+		pointers: make(map[*yaml.Node]string),
+	}
+	if err := f.walk(root.Content[0], ""); err != nil {
+		return nil, err
+	}
+	if !f.changed {
+		return openapi, nil
+	}
+
+	flattened, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode flattened OpenAPI document: %w", err)
+	}
+	return flattened, nil
+}
+
+// flattenState tracks a schema node through flattening, to detect reference cycles
+type flattenState int
+
+const (
+	flattenPending flattenState = iota + 1
+	flattenDone
+)
+
+// allOfFlattener rewrites the allOf schemas of one document
+type allOfFlattener struct {
+	schemas  *yaml.Node // components/schemas, nil if the document has none
+	state    map[*yaml.Node]flattenState
+	pointers map[*yaml.Node]string // JSON pointer of each node being flattened
+	changed  bool
+}
+
+// namedMaps are the keys whose values map names to schemas, so an "example" key below
+// them names a schema rather than holding an example value
+var namedMaps = map[string]bool{
+	"properties":        true,
+	"schemas":           true,
+	"patternProperties": true,
+	"$defs":             true,
+	"definitions":       true,
+	"dependentSchemas":  true,
+}
+
+// walk flattens every allOf below node, the value at JSON pointer. Example values are
+// literal data and are left as written, even when they hold an allOf key.
+func (f *allOfFlattener) walk(node *yaml.Node, pointer string) error {
+	if node.Kind == yaml.MappingNode {
+		if err := f.flatten(node, pointer); err != nil {
+			return err
+		}
+		named := namedMaps[unescapeToken(pointer[strings.LastIndex(pointer, "/")+1:])]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if !named && (key == "example" || key == "examples") {
+				continue
+			}
+			if err := f.walk(node.Content[i+1], pointer+"/"+escapeToken(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, child := range node.Content {
+		if err := f.walk(child, fmt.Sprintf("%s/%d", pointer, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatten replaces the allOf of schema, at pointer, with the merged members
+func (f *allOfFlattener) flatten(schema *yaml.Node, pointer string) error {
+	allOf := mappingValue(schema, "allOf")
+	if allOf == nil || allOf.Kind != yaml.SequenceNode {
+		return nil
+	}
+	switch f.state[schema] {
+	case flattenDone:
+		return nil
+	case flattenPending:
+		return fmt.Errorf("allOf at %s references itself", pointer)
+	}
+	f.state[schema] = flattenPending
+	f.pointers[schema] = pointer
+
+	if ref := wrappedRef(schema, allOf); ref != nil {
+		// A lone $ref wrapped to attach keywords such as description or nullable keeps
+		// pointing at its schema, with the keywords next to the $ref
+		if name, ok := strings.CutPrefix(ref.Value, schemasPointer); ok {
+			if target := mappingValue(f.schemas, unescapeToken(name)); target != nil {
+				targetPointer := "/components/schemas/" + name
+				if f.state[target] == flattenPending {
+					return fmt.Errorf("allOf at %s references itself through %s", f.pointers[target], targetPointer)
+				}
+				if err := f.flatten(target, targetPointer); err != nil {
+					return err
+				}
+			}
+		}
+		removeKey(schema, "allOf")
+		schema.Content = append(schema.Content, scalarNode("$ref"), ref)
+		f.state[schema] = flattenDone
+		f.changed = true
+		return nil
+	}
+
+	properties := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	owners := make(map[string]int)
+	var required []*yaml.Node
+	merge := func(member *yaml.Node, index int) error {
+		if props := mappingValue(member, "properties"); props != nil {
+			for i := 0; i+1 < len(props.Content); i += 2 {
+				name := props.Content[i].Value
+				if existing := mappingValue(properties, name); existing != nil {
+					if !nodesEqual(existing, props.Content[i+1]) {
+						return fmt.Errorf("allOf at %s: %s and %s define property '%s' differently",
+							pointer, describeMember(owners[name]), describeMember(index), name)
+					}
+					continue
+				}
+				owners[name] = index
+				properties.Content = append(properties.Content, props.Content[i], props.Content[i+1])
+			}
+		}
+		if req := mappingValue(member, "required"); req != nil {
+			required = unionScalars(required, req.Content)
+		}
+		return nil
+	}
+
+	for i, item := range allOf.Content {
+		member, err := f.member(item, fmt.Sprintf("%s/allOf/%d", pointer, i))
+		if err != nil {
+			return err
+		}
+		if err := merge(member, i); err != nil {
+			return err
+		}
+	}
+	// Properties declared next to the allOf extend the members
+	if err := merge(schema, -1); err != nil {
+		return err
+	}
+
+	removeKey(schema, "allOf")
+	removeKey(schema, "properties")
+	removeKey(schema, "required")
+	if mappingValue(schema, "type") == nil {
+		schema.Content = append(schema.Content, scalarNode("type"), scalarNode("object"))
+	}
+	if len(properties.Content) > 0 {
+		schema.Content = append(schema.Content, scalarNode("properties"), properties)
+	}
+	if len(required) > 0 {
+		schema.Content = append(schema.Content, scalarNode("required"),
+			&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Content: required})
+	}
+
+	f.state[schema] = flattenDone
+	f.changed = true
+	return nil
+}
+
+// member returns the flattened object schema an allOf item, at pointer, stands for
+func (f *allOfFlattener) member(item *yaml.Node, pointer string) (*yaml.Node, error) {
+	if item.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("allOf member at %s is not a schema", pointer)
+	}
+
+	if ref := mappingValue(item, "$ref"); ref != nil {
+		name, ok := strings.CutPrefix(ref.Value, schemasPointer)
+		target := mappingValue(f.schemas, unescapeToken(name))
+		if !ok || target == nil || target.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("allOf member at %s references '%s', which is not a component schema", pointer, ref.Value)
+		}
+		targetPointer := "/components/schemas/" + name
+		if f.state[target] == flattenPending {
+			return nil, fmt.Errorf("allOf at %s references itself through %s", f.pointers[target], targetPointer)
+		}
+		if err := f.flatten(target, targetPointer); err != nil {
+			return nil, err
+		}
+		item, pointer = target, targetPointer
+	} else if err := f.flatten(item, pointer); err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"oneOf", "anyOf", "not"} {
+		if mappingValue(item, key) != nil {
+			return nil, fmt.Errorf("allOf member at %s uses %s; only object members can be merged", pointer, key)
+		}
+	}
+	if typ := mappingValue(item, "type"); typ != nil && !objectType(typ) {
+		return nil, fmt.Errorf("allOf member at %s has type '%s'; only object members can be merged", pointer, describeType(typ))
+	}
+	return item, nil
+}
+
+// wrappedRef returns the $ref of an allOf holding nothing but one reference, when the
+// schema declares no properties or required list of its own to merge into it
+func wrappedRef(schema, allOf *yaml.Node) *yaml.Node {
+	if len(allOf.Content) != 1 || mappingValue(schema, "properties") != nil || mappingValue(schema, "required") != nil ||
+		mappingValue(schema, "$ref") != nil {
+		return nil
+	}
+	member := allOf.Content[0]
+	if member.Kind != yaml.MappingNode || len(member.Content) != 2 {
+		return nil
+	}
+	return mappingValue(member, "$ref")
+}
+
+// objectType reports whether a type keyword is object, alone or as in OpenAPI 3.1
+// [object, "null"]
+func objectType(typ *yaml.Node) bool {
+	if typ.Kind == yaml.ScalarNode {
+		return typ.Value == "object"
+	}
+	object := false
+	for _, item := range typ.Content {
+		switch item.Value {
+		case "object":
+			object = true
+		case "null":
+		default:
+			return false
+		}
+	}
+	return object
+}
+
+// describeType formats a type keyword for an error, e.g. string or [string, null]
+func describeType(typ *yaml.Node) string {
+	if typ.Kind == yaml.ScalarNode {
+		return typ.Value
+	}
+	values := make([]string, 0, len(typ.Content))
+	for _, item := range typ.Content {
+		values = append(values, item.Value)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// describeMember names an allOf member in a conflict message, -1 for the schema itself
+func describeMember(index int) string {
+	if index < 0 {
+		return "the schema itself"
+	}
+	return fmt.Sprintf("member %d", index)
+}
+
+// escapeToken encodes a JSON pointer token, the inverse of unescapeToken
+func escapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
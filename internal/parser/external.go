@@ -0,0 +1,297 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// schemasPointer is the prefix of a reference to a component schema of the same document
+const schemasPointer = "#/components/schemas/"
+
+// ResolveExternalRefs returns openapi with every $ref into another file replaced by a
+// reference to a copy of its target added under components/schemas, so the document no
+// longer depends on other files. Relative paths are resolved against baseDir for the
+// document and against the referencing file for references inside other files. The copy
+// is named after the last segment of the reference, e.g. Address for
+// './common.yaml#/components/schemas/Address', or after the file name when the whole
+// file is the schema. File references in openapi are left alone if baseDir is empty.
+//
+// Every referenced file must lie inside rootDir, or baseDir when rootDir is empty, so a
+// spec cannot read other files on the host through absolute paths, '../' or symlinks.
+//
+// References to URLs, and relative references inside documents fetched from one, are
+// loaded with fetch. They are left alone if fetch is nil. openapi is returned unchanged
+// if nothing was resolved.
+func ResolveExternalRefs(openapi []byte, baseDir, rootDir string, fetch func(url string) ([]byte, error)) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(openapi, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return openapi, nil
+	}
+
+	r := &refResolver{
+		doc:      root.Content[0],
+		fetch:    fetch,
+		files:    make(map[string]*yaml.Node),
+		imported: make(map[string]string),
+	}
+	if baseDir != "" {
+		if rootDir == "" {
+			rootDir = baseDir
+		}
+		var err error
+		if r.baseDir, err = filepath.Abs(baseDir); err != nil {
+			return nil, err
+		}
+		if r.rootDir, err = filepath.Abs(rootDir); err != nil {
+			return nil, err
+		}
+	}
+	defer func() {
+		if r.root != nil {
+			r.root.Close()
+		}
+	}()
+	if err := r.walk(root.Content[0], ""); err != nil {
+		return nil, err
+	}
+	if len(r.imported) == 0 {
+		return openapi, nil
+	}
+
+	resolved, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resolved OpenAPI document: %w", err)
+	}
+	return resolved, nil
+}
+
+// refResolver copies the targets of file references into a document
+type refResolver struct {
+	doc     *yaml.Node
+	baseDir string
+	// rootDir is the directory every referenced file must lie inside, opened as root on
+	// the first file read
+	rootDir string
+	root    *os.Root
+	fetch   func(url string) ([]byte, error)
+	// schemas is the components/schemas mapping of doc, created on the first import
+	schemas *yaml.Node
+	// files caches parsed files by path or URL
+	files map[string]*yaml.Node
+	// imported maps "<path or URL>#<pointer>" of each copied target to its schema name
+	imported map[string]string
+}
+
+// walk rewrites the external references below node. file is the path or URL of the file
+// containing node, empty for the document itself.
+func (r *refResolver) walk(node *yaml.Node, file string) error {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := node.Content[i+1]
+			if node.Content[i].Value != "$ref" || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			target, pointer, _ := strings.Cut(value.Value, "#")
+			target, ok, err := r.locate(file, target)
+			if err != nil {
+				return fmt.Errorf("failed to resolve reference '%s': %w", value.Value, err)
+			}
+			if !ok {
+				continue
+			}
+
+			name, err := r.importSchema(target, pointer)
+			if err != nil {
+				return fmt.Errorf("failed to resolve reference '%s': %w", value.Value, err)
+			}
+			value.Value = schemasPointer + name
+		}
+	}
+
+	for _, child := range node.Content {
+		if err := r.walk(child, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// locate returns the path or URL of the file a reference target names, relative to file,
+// and false if the reference is left alone
+func (r *refResolver) locate(file, target string) (string, bool, error) {
+	remote := strings.Contains(file, "://")
+	switch {
+	case target == "" && file == "":
+		// A reference within the document itself
+		return "", false, nil
+	case target == "":
+		return file, true, nil
+	case strings.Contains(target, "://") || remote:
+		if r.fetch == nil {
+			return "", false, nil
+		}
+		if !remote {
+			return target, true, nil
+		}
+		base, err := url.Parse(file)
+		if err != nil {
+			return "", false, err
+		}
+		ref, err := url.Parse(target)
+		if err != nil {
+			return "", false, err
+		}
+		return base.ResolveReference(ref).String(), true, nil
+	case file == "" && r.baseDir == "":
+		return "", false, nil
+	case filepath.IsAbs(target):
+		return target, true, nil
+	case file == "":
+		return filepath.Join(r.baseDir, filepath.FromSlash(target)), true, nil
+	}
+	return filepath.Join(filepath.Dir(file), filepath.FromSlash(target)), true, nil
+}
+
+// importSchema copies the node at pointer in the file at target into components/schemas
+// once, returning the name it is declared under
+func (r *refResolver) importSchema(target, pointer string) (string, error) {
+	key := target + "#" + pointer
+	if name, ok := r.imported[key]; ok {
+		return name, nil
+	}
+
+	file, err := r.load(target)
+	if err != nil {
+		return "", err
+	}
+	node, err := resolvePointer(file, pointer)
+	if err != nil {
+		return "", fmt.Errorf("'%s': %w", target, err)
+	}
+
+	name := unescapeToken(pointer[strings.LastIndex(pointer, "/")+1:])
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(filepath.ToSlash(target)), path.Ext(target))
+	}
+	if r.schemas == nil {
+		r.schemas = ensureMapping(ensureMapping(r.doc, "components"), "schemas")
+	}
+	if mappingValue(r.schemas, name) != nil {
+		return "", fmt.Errorf("schema '%s' from '%s' conflicts with a schema of the same name", name, target)
+	}
+
+	// Record the name before walking the copy, so cyclic references resolve to it
+	r.imported[key] = name
+	schema := copyNode(node)
+	r.schemas.Content = append(r.schemas.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, schema)
+	if err := r.walk(schema, target); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// load returns the root node of the file at target, parsing it on first use
+func (r *refResolver) load(target string) (*yaml.Node, error) {
+	if file, ok := r.files[target]; ok {
+		return file, nil
+	}
+
+	var data []byte
+	var err error
+	if strings.Contains(target, "://") {
+		data, err = r.fetch(target)
+	} else {
+		data, err = r.readFile(target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse '%s': %w", target, err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil, fmt.Errorf("'%s' is empty", target)
+	}
+
+	r.files[target] = root.Content[0]
+	return root.Content[0], nil
+}
+
+// readFile reads the local file at target, which must lie inside the root directory.
+// Reading through the root also refuses symlinks that lead out of it.
+func (r *refResolver) readFile(target string) ([]byte, error) {
+	rel, err := filepath.Rel(r.rootDir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("'%s' is outside the directory '%s'", target, r.rootDir)
+	}
+
+	if r.root == nil {
+		if r.root, err = os.OpenRoot(r.rootDir); err != nil {
+			return nil, err
+		}
+	}
+	f, err := r.root.Open(rel)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// resolvePointer returns the node a JSON pointer such as /components/schemas/Address
+// addresses in root
+func resolvePointer(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	node := root
+	if pointer == "" || pointer == "/" {
+		return node, nil
+	}
+
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = unescapeToken(token)
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = mappingValue(node, token)
+		case yaml.SequenceNode:
+			if index, err := strconv.Atoi(token); err == nil && index >= 0 && index < len(node.Content) {
+				next = node.Content[index]
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("'%s' not found", pointer)
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// unescapeToken decodes the ~1 and ~0 escapes of a JSON pointer token
+func unescapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// copyNode returns a deep copy of node, so rewriting the references of a copied schema
+// leaves the cached file intact
+func copyNode(node *yaml.Node) *yaml.Node {
+	dup := *node
+	dup.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		dup.Content[i] = copyNode(child)
+	}
+	if node.Alias != nil {
+		dup.Alias = copyNode(node.Alias)
+	}
+	return &dup
+}
@@ -0,0 +1,232 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// MergeConflict describes a schema or property defined differently by the two documents
+// passed to MergeDocuments
+type MergeConflict struct {
+	Schema   string // Top-level schema containing the conflict
+	Property string // Dotted path of the property below Schema, empty for the schema itself
+	Message  string
+}
+
+// conflictingKeys are the schema keywords whose values must agree for the merged
+// schema to decode documents valid against either version
+var conflictingKeys = map[string]bool{"type": true, "format": true, "$ref": true}
+
+// MergeDocuments returns newer with the component schemas of older merged in, so the
+// result describes documents valid against either. Schemas only in older are appended.
+// Schemas in both get the union of their properties, the union of their enum values
+// with the values of older first, and only the required properties both agree on.
+// Where a type, format or $ref differs, newer wins and a conflict is reported. Other
+// keywords come from newer when both set them.
+func MergeDocuments(older, newer []byte) ([]byte, []MergeConflict, error) {
+	oldSchemas, _, err := componentSchemas(older)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse older document: %w", err)
+	}
+	newSchemas, root, err := componentSchemas(newer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse newer document: %w", err)
+	}
+
+	var conflicts []MergeConflict
+	for i := 0; i+1 < len(oldSchemas.Content); i += 2 {
+		name := oldSchemas.Content[i].Value
+		oldSchema := oldSchemas.Content[i+1]
+		newSchema := mappingValue(newSchemas, name)
+		if newSchema == nil {
+			newSchemas.Content = append(newSchemas.Content, oldSchemas.Content[i], oldSchema)
+			continue
+		}
+		merger := &schemaMerger{schema: name}
+		merger.merge("", oldSchema, newSchema)
+		conflicts = append(conflicts, merger.conflicts...)
+	}
+
+	merged, err := yaml.Marshal(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode merged document: %w", err)
+	}
+	return merged, conflicts, nil
+}
+
+// componentSchemas parses a document and returns its components/schemas mapping and root.
+// The mapping is created in the root when missing.
+func componentSchemas(data []byte) (*yaml.Node, *yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("document is not a mapping")
+	}
+
+	components := ensureMapping(root.Content[0], "components")
+	return ensureMapping(components, "schemas"), &root, nil
+}
+
+// ensureMapping returns the mapping under key in node, adding an empty one if missing
+func ensureMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := mappingValue(node, key); value != nil && value.Kind == yaml.MappingNode {
+		return value
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// mappingValue returns the value under key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// schemaMerger merges the versions of one top-level schema, recording conflicts
+type schemaMerger struct {
+	schema    string
+	conflicts []MergeConflict
+}
+
+func (m *schemaMerger) conflict(property, format string, args ...any) {
+	m.conflicts = append(m.conflicts, MergeConflict{
+		Schema:   m.schema,
+		Property: property,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// merge merges older into newer, the schema of property
+func (m *schemaMerger) merge(property string, older, newer *yaml.Node) {
+	if older.Kind != yaml.MappingNode || newer.Kind != yaml.MappingNode {
+		if !nodesEqual(older, newer) {
+			m.conflict(property, "schema differs between versions")
+		}
+		return
+	}
+
+	for i := 0; i+1 < len(older.Content); i += 2 {
+		key := older.Content[i].Value
+		oldValue := older.Content[i+1]
+		newValue := mappingValue(newer, key)
+
+		switch {
+		case key == "required":
+			// Only properties both versions require stay required
+			if newValue != nil {
+				newValue.Content = intersectScalars(newValue.Content, oldValue.Content)
+			}
+		case newValue == nil:
+			newer.Content = append(newer.Content, older.Content[i], oldValue)
+		case key == "properties" && oldValue.Kind == yaml.MappingNode && newValue.Kind == yaml.MappingNode:
+			m.mergeProperties(property, oldValue, newValue)
+		case key == "items":
+			m.merge(property, oldValue, newValue)
+		case key == "enum" && oldValue.Kind == yaml.SequenceNode && newValue.Kind == yaml.SequenceNode:
+			newValue.Content = unionScalars(oldValue.Content, newValue.Content)
+		case conflictingKeys[key] && !nodesEqual(oldValue, newValue):
+			m.conflict(property, "%s is %s in the older version and %s in the newer one", key,
+				describe(oldValue), describe(newValue))
+		}
+	}
+
+	// A required list only newer has cannot apply to documents of the older version
+	if required := mappingValue(newer, "required"); required != nil &&
+		(mappingValue(older, "required") == nil || len(required.Content) == 0) {
+		removeKey(newer, "required")
+	}
+}
+
+// removeKey deletes key and its value from a mapping node
+func removeKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// mergeProperties adds the properties of older missing from newer and merges the rest
+func (m *schemaMerger) mergeProperties(property string, older, newer *yaml.Node) {
+	for i := 0; i+1 < len(older.Content); i += 2 {
+		name := older.Content[i].Value
+		path := name
+		if property != "" {
+			path = property + "." + name
+		}
+		if newValue := mappingValue(newer, name); newValue != nil {
+			m.merge(path, older.Content[i+1], newValue)
+			continue
+		}
+		newer.Content = append(newer.Content, older.Content[i], older.Content[i+1])
+	}
+}
+
+// unionScalars returns older followed by the values of newer not in older
+func unionScalars(older, newer []*yaml.Node) []*yaml.Node {
+	result := append([]*yaml.Node{}, older...)
+	for _, value := range newer {
+		if !containsNode(older, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// intersectScalars returns the values of newer that are also in older
+func intersectScalars(newer, older []*yaml.Node) []*yaml.Node {
+	var result []*yaml.Node
+	for _, value := range newer {
+		if containsNode(older, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func containsNode(nodes []*yaml.Node, node *yaml.Node) bool {
+	for _, n := range nodes {
+		if nodesEqual(n, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodesEqual compares the content of two nodes, ignoring style and position
+func nodesEqual(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !nodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// describe renders a node for a conflict message
+func describe(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return fmt.Sprintf("'%s'", node.Value)
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return "a different value"
+	}
+	return fmt.Sprintf("'%s'", strings.TrimSpace(string(data)))
+}
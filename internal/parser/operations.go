@@ -0,0 +1,383 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// schemaRefPrefix is the reference prefix of component schemas
+const schemaRefPrefix = "#/components/schemas/"
+
+// OperationSchemas returns the names of component schemas used by operations, either
+// directly by a parameter, request body or response, including those of callbacks, or
+// indirectly through the schemas those reference
+func (d *Document) OperationSchemas() map[string]bool {
+	used := make(map[string]bool)
+	if d.model.Model.Paths == nil || d.model.Model.Paths.PathItems == nil {
+		return used
+	}
+
+	for _, pathItem := range d.model.Model.Paths.PathItems.FromOldest() {
+		collectPathItem(pathItem, used)
+	}
+	return used
+}
+
+// PatchSchemas returns the names of component schemas referenced directly by the request
+// body of a PATCH operation
+func (d *Document) PatchSchemas() map[string]bool {
+	patched := make(map[string]bool)
+	if d.model.Model.Paths == nil || d.model.Model.Paths.PathItems == nil {
+		return patched
+	}
+
+	for _, pathItem := range d.model.Model.Paths.PathItems.FromOldest() {
+		if pathItem == nil || pathItem.Patch == nil || pathItem.Patch.RequestBody == nil {
+			continue
+		}
+		if name := contentSchemaName(pathItem.Patch.RequestBody.Content); name != "" {
+			patched[name] = true
+		}
+	}
+	return patched
+}
+
+// ResourceEntry lists the paths of one REST resource and the component schemas their
+// operations use
+type ResourceEntry struct {
+	Name    string          // First path segment that is not a parameter, e.g. users for /users/{id}
+	Paths   map[string]bool // Path templates of the resource
+	Schemas map[string]bool // Component schemas used directly or indirectly
+}
+
+// Resources groups the paths of the spec by resource, in order of first declaration.
+// Paths without a literal segment, such as / or /{id}, belong to no resource.
+func (d *Document) Resources() []*ResourceEntry {
+	var entries []*ResourceEntry
+	if d.model.Model.Paths == nil || d.model.Model.Paths.PathItems == nil {
+		return entries
+	}
+
+	index := make(map[string]*ResourceEntry)
+	for path, pathItem := range d.model.Model.Paths.PathItems.FromOldest() {
+		name := resourceName(path)
+		if name == "" {
+			continue
+		}
+		entry, ok := index[name]
+		if !ok {
+			entry = &ResourceEntry{Name: name, Paths: make(map[string]bool), Schemas: make(map[string]bool)}
+			index[name] = entry
+			entries = append(entries, entry)
+		}
+		entry.Paths[path] = true
+		collectPathItem(pathItem, entry.Schemas)
+	}
+	return entries
+}
+
+// resourceName returns the first path segment that is not a parameter, or an empty
+// string if there is none
+func resourceName(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			return segment
+		}
+	}
+	return ""
+}
+
+// RouteEntry describes an operation of the spec
+type RouteEntry struct {
+	Method    string // HTTP method in upper case
+	Path      string // Path template, e.g. /users/{id}
+	Operation string // operationId, empty if none
+	Request   string // Component schema of the request body, empty if none or inline
+	Response  string // Component schema of the first 2xx response, empty if none or inline
+	// RequestMediaType is the media type of the request body, the first JSON one when
+	// there are several, empty if there is no request body
+	RequestMediaType string
+
+	RequestSchema  *base.SchemaProxy // Inline schema of the request body, nil if none or a component
+	RequestBody    *base.SchemaProxy // Schema of the request body, inline or a $ref, nil if none
+	ResponseSchema *base.SchemaProxy // Inline schema of the first 2xx response, nil if none or a component
+}
+
+// Routes returns every operation in declaration order
+func (d *Document) Routes() []*RouteEntry {
+	var entries []*RouteEntry
+	if d.model.Model.Paths == nil || d.model.Model.Paths.PathItems == nil {
+		return entries
+	}
+
+	for path, pathItem := range d.model.Model.Paths.PathItems.FromOldest() {
+		if pathItem == nil {
+			continue
+		}
+		for method, op := range pathItem.GetOperations().FromOldest() {
+			entry := &RouteEntry{
+				Method:    strings.ToUpper(method),
+				Path:      path,
+				Operation: op.OperationId,
+			}
+			if op.RequestBody != nil {
+				if mediaType, content := requestContent(op.RequestBody.Content); content != nil {
+					entry.RequestMediaType = mediaType
+					entry.Request = contentSchemaName(content)
+					entry.RequestSchema = inlineContentSchema(content)
+					if media := content.GetOrZero(mediaType); media != nil {
+						entry.RequestBody = media.Schema
+					}
+				}
+			}
+			if op.Responses != nil && op.Responses.Codes != nil {
+				for code, response := range op.Responses.Codes.FromOldest() {
+					if strings.HasPrefix(code, "2") && response != nil {
+						entry.Response = contentSchemaName(response.Content)
+						entry.ResponseSchema = inlineContentSchema(response.Content)
+						break
+					}
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// CallbackEntry describes a request an operation sends back to the client
+type CallbackEntry struct {
+	Operation  string // operationId, or method and path when it has none
+	Name       string // Callback name, e.g. onUserCreated
+	Expression string // Runtime expression of the callback URL
+	Method     string // HTTP method in upper case
+	Request    string // Component schema of the request body, empty if none or inline
+
+	OperationID     string            // operationId of the operation, empty if none
+	OperationMethod string            // HTTP method of the operation in upper case
+	OperationPath   string            // Path template of the operation
+	RequestSchema   *base.SchemaProxy // Inline schema of the request body, nil if none or a component
+}
+
+// Callbacks returns the callbacks of every operation in declaration order
+func (d *Document) Callbacks() []*CallbackEntry {
+	var entries []*CallbackEntry
+	if d.model.Model.Paths == nil || d.model.Model.Paths.PathItems == nil {
+		return entries
+	}
+
+	for path, pathItem := range d.model.Model.Paths.PathItems.FromOldest() {
+		if pathItem == nil {
+			continue
+		}
+		for method, op := range pathItem.GetOperations().FromOldest() {
+			if op.Callbacks == nil {
+				continue
+			}
+			operation := op.OperationId
+			if operation == "" {
+				operation = strings.ToUpper(method) + " " + path
+			}
+
+			for name, callback := range op.Callbacks.FromOldest() {
+				if callback == nil || callback.Expression == nil {
+					continue
+				}
+				for expression, callbackItem := range callback.Expression.FromOldest() {
+					if callbackItem == nil {
+						continue
+					}
+					for callbackMethod, callbackOp := range callbackItem.GetOperations().FromOldest() {
+						entry := &CallbackEntry{
+							Operation:       operation,
+							Name:            name,
+							Expression:      expression,
+							Method:          strings.ToUpper(callbackMethod),
+							OperationID:     op.OperationId,
+							OperationMethod: strings.ToUpper(method),
+							OperationPath:   path,
+						}
+						if callbackOp.RequestBody != nil {
+							entry.Request = contentSchemaName(callbackOp.RequestBody.Content)
+							entry.RequestSchema = inlineContentSchema(callbackOp.RequestBody.Content)
+						}
+						entries = append(entries, entry)
+					}
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// requestContent returns the media type of a request body to map, the first JSON media
+// type or else the first one, and content reduced to that media type
+func requestContent(content *orderedmap.Map[string, *v3.MediaType]) (string, *orderedmap.Map[string, *v3.MediaType]) {
+	if content == nil {
+		return "", nil
+	}
+	chosen := ""
+	for mediaType := range content.KeysFromOldest() {
+		if chosen == "" {
+			chosen = mediaType
+		}
+		if IsJSONMediaType(mediaType) {
+			chosen = mediaType
+			break
+		}
+	}
+	if chosen == "" {
+		return "", nil
+	}
+
+	reduced := orderedmap.New[string, *v3.MediaType]()
+	reduced.Set(chosen, content.GetOrZero(chosen))
+	return chosen, reduced
+}
+
+// IsJSONMediaType reports whether mediaType is application/json or a JSON based media
+// type such as application/merge-patch+json, ignoring parameters
+func IsJSONMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(strings.ToLower(mediaType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// contentSchemaName returns the component schema referenced by the first media type
+// of content, or an empty string if it is inline
+func contentSchemaName(content *orderedmap.Map[string, *v3.MediaType]) string {
+	if content == nil {
+		return ""
+	}
+	for _, mediaType := range content.FromOldest() {
+		if mediaType == nil || mediaType.Schema == nil || !mediaType.Schema.IsReference() {
+			return ""
+		}
+		name, _ := strings.CutPrefix(mediaType.Schema.GetReference(), schemaRefPrefix)
+		return name
+	}
+	return ""
+}
+
+// inlineContentSchema returns the schema of the first media type of content, or nil if
+// there is none or it references a component schema
+func inlineContentSchema(content *orderedmap.Map[string, *v3.MediaType]) *base.SchemaProxy {
+	if content == nil {
+		return nil
+	}
+	for _, mediaType := range content.FromOldest() {
+		if mediaType == nil || mediaType.Schema == nil || mediaType.Schema.IsReference() {
+			return nil
+		}
+		return mediaType.Schema
+	}
+	return nil
+}
+
+// collectPathItem records the schemas used by the operations of a path item, including
+// the requests and responses of their callbacks
+func collectPathItem(pathItem *v3.PathItem, used map[string]bool) {
+	if pathItem == nil {
+		return
+	}
+	for _, param := range pathItem.Parameters {
+		collectParameter(param, used)
+	}
+	for _, op := range pathItem.GetOperations().FromOldest() {
+		for _, param := range op.Parameters {
+			collectParameter(param, used)
+		}
+		if op.RequestBody != nil {
+			collectContent(op.RequestBody.Content, used)
+		}
+		if op.Responses != nil {
+			if op.Responses.Default != nil {
+				collectContent(op.Responses.Default.Content, used)
+			}
+			if op.Responses.Codes != nil {
+				for _, response := range op.Responses.Codes.FromOldest() {
+					if response != nil {
+						collectContent(response.Content, used)
+					}
+				}
+			}
+		}
+		if op.Callbacks != nil {
+			for _, callback := range op.Callbacks.FromOldest() {
+				if callback == nil || callback.Expression == nil {
+					continue
+				}
+				for _, callbackItem := range callback.Expression.FromOldest() {
+					collectPathItem(callbackItem, used)
+				}
+			}
+		}
+	}
+}
+
+// collectParameter records the schemas used by a parameter
+func collectParameter(param *v3.Parameter, used map[string]bool) {
+	if param == nil {
+		return
+	}
+	collectSchema(param.Schema, used)
+	collectContent(param.Content, used)
+}
+
+// collectContent records the schemas of every media type
+func collectContent(content *orderedmap.Map[string, *v3.MediaType], used map[string]bool) {
+	if content == nil {
+		return
+	}
+	for _, mediaType := range content.FromOldest() {
+		if mediaType != nil {
+			collectSchema(mediaType.Schema, used)
+		}
+	}
+}
+
+// collectSchema records the component schemas proxy references, following references
+// inside properties, items, compositions and additionalProperties. Each component is
+// walked once, so circular references terminate.
+func collectSchema(proxy *base.SchemaProxy, used map[string]bool) {
+	if proxy == nil {
+		return
+	}
+
+	if proxy.IsReference() {
+		name, found := strings.CutPrefix(proxy.GetReference(), schemaRefPrefix)
+		if !found || used[name] {
+			return
+		}
+		used[name] = true
+	}
+
+	schema := proxy.Schema()
+	if schema == nil {
+		return
+	}
+
+	if schema.Properties != nil {
+		for _, prop := range schema.Properties.FromOldest() {
+			collectSchema(prop, used)
+		}
+	}
+	if schema.Items != nil && schema.Items.IsA() {
+		collectSchema(schema.Items.A, used)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() {
+		collectSchema(schema.AdditionalProperties.A, used)
+	}
+	for _, variant := range schema.OneOf {
+		collectSchema(variant, used)
+	}
+	for _, variant := range schema.AnyOf {
+		collectSchema(variant, used)
+	}
+	for _, variant := range schema.AllOf {
+		collectSchema(variant, used)
+	}
+}
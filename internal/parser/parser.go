@@ -6,8 +6,18 @@ import (
 	"github.com/pb33f/libopenapi"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
 )
 
+// retainedSections are the top-level keys conversion reads; everything else is pruned
+var retainedSections = map[string]bool{
+	"openapi":           true,
+	"info":              true,
+	"servers":           true,
+	"components":        true,
+	"jsonSchemaDialect": true,
+}
+
 // Document wraps the libopenapi v3 document model
 type Document struct {
 	model *libopenapi.DocumentModel[v3.Document]
@@ -19,6 +29,21 @@ type SchemaEntry struct {
 	Proxy *base.SchemaProxy
 }
 
+// ServerEntry represents a server from the document's servers list
+type ServerEntry struct {
+	URL         string
+	Description string
+	Variables   map[string]string // variable name -> default value
+}
+
+// InfoEntry holds the metadata from the document's info object
+type InfoEntry struct {
+	Title       string
+	Version     string
+	Description string
+	Extensions  map[string]*yaml.Node // x- extensions, nil if there are none
+}
+
 // ParseDocument parses OpenAPI bytes and returns the document.
 // It validates that the document is OpenAPI 3.x and handles both YAML and JSON formats.
 func ParseDocument(openapi []byte) (*Document, error) {
@@ -39,6 +64,79 @@ func ParseDocument(openapi []byte) (*Document, error) {
 	return &Document{model: model}, nil
 }
 
+// PruneDocument removes the parts of an OpenAPI document that conversion does not read,
+// such as paths, webhooks and components other than schemas, so the full model is never
+// built for them. The result is re-encoded as YAML; references from schemas into removed
+// sections no longer resolve. The bool reports whether a removed path had a PATCH
+// operation with a request body, whose schema PatchSchemas would have returned.
+func PruneDocument(openapi []byte) ([]byte, bool, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(openapi, &root); err != nil {
+		return nil, false, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return openapi, false, nil
+	}
+
+	doc := root.Content[0]
+	patched := false
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "paths" {
+			patched = hasPatchBody(doc.Content[i+1])
+		}
+	}
+	doc.Content = retainKeys(doc.Content, func(key string) bool { return retainedSections[key] })
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "components" && doc.Content[i+1].Kind == yaml.MappingNode {
+			components := doc.Content[i+1]
+			components.Content = retainKeys(components.Content, func(key string) bool { return key == "schemas" })
+		}
+	}
+
+	pruned, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode pruned OpenAPI document: %w", err)
+	}
+	return pruned, patched, nil
+}
+
+// hasPatchBody reports whether a paths node has a PATCH operation with a request body
+func hasPatchBody(paths *yaml.Node) bool {
+	if paths.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 1; i < len(paths.Content); i += 2 {
+		item := paths.Content[i]
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			operation := item.Content[j+1]
+			if item.Content[j].Value != "patch" || operation.Kind != yaml.MappingNode {
+				continue
+			}
+			for k := 0; k+1 < len(operation.Content); k += 2 {
+				if operation.Content[k].Value == "requestBody" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// retainKeys filters the key/value pairs of a mapping node's content
+func retainKeys(content []*yaml.Node, keep func(string) bool) []*yaml.Node {
+	result := content[:0]
+	for i := 0; i+1 < len(content); i += 2 {
+		if keep(content[i].Value) {
+			result = append(result, content[i], content[i+1])
+		}
+	}
+	return result
+}
+
 // Schemas returns schemas from components/schemas in insertion order.
 // Returns an empty slice if there are no schemas defined.
 func (d *Document) Schemas() ([]*SchemaEntry, error) {
@@ -60,3 +158,47 @@ func (d *Document) Schemas() ([]*SchemaEntry, error) {
 
 	return entries, nil
 }
+
+// Info returns the metadata from the document's info object, empty if it has none
+func (d *Document) Info() InfoEntry {
+	info := d.model.Model.Info
+	if info == nil {
+		return InfoEntry{}
+	}
+	entry := InfoEntry{Title: info.Title, Version: info.Version, Description: info.Description}
+	if info.Extensions != nil {
+		entry.Extensions = make(map[string]*yaml.Node, info.Extensions.Len())
+		for name, node := range info.Extensions.FromOldest() {
+			entry.Extensions[name] = node
+		}
+	}
+	return entry
+}
+
+// Servers returns the document-level servers in declaration order.
+// Returns an empty slice if there are no servers defined.
+func (d *Document) Servers() []*ServerEntry {
+	entries := make([]*ServerEntry, 0, len(d.model.Model.Servers))
+	for _, server := range d.model.Model.Servers {
+		if server == nil {
+			continue
+		}
+
+		variables := make(map[string]string)
+		if server.Variables != nil {
+			for name, variable := range server.Variables.FromOldest() {
+				if variable != nil {
+					variables[name] = variable.Default
+				}
+			}
+		}
+
+		entries = append(entries, &ServerEntry{
+			URL:         server.URL,
+			Description: server.Description,
+			Variables:   variables,
+		})
+	}
+
+	return entries
+}
@@ -0,0 +1,162 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitiveFields(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		given    string
+		expected string
+		wantErr  string
+	}{
+		{
+			name: "password format",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Login:
+      type: object
+      properties:
+        user:
+          type: string
+        password:
+          type: string
+          format: password
+`,
+			expected: `message Login {
+  string user = 1 [json_name = "user"];
+  string password = 2 [json_name = "password", debug_redact = true];
+}`,
+		},
+		{
+			name: "sensitive extension",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Login:
+      type: object
+      properties:
+        pin:
+          type: integer
+          x-sensitive: true
+        hint:
+          type: string
+          x-sensitive: false
+`,
+			expected: `message Login {
+  int32 pin = 1 [json_name = "pin", debug_redact = true];
+  string hint = 2 [json_name = "hint"];
+}`,
+		},
+		{
+			name: "invalid sensitive extension",
+			given: `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Login:
+      type: object
+      properties:
+        pin:
+          type: integer
+          x-sensitive: always
+`,
+			wantErr: "x-sensitive must be a boolean, got: always",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(test.given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
+
+func TestSensitiveGoFields(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Credential:
+      oneOf:
+        - $ref: '#/components/schemas/Password'
+        - $ref: '#/components/schemas/Token'
+      discriminator:
+        propertyName: kind
+    Password:
+      type: object
+      properties:
+        kind:
+          type: string
+        secret:
+          type: string
+          format: password
+        attempts:
+          type: integer
+          x-sensitive: true
+    Token:
+      type: object
+      properties:
+        kind:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		GoPackagePath: "github.com/example/types/v1",
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	golang := string(result.Golang)
+
+	assert.Contains(t, golang, "\t\"log/slog\"\n")
+	assert.Contains(t, golang, `// String formats Password with sensitive fields redacted
+func (x *Password) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	type plain Password
+	c := plain(*x)
+	c.Secret = "[REDACTED]"
+	c.Attempts = 0
+	return fmt.Sprintf("%+v", c)
+}
+
+// LogValue implements slog.LogValuer with sensitive fields redacted
+func (x *Password) LogValue() slog.Value {
+	if x == nil {
+		return slog.AnyValue(nil)
+	}
+	return slog.GroupValue(
+		slog.Any("kind", x.Kind),
+		slog.String("secret", "[REDACTED]"),
+		slog.String("attempts", "[REDACTED]"),
+	)
+}
+`)
+	assert.NotContains(t, golang, "func (x *Token) String")
+	assert.NotContains(t, golang, "func (x *Credential) String")
+}
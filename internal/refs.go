@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// resolvedRef caches what the builder needs from a $ref. Specs commonly reference the
+// same schema from hundreds of properties, so each reference string is split and its
+// target inspected once per conversion.
+type resolvedRef struct {
+	name       string   // Last path segment, e.g. "User" for #/components/schemas/User
+	typeName   string   // Proto type of the target, the name reserved for its definition
+	nameErr    error    // Set when the reference is not #/components/schemas/Name
+	inspected  bool     // The target schema has been inspected
+	stringEnum bool     // Target is a string enum, mapped to a string field
+	enumValues []string // Values of a string enum target
+}
+
+// lookupRef returns the cache entry for a reference, splitting it on first use
+func (c *Context) lookupRef(ref string) *resolvedRef {
+	if cached, ok := c.refs[ref]; ok {
+		return cached
+	}
+
+	resolved := &resolvedRef{}
+	resolved.name, resolved.nameErr = extractReferenceName(ref)
+	if resolved.nameErr != nil {
+		parts := strings.Split(ref, "/")
+		resolved.name = parts[len(parts)-1]
+	}
+	if target, ok := c.Aliases[resolved.name]; ok {
+		resolved.name = target
+	}
+
+	c.refs[ref] = resolved
+	return resolved
+}
+
+// schemaOf returns the schema of a property, array item or map value. References to
+// component schemas return the schema of the component entry, so a component is built
+// once instead of again for the first reference to it.
+func (c *Context) schemaOf(proxy *base.SchemaProxy) *base.Schema {
+	if proxy.IsReference() {
+		if resolved := c.lookupRef(proxy.GetReference()); resolved.nameErr == nil {
+			if target, ok := c.components[resolved.name]; ok {
+				return target.Schema()
+			}
+		}
+	}
+	return proxy.Schema()
+}
+
+// refName returns the last path segment of a reference, the schema name for references
+// into components/schemas, or the schema a skipped alias references
+func (c *Context) refName(ref string) string {
+	return c.lookupRef(ref).name
+}
+
+// resolveRef returns the cached name, type and metadata for a reference. target is the
+// resolved schema; callers handle unresolved targets before calling.
+func (c *Context) resolveRef(ref string, target *base.Schema) *resolvedRef {
+	resolved := c.lookupRef(ref)
+	if !resolved.inspected {
+		resolved.inspected = true
+		resolved.typeName = resolved.name
+		if isStringEnum(target) {
+			resolved.stringEnum = true
+			resolved.enumValues = extractEnumValues(target)
+		} else {
+			resolved.typeName = c.definitionName(resolved.name)
+		}
+	}
+	return resolved
+}
+
+// definitionName returns the name reserved for the definition of a component schema,
+// or the schema name if it has no proto definition
+func (c *Context) definitionName(schemaName string) string {
+	if name, ok := c.names[schemaName]; ok {
+		return name
+	}
+	return schemaName
+}
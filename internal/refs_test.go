@@ -1,6 +1,11 @@
 package internal_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	conv "github.com/duh-rpc/openapi-proto.go"
@@ -130,3 +135,275 @@ components:
 	// The error comes from libopenapi build stage indicating the reference cannot be resolved
 	assert.Contains(t, err.Error(), "cannot resolve reference")
 }
+
+func TestConvertExternalReferenceBaseDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shared"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "common.yaml"), []byte(`
+components:
+  schemas:
+    Address:
+      type: object
+      properties:
+        country:
+          $ref: '#/components/schemas/Country'
+    Country:
+      type: object
+      properties:
+        code:
+          type: string
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "Money.yaml"), []byte(`
+type: object
+properties:
+  amount:
+    type: integer
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "User.yaml"), []byte(`
+type: object
+`), 0o644))
+	outside := filepath.Join(t.TempDir(), "secret.yaml")
+	require.NoError(t, os.WriteFile(outside, []byte(`
+Secret:
+  type: object
+`), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "shared", "link.yaml")))
+
+	for _, test := range []struct {
+		name     string
+		ref      string
+		expected string
+		err      string
+	}{
+		{
+			name: "component in other file",
+			ref:  "./shared/common.yaml#/components/schemas/Address",
+			expected: `message User {
+  Address field = 1 [json_name = "field"];
+}
+
+message Address {
+  Country country = 1 [json_name = "country"];
+}
+
+message Country {
+  string code = 1 [json_name = "code"];
+}
+`,
+		},
+		{
+			name: "whole file",
+			ref:  "shared/Money.yaml",
+			expected: `message User {
+  Money field = 1 [json_name = "field"];
+}
+
+message Money {
+  int32 amount = 1 [json_name = "amount"];
+}
+`,
+		},
+		{
+			name: "missing file",
+			ref:  "./missing.yaml#/Address",
+			err:  "failed to resolve reference './missing.yaml#/Address'",
+		},
+		{
+			name: "missing schema",
+			ref:  "./shared/common.yaml#/components/schemas/Phone",
+			err:  "'/components/schemas/Phone' not found",
+		},
+		{
+			name: "absolute path inside base dir",
+			ref:  filepath.Join(dir, "shared", "Money.yaml"),
+			expected: `message Money {
+  int32 amount = 1 [json_name = "amount"];
+}
+`,
+		},
+		{
+			name: "absolute path outside base dir",
+			ref:  outside + "#/Secret",
+			err:  "'" + outside + "' is outside the directory '" + dir + "'",
+		},
+		{
+			name: "parent directory",
+			ref:  "../secret.yaml#/Secret",
+			err:  "'" + filepath.Join(filepath.Dir(dir), "secret.yaml") + "' is outside the directory '" + dir + "'",
+		},
+		{
+			name: "symlink out of base dir",
+			ref:  "./shared/link.yaml#/Secret",
+			err:  "path escapes from parent",
+		},
+		{
+			name: "name clash",
+			ref:  "./shared/User.yaml",
+			err:  "schema 'User' from '" + filepath.Join(dir, "shared", "User.yaml") + "' conflicts with a schema of the same name",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `
+openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        field:
+          $ref: '` + test.ref + `'
+`
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				BaseDir:     dir,
+			})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
+
+func TestConvertRemoteReference(t *testing.T) {
+	var fetches, privateFetches int
+	// private is reachable as localhost, which is not an allowed host
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		privateFetches++
+		_, _ = w.Write([]byte(`
+Money:
+  type: object
+  properties:
+    secret:
+      type: string
+`))
+	}))
+	defer private.Close()
+	private.URL = strings.Replace(private.URL, "127.0.0.1", "localhost", 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		switch r.URL.Path {
+		case "/schemas/common.yaml":
+			_, _ = w.Write([]byte(`
+Money:
+  type: object
+  properties:
+    currency:
+      $ref: 'Currency.yaml'
+`))
+		case "/schemas/Currency.yaml":
+			_, _ = w.Write([]byte(`
+type: object
+properties:
+  code:
+    type: string
+`))
+		case "/schemas/redirect.yaml":
+			http.Redirect(w, r, private.URL+"/secret.yaml", http.StatusFound)
+		case "/schemas/allowed-redirect.yaml":
+			http.Redirect(w, r, "/schemas/common.yaml", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	given := func(ref string) []byte {
+		return []byte(`
+openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        total:
+          $ref: '` + ref + `'
+`)
+	}
+	expected := `message Order {
+  Money total = 1 [json_name = "total"];
+}
+
+message Money {
+  Currency currency = 1 [json_name = "currency"];
+}
+
+message Currency {
+  string code = 1 [json_name = "code"];
+}
+`
+	cacheDir := t.TempDir()
+
+	for _, test := range []struct {
+		name    string
+		ref     string
+		remote  conv.RemoteRefOptions
+		fetches int
+		err     string
+	}{
+		{
+			name:    "allowed host",
+			ref:     server.URL + "/schemas/common.yaml#/Money",
+			remote:  conv.RemoteRefOptions{AllowedHosts: []string{"127.0.0.1"}, CacheDir: cacheDir},
+			fetches: 2,
+		},
+		{
+			name:   "cached",
+			ref:    server.URL + "/schemas/common.yaml#/Money",
+			remote: conv.RemoteRefOptions{AllowedHosts: []string{"127.0.0.1"}, CacheDir: cacheDir},
+		},
+		{
+			name:   "host not allowed",
+			ref:    server.URL + "/schemas/common.yaml#/Money",
+			remote: conv.RemoteRefOptions{AllowedHosts: []string{"schemas.example.com"}},
+			err:    "host '127.0.0.1' is not in RemoteRefs.AllowedHosts",
+		},
+		{
+			name:    "redirect to allowed host",
+			ref:     server.URL + "/schemas/allowed-redirect.yaml#/Money",
+			remote:  conv.RemoteRefOptions{AllowedHosts: []string{"127.0.0.1"}},
+			fetches: 3,
+		},
+		{
+			name:    "redirect to host not allowed",
+			ref:     server.URL + "/schemas/redirect.yaml#/Money",
+			remote:  conv.RemoteRefOptions{AllowedHosts: []string{"127.0.0.1"}},
+			fetches: 1,
+			err:     "host 'localhost' is not in RemoteRefs.AllowedHosts",
+		},
+		{
+			name:    "not found",
+			ref:     server.URL + "/schemas/missing.yaml#/Money",
+			remote:  conv.RemoteRefOptions{AllowedHosts: []string{"127.0.0.1"}},
+			fetches: 1,
+			err:     "404 Not Found",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fetches, privateFetches = 0, 0
+			result, err := conv.Convert(given(test.ref), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				RemoteRefs:  test.remote,
+			})
+			assert.Equal(t, test.fetches, fetches)
+			assert.Zero(t, privateFetches)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), expected)
+		})
+	}
+}
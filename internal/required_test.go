@@ -6,6 +6,7 @@ import (
 	conv "github.com/duh-rpc/openapi-proto.go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 func TestConvertRequiredIgnored(t *testing.T) {
@@ -278,3 +279,118 @@ message Mixed {
 		})
 	}
 }
+
+func TestConvertOptionalUnlessRequired(t *testing.T) {
+	given := `openapi: 3.1.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Status:
+      type: integer
+      enum: [1, 2]
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+    User:
+      type: object
+      required: [email, status]
+      properties:
+        email:
+          type: string
+        age:
+          type: integer
+        status:
+          $ref: '#/components/schemas/Status'
+        previous:
+          $ref: '#/components/schemas/Status'
+        createdAt:
+          type: string
+          format: date-time
+        address:
+          $ref: '#/components/schemas/Address'
+        tags:
+          type: array
+          items:
+            type: string
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+        profile:
+          type: object
+          required: [theme]
+          properties:
+            theme:
+              type: string
+            locale:
+              type: string
+`
+
+	for _, test := range []struct {
+		name     string
+		opts     conv.ConvertOptions
+		expected string
+	}{
+		{
+			name: "disabled",
+			expected: `message User {
+  message Profile {
+    string theme = 1 [json_name = "theme"];
+    string locale = 2 [json_name = "locale"];
+  }
+
+  string email = 1 [json_name = "email"];
+  int32 age = 2 [json_name = "age"];
+  Status status = 3 [json_name = "status"];
+  Status previous = 4 [json_name = "previous"];
+  google.protobuf.Timestamp createdAt = 5 [json_name = "createdAt"];
+  Address address = 6 [json_name = "address"];
+  repeated string tags = 7 [json_name = "tags"];
+  map<string, string> labels = 8 [json_name = "labels"];
+  Profile profile = 9 [json_name = "profile"];
+}
+`,
+		},
+		{
+			name: "enabled",
+			opts: conv.ConvertOptions{OptionalUnlessRequired: true},
+			expected: `message User {
+  message Profile {
+    string theme = 1 [json_name = "theme"];
+    optional string locale = 2 [json_name = "locale"];
+  }
+
+  string email = 1 [json_name = "email"];
+  optional int32 age = 2 [json_name = "age"];
+  Status status = 3 [json_name = "status"];
+  optional Status previous = 4 [json_name = "previous"];
+  google.protobuf.Timestamp createdAt = 5 [json_name = "createdAt"];
+  Address address = 6 [json_name = "address"];
+  repeated string tags = 7 [json_name = "tags"];
+  map<string, string> labels = 8 [json_name = "labels"];
+  Profile profile = 9 [json_name = "profile"];
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.PackageName = "testpkg"
+			test.opts.PackagePath = "github.com/example/proto/v1"
+			result, err := conv.Convert([]byte(given), test.opts)
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+
+			files, err := result.Files()
+			require.NoError(t, err)
+			desc, err := files.FindDescriptorByName("testpkg.User")
+			require.NoError(t, err)
+			fields := desc.(protoreflect.MessageDescriptor).Fields()
+			assert.Equal(t, test.opts.OptionalUnlessRequired, fields.ByName("age").HasPresence())
+			assert.False(t, fields.ByName("email").HasPresence())
+		})
+	}
+}
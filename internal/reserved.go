@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LockedMessage records the fields of a message as generated by a previous conversion
+type LockedMessage struct {
+	Fields          map[string]int // Proto field name to field number
+	ReservedNumbers []int
+	ReservedNames   []string
+}
+
+// ApplyReservations reserves the numbers and names of fields recorded in lock that are
+// no longer generated, so later fields cannot reuse them. Messages are matched by full
+// name, e.g. "User.Address" for nested messages, and reservations already in lock are
+// carried forward. A number now used by another field cannot be reserved and is
+// reported as a warning instead.
+func ApplyReservations(messages []*ProtoMessage, lock map[string]LockedMessage) []string {
+	var warnings []string
+	for _, msg := range messages {
+		warnings = append(warnings, applyReservations(msg, "", lock)...)
+	}
+	return warnings
+}
+
+// applyReservations reserves removed fields of msg and its nested messages. scope is
+// the full name of the enclosing message, empty at the top level.
+func applyReservations(msg *ProtoMessage, scope string, lock map[string]LockedMessage) []string {
+	fullName := msg.Name
+	if scope != "" {
+		fullName = scope + "." + msg.Name
+	}
+
+	var warnings []string
+	for _, nested := range msg.Nested {
+		warnings = append(warnings, applyReservations(nested, fullName, lock)...)
+	}
+
+	locked, found := lock[fullName]
+	if !found {
+		return warnings
+	}
+
+	fieldsByNumber := make(map[int]string, len(msg.Fields))
+	fieldsByName := make(map[string]bool, len(msg.Fields))
+	for _, field := range msg.Fields {
+		fieldsByNumber[field.Number] = field.Name
+		fieldsByName[field.Name] = true
+	}
+
+	numbers := make(map[int]bool)
+	names := make(map[string]bool)
+	for _, number := range locked.ReservedNumbers {
+		if name, used := fieldsByNumber[number]; used {
+			warnings = append(warnings, fmt.Sprintf("%s: field %s uses number %d, which was reserved for a removed field", fullName, name, number))
+			continue
+		}
+		numbers[number] = true
+	}
+	for _, name := range locked.ReservedNames {
+		if !fieldsByName[name] {
+			names[name] = true
+		}
+	}
+
+	removed := make([]string, 0, len(locked.Fields))
+	for name := range locked.Fields {
+		if !fieldsByName[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+
+	for _, name := range removed {
+		number := locked.Fields[name]
+		names[name] = true
+		if current, used := fieldsByNumber[number]; used {
+			warnings = append(warnings, fmt.Sprintf("%s: removed field %s had number %d, which is now used by %s", fullName, name, number, current))
+			continue
+		}
+		numbers[number] = true
+	}
+
+	msg.ReservedNumbers = nil
+	for number := range numbers {
+		msg.ReservedNumbers = append(msg.ReservedNumbers, number)
+	}
+	sort.Ints(msg.ReservedNumbers)
+
+	msg.ReservedNames = nil
+	for name := range names {
+		msg.ReservedNames = append(msg.ReservedNames, name)
+	}
+	sort.Strings(msg.ReservedNames)
+
+	return warnings
+}
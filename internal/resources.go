@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+// resourceImport declares the google.api.resource_reference extension
+const resourceImport = "google/api/resource.proto"
+
+// resourceIDSuffixes are the property name suffixes that mark a resource reference
+var resourceIDSuffixes = []string{"_id", "Id", "ID"}
+
+// ResourceReferences controls which fields are annotated with google.api.resource_reference
+type ResourceReferences struct {
+	Detect  bool   // Annotate string fields named after a schema plus an Id suffix
+	Service string // Prefix of the resource type, e.g. library.example.com
+}
+
+// resourceAnnotator walks messages alongside their schemas to annotate reference fields
+type resourceAnnotator struct {
+	opts     ResourceReferences
+	schemas  map[string]bool // PascalCase names of every component schema
+	warnings []string
+	used     bool
+}
+
+// ApplyResourceReferences annotates string fields that reference another resource with
+// (google.api.resource_reference). A field is a reference when its property has an
+// x-proto-resource-ref extension naming the resource, or, when Detect is set, when its
+// name is a schema name plus an Id suffix (userId for User). Reference fields are renamed
+// to drop the suffix (user), keeping their json_name, and a warning describes each rename.
+func ApplyResourceReferences(entries []*parser.SchemaEntry, messages []*ProtoMessage, opts ResourceReferences, ctx *Context) ([]string, error) {
+	a := &resourceAnnotator{
+		opts:    opts,
+		schemas: make(map[string]bool, len(entries)),
+	}
+	proxies := make(map[string]*base.SchemaProxy, len(entries))
+	for _, entry := range entries {
+		a.schemas[ToPascalCase(entry.Name)] = true
+		proxies[entry.Name] = entry.Proxy
+	}
+
+	for _, msg := range messages {
+		proxy, ok := proxies[msg.OriginalSchema]
+		if !ok {
+			continue
+		}
+		if err := a.annotateMessage(msg, proxy.Schema()); err != nil {
+			return nil, WrapSchemaError(msg.OriginalSchema, err)
+		}
+	}
+
+	if a.used {
+		ctx.AddImport(resourceImport)
+	}
+	return a.warnings, nil
+}
+
+// annotateMessage annotates the fields of msg, recursing into nested messages
+func (a *resourceAnnotator) annotateMessage(msg *ProtoMessage, schema *base.Schema) error {
+	if schema == nil || schema.Properties == nil {
+		return nil
+	}
+
+	for _, field := range msg.Fields {
+		proxy, ok := schema.Properties.Get(field.JSONName)
+		if !ok || proxy.Schema() == nil {
+			continue
+		}
+
+		resource, explicit, err := extractResourceRef(proxy)
+		if err != nil {
+			return fmt.Errorf("property '%s': %w", field.JSONName, err)
+		}
+
+		if field.Repeated {
+			items := proxy.Schema().Items
+			if items == nil || items.A == nil {
+				continue
+			}
+			proxy = items.A
+		}
+
+		for _, nested := range msg.Nested {
+			if nested.Name == field.Type {
+				if err := a.annotateMessage(nested, proxy.Schema()); err != nil {
+					return fmt.Errorf("property '%s': %w", field.JSONName, err)
+				}
+			}
+		}
+
+		if err := a.annotateField(msg, field, resource, explicit); err != nil {
+			return fmt.Errorf("property '%s': %w", field.JSONName, err)
+		}
+	}
+
+	return nil
+}
+
+// annotateField adds the resource reference option to a single field when it references
+// a resource. resource is the x-proto-resource-ref value when explicit is set.
+func (a *resourceAnnotator) annotateField(msg *ProtoMessage, field *ProtoField, resource string, explicit bool) error {
+	trimmed, hasSuffix := trimResourceIDSuffix(field.JSONName)
+	if !explicit {
+		if !a.opts.Detect || field.Repeated || field.Type != "string" || !hasSuffix || !a.schemas[ToPascalCase(trimmed)] {
+			return nil
+		}
+		resource = ToPascalCase(trimmed)
+	}
+
+	if field.Type != "string" {
+		return Errorf(CodeInvalidExtension, "x-proto-resource-ref requires a string field, got: %s", field.Type)
+	}
+
+	if !strings.Contains(resource, "/") {
+		resource = a.opts.Service + "/" + resource
+	}
+	field.Options = append(field.Options, fmt.Sprintf("(google.api.resource_reference) = {type: \"%s\"}", resource))
+	a.used = true
+
+	if !hasSuffix || field.Name != field.JSONName {
+		return nil
+	}
+	if hasField(msg, trimmed) {
+		a.warnings = append(a.warnings, fmt.Sprintf("%s: cannot rename resource reference field %s to %s, the name is already used", msg.Name, field.Name, trimmed))
+		return nil
+	}
+	a.warnings = append(a.warnings, fmt.Sprintf("%s: renamed resource reference field %s to %s", msg.Name, field.Name, trimmed))
+	field.Name = trimmed
+	return nil
+}
+
+// extractResourceRef reads the x-proto-resource-ref extension, either a schema name
+// such as User or a full resource type such as library.example.com/Book
+func extractResourceRef(proxy *base.SchemaProxy) (string, bool, error) {
+	schema := proxy.Schema()
+	if schema == nil || schema.Extensions == nil {
+		return "", false, nil
+	}
+
+	node, found := schema.Extensions.Get("x-proto-resource-ref")
+	if !found || node == nil {
+		return "", false, nil
+	}
+
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return "", false, Errorf(CodeInvalidExtension, "x-proto-resource-ref must be a resource type string")
+	}
+
+	return node.Value, true, nil
+}
+
+// trimResourceIDSuffix strips an Id suffix from a property name, so userId and user_id become user
+func trimResourceIDSuffix(name string) (string, bool) {
+	for _, suffix := range resourceIDSuffixes {
+		if trimmed, ok := strings.CutSuffix(name, suffix); ok && trimmed != "" {
+			return trimmed, true
+		}
+	}
+	return name, false
+}
@@ -0,0 +1,133 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceReferences(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+    Book:
+      type: object
+      properties:
+        authorId:
+          type: string
+          x-proto-resource-ref: User
+        shelf:
+          type: string
+          x-proto-resource-ref: library.example.com/Shelf
+        userId:
+          type: string
+        publisherId:
+          type: string
+        copyCount:
+          type: integer
+`
+
+	for _, test := range []struct {
+		name     string
+		opts     conv.ResourceReferenceOptions
+		expected string
+		warnings []string
+	}{
+		{
+			name: "extension only",
+			expected: `message Book {
+  string author = 1 [json_name = "authorId", (google.api.resource_reference) = {type: "testpkg/User"}];
+  string shelf = 2 [json_name = "shelf", (google.api.resource_reference) = {type: "library.example.com/Shelf"}];
+  string userId = 3 [json_name = "userId"];
+  string publisherId = 4 [json_name = "publisherId"];
+  int32 copyCount = 5 [json_name = "copyCount"];
+}
+`,
+			warnings: []string{"Book: renamed resource reference field authorId to author"},
+		},
+		{
+			name: "detect with service",
+			opts: conv.ResourceReferenceOptions{Detect: true, Service: "library.example.com"},
+			expected: `message Book {
+  string author = 1 [json_name = "authorId", (google.api.resource_reference) = {type: "library.example.com/User"}];
+  string shelf = 2 [json_name = "shelf", (google.api.resource_reference) = {type: "library.example.com/Shelf"}];
+  string user = 3 [json_name = "userId", (google.api.resource_reference) = {type: "library.example.com/User"}];
+  string publisherId = 4 [json_name = "publisherId"];
+  int32 copyCount = 5 [json_name = "copyCount"];
+}
+`,
+			warnings: []string{
+				"Book: renamed resource reference field authorId to author",
+				"Book: renamed resource reference field userId to user",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:        "testpkg",
+				PackagePath:        "github.com/example/proto/v1",
+				ResourceReferences: test.opts,
+			})
+			require.NoError(t, err)
+
+			proto := string(result.Protobuf)
+			assert.Contains(t, proto, "import \"google/api/resource.proto\";\n")
+			assert.Contains(t, proto, test.expected)
+			assert.Equal(t, test.warnings, result.Warnings)
+		})
+	}
+}
+
+func TestResourceReferenceErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		property string
+		wantErr  string
+	}{
+		{
+			name: "not a string field",
+			property: `        count:
+          type: integer
+          x-proto-resource-ref: User
+`,
+			wantErr: "schema 'Book': property 'count': x-proto-resource-ref requires a string field, got: int32",
+		},
+		{
+			name: "not a string value",
+			property: `        ownerId:
+          type: string
+          x-proto-resource-ref: [User]
+`,
+			wantErr: "schema 'Book': property 'ownerId': x-proto-resource-ref must be a resource type string",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Book:
+      type: object
+      properties:
+` + test.property
+
+			_, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			require.ErrorContains(t, err, test.wantErr)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	conv "github.com/duh-rpc/openapi-proto.go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 func TestConvertScalarTypes(t *testing.T) {
@@ -61,6 +62,7 @@ components:
 package testpkg;
 
 import "google/protobuf/timestamp.proto";
+import "google/type/date.proto";
 
 option go_package = "github.com/example/proto/v1";
 
@@ -72,7 +74,7 @@ message AllTypes {
   string stringField = 5 [json_name = "stringField"];
   bytes bytesField = 6 [json_name = "bytesField"];
   bytes binaryField = 7 [json_name = "binaryField"];
-  google.protobuf.Timestamp dateField = 8 [json_name = "dateField"];
+  google.type.Date dateField = 8 [json_name = "dateField"];
   google.protobuf.Timestamp dateTimeField = 9 [json_name = "dateTimeField"];
   bool boolField = 10 [json_name = "boolField"];
 }
@@ -131,6 +133,41 @@ message Thing {
   double value = 1 [json_name = "value"];
 }
 
+`,
+		},
+		{
+			name: "time and duration",
+			given: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Shift:
+      type: object
+      properties:
+        start:
+          type: string
+          format: time
+        length:
+          type: string
+          format: duration
+`,
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+import "google/protobuf/duration.proto";
+import "google/type/timeofday.proto";
+
+option go_package = "github.com/example/proto/v1";
+
+message Shift {
+  google.type.TimeOfDay start = 1 [json_name = "start"];
+  google.protobuf.Duration length = 2 [json_name = "length"];
+}
+
 `,
 		},
 		{
@@ -184,3 +221,314 @@ message Event {
 		})
 	}
 }
+
+func TestConvertDateTimeFormats(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		format   string
+		example  string
+		expected string
+		fixture  string
+		json     string
+	}{
+		{
+			name:     "date",
+			format:   "date",
+			example:  "'2024-03-01'",
+			expected: "google.type.Date",
+			fixture:  "value {\n  year: 2024\n  month: 3\n  day: 1\n}\n",
+			json:     "{\n  \"value\": {\n    \"year\": 2024,\n    \"month\": 3,\n    \"day\": 1\n  }\n}\n",
+		},
+		{
+			name:     "time",
+			format:   "time",
+			example:  "'13:45:30.5'",
+			expected: "google.type.TimeOfDay",
+			fixture:  "value {\n  hours: 13\n  minutes: 45\n  seconds: 30\n  nanos: 500000000\n}\n",
+			json:     "{\n  \"value\": {\n    \"hours\": 13,\n    \"minutes\": 45,\n    \"seconds\": 30,\n    \"nanos\": 500000000\n  }\n}\n",
+		},
+		{
+			name:     "ISO 8601 duration",
+			format:   "duration",
+			example:  "PT1H30M",
+			expected: "google.protobuf.Duration",
+			fixture:  "value {\n  seconds: 5400\n  nanos: 0\n}\n",
+			json:     "{\n  \"value\": \"5400s\"\n}\n",
+		},
+		{
+			name:     "Go duration",
+			format:   "duration",
+			example:  "1.5s",
+			expected: "google.protobuf.Duration",
+			fixture:  "value {\n  seconds: 1\n  nanos: 500000000\n}\n",
+			json:     "{\n  \"value\": \"1.5s\"\n}\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Window:
+      type: object
+      properties:
+        value:
+          type: string
+          format: ` + test.format + `
+          example: ` + test.example + "\n"
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:  "testpkg",
+				PackagePath:  "github.com/example/proto/v1",
+				EmitFixtures: true,
+				EmitExamples: true,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected+` value = 1 [json_name = "value"];`)
+			assert.Equal(t, test.fixture, string(result.Fixtures["Window"]))
+			assert.Equal(t, test.json, string(result.Examples["Window"]))
+
+			files, err := result.Files()
+			require.NoError(t, err)
+			desc, err := files.FindDescriptorByName("testpkg.Window")
+			require.NoError(t, err)
+			field := desc.(protoreflect.MessageDescriptor).Fields().ByName("value")
+			assert.Equal(t, protoreflect.FullName(test.expected), field.Message().FullName())
+		})
+	}
+}
+
+func TestConvertProtoTypeOverride(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		property string
+		expected string
+		wantErr  string
+	}{
+		{
+			name: "unsigned integer",
+			property: `
+          type: integer
+          format: int64
+          x-proto-type: uint64`,
+			expected: `uint64 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "zigzag integer",
+			property: `
+          type: integer
+          x-proto-type: sint32`,
+			expected: `sint32 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "fixed width array items",
+			property: `
+          type: array
+          items:
+            type: integer
+            x-proto-type: fixed64`,
+			expected: `repeated fixed64 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "map values",
+			property: `
+          type: object
+          additionalProperties:
+            type: number
+            x-proto-type: float`,
+			expected: `map<string, float> value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "bytes replaces format",
+			property: `
+          type: string
+          format: uuid
+          x-proto-type: bytes`,
+			expected: `bytes value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "string keeps a date as text",
+			property: `
+          type: string
+          format: date
+          x-proto-type: string`,
+			expected: `string value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "incompatible with declared type",
+			property: `
+          type: string
+          x-proto-type: uint64`,
+			wantErr: "x-proto-type uint64 cannot hold values of type string",
+		},
+		{
+			name: "integer as floating point",
+			property: `
+          type: integer
+          x-proto-type: double`,
+			wantErr: "x-proto-type double cannot hold values of type integer",
+		},
+		{
+			name: "not a scalar",
+			property: `
+          type: integer
+          x-proto-type: uint128`,
+			wantErr: "x-proto-type must be a proto3 scalar type, got: uint128",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Metric:
+      type: object
+      properties:
+        value:` + test.property + "\n"
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			})
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+			assert.NotContains(t, string(result.Protobuf), "import")
+
+			_, err = result.Files()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConvertUnsignedIntegers(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		property string
+		minimum  bool
+		expected string
+		wantErr  string
+	}{
+		{
+			name: "unsigned format",
+			property: `
+          type: integer
+          format: uint64`,
+			expected: `uint64 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "minimum ignored by default",
+			property: `
+          type: integer
+          minimum: 0`,
+			expected: `  int32 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "minimum zero",
+			property: `
+          type: integer
+          minimum: 0`,
+			minimum:  true,
+			expected: `uint32 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "positive minimum with int64",
+			property: `
+          type: integer
+          format: int64
+          minimum: 1`,
+			minimum:  true,
+			expected: `uint64 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "negative minimum",
+			property: `
+          type: integer
+          minimum: -10`,
+			minimum:  true,
+			expected: `  int32 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "array items",
+			property: `
+          type: array
+          items:
+            type: integer
+            minimum: 0`,
+			minimum:  true,
+			expected: `repeated uint32 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "extension",
+			property: `
+          type: integer
+          format: int64
+          x-proto-unsigned: true`,
+			expected: `uint64 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "extension keeps signed",
+			property: `
+          type: integer
+          minimum: 0
+          x-proto-unsigned: false`,
+			minimum:  true,
+			expected: `  int32 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "proto type wins",
+			property: `
+          type: integer
+          minimum: 0
+          x-proto-type: fixed32`,
+			minimum:  true,
+			expected: `fixed32 value = 1 [json_name = "value"];`,
+		},
+		{
+			name: "extension not a boolean",
+			property: `
+          type: integer
+          x-proto-unsigned: "yes"`,
+			wantErr: "x-proto-unsigned must be a boolean, got: yes",
+		},
+		{
+			name: "extension on a string",
+			property: `
+          type: string
+          x-proto-unsigned: true`,
+			wantErr: "x-proto-unsigned can only be set on integers, got type: string",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Counter:
+      type: object
+      properties:
+        value:` + test.property + "\n"
+
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:         "testpkg",
+				PackagePath:         "github.com/example/proto/v1",
+				UnsignedFromMinimum: test.minimum,
+			})
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), test.expected)
+		})
+	}
+}
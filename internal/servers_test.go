@@ -0,0 +1,102 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServersFileComment(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+    description: Production
+  - url: https://{region}.example.com/{basePath}
+    description: |
+      Regional
+      endpoint
+    variables:
+      region:
+        default: us-east
+      basePath:
+        default: v2
+  - url: http://localhost:8080
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+	expected := `// Servers:
+//   https://api.example.com/v1 - Production
+//   https://{region}.example.com/{basePath} - Regional endpoint
+//   http://localhost:8080
+
+syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+message User {
+  string name = 1 [json_name = "name"];
+}
+
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(result.Protobuf))
+
+	assert.Equal(t, []conv.Server{
+		{
+			URL:         "https://api.example.com/v1",
+			BaseURL:     "https://api.example.com/v1",
+			Description: "Production",
+		},
+		{
+			URL:         "https://{region}.example.com/{basePath}",
+			BaseURL:     "https://us-east.example.com/v2",
+			Description: "Regional\nendpoint\n",
+		},
+		{
+			URL:     "http://localhost:8080",
+			BaseURL: "http://localhost:8080",
+		},
+	}, result.Servers)
+}
+
+func TestServersAbsent(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Servers)
+	assert.True(t, strings.HasPrefix(string(result.Protobuf), "syntax = \"proto3\";"))
+}
@@ -0,0 +1,87 @@
+package internal
+
+// RemoveDefinitions removes the top-level messages and enums named in names from
+// messages and definitions. When qualifier is not empty the removed definitions are
+// declared in that proto package instead: fields referencing them are rewritten to the
+// qualified name and importPath is imported. The returned map links the schema of each
+// removed message to its qualified name, or to an empty string without a qualifier.
+func RemoveDefinitions(ctx *Context, names map[string]bool, qualifier, importPath string) map[string]string {
+	aliases := make(map[string]string)
+	renames := make(map[string]string)
+	for _, msg := range ctx.Messages {
+		if !names[msg.Name] {
+			continue
+		}
+		aliases[msg.OriginalSchema] = ""
+		if qualifier != "" {
+			renames[msg.Name] = qualifier + "." + msg.Name
+			renames[msg.OriginalSchema] = qualifier + "." + msg.Name
+			aliases[msg.OriginalSchema] = qualifier + "." + msg.Name
+		}
+	}
+	for _, enum := range ctx.Enums {
+		if names[enum.Name] && qualifier != "" {
+			renames[enum.Name] = qualifier + "." + enum.Name
+		}
+	}
+
+	messages := make([]*ProtoMessage, 0, len(ctx.Messages))
+	for _, msg := range ctx.Messages {
+		if !names[msg.Name] {
+			messages = append(messages, msg)
+		}
+	}
+	ctx.Messages = messages
+
+	enums := make([]*ProtoEnum, 0, len(ctx.Enums))
+	for _, enum := range ctx.Enums {
+		if !names[enum.Name] {
+			enums = append(enums, enum)
+		}
+	}
+	ctx.Enums = enums
+
+	definitions := make([]interface{}, 0, len(ctx.Definitions))
+	for _, def := range ctx.Definitions {
+		switch d := def.(type) {
+		case *ProtoMessage:
+			if names[d.Name] {
+				continue
+			}
+		case *ProtoEnum:
+			if names[d.Name] {
+				continue
+			}
+		}
+		definitions = append(definitions, def)
+	}
+	ctx.Definitions = definitions
+
+	if len(renames) == 0 {
+		return aliases
+	}
+	referenced := false
+	for _, msg := range ctx.Messages {
+		referenced = renameReferences(msg, renames) || referenced
+	}
+	if referenced {
+		ctx.AddImport(importPath)
+	}
+	return aliases
+}
+
+// renameReferences rewrites field types in msg and its nested messages, reporting
+// whether any field was rewritten
+func renameReferences(msg *ProtoMessage, renames map[string]string) bool {
+	renamed := false
+	for _, field := range msg.Fields {
+		if name, ok := renames[field.Type]; ok {
+			field.Type = name
+			renamed = true
+		}
+	}
+	for _, nested := range msg.Nested {
+		renamed = renameReferences(nested, renames) || renamed
+	}
+	return renamed
+}
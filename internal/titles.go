@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+// genericNames are schema keys too vague to name a message on their own
+var genericNames = map[string]bool{
+	"Body":     true,
+	"Data":     true,
+	"Item":     true,
+	"Model":    true,
+	"Object":   true,
+	"Payload":  true,
+	"Request":  true,
+	"Response": true,
+	"Schema":   true,
+	"Type":     true,
+	"Value":    true,
+}
+
+// ApplyTitleNames renames top-level messages whose schema key is unusable as a message
+// name, because it starts with a digit or is generic such as "Object" or "Data", to the
+// PascalCase form of the schema's title. Messages without a title, or whose title is
+// itself unusable or already names another definition, keep their name. References to
+// renamed messages are rewritten. The returned map links each renamed schema to its new
+// message name.
+func ApplyTitleNames(ctx *Context, entries []*parser.SchemaEntry) map[string]string {
+	titles := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if schema := entry.Proxy.Schema(); schema != nil && schema.Title != "" {
+			titles[entry.Name] = schema.Title
+		}
+	}
+
+	aliases := make(map[string]string)
+	renames := make(map[string]string)
+	for _, msg := range ctx.Messages {
+		if !unusableName(msg.Name) {
+			continue
+		}
+		name := WordsToPascalCase(titles[msg.OriginalSchema])
+		if unusableName(name) || nameInUse(ctx, name) {
+			continue
+		}
+		renames[msg.Name] = name
+		renames[msg.OriginalSchema] = name
+		msg.Name = name
+		aliases[msg.OriginalSchema] = name
+	}
+
+	if len(renames) == 0 {
+		return nil
+	}
+	for _, msg := range ctx.Messages {
+		renameFieldTypes(msg, renames)
+	}
+	return aliases
+}
+
+// unusableName reports whether name is empty, starts with a digit or is generic
+func unusableName(name string) bool {
+	if name == "" {
+		return true
+	}
+	first := []rune(name)[0]
+	return unicode.IsDigit(first) || genericNames[name]
+}
+
+// WordsToPascalCase converts free form text such as "Order confirmation" to a PascalCase
+// message name, dropping characters that are not letters or digits
+func WordsToPascalCase(title string) string {
+	var result strings.Builder
+	capitalizeNext := true
+	for _, r := range title {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			capitalizeNext = true
+			continue
+		}
+		if capitalizeNext {
+			r = toUpperASCII(foldASCII(r))
+			capitalizeNext = false
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
@@ -0,0 +1,163 @@
+package internal_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTitleNames(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    200_response:
+      title: order confirmation
+      type: object
+      properties:
+        id:
+          type: string
+    Object:
+      title: Shipping-Label
+      type: object
+      properties:
+        carrier:
+          type: string
+    Data:
+      type: object
+      properties:
+        value:
+          type: string
+    User:
+      title: Account
+      type: object
+      properties:
+        confirmation:
+          $ref: '#/components/schemas/200_response'
+        label:
+          $ref: '#/components/schemas/Object'
+`
+
+	for _, test := range []struct {
+		name     string
+		titles   bool
+		expected string
+		aliases  map[string]string
+	}{
+		{
+			name: "disabled",
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+message 200Response {
+  string id = 1 [json_name = "id"];
+}
+
+message Object {
+  string carrier = 1 [json_name = "carrier"];
+}
+
+message Data {
+  string value = 1 [json_name = "value"];
+}
+
+message User {
+  200Response confirmation = 1 [json_name = "confirmation"];
+  Object label = 2 [json_name = "label"];
+}
+
+`,
+			aliases: map[string]string{},
+		},
+		{
+			name:   "enabled",
+			titles: true,
+			expected: `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+message OrderConfirmation {
+  string id = 1 [json_name = "id"];
+}
+
+message ShippingLabel {
+  string carrier = 1 [json_name = "carrier"];
+}
+
+message Data {
+  string value = 1 [json_name = "value"];
+}
+
+message User {
+  OrderConfirmation confirmation = 1 [json_name = "confirmation"];
+  ShippingLabel label = 2 [json_name = "label"];
+}
+
+`,
+			aliases: map[string]string{
+				"200_response": "OrderConfirmation",
+				"Object":       "ShippingLabel",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				TitleNames:  test.titles,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(result.Protobuf))
+
+			aliases := make(map[string]string)
+			for name, info := range result.TypeMap {
+				if info.AliasOf != "" {
+					aliases[name] = info.AliasOf
+				}
+			}
+			assert.Equal(t, test.aliases, aliases)
+		})
+	}
+}
+
+func TestTitleNamesInUse(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Item:
+      title: Product
+      type: object
+      properties:
+        sku:
+          type: string
+    Product:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		TitleNames:  true,
+	})
+	require.NoError(t, err)
+
+	proto := string(result.Protobuf)
+	assert.Contains(t, proto, "message Item {")
+	assert.Contains(t, proto, "message Product {")
+	assert.Empty(t, result.TypeMap["Item"].AliasOf)
+}
@@ -0,0 +1,83 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertLimits(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        profile:
+          type: object
+          properties:
+            location:
+              type: object
+              properties:
+                city:
+                  type: string
+    Order:
+      type: object
+      properties:
+        item:
+          type: array
+          items:
+            type: object
+            properties:
+              sku:
+                type: string
+`
+
+	for _, test := range []struct {
+		name    string
+		limits  conv.Limits
+		wantErr string
+	}{
+		{
+			name:   "within limits",
+			limits: conv.Limits{MaxSpecBytes: len(given), MaxDepth: 2, MaxMessages: 5},
+		},
+		{
+			name:    "spec too large",
+			limits:  conv.Limits{MaxSpecBytes: 100},
+			wantErr: "exceeds limit of 100",
+		},
+		{
+			name:    "nesting too deep",
+			limits:  conv.Limits{MaxDepth: 1},
+			wantErr: "schema 'User': property 'profile' property 'location': nesting depth exceeds limit of 1",
+		},
+		{
+			name:    "too many messages",
+			limits:  conv.Limits{MaxMessages: 4},
+			wantErr: "schema 'Order': property 'item' generated message count exceeds limit of 4",
+		},
+		{
+			name:    "negative limit",
+			limits:  conv.Limits{MaxDepth: -1},
+			wantErr: "limits cannot be negative",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				Limits:      test.limits,
+			})
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package conv
+
+import (
+	"fmt"
+
+	"github.com/duh-rpc/openapi-proto.go/internal"
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+// LintFinding is a convertibility problem reported by LintForProto
+type LintFinding struct {
+	// Code classifies the problem, using the same codes as conversion errors
+	Code ErrorCode
+	// Schema is the top-level schema containing the problem
+	Schema string
+	// Property is the dotted path of the property below Schema, e.g. "profile.location",
+	// or empty when the problem is with the schema itself
+	Property string
+	// Message is the error Convert would return for the problem
+	Message string
+	// Fixes is a suggested patch resolving the problem, as on Error
+	Fixes []Fix
+}
+
+// LintForProto checks an OpenAPI spec for problems that stop it from converting, such
+// as plural inline names, oneOf without a discriminator, allOf/anyOf/not compositions
+// and partial x-proto-number coverage. Convert stops at the first problem; LintForProto
+// reports every problem it finds, in spec order, so spec authors can fix them in one
+// pass before adopting generation.
+//
+// Findings are advisory: an empty result means none of the checked problems were found,
+// not that conversion will succeed. An error is returned only if the spec cannot be
+// parsed.
+func LintForProto(openapi []byte) ([]LintFinding, error) {
+	if len(openapi) == 0 {
+		return nil, &Error{Code: ErrorCodeInvalidInput, Err: fmt.Errorf("openapi input cannot be empty")}
+	}
+
+	doc, err := parser.ParseDocument(openapi)
+	if err != nil {
+		return nil, &Error{Code: ErrorCodeParse, Err: err}
+	}
+
+	schemas, err := doc.Schemas()
+	if err != nil {
+		return nil, &Error{Code: ErrorCodeParse, Err: err}
+	}
+	return lintSchemas(schemas), nil
+}
+
+// lintSchemas returns the findings for the schemas of a parsed spec
+func lintSchemas(schemas []*parser.SchemaEntry) []LintFinding {
+	var findings []LintFinding
+	for _, finding := range internal.Lint(schemas) {
+		findings = append(findings, LintFinding{
+			Code:     ErrorCode(finding.Code),
+			Schema:   finding.Schema,
+			Property: finding.Property,
+			Message:  finding.Message,
+			Fixes:    buildFixes(finding.Fixes),
+		})
+	}
+	return findings
+}
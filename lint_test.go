@@ -0,0 +1,150 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintForProto(t *testing.T) {
+	header := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+`
+
+	for _, test := range []struct {
+		name     string
+		given    string
+		expected []conv.LintFinding
+	}{
+		{
+			name: "clean",
+			given: header + `    User:
+      type: object
+      properties:
+        name:
+          type: string
+        address:
+          $ref: '#/components/schemas/Address'
+    Address:
+      type: object
+      properties:
+        street:
+          type: string
+`,
+		},
+		{
+			name: "all problems reported",
+			given: header + `    User:
+      type: object
+      properties:
+        addresses:
+          type: array
+          items:
+            type: object
+            properties:
+              street:
+                type: string
+        profile:
+          type: object
+          properties:
+            bio:
+              type: string
+              x-proto-number: 1
+            website:
+              type: string
+            settings:
+              type: object
+              properties:
+                theme:
+                  type: string
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+    Cat:
+      type: object
+      properties:
+        name:
+          type: string
+    Dog:
+      allOf:
+        - $ref: '#/components/schemas/Cat'
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+          x-proto-number: 1
+        total:
+          type: number
+        extra:
+          anyOf:
+            - type: string
+            - type: integer
+`,
+			expected: []conv.LintFinding{
+				{
+					Code:     conv.ErrorCodePluralInlineName,
+					Schema:   "User",
+					Property: "addresses",
+					Message:  "schema 'User': property 'addresses' cannot derive message name from plural array property 'addresses'; use singular form or $ref",
+				},
+				{
+					Code:     conv.ErrorCodeMixedNumbering,
+					Schema:   "User",
+					Property: "profile",
+					Message:  "schema 'User': property 'profile' x-proto-number must be specified on all fields or none (found on 1 of 3 fields); inline objects and array items are numbered separately from their parent",
+				},
+				{
+					Code:     conv.ErrorCodePluralInlineName,
+					Schema:   "User",
+					Property: "profile.settings",
+					Message:  "schema 'User': property 'settings' cannot derive message name from property 'settings'; use singular form or $ref",
+				},
+				{
+					Code:    conv.ErrorCodeInvalidOneOf,
+					Schema:  "Pet",
+					Message: "schema 'Pet': oneOf requires discriminator",
+				},
+				{
+					Code:    conv.ErrorCodeUnsupportedAllOf,
+					Schema:  "Dog",
+					Message: "schema 'Dog': uses 'allOf' which is not supported",
+				},
+				{
+					Code:    conv.ErrorCodeMixedNumbering,
+					Schema:  "Order",
+					Message: "schema 'Order': x-proto-number must be specified on all fields or none (found on 1 of 3 fields)",
+				},
+				{
+					Code:     conv.ErrorCodeUnsupportedAnyOf,
+					Schema:   "Order",
+					Property: "extra",
+					Message:  "schema 'Order': property 'extra' uses 'anyOf' which is not supported",
+				},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			findings, err := conv.LintForProto([]byte(test.given))
+			require.NoError(t, err)
+
+			// Fixes are covered by TestConvertSuggestedFixes
+			for i := range findings {
+				findings[i].Fixes = nil
+			}
+			assert.Equal(t, test.expected, findings)
+		})
+	}
+}
+
+func TestLintForProtoInvalidSpec(t *testing.T) {
+	_, err := conv.LintForProto([]byte("swagger: '2.0'\n"))
+	require.ErrorContains(t, err, "failed to build OpenAPI model")
+}
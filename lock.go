@@ -0,0 +1,70 @@
+package conv
+
+import "github.com/duh-rpc/openapi-proto.go/internal"
+
+// Lock records the field numbers generated for each proto message. Store it alongside
+// the generated proto, for example marshaled as JSON, and pass it back through
+// ConvertOptions.Lock so fields removed from the spec are reserved instead of being
+// silently dropped.
+type Lock struct {
+	// Messages maps full message names, e.g. "User" or "User.Address" for nested
+	// messages, to their fields
+	Messages map[string]LockedMessage `json:"messages"`
+}
+
+// LockedMessage records the fields and reservations of one message
+type LockedMessage struct {
+	// Fields maps proto field names to field numbers
+	Fields map[string]int `json:"fields"`
+	// ReservedNumbers and ReservedNames are carried forward so fields stay reserved
+	// across any number of conversions
+	ReservedNumbers []int    `json:"reservedNumbers,omitempty"`
+	ReservedNames   []string `json:"reservedNames,omitempty"`
+}
+
+// Lock returns the field numbers and reservations of every proto message in the result
+func (r *ConvertResult) Lock() *Lock {
+	lock := &Lock{Messages: make(map[string]LockedMessage)}
+	for _, def := range r.Definitions {
+		if msg, ok := def.(*ProtoMessage); ok {
+			lockMessage(lock, "", msg)
+		}
+	}
+	return lock
+}
+
+// lockMessage records msg and its nested messages. scope is the full name of the
+// enclosing message, empty at the top level.
+func lockMessage(lock *Lock, scope string, msg *ProtoMessage) {
+	fullName := msg.Name
+	if scope != "" {
+		fullName = scope + "." + msg.Name
+	}
+
+	locked := LockedMessage{
+		Fields:          make(map[string]int, len(msg.Fields)),
+		ReservedNumbers: msg.ReservedNumbers,
+		ReservedNames:   msg.ReservedNames,
+	}
+	for _, field := range msg.Fields {
+		locked.Fields[field.Name] = field.Number
+	}
+	lock.Messages[fullName] = locked
+
+	for _, nested := range msg.Nested {
+		lockMessage(lock, fullName, nested)
+	}
+}
+
+// messages converts the lock into its internal representation
+func (l *Lock) messages() map[string]internal.LockedMessage {
+	result := make(map[string]internal.LockedMessage, len(l.Messages))
+	for name, msg := range l.Messages {
+		result[name] = internal.LockedMessage{
+			Fields:          msg.Fields,
+			ReservedNumbers: msg.ReservedNumbers,
+			ReservedNames:   msg.ReservedNames,
+		}
+	}
+	return result
+}
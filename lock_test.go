@@ -0,0 +1,206 @@
+package conv_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lockSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+        email:
+          type: string
+        name:
+          type: string
+        location:
+          type: object
+          properties:
+            street:
+              type: string
+            city:
+              type: string
+`
+
+func TestConvertLockReservesRemovedFields(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		spec     string
+		contains []string
+		warnings []string
+	}{
+		{
+			name: "removed field",
+			spec: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+          x-proto-number: 1
+        name:
+          type: string
+          x-proto-number: 3
+        location:
+          type: object
+          x-proto-number: 4
+          properties:
+            street:
+              type: string
+            city:
+              type: string
+`,
+			contains: []string{
+				"message User {\n  reserved 2;\n  reserved \"email\";\n",
+			},
+		},
+		{
+			name: "removed nested field",
+			spec: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+        email:
+          type: string
+        name:
+          type: string
+        location:
+          type: object
+          properties:
+            street:
+              type: string
+`,
+			contains: []string{
+				"  message Location {\n    reserved 2;\n    reserved \"city\";\n",
+			},
+		},
+		{
+			name: "removed number reused",
+			spec: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+        location:
+          type: object
+          properties:
+            street:
+              type: string
+            city:
+              type: string
+`,
+			contains: []string{
+				"message User {\n  reserved \"email\";\n",
+			},
+			warnings: []string{
+				"User: removed field email had number 2, which is now used by name",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			opts := conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+			}
+			previous, err := conv.Convert([]byte(lockSpec), opts)
+			require.NoError(t, err)
+
+			opts.Lock = previous.Lock()
+			result, err := conv.Convert([]byte(test.spec), opts)
+			require.NoError(t, err)
+
+			for _, want := range test.contains {
+				assert.Contains(t, string(result.Protobuf), want)
+			}
+			assert.Equal(t, test.warnings, result.Warnings)
+
+			_, err = result.Files()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConvertLockCarriesReservations(t *testing.T) {
+	opts := conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	}
+	previous, err := conv.Convert([]byte(lockSpec), opts)
+	require.NoError(t, err)
+
+	// Removes email, then round-trips the lock through JSON as it would be stored on disk
+	spec := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+          x-proto-number: 1
+        name:
+          type: string
+          x-proto-number: 3
+        location:
+          type: object
+          x-proto-number: 4
+          properties:
+            street:
+              type: string
+            city:
+              type: string
+`
+	opts.Lock = previous.Lock()
+	removed, err := conv.Convert([]byte(spec), opts)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(removed.Lock())
+	require.NoError(t, err)
+	var lock conv.Lock
+	require.NoError(t, json.Unmarshal(data, &lock))
+	assert.Equal(t, []int{2}, lock.Messages["User"].ReservedNumbers)
+	assert.Equal(t, []string{"email"}, lock.Messages["User"].ReservedNames)
+
+	opts.Lock = &lock
+	result, err := conv.Convert([]byte(spec), opts)
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Protobuf), "message User {\n  reserved 2;\n  reserved \"email\";\n")
+
+	user := result.Definitions[0].(*conv.ProtoMessage)
+	assert.Equal(t, []int{2}, user.ReservedNumbers)
+	assert.Equal(t, []string{"email"}, user.ReservedNames)
+}
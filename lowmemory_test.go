@@ -0,0 +1,179 @@
+package conv_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertLowMemory(t *testing.T) {
+	given := largeSpec(20, 5)
+
+	full, err := conv.Convert(given, conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	pruned, err := conv.Convert(given, conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		LowMemory:   true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, string(full.Protobuf), string(pruned.Protobuf))
+	assert.Equal(t, full.Servers, pruned.Servers)
+	assert.Equal(t, full.TypeMap, pruned.TypeMap)
+}
+
+func TestConvertLowMemoryPatchWarning(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /owners/{id}:
+    patch:
+      requestBody:
+        content:
+          application/merge-patch+json:
+            schema:
+              $ref: '#/components/schemas/Owner'
+      responses:
+        '204':
+          description: Updated
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+        pet:
+          $ref: '#/components/schemas/Pet'
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	for _, test := range []struct {
+		name      string
+		lowMemory bool
+		warnings  []string
+	}{
+		{name: "full model"},
+		{
+			name:      "low memory",
+			lowMemory: true,
+			warnings:  []string{"FieldMask helpers skipped for PATCH request bodies: low memory drops paths"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				GoPackagePath: "github.com/example/go/api",
+				LowMemory:     test.lowMemory,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.warnings, result.Warnings)
+			assert.Equal(t, !test.lowMemory, strings.Contains(string(result.Golang), "FieldMask("))
+		})
+	}
+}
+
+func BenchmarkConvertLargeSpec(b *testing.B) {
+	given := largeSpec(5000, 50)
+
+	for _, test := range []struct {
+		name      string
+		lowMemory bool
+	}{
+		{name: "full model"},
+		{name: "low memory", lowMemory: true},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, err := conv.Convert(given, conv.ConvertOptions{
+					PackageName: "testpkg",
+					PackagePath: "github.com/example/proto/v1",
+					LowMemory:   test.lowMemory,
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// largeSpec builds a spec with many operations and a few schemas, the shape of
+// large public API descriptions where paths dominate the document size
+func largeSpec(paths, schemas int) []byte {
+	var spec strings.Builder
+	spec.WriteString(`openapi: 3.0.0
+info:
+  title: Large API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+`)
+	for i := 0; i < paths; i++ {
+		spec.WriteString(fmt.Sprintf(`  /resource%d/{id}:
+    parameters:
+      - $ref: '#/components/parameters/Id'
+    get:
+      operationId: getResource%d
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Schema%d'
+`, i, i, i%schemas))
+	}
+
+	spec.WriteString(`components:
+  parameters:
+    Id:
+      name: id
+      in: path
+      required: true
+      schema:
+        type: string
+  schemas:
+`)
+	for i := 0; i < schemas; i++ {
+		spec.WriteString(fmt.Sprintf(`    Schema%d:
+      type: object
+      properties:
+        id:
+          type: string
+        count:
+          type: integer
+`, i))
+	}
+
+	return []byte(spec.String())
+}
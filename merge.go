@@ -0,0 +1,47 @@
+package conv
+
+import "github.com/duh-rpc/openapi-proto.go/internal/parser"
+
+// MergeConflict reports a schema or property whose type, format or $ref differs between
+// the specs merged by MergeSpecs. The newer definition is kept.
+type MergeConflict struct {
+	// Schema is the top-level schema containing the conflict
+	Schema string
+	// Property is the dotted path of the property below Schema, e.g. "profile.location",
+	// or empty when the conflict is with the schema itself
+	Property string
+	// Message describes both definitions
+	Message string
+}
+
+// MergeResult is the spec produced by MergeSpecs
+type MergeResult struct {
+	// Spec is the merged OpenAPI document, encoded as YAML
+	Spec []byte
+	// Conflicts lists every definition that differs between the versions, in the order
+	// of the older spec
+	Conflicts []MergeConflict
+}
+
+// MergeSpecs merges two versions of the same spec so that converting the result yields
+// a superset proto able to decode documents from clients of either version. The result
+// is newer with the component schemas of older merged in: schemas only in older are
+// added, and schemas in both get the union of their properties and enum values while
+// only properties required by both stay required. Conflicts are reported rather than
+// returned as an error, so callers decide whether they are acceptable.
+func MergeSpecs(older, newer []byte) (*MergeResult, error) {
+	merged, conflicts, err := parser.MergeDocuments(older, newer)
+	if err != nil {
+		return nil, &Error{Code: ErrorCodeParse, Err: err}
+	}
+
+	result := &MergeResult{Spec: merged}
+	for _, conflict := range conflicts {
+		result.Conflicts = append(result.Conflicts, MergeConflict{
+			Schema:   conflict.Schema,
+			Property: conflict.Property,
+			Message:  conflict.Message,
+		})
+	}
+	return result, nil
+}
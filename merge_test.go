@@ -0,0 +1,118 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSpecs(t *testing.T) {
+	older := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+        age:
+          type: integer
+        location:
+          type: object
+          properties:
+            city:
+              type: string
+    Status:
+      type: integer
+      enum: [1, 2]
+    Legacy:
+      type: object
+      properties:
+        note:
+          type: string
+`
+	newer := `openapi: 3.0.0
+info:
+  title: Test
+  version: 2.0.0
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, email]
+      properties:
+        id:
+          type: string
+        email:
+          type: string
+        age:
+          type: string
+        location:
+          type: object
+          properties:
+            zip:
+              type: string
+    Status:
+      type: integer
+      enum: [2, 3]
+`
+
+	merged, err := conv.MergeSpecs([]byte(older), []byte(newer))
+	require.NoError(t, err)
+	assert.Equal(t, []conv.MergeConflict{
+		{Schema: "User", Property: "age", Message: "type is 'integer' in the older version and 'string' in the newer one"},
+	}, merged.Conflicts)
+
+	result, err := conv.Convert(merged.Spec, conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `syntax = "proto3";
+
+package testpkg;
+
+option go_package = "github.com/example/proto/v1";
+
+message User {
+  message Location {
+    string zip = 1 [json_name = "zip"];
+    string city = 2 [json_name = "city"];
+  }
+
+  string id = 1 [json_name = "id"];
+  string email = 2 [json_name = "email"];
+  string age = 3 [json_name = "age"];
+  Location location = 4 [json_name = "location"];
+  string name = 5 [json_name = "name"];
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_1 = 1;
+  STATUS_2 = 2;
+  STATUS_3 = 3;
+}
+
+message Legacy {
+  string note = 1 [json_name = "note"];
+}
+
+`, string(result.Protobuf))
+	assert.Contains(t, string(merged.Spec), "required: [id]\n")
+}
+
+func TestMergeSpecsInvalid(t *testing.T) {
+	_, err := conv.MergeSpecs([]byte("- not\n- a mapping\n"), []byte("openapi: 3.0.0\n"))
+	require.ErrorContains(t, err, "failed to parse older document: document is not a mapping")
+}
@@ -0,0 +1,214 @@
+package conv
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+// mockWildcard matches a path parameter ServeMux accepts as a wildcard, e.g. {id}
+var mockWildcard = regexp.MustCompile(`^\{[A-Za-z_][A-Za-z0-9_]*\}$`)
+
+// MockServer returns the source of a runnable Go program, package main, that serves
+// every operation in Routes with net/http, so teams get a contract stub together with
+// their protos. Each handler decodes a JSON request body into its generated type,
+// replying 400 Bad Request if it does not decode, and answers 200 OK with the example
+// of the response message from Examples, or {} when there is none. Operations without
+// a response type answer with an empty body. Bodies of other media types are not
+// decoded.
+//
+// Proto messages decode with protojson into the types protoc-gen-go generates in the
+// go_package of the proto file, and schemas generated as Go with encoding/json into
+// the Go output, so the program builds in a module containing both. Set EmitExamples
+// for example responses. The program listens on the address in the MOCK_ADDR
+// environment variable, :8080 by default.
+func (r *ConvertResult) MockServer() ([]byte, error) {
+	if len(r.Routes) == 0 {
+		return nil, fmt.Errorf("no operations to serve")
+	}
+
+	m := &mockBuilder{result: r, aliases: make(map[string]string)}
+	var handlers strings.Builder
+	registered := make(map[string]bool, len(r.Routes))
+	for _, route := range r.Routes {
+		pattern := route.Method + " " + mockPath(route.Path)
+		if registered[pattern] {
+			// Paths differing only in parameters inside a segment share a pattern
+			continue
+		}
+		registered[pattern] = true
+		m.writeHandler(&handlers, route, pattern)
+	}
+
+	var src strings.Builder
+	src.WriteString("// Code generated by openapi-proto. DO NOT EDIT.\n\n")
+	src.WriteString("// Command mock serves the operations of the spec with example responses\n")
+	src.WriteString("package main\n\nimport (\n")
+	imports := []string{`"io"`, `"log"`, `"net/http"`, `"os"`}
+	var external []string
+	if m.protoJSON {
+		external = append(external, `"google.golang.org/protobuf/encoding/protojson"`, `"google.golang.org/protobuf/proto"`)
+	}
+	if m.goJSON {
+		imports = append(imports, `"encoding/json"`)
+	}
+	for path, alias := range m.aliases {
+		external = append(external, alias+" "+strconv.Quote(path))
+	}
+	sort.Strings(imports)
+	sort.Slice(external, func(i, j int) bool {
+		return importPath(external[i]) < importPath(external[j])
+	})
+	for _, imp := range imports {
+		src.WriteString("\t" + imp + "\n")
+	}
+	if len(external) > 0 {
+		src.WriteString("\n")
+	}
+	for _, imp := range external {
+		src.WriteString("\t" + imp + "\n")
+	}
+	src.WriteString(")\n\nfunc main() {\n\tmux := http.NewServeMux()\n")
+	src.WriteString(handlers.String())
+	src.WriteString(`
+	addr := os.Getenv("MOCK_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	log.Printf("mock server listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+// respond writes status and, if not empty, the JSON body
+func respond(w http.ResponseWriter, status int, body string) {
+	if body != "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, body)
+}
+`)
+	if m.protoJSON {
+		src.WriteString(`
+// decodeProto reads the request body into m, replying 400 Bad Request if it is not the
+// protobuf JSON form of m
+func decodeProto(w http.ResponseWriter, r *http.Request, m proto.Message) bool {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = protojson.Unmarshal(body, m)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+`)
+	}
+	if m.goJSON {
+		src.WriteString(`
+// decodeJSON reads the request body into v, replying 400 Bad Request if it does not decode
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+`)
+	}
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format mock server: %w", err)
+	}
+	return formatted, nil
+}
+
+// mockBuilder collects the imports and helpers the handlers of a mock server use
+type mockBuilder struct {
+	result *ConvertResult
+	// aliases maps the import path of generated types to their package alias
+	aliases   map[string]string
+	protoJSON bool
+	goJSON    bool
+}
+
+// writeHandler writes the registration of the handler for route under pattern
+func (m *mockBuilder) writeHandler(result *strings.Builder, route Route, pattern string) {
+	result.WriteString(fmt.Sprintf("\tmux.HandleFunc(%q, func(w http.ResponseWriter, r *http.Request) {\n", pattern))
+	if parser.IsJSONMediaType(route.ContentType) {
+		if decode := m.decodeCall(route.Request); decode != "" {
+			result.WriteString(fmt.Sprintf("\t\tif !%s {\n\t\t\treturn\n\t\t}\n", decode))
+		}
+	}
+
+	body := ""
+	if route.Response != "" {
+		body = "{}"
+		name := strings.TrimPrefix(route.Response, m.result.packageName+".")
+		if example, ok := m.result.Examples[name]; ok {
+			body = strings.TrimSpace(string(example))
+		}
+	}
+	result.WriteString(fmt.Sprintf("\t\trespond(w, http.StatusOK, %s)\n\t})\n", strconv.Quote(body)))
+}
+
+// decodeCall returns the call decoding the request body into typ, a Route request type,
+// or an empty string if typ is not a type of the conversion
+func (m *mockBuilder) decodeCall(typ string) string {
+	r := m.result
+	if name, ok := strings.CutPrefix(typ, r.packageName+"."); ok && !strings.Contains(name, ".") {
+		m.protoJSON = true
+		return fmt.Sprintf("decodeProto(w, r, &%s.%s{})", m.alias(r.packagePath), name)
+	}
+	if name, ok := strings.CutPrefix(typ, r.goPackagePath+"."); ok && r.goPackagePath != "" {
+		m.goJSON = true
+		return fmt.Sprintf("decodeJSON(w, r, &%s.%s{})", m.alias(r.goPackagePath), name)
+	}
+	return ""
+}
+
+// alias returns the package alias of an import path, api for the first and api2, api3
+// and so on for others
+func (m *mockBuilder) alias(path string) string {
+	path, _, _ = strings.Cut(path, ";")
+	if alias, ok := m.aliases[path]; ok {
+		return alias
+	}
+	alias := "api"
+	if len(m.aliases) > 0 {
+		alias = fmt.Sprintf("api%d", len(m.aliases)+1)
+	}
+	m.aliases[path] = alias
+	return alias
+}
+
+// importPath returns the quoted path of an import spec, dropping its alias
+func importPath(spec string) string {
+	return spec[strings.Index(spec, `"`):]
+}
+
+// mockPath returns the ServeMux pattern path of an OpenAPI path template. Parameters
+// that are not a whole segment or not a Go identifier, e.g. {file}.json or {user-id},
+// become numbered wildcards, since ServeMux does not accept them.
+func mockPath(path string) string {
+	segments := strings.Split(path, "/")
+	wildcards := 0
+	for i, segment := range segments {
+		if !strings.Contains(segment, "{") {
+			continue
+		}
+		if !mockWildcard.MatchString(segment) {
+			segment = fmt.Sprintf("{p%d}", wildcards)
+		}
+		segments[i] = segment
+		wildcards++
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,130 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertResultMockServer(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/User'
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /users/{user-id}/avatar.{ext}:
+    put:
+      operationId: uploadAvatar
+      requestBody:
+        content:
+          image/png:
+            schema:
+              type: string
+              format: binary
+      responses:
+        '204':
+          description: Stored
+  /payments:
+    post:
+      operationId: createPayment
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Payment'
+      responses:
+        '200':
+          description: OK
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Alice
+    Payment:
+      oneOf:
+        - $ref: '#/components/schemas/Card'
+        - $ref: '#/components/schemas/Wire'
+      discriminator:
+        propertyName: kind
+    Card:
+      type: object
+      properties:
+        kind:
+          type: string
+    Wire:
+      type: object
+      properties:
+        kind:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:   "api.v1",
+		PackagePath:   "github.com/example/proto/v1",
+		GoPackagePath: "github.com/example/go/api",
+		EmitExamples:  true,
+	})
+	require.NoError(t, err)
+
+	server, err := result.MockServer()
+	require.NoError(t, err)
+	for _, want := range []string{
+		"\tapi2 \"github.com/example/go/api\"\n\tapi \"github.com/example/proto/v1\"\n",
+		"\tmux.HandleFunc(\"POST /users\", func(w http.ResponseWriter, r *http.Request) {\n" +
+			"\t\tif !decodeProto(w, r, &api.User{}) {\n\t\t\treturn\n\t\t}\n" +
+			"\t\trespond(w, http.StatusOK, \"{\\n  \\\"name\\\": \\\"Alice\\\"\\n}\")\n\t})\n",
+		"\tmux.HandleFunc(\"PUT /users/{p0}/{p1}\", func(w http.ResponseWriter, r *http.Request) {\n" +
+			"\t\trespond(w, http.StatusOK, \"\")\n\t})\n",
+		"\tmux.HandleFunc(\"POST /payments\", func(w http.ResponseWriter, r *http.Request) {\n" +
+			"\t\tif !decodeJSON(w, r, &api2.Payment{}) {\n\t\t\treturn\n\t\t}\n",
+		"func decodeProto(w http.ResponseWriter, r *http.Request, m proto.Message) bool {",
+		"func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {",
+	} {
+		assert.Contains(t, string(server), want)
+	}
+}
+
+func TestConvertResultMockServerNoRoutes(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "api.v1",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	_, err = result.MockServer()
+	require.ErrorContains(t, err, "no operations to serve")
+}
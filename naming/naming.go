@@ -0,0 +1,69 @@
+// Package naming exposes the identifier rules the converter uses, so code generated
+// alongside its output, such as server scaffolds or client SDKs, can derive the same
+// message, field and enum value names from an OpenAPI spec.
+//
+// The rules are:
+//
+//   - Message and enum names are the PascalCase form of the schema key or property name.
+//     Underscores start a new word and are removed; other characters keep their case,
+//     except that an all caps word without underscores is lowered after its first letter
+//     (user_account → UserAccount, shippingAddress → ShippingAddress, USER → User).
+//   - Field names keep the property name. Characters other than ASCII letters, digits and
+//     underscores become a single underscore, a trailing one is trimmed, and names that do
+//     not start with an ASCII letter are rejected (user-name → user_name).
+//   - Enum values are the upper snake case enum name followed by the upper snake case
+//     value, with hyphens replaced by underscores (Status, in-progress →
+//     STATUS_IN_PROGRESS). Every enum starts with the UNSPECIFIED value
+//     (STATUS_UNSPECIFIED).
+//   - Snake case inserts an underscore before every upper case letter except the first
+//     (HTTPStatus → h_t_t_p_status).
+//   - Names that are already taken get a numeric suffix starting at _2 (User, User_2).
+//
+// Case conversion only changes ASCII letters. 'İ', 'ı', 'ſ' and the Kelvin sign are
+// folded to their ASCII letter first; every other non-ASCII letter is copied unchanged.
+package naming
+
+import "github.com/duh-rpc/openapi-proto.go/internal"
+
+// PascalCase returns the message or enum name for a schema key or property name
+func PascalCase(s string) string {
+	return internal.ToPascalCase(s)
+}
+
+// SnakeCase converts a camelCase or PascalCase name to snake_case
+func SnakeCase(s string) string {
+	return internal.ToSnakeCase(s)
+}
+
+// FieldName returns the proto field name for a property name, or an error if the name
+// does not start with a letter or contains no valid characters
+func FieldName(property string) (string, error) {
+	return internal.SanitizeFieldName(property)
+}
+
+// EnumValueName returns the proto name of an enum value
+func EnumValueName(enumName, value string) string {
+	return internal.ToEnumValueName(enumName, value)
+}
+
+// UnspecifiedValueName returns the name of the zero value every enum starts with
+func UnspecifiedValueName(enumName string) string {
+	return internal.ToUnspecifiedValueName(enumName)
+}
+
+// Tracker hands out unique names in the order they are requested, the way the
+// converter names top-level definitions and the fields of each message. The zero value
+// is not usable; create one with NewTracker.
+type Tracker struct {
+	tracker *internal.NameTracker
+}
+
+// NewTracker returns a Tracker with no names taken
+func NewTracker() *Tracker {
+	return &Tracker{tracker: internal.NewNameTracker()}
+}
+
+// Unique returns name if it is free, otherwise name with the next numeric suffix
+func (t *Tracker) Unique(name string) string {
+	return t.tracker.UniqueName(name)
+}
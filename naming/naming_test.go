@@ -0,0 +1,64 @@
+package naming_test
+
+import (
+	"testing"
+
+	"github.com/duh-rpc/openapi-proto.go/naming"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPascalCase(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		given    string
+		expected string
+	}{
+		{name: "snake case", given: "user_account", expected: "UserAccount"},
+		{name: "camel case", given: "shippingAddress", expected: "ShippingAddress"},
+		{name: "all caps", given: "USER", expected: "User"},
+		{name: "dotted capital I", given: "İtem", expected: "Item"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, naming.PascalCase(test.given))
+		})
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		given    string
+		expected string
+		err      string
+	}{
+		{name: "valid", given: "userId", expected: "userId"},
+		{name: "hyphen", given: "user-name", expected: "user_name"},
+		{name: "leading digit", given: "2fa", err: "must start with a letter"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := naming.FieldName(test.given)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestEnumValueName(t *testing.T) {
+	assert.Equal(t, "STATUS_IN_PROGRESS", naming.EnumValueName("Status", "in-progress"))
+	assert.Equal(t, "SORT_BY_CREATED_AT", naming.EnumValueName("SortBy", "createdAt"))
+	assert.Equal(t, "ORDER_STATUS_UNSPECIFIED", naming.UnspecifiedValueName("OrderStatus"))
+	assert.Equal(t, "h_t_t_p_status", naming.SnakeCase("HTTPStatus"))
+}
+
+func TestTracker(t *testing.T) {
+	tracker := naming.NewTracker()
+	assert.Equal(t, "User", tracker.Unique("User"))
+	assert.Equal(t, "User_2", tracker.Unique("User"))
+	assert.Equal(t, "User_3", tracker.Unique("User"))
+	assert.Equal(t, "Order", tracker.Unique("Order"))
+}
@@ -0,0 +1,522 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertOperationsOnly(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          $ref: '#/components/schemas/UserId'
+    get:
+      parameters:
+        - name: view
+          in: query
+          schema:
+            $ref: '#/components/schemas/View'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        default:
+          description: Error
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorResponse'
+  /users:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: array
+              items:
+                $ref: '#/components/schemas/CreateUserRequest'
+      responses:
+        '204':
+          description: Created
+      callbacks:
+        onCreated:
+          '{$request.body#/callbackUrl}':
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      $ref: '#/components/schemas/UserCreated'
+              responses:
+                '200':
+                  description: Received
+components:
+  schemas:
+    Unused:
+      type: object
+      properties:
+        id:
+          type: string
+    UserId:
+      type: object
+      properties:
+        value:
+          type: string
+    View:
+      type: string
+      enum: [basic, full]
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+        profile:
+          $ref: '#/components/schemas/Profile'
+    Profile:
+      type: object
+      properties:
+        manager:
+          $ref: '#/components/schemas/User'
+    ErrorResponse:
+      type: object
+      properties:
+        message:
+          type: string
+    CreateUserRequest:
+      type: object
+      properties:
+        name:
+          type: string
+    UserCreated:
+      type: object
+      properties:
+        id:
+          type: string
+    UnusedChild:
+      type: object
+      properties:
+        parent:
+          $ref: '#/components/schemas/Unused'
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:    "testpkg",
+		PackagePath:    "github.com/example/proto/v1",
+		OperationsOnly: true,
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, def := range result.Definitions {
+		switch d := def.(type) {
+		case *conv.ProtoMessage:
+			names = append(names, d.Name)
+		case *conv.ProtoEnum:
+			names = append(names, d.Name)
+		}
+	}
+	assert.Equal(t, []string{"UserId", "User", "Profile", "ErrorResponse", "CreateUserRequest", "UserCreated"}, names)
+	assert.NotContains(t, string(result.Protobuf), "Unused")
+}
+
+func TestConvertOperationsOnlyWithoutPaths(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:    "testpkg",
+		PackagePath:    "github.com/example/proto/v1",
+		OperationsOnly: true,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Definitions)
+}
+
+func TestConvertOperationMessages(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                profile:
+                  $ref: '#/components/schemas/Profile'
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Profile'
+  /users/{id}:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: string
+    put:
+      operationId: createUser
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+      responses:
+        '200':
+          description: OK
+          content:
+            text/plain:
+              schema:
+                type: string
+components:
+  schemas:
+    Profile:
+      type: object
+      properties:
+        bio:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:       "testpkg",
+		PackagePath:       "github.com/example/proto/v1",
+		OperationMessages: true,
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, def := range result.Definitions {
+		if msg, ok := def.(*conv.ProtoMessage); ok {
+			names = append(names, msg.Name)
+		}
+	}
+	assert.Equal(t, []string{"Profile", "CreateUserRequest", "GetUsersIdResponse", "CreateUserRequest2"}, names)
+	assert.Contains(t, string(result.Protobuf), `Profile profile = 2 [json_name = "profile"];`)
+
+	assert.Equal(t, []conv.Route{
+		{Method: "POST", Path: "/users", RPC: "CreateUser", Request: "testpkg.CreateUserRequest", Response: "testpkg.Profile", ContentType: "application/json"},
+		{Method: "GET", Path: "/users/{id}", RPC: "GetUsersId", Response: "testpkg.GetUsersIdResponse"},
+		{Method: "PUT", Path: "/users/{id}", RPC: "CreateUser", Request: "testpkg.CreateUserRequest2", ContentType: "application/json"},
+	}, result.Routes)
+}
+
+func TestConvertOperationMessagesPathsOnly(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /orders:
+    post:
+      operationId: placeOrder
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                sku:
+                  type: string
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: string
+`
+
+	for _, test := range []struct {
+		name     string
+		messages bool
+		expected []string
+	}{
+		{name: "default", messages: false},
+		{name: "operation messages", messages: true, expected: []string{"PlaceOrderRequest", "PlaceOrderResponse"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:       "testpkg",
+				PackagePath:       "github.com/example/proto/v1",
+				OperationMessages: test.messages,
+			})
+			require.NoError(t, err)
+
+			var names []string
+			for name := range result.TypeMap {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, test.expected, names)
+
+			_, err = result.Files()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConvertOperationMessagesBodyStrategies(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /files/{id}/content:
+    put:
+      operationId: uploadContent
+      requestBody:
+        content:
+          application/octet-stream:
+            schema:
+              type: string
+              format: binary
+      responses:
+        '204':
+          description: Stored
+  /forms:
+    post:
+      operationId: submitForm
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                title:
+                  type: string
+  /notes:
+    post:
+      operationId: createNote
+      requestBody:
+        content:
+          application/xml:
+            schema:
+              $ref: '#/components/schemas/Note'
+          application/json:
+            schema:
+              type: object
+              properties:
+                text:
+                  type: string
+      responses:
+        '201':
+          description: Created
+components:
+  schemas:
+    Note:
+      type: object
+      properties:
+        text:
+          type: string
+`
+
+	for _, test := range []struct {
+		name       string
+		strategies map[string]conv.BodyStrategy
+		expected   []string
+		routes     []conv.Route
+	}{
+		{
+			name: "defaults",
+			expected: []string{
+				"message UploadContentRequest {\n  // Raw application/octet-stream request body\n  bytes body = 1 [json_name = \"body\"];\n}",
+				"message SubmitFormRequest {\n  string title = 1 [json_name = \"title\"];\n}",
+				"message CreateNoteRequest {\n  string text = 1 [json_name = \"text\"];\n}",
+			},
+			routes: []conv.Route{
+				{Method: "PUT", Path: "/files/{id}/content", RPC: "UploadContent", Request: "testpkg.UploadContentRequest", ContentType: "application/octet-stream"},
+				{Method: "POST", Path: "/forms", RPC: "SubmitForm", Request: "testpkg.SubmitFormRequest", ContentType: "multipart/form-data"},
+				{Method: "POST", Path: "/notes", RPC: "CreateNote", Request: "testpkg.CreateNoteRequest", ContentType: "application/json"},
+			},
+		},
+		{
+			name: "overrides",
+			strategies: map[string]conv.BodyStrategy{
+				"multipart/form-data": conv.BodyStrategyBytes,
+				"application/json":    conv.BodyStrategyBytes,
+			},
+			expected: []string{
+				"message SubmitFormRequest {\n  // Raw multipart/form-data request body\n  bytes body = 1 [json_name = \"body\"];\n}",
+				"message CreateNoteRequest {\n  // Raw application/json request body\n  bytes body = 1 [json_name = \"body\"];\n}",
+			},
+			routes: []conv.Route{
+				{Method: "PUT", Path: "/files/{id}/content", RPC: "UploadContent", Request: "testpkg.UploadContentRequest", ContentType: "application/octet-stream"},
+				{Method: "POST", Path: "/forms", RPC: "SubmitForm", Request: "testpkg.SubmitFormRequest", ContentType: "multipart/form-data"},
+				{Method: "POST", Path: "/notes", RPC: "CreateNote", Request: "testpkg.CreateNoteRequest", ContentType: "application/json"},
+			},
+		},
+		{
+			name: "fields for a binary body",
+			strategies: map[string]conv.BodyStrategy{
+				"application/octet-stream": conv.BodyStrategyFields,
+			},
+			routes: []conv.Route{
+				{Method: "PUT", Path: "/files/{id}/content", RPC: "UploadContent", ContentType: "application/octet-stream"},
+				{Method: "POST", Path: "/forms", RPC: "SubmitForm", Request: "testpkg.SubmitFormRequest", ContentType: "multipart/form-data"},
+				{Method: "POST", Path: "/notes", RPC: "CreateNote", Request: "testpkg.CreateNoteRequest", ContentType: "application/json"},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+				PackageName:       "testpkg",
+				PackagePath:       "github.com/example/proto/v1",
+				OperationMessages: true,
+				BodyStrategies:    test.strategies,
+			})
+			require.NoError(t, err)
+			for _, expected := range test.expected {
+				assert.Contains(t, string(result.Protobuf), expected)
+			}
+			assert.Equal(t, test.routes, result.Routes)
+		})
+	}
+}
+
+func TestConvertOperationMessagesFileUploads(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /profiles:
+    post:
+      operationId: createProfile
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                avatar:
+                  type: string
+                  format: binary
+                attachments:
+                  type: array
+                  items:
+                    type: string
+                    format: binary
+  /imports:
+    post:
+      operationId: startImport
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              $ref: '#/components/schemas/Import'
+  /notes:
+    post:
+      operationId: createNote
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              $ref: '#/components/schemas/Note'
+components:
+  schemas:
+    Import:
+      type: object
+      properties:
+        file:
+          type: string
+          format: binary
+        file_filename:
+          type: string
+          description: Original filename
+    Note:
+      type: object
+      properties:
+        text:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:       "testpkg",
+		PackagePath:       "github.com/example/proto/v1",
+		OperationMessages: true,
+	})
+	require.NoError(t, err)
+
+	for _, expected := range []string{
+		`message CreateProfileRequest {
+  string name = 1 [json_name = "name"];
+  bytes avatar = 2 [json_name = "avatar"];
+  // Filename of the avatar part
+  string avatar_filename = 3 [json_name = "avatar_filename"];
+  // Content type of the avatar part
+  string avatar_content_type = 4 [json_name = "avatar_content_type"];
+  repeated bytes attachments = 5 [json_name = "attachments"];
+  // Filenames of the attachments parts
+  repeated string attachments_filename = 6 [json_name = "attachments_filename"];
+  // Content types of the attachments parts
+  repeated string attachments_content_type = 7 [json_name = "attachments_content_type"];
+}`,
+		`message StartImportRequest {
+  bytes file = 1 [json_name = "file"];
+  // Content type of the file part
+  string file_content_type = 2 [json_name = "file_content_type"];
+  // Original filename
+  string file_filename = 3 [json_name = "file_filename"];
+}`,
+		`message Import {
+  bytes file = 1 [json_name = "file"];
+  // Original filename
+  string file_filename = 2 [json_name = "file_filename"];
+}`,
+	} {
+		assert.Contains(t, string(result.Protobuf), expected)
+	}
+	assert.Equal(t, []conv.Route{
+		{Method: "POST", Path: "/profiles", RPC: "CreateProfile", Request: "testpkg.CreateProfileRequest", ContentType: "multipart/form-data"},
+		{Method: "POST", Path: "/imports", RPC: "StartImport", Request: "testpkg.StartImportRequest", ContentType: "multipart/form-data"},
+		{Method: "POST", Path: "/notes", RPC: "CreateNote", Request: "testpkg.Note", ContentType: "multipart/form-data"},
+	}, result.Routes)
+}
@@ -0,0 +1,256 @@
+package conv
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// mediaTypePattern matches a media type without parameters, e.g. "application/json"
+var mediaTypePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9!#$&^_.+-]*/[a-z0-9][a-z0-9!#$&^_.+-]*$`)
+
+// protoPackage matches a proto package name, dot separated identifiers such as "api.v1"
+var protoPackage = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// OptionsError reports every problem found while validating ConvertOptions
+type OptionsError struct {
+	// Problems describes each invalid option or combination, e.g. "package name cannot be empty"
+	Problems []string
+}
+
+// Error lists all problems, separated by semicolons
+func (e *OptionsError) Error() string {
+	return "invalid options: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks every option up front, before any conversion work, and returns an
+// *OptionsError listing all problems, or nil if the options are usable
+func (opts ConvertOptions) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case opts.PackageName == "":
+		add("package name cannot be empty")
+	case !protoPackage.MatchString(opts.PackageName):
+		add("package name '%s' is not a valid proto package", opts.PackageName)
+	}
+
+	switch {
+	case opts.PackagePath == "":
+		add("package path cannot be empty")
+	case strings.ContainsAny(opts.PackagePath, "\" \t\n"):
+		add("package path '%s' cannot contain quotes or whitespace", opts.PackagePath)
+	}
+
+	switch {
+	case strings.ContainsAny(opts.GoPackagePath, "\" \t\n"):
+		add("go package path '%s' cannot contain quotes or whitespace", opts.GoPackagePath)
+	case opts.GoPackagePath != "":
+		if problem := goImportPathProblem(opts.GoPackagePath); problem != "" {
+			add("go package path '%s' %s", opts.GoPackagePath, problem)
+		}
+	}
+
+	switch {
+	case opts.ExampleOption.Name == "" && opts.ExampleOption.Import != "":
+		add("example option import requires ExampleOption.Name")
+	case opts.ExampleOption.Name == "":
+	case !protoPackage.MatchString(opts.ExampleOption.Name):
+		add("example option '%s' is not a valid proto extension name", opts.ExampleOption.Name)
+	case opts.ExampleOption.Import == "":
+		add("example option '%s' requires the import declaring it", opts.ExampleOption.Name)
+	case strings.ContainsAny(opts.ExampleOption.Import, "\" \t\n"):
+		add("example option import '%s' cannot contain quotes or whitespace", opts.ExampleOption.Import)
+	}
+
+	if opts.GoModFile != "" {
+		goPackagePath := opts.GoPackagePath
+		if goPackagePath == "" {
+			goPackagePath = opts.PackagePath
+		}
+		if problem := goModuleProblem(goPackagePath, opts.GoModFile); problem != "" {
+			add("%s", problem)
+		}
+	}
+
+	switch opts.FieldOrder {
+	case "", FieldOrderSpec, FieldOrderByNumber, FieldOrderAlphabetical:
+	default:
+		add("unknown field order: %s", opts.FieldOrder)
+	}
+
+	switch opts.OneOfStrategy {
+	case "", OneOfStrategyGo, OneOfStrategyProtoOneof:
+	default:
+		add("unknown oneOf strategy: %s", opts.OneOfStrategy)
+	}
+
+	switch opts.NullableFields {
+	case "", NullableFieldsIgnore, NullableFieldsOptional, NullableFieldsWrappers:
+	default:
+		add("unknown nullable fields style: %s", opts.NullableFields)
+	}
+
+	switch opts.SchemaAliases {
+	case "", SchemaAliasesCopy, SchemaAliasesSkip, SchemaAliasesComment:
+	default:
+		add("unknown schema aliases style: %s", opts.SchemaAliases)
+	}
+
+	switch opts.AnyOfStrategy {
+	case "", AnyOfStrategyWidest, AnyOfStrategyAny:
+	default:
+		add("unknown anyOf strategy: %s", opts.AnyOfStrategy)
+	}
+
+	switch opts.InlineObjects {
+	case "", InlineObjectsNested, InlineObjectsHoisted:
+	default:
+		add("unknown inline objects style: %s", opts.InlineObjects)
+	}
+
+	switch opts.InlineEnums {
+	case "", InlineEnumsFileScope, InlineEnumsNested:
+	default:
+		add("unknown inline enums placement: %s", opts.InlineEnums)
+	}
+
+	switch opts.EmptyMessages {
+	case "", EmptyMessagesKeep, EmptyMessagesOmit, EmptyMessagesWellKnown, EmptyMessagesTODO:
+	default:
+		add("unknown empty messages mode: %s", opts.EmptyMessages)
+	}
+
+	if opts.Format.IndentWidth < 0 {
+		add("indent width cannot be negative")
+	}
+
+	if opts.Format.MaxCommentWidth < 0 {
+		add("max comment width cannot be negative")
+	}
+
+	if opts.Descriptions.MaxLength < 0 {
+		add("description max length cannot be negative")
+	}
+
+	switch opts.Descriptions.Require {
+	case "", RequireDescriptionsWarn, RequireDescriptionsError:
+	default:
+		add("unknown description requirement: %s", opts.Descriptions.Require)
+	}
+
+	if opts.Limits.MaxSpecBytes < 0 || opts.Limits.MaxDepth < 0 || opts.Limits.MaxMessages < 0 {
+		add("limits cannot be negative")
+	}
+
+	if strings.Contains(opts.ResourceReferences.Service, "/") {
+		add("resource reference service '%s' cannot contain '/'", opts.ResourceReferences.Service)
+	}
+
+	if strings.ContainsAny(opts.Provenance.Source, "\r\n") {
+		add("provenance source '%s' cannot contain line breaks", opts.Provenance.Source)
+	}
+	for _, command := range opts.Provenance.GoGenerate {
+		if strings.TrimSpace(command) == "" || strings.ContainsAny(command, "\r\n") {
+			add("go:generate command '%s' must be a single non-empty line", command)
+		}
+	}
+
+	if _, err := renderHeader("proto header", opts.ProtoHeader, HeaderData{}); err != nil {
+		add("%v", err)
+	}
+	if _, err := renderHeader("go header", opts.GoHeader, HeaderData{}); err != nil {
+		add("%v", err)
+	}
+
+	if opts.RootDir != "" {
+		if opts.BaseDir == "" {
+			add("root dir requires BaseDir")
+		} else if !insideDir(opts.RootDir, opts.BaseDir) {
+			add("base dir '%s' is outside root dir '%s'", opts.BaseDir, opts.RootDir)
+		}
+	}
+
+	for _, host := range opts.RemoteRefs.AllowedHosts {
+		if host == "" || host != strings.ToLower(host) || strings.ContainsAny(host, "/: ") {
+			add("allowed host '%s' must be a lower case host name without scheme, port or path", host)
+		}
+	}
+	if opts.RemoteRefs.Timeout < 0 {
+		add("remote reference timeout cannot be negative")
+	}
+
+	if opts.OperationsOnly && opts.LowMemory {
+		add("operations only cannot be combined with low memory, which drops paths")
+	}
+	if opts.OperationMessages && opts.LowMemory {
+		add("operation messages cannot be combined with low memory, which drops paths")
+	}
+	if len(opts.BodyStrategies) > 0 && !opts.OperationMessages {
+		add("body strategies require OperationMessages")
+	}
+	for _, mediaType := range sortedKeys(opts.BodyStrategies) {
+		switch strategy := opts.BodyStrategies[mediaType]; {
+		case !mediaTypePattern.MatchString(mediaType):
+			add("body strategy media type '%s' must be a lower case type/subtype without parameters", mediaType)
+		case strategy != BodyStrategyFields && strategy != BodyStrategyBytes:
+			add("unknown body strategy for '%s': %s", mediaType, strategy)
+		}
+	}
+
+	for _, from := range sortedKeys(opts.ImportRewrites) {
+		to := opts.ImportRewrites[from]
+		switch {
+		case from == "" || to == "":
+			add("import rewrite '%s' to '%s' cannot have an empty path", from, to)
+		case strings.ContainsAny(from+to, "\" \t\n"):
+			add("import rewrite '%s' to '%s' cannot contain quotes or whitespace", from, to)
+		case strings.Contains(strings.TrimSuffix(from, "*"), "*") || strings.Contains(strings.TrimSuffix(to, "*"), "*"):
+			add("import rewrite '%s' to '%s' can only use '*' at the end of a path", from, to)
+		case strings.HasSuffix(to, "*") && !strings.HasSuffix(from, "*"):
+			add("import rewrite '%s' to '%s' cannot end in '*' unless the path it replaces does", from, to)
+		}
+	}
+
+	for _, path := range sortedKeys(opts.ImportKinds) {
+		switch opts.ImportKinds[path] {
+		case ImportPublic, ImportWeak:
+		default:
+			add("unknown import kind for '%s': %s", path, opts.ImportKinds[path])
+		}
+	}
+
+	if len(problems) > 0 {
+		return &OptionsError{Problems: problems}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in order, so problems are reported deterministically
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// insideDir reports whether path is dir or lies below it, comparing absolute paths
+func insideDir(dir, path string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,307 @@
+package conv_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertOptionsValidate(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		opts     conv.ConvertOptions
+		problems []string
+	}{
+		{
+			name: "valid",
+			opts: conv.ConvertOptions{
+				PackageName: "api.v1",
+				PackagePath: "github.com/example/proto/v1",
+			},
+		},
+		{
+			name: "all problems at once",
+			opts: conv.ConvertOptions{
+				PackageName:   "api-v1",
+				GoPackagePath: "github.com/example/go v1",
+				FieldOrder:    "random",
+				InlineObjects: "flat",
+				InlineEnums:   "global",
+				Format:        conv.FormatOptions{IndentWidth: -1, MaxCommentWidth: -1},
+				Descriptions:  conv.DescriptionOptions{MaxLength: -1},
+				Limits:        conv.Limits{MaxDepth: -1},
+				ResourceReferences: conv.ResourceReferenceOptions{
+					Service: "library.example.com/v1",
+				},
+			},
+			problems: []string{
+				"package name 'api-v1' is not a valid proto package",
+				"package path cannot be empty",
+				"go package path 'github.com/example/go v1' cannot contain quotes or whitespace",
+				"unknown field order: random",
+				"unknown inline objects style: flat",
+				"unknown inline enums placement: global",
+				"indent width cannot be negative",
+				"max comment width cannot be negative",
+				"description max length cannot be negative",
+				"limits cannot be negative",
+				"resource reference service 'library.example.com/v1' cannot contain '/'",
+			},
+		},
+		{
+			name: "package path with quote",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: `github.com/example/"proto"`,
+			},
+			problems: []string{
+				`package path 'github.com/example/"proto"' cannot contain quotes or whitespace`,
+			},
+		},
+		{
+			name: "invalid remote refs",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				RemoteRefs: conv.RemoteRefOptions{
+					AllowedHosts: []string{"https://schemas.example.com"},
+					Timeout:      -1,
+				},
+			},
+			problems: []string{
+				"allowed host 'https://schemas.example.com' must be a lower case host name without scheme, port or path",
+				"remote reference timeout cannot be negative",
+			},
+		},
+		{
+			name: "invalid body strategies",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				BodyStrategies: map[string]conv.BodyStrategy{
+					"Application/JSON":    conv.BodyStrategyBytes,
+					"multipart/form-data": "parts",
+				},
+			},
+			problems: []string{
+				"body strategies require OperationMessages",
+				"body strategy media type 'Application/JSON' must be a lower case type/subtype without parameters",
+				"unknown body strategy for 'multipart/form-data': parts",
+			},
+		},
+		{
+			name: "root dir without base dir",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				RootDir:     "specs",
+			},
+			problems: []string{"root dir requires BaseDir"},
+		},
+		{
+			name: "base dir outside root dir",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				BaseDir:     "specs/../common",
+				RootDir:     "specs",
+			},
+			problems: []string{"base dir 'specs/../common' is outside root dir 'specs'"},
+		},
+		{
+			name: "base dir inside root dir",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				BaseDir:     "specs/api",
+				RootDir:     "specs",
+			},
+		},
+		{
+			name: "invalid import rewrites",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				ImportRewrites: map[string]string{
+					"google/api/field_behavior.proto": "",
+					"google/*/annotations.proto":      "vendor/annotations.proto",
+					"google/type/date.proto":          "vendor/google/type/*",
+				},
+			},
+			problems: []string{
+				"import rewrite 'google/*/annotations.proto' to 'vendor/annotations.proto' can only use '*' at the end of a path",
+				"import rewrite 'google/api/field_behavior.proto' to '' cannot have an empty path",
+				"import rewrite 'google/type/date.proto' to 'vendor/google/type/*' cannot end in '*' unless the path it replaces does",
+			},
+		},
+		{
+			name: "operations only with low memory",
+			opts: conv.ConvertOptions{
+				PackageName:    "testpkg",
+				PackagePath:    "github.com/example/proto/v1",
+				OperationsOnly: true,
+				LowMemory:      true,
+			},
+			problems: []string{
+				"operations only cannot be combined with low memory, which drops paths",
+			},
+		},
+		{
+			name: "operation messages with low memory",
+			opts: conv.ConvertOptions{
+				PackageName:       "testpkg",
+				PackagePath:       "github.com/example/proto/v1",
+				OperationMessages: true,
+				LowMemory:         true,
+			},
+			problems: []string{
+				"operation messages cannot be combined with low memory, which drops paths",
+			},
+		},
+		{
+			name: "multi-line provenance",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				Provenance: conv.ProvenanceOptions{
+					Source:     "api.yaml\nrm",
+					GoGenerate: []string{"go run ./gen", " "},
+				},
+			},
+			problems: []string{
+				"provenance source 'api.yaml\nrm' cannot contain line breaks",
+				"go:generate command ' ' must be a single non-empty line",
+			},
+		},
+		{
+			name: "unimportable go package path",
+			opts: conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				GoPackagePath: "github.com/example/../go/v1",
+			},
+			problems: []string{
+				"go package path 'github.com/example/../go/v1' cannot contain '.' or '..' elements",
+			},
+		},
+		{
+			name: "go package path with invalid character",
+			opts: conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				GoPackagePath: "github.com/example/go;api",
+			},
+			problems: []string{
+				`go package path 'github.com/example/go;api' contains ';', which is not allowed in a Go import path`,
+			},
+		},
+		{
+			name: "invalid example option name",
+			opts: conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				ExampleOption: conv.ExampleOption{Name: "(api.example)"},
+			},
+			problems: []string{
+				"example option '(api.example)' is not a valid proto extension name",
+			},
+		},
+		{
+			name: "unknown import kind",
+			opts: conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				ImportKinds: map[string]conv.ImportKind{"google/api/resource.proto": "strong"},
+			},
+			problems: []string{
+				"unknown import kind for 'google/api/resource.proto': strong",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.opts.Validate()
+			if test.problems == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var optsErr *conv.OptionsError
+			require.True(t, errors.As(err, &optsErr))
+			assert.Equal(t, test.problems, optsErr.Problems)
+		})
+	}
+}
+
+func TestConvertReturnsOptionsError(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+`
+
+	_, err := conv.Convert([]byte(given), conv.ConvertOptions{FieldOrder: "random"})
+	require.ErrorContains(t, err, "invalid options: package name cannot be empty; package path cannot be empty; unknown field order: random")
+
+	var optsErr *conv.OptionsError
+	require.True(t, errors.As(err, &optsErr))
+	assert.Len(t, optsErr.Problems, 3)
+}
+
+func TestConvertOptionsGoModFile(t *testing.T) {
+	modFile := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(modFile, []byte("// service module\nmodule github.com/example/service // main\n\ngo 1.24\n"), 0o644))
+
+	for _, test := range []struct {
+		name          string
+		goPackagePath string
+		modFile       string
+		problem       string
+	}{
+		{
+			name:          "inside module",
+			goPackagePath: "github.com/example/service/gen/api",
+			modFile:       modFile,
+		},
+		{
+			name:    "defaults to package path",
+			modFile: modFile,
+			problem: "go package path 'github.com/example/proto/v1' is outside module 'github.com/example/service' declared in '" +
+				modFile + "', so the module cannot import the generated code; set GoPackagePath to a path inside the module, " +
+				"e.g. 'github.com/example/service/v1'",
+		},
+		{
+			name:          "prefix is not a parent",
+			goPackagePath: "github.com/example/service2/api",
+			modFile:       modFile,
+			problem:       "go package path 'github.com/example/service2/api' is outside module 'github.com/example/service'",
+		},
+		{
+			name:    "missing go.mod",
+			modFile: filepath.Join(t.TempDir(), "go.mod"),
+			problem: "cannot read module path from",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				GoPackagePath: test.goPackagePath,
+				GoModFile:     test.modFile,
+			}.Validate()
+			if test.problem == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, test.problem)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package conv
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ArtifactKind identifies the kind of an artifact passed to OutputSink.WriteArtifact
+type ArtifactKind string
+
+const (
+	// ArtifactExample is a sample protobuf JSON document from ConvertResult.Examples
+	ArtifactExample ArtifactKind = "example"
+	// ArtifactFixture is a protobuf text format instance from ConvertResult.Fixtures
+	ArtifactFixture ArtifactKind = "fixture"
+)
+
+// OutputSink receives the files of a ConvertResult, so callers can stream them into zip
+// archives, virtual filesystems or code review APIs instead of handling each field
+type OutputSink interface {
+	// WriteProtoFile receives the proto output, named after the package, e.g. "api.proto"
+	WriteProtoFile(name string, data []byte) error
+	// WriteGoFile receives the Go output, named after the Go package, e.g. "types.go"
+	WriteGoFile(name string, data []byte) error
+	// WriteArtifact receives every other output, named after the proto message, e.g.
+	// "User.json" for an example or "User.txtpb" for a fixture
+	WriteArtifact(kind ArtifactKind, name string, data []byte) error
+}
+
+// Write passes every non-empty output of the result to sink: the proto file, the Go
+// file, examples and fixtures sorted by message name, then the route table. It stops
+// at the first error returned by the sink.
+func (r *ConvertResult) Write(sink OutputSink) error {
+	if len(r.Protobuf) > 0 {
+		if err := sink.WriteProtoFile(r.packageName+".proto", r.Protobuf); err != nil {
+			return fmt.Errorf("failed to write proto file: %w", err)
+		}
+	}
+
+	if len(r.Golang) > 0 {
+		if err := sink.WriteGoFile(r.goPackageName+".go", r.Golang); err != nil {
+			return fmt.Errorf("failed to write Go file: %w", err)
+		}
+	}
+
+	if err := writeArtifacts(sink, ArtifactExample, r.Examples, ".json"); err != nil {
+		return err
+	}
+	if err := writeArtifacts(sink, ArtifactFixture, r.Fixtures, ".txtpb"); err != nil {
+		return err
+	}
+
+	if len(r.Routes) > 0 {
+		data, err := r.RouteTable()
+		if err != nil {
+			return err
+		}
+		if err := sink.WriteArtifact(ArtifactRoutes, routesFile, data); err != nil {
+			return fmt.Errorf("failed to write route table: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeArtifacts writes artifacts of one kind in message name order
+func writeArtifacts(sink OutputSink, kind ArtifactKind, artifacts map[string][]byte, ext string) error {
+	names := make([]string, 0, len(artifacts))
+	for name := range artifacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := sink.WriteArtifact(kind, name+ext, artifacts[name]); err != nil {
+			return fmt.Errorf("failed to write %s '%s': %w", kind, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package conv_test
+
+import (
+	"errors"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const outputSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+    User:
+      type: object
+      example:
+        name: Ada
+      properties:
+        name:
+          type: string
+    Order:
+      type: object
+      example:
+        id: o-1
+      properties:
+        id:
+          type: string
+`
+
+// recordingSink records the name of each file written, prefixed by its kind
+type recordingSink struct {
+	written []string
+	fail    string
+}
+
+func (s *recordingSink) WriteProtoFile(name string, data []byte) error {
+	return s.write("proto", name)
+}
+
+func (s *recordingSink) WriteGoFile(name string, data []byte) error {
+	return s.write("go", name)
+}
+
+func (s *recordingSink) WriteArtifact(kind conv.ArtifactKind, name string, data []byte) error {
+	return s.write(string(kind), name)
+}
+
+func (s *recordingSink) write(kind, name string) error {
+	if name == s.fail {
+		return errors.New("disk full")
+	}
+	s.written = append(s.written, kind+" "+name)
+	return nil
+}
+
+func TestConvertResultWrite(t *testing.T) {
+	result, err := conv.Convert([]byte(outputSpec), conv.ConvertOptions{
+		PackageName:   "testpkg",
+		PackagePath:   "github.com/example/proto/v1",
+		GoPackagePath: "github.com/example/types/v1",
+		EmitExamples:  true,
+		EmitFixtures:  true,
+	})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name     string
+		fail     string
+		written  []string
+		errorMsg string
+	}{
+		{
+			name: "all outputs",
+			written: []string{
+				"proto testpkg.proto",
+				"go types.go",
+				"example Order.json",
+				"example User.json",
+				"fixture Order.txtpb",
+				"fixture User.txtpb",
+			},
+		},
+		{
+			name: "stops at first error",
+			fail: "User.json",
+			written: []string{
+				"proto testpkg.proto",
+				"go types.go",
+				"example Order.json",
+			},
+			errorMsg: "failed to write example 'User': disk full",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			sink := &recordingSink{fail: test.fail}
+			err := result.Write(sink)
+			if test.errorMsg != "" {
+				require.ErrorContains(t, err, test.errorMsg)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.written, sink.written)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Package plugin runs the OpenAPI conversion as a protoc or buf plugin, so pipelines
+// built on `buf generate` can produce proto and Go output from OpenAPI specs with the
+// same tooling as their other code generation.
+//
+// Each proto file to generate anchors one OpenAPI spec. The spec is found, in order,
+// from the source plugin parameter, from an "x-openapi-source: <path>" line in a comment
+// of the anchor file, or from a sidecar file next to it with the .proto extension replaced
+// by .openapi.yaml. Paths are relative to the directory the plugin runs in. The outputs
+// are written next to the anchor file.
+//
+// Parameters are comma separated key=value pairs:
+//
+//   - source: path of the OpenAPI spec, overriding the lookup above
+//   - package: proto package name, defaults to the package of the anchor file
+//   - package_path: proto package path, defaults to the go_package of the anchor file
+//   - go_package_path: Go package path, defaults to package_path
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// sourceKey introduces the spec path in a comment of the anchor file
+const sourceKey = "x-openapi-source:"
+
+// sidecarExt replaces .proto in the name of the anchor file to find a sidecar spec
+const sidecarExt = ".openapi.yaml"
+
+// parameters are the options passed to the plugin
+type parameters struct {
+	source        string
+	packageName   string
+	packagePath   string
+	goPackagePath string
+}
+
+// parseParameters parses the comma separated key=value parameter of a request
+func parseParameters(parameter string) (parameters, error) {
+	var params parameters
+	if parameter == "" {
+		return params, nil
+	}
+	for _, pair := range strings.Split(parameter, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return params, fmt.Errorf("parameter '%s' must be key=value", pair)
+		}
+		switch key {
+		case "source":
+			params.source = value
+		case "package":
+			params.packageName = value
+		case "package_path":
+			params.packagePath = value
+		case "go_package_path":
+			params.goPackagePath = value
+		default:
+			return params, fmt.Errorf("unknown parameter '%s'", key)
+		}
+	}
+	return params, nil
+}
+
+// Generate converts the spec anchored by each file to generate and returns the outputs,
+// or a response with Error set describing the first failure
+func Generate(req *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	resp := &pluginpb.CodeGeneratorResponse{
+		SupportedFeatures: proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)),
+	}
+
+	params, err := parseParameters(req.GetParameter())
+	if err != nil {
+		resp.Error = proto.String(err.Error())
+		return resp
+	}
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto, len(req.GetProtoFile()))
+	for _, file := range req.GetProtoFile() {
+		files[file.GetName()] = file
+	}
+
+	for _, name := range req.GetFileToGenerate() {
+		file, ok := files[name]
+		if !ok {
+			resp.Error = proto.String(fmt.Sprintf("%s: file to generate is missing from the request", name))
+			return resp
+		}
+		if err := generateFile(file, params, resp); err != nil {
+			resp.Error = proto.String(fmt.Sprintf("%s: %v", name, err))
+			return resp
+		}
+	}
+	return resp
+}
+
+// generateFile converts the spec anchored by file and adds its outputs to resp
+func generateFile(file *descriptorpb.FileDescriptorProto, params parameters, resp *pluginpb.CodeGeneratorResponse) error {
+	source, err := findSource(file, params)
+	if err != nil {
+		return err
+	}
+	spec, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI source: %w", err)
+	}
+
+	opts := conv.ConvertOptions{
+		PackageName:   params.packageName,
+		PackagePath:   params.packagePath,
+		GoPackagePath: params.goPackagePath,
+	}
+	if opts.PackageName == "" {
+		opts.PackageName = file.GetPackage()
+	}
+	if opts.PackagePath == "" {
+		opts.PackagePath, _, _ = strings.Cut(file.GetOptions().GetGoPackage(), ";")
+	}
+
+	result, err := conv.Convert(spec, opts)
+	if err != nil {
+		return fmt.Errorf("failed to convert '%s': %w", source, err)
+	}
+	return result.Write(&responseSink{dir: path.Dir(file.GetName()), resp: resp})
+}
+
+// findSource returns the path of the spec anchored by file
+func findSource(file *descriptorpb.FileDescriptorProto, params parameters) (string, error) {
+	if params.source != "" {
+		return params.source, nil
+	}
+
+	for _, location := range file.GetSourceCodeInfo().GetLocation() {
+		comments := append([]string{location.GetLeadingComments(), location.GetTrailingComments()},
+			location.GetLeadingDetachedComments()...)
+		for _, comment := range comments {
+			for _, line := range strings.Split(comment, "\n") {
+				if source, ok := strings.CutPrefix(strings.TrimSpace(line), sourceKey); ok {
+					return strings.TrimSpace(source), nil
+				}
+			}
+		}
+	}
+
+	sidecar := strings.TrimSuffix(file.GetName(), ".proto") + sidecarExt
+	if _, err := os.Stat(sidecar); err == nil {
+		return sidecar, nil
+	}
+	return "", fmt.Errorf("no OpenAPI source: set the source parameter, add an '%s <path>' comment or create %s",
+		sourceKey, sidecar)
+}
+
+// responseSink adds the outputs of a conversion to a plugin response
+type responseSink struct {
+	dir  string
+	resp *pluginpb.CodeGeneratorResponse
+}
+
+func (s *responseSink) WriteProtoFile(name string, data []byte) error {
+	s.add(name, data)
+	return nil
+}
+
+func (s *responseSink) WriteGoFile(name string, data []byte) error {
+	s.add(name, data)
+	return nil
+}
+
+func (s *responseSink) WriteArtifact(kind conv.ArtifactKind, name string, data []byte) error {
+	s.add(path.Join(string(kind), name), data)
+	return nil
+}
+
+func (s *responseSink) add(name string, data []byte) {
+	s.resp.File = append(s.resp.File, &pluginpb.CodeGeneratorResponse_File{
+		Name:    proto.String(path.Join(s.dir, name)),
+		Content: proto.String(string(data)),
+	})
+}
@@ -0,0 +1,123 @@
+package plugin_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/openapi-proto.go/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const spec = `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+const expected = `syntax = "proto3";
+
+package api.v1;
+
+option go_package = "github.com/example/api/v1";
+
+message User {
+  string name = 1 [json_name = "name"];
+}
+
+`
+
+// anchor returns a request generating api/v1/anchor.proto with the given comment
+func anchor(parameter, comment string) *pluginpb.CodeGeneratorRequest {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("api/v1/anchor.proto"),
+		Package: proto.String("api.v1"),
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("github.com/example/api/v1;apiv1")},
+	}
+	if comment != "" {
+		file.SourceCodeInfo = &descriptorpb.SourceCodeInfo{
+			Location: []*descriptorpb.SourceCodeInfo_Location{{LeadingDetachedComments: []string{comment}}},
+		}
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"api/v1/anchor.proto"},
+		Parameter:      proto.String(parameter),
+		ProtoFile:      []*descriptorpb.FileDescriptorProto{file},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("spec.yaml", []byte(spec), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join("api", "v1"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join("api", "v1", "anchor.openapi.yaml"), []byte(spec), 0o644))
+
+	for _, test := range []struct {
+		name    string
+		req     *pluginpb.CodeGeneratorRequest
+		file    string
+		content string
+		err     string
+	}{
+		{
+			name:    "source parameter",
+			req:     anchor("source=spec.yaml,package=api.v2", ""),
+			file:    "api/v1/api.v2.proto",
+			content: "package api.v2;",
+		},
+		{
+			name:    "source comment",
+			req:     anchor("", " x-openapi-source: spec.yaml\n"),
+			file:    "api/v1/api.v1.proto",
+			content: expected,
+		},
+		{
+			name:    "sidecar",
+			req:     anchor("", ""),
+			file:    "api/v1/api.v1.proto",
+			content: expected,
+		},
+		{
+			name: "missing source",
+			req:  anchor("", " x-openapi-source: missing.yaml"),
+			err:  "api/v1/anchor.proto: failed to read OpenAPI source",
+		},
+		{
+			name: "unknown parameter",
+			req:  anchor("spec=spec.yaml", ""),
+			err:  "unknown parameter 'spec'",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp := plugin.Generate(test.req)
+			if test.err != "" {
+				assert.Contains(t, resp.GetError(), test.err)
+				return
+			}
+			require.Empty(t, resp.GetError())
+			require.Len(t, resp.GetFile(), 1)
+			assert.Equal(t, test.file, resp.GetFile()[0].GetName())
+			assert.Contains(t, resp.GetFile()[0].GetContent(), test.content)
+		})
+	}
+}
+
+func TestGenerateWithoutSource(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	resp := plugin.Generate(anchor("", ""))
+	assert.Equal(t, "api/v1/anchor.proto: no OpenAPI source: set the source parameter, add an "+
+		"'x-openapi-source: <path>' comment or create api/v1/anchor.openapi.yaml", resp.GetError())
+}
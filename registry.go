@@ -0,0 +1,456 @@
+package conv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/duh-rpc/openapi-proto.go/internal"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// wellKnownTypes maps the well-known messages the proto output can reference to the
+// files declaring them
+var wellKnownTypes = map[string]protoreflect.FileDescriptor{
+	"google.protobuf.Timestamp":   timestamppb.File_google_protobuf_timestamp_proto,
+	"google.protobuf.Duration":    durationpb.File_google_protobuf_duration_proto,
+	"google.type.Date":            dateFile,
+	"google.type.TimeOfDay":       timeOfDayFile,
+	"google.protobuf.Empty":       emptypb.File_google_protobuf_empty_proto,
+	"google.protobuf.Any":         anypb.File_google_protobuf_any_proto,
+	"google.protobuf.DoubleValue": wrapperspb.File_google_protobuf_wrappers_proto,
+	"google.protobuf.FloatValue":  wrapperspb.File_google_protobuf_wrappers_proto,
+	"google.protobuf.Int64Value":  wrapperspb.File_google_protobuf_wrappers_proto,
+	"google.protobuf.UInt64Value": wrapperspb.File_google_protobuf_wrappers_proto,
+	"google.protobuf.Int32Value":  wrapperspb.File_google_protobuf_wrappers_proto,
+	"google.protobuf.UInt32Value": wrapperspb.File_google_protobuf_wrappers_proto,
+	"google.protobuf.BoolValue":   wrapperspb.File_google_protobuf_wrappers_proto,
+	"google.protobuf.StringValue": wrapperspb.File_google_protobuf_wrappers_proto,
+	"google.protobuf.BytesValue":  wrapperspb.File_google_protobuf_wrappers_proto,
+}
+
+// The google.type messages are not part of the protobuf module, so their files are
+// declared here as protoc would compile them
+var (
+	dateFile      = googleTypeFile("google/type/date.proto", "Date", "year", "month", "day")
+	timeOfDayFile = googleTypeFile("google/type/timeofday.proto", "TimeOfDay", "hours", "minutes", "seconds", "nanos")
+)
+
+// googleTypeFile builds the descriptor of a google.type file declaring one message with
+// int32 fields
+func googleTypeFile(path, message string, fields ...string) protoreflect.FileDescriptor {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(message)}
+	for i, field := range fields {
+		msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(field),
+			JsonName: proto.String(field),
+			Number:   proto.Int32(int32(i + 1)),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+		})
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String(path),
+		Package:     proto.String("google.type"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{msg},
+	}, new(protoregistry.Files))
+	if err != nil {
+		panic(fmt.Sprintf("invalid descriptor for %s: %v", path, err))
+	}
+	return file
+}
+
+var scalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"double":   descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"float":    descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+	"int32":    descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"int64":    descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint32":   descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"uint64":   descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"sint32":   descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+	"sint64":   descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+	"fixed32":  descriptorpb.FieldDescriptorProto_TYPE_FIXED32,
+	"fixed64":  descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+	"sfixed32": descriptorpb.FieldDescriptorProto_TYPE_SFIXED32,
+	"sfixed64": descriptorpb.FieldDescriptorProto_TYPE_SFIXED64,
+	"bool":     descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"string":   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bytes":    descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+}
+
+// FileDescriptor builds a descriptor for the proto output from Definitions, equivalent
+// to compiling Protobuf with protoc. Returns an error if the result has no proto output,
+// or if a field carries an option that cannot be represented, see Files.
+func (r *ConvertResult) FileDescriptor() (*descriptorpb.FileDescriptorProto, error) {
+	if len(r.Protobuf) == 0 {
+		return nil, fmt.Errorf("result has no proto output")
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(r.packageName + ".proto"),
+		Package: proto.String(r.packageName),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String(r.packagePath)},
+	}
+	for _, option := range r.fileOptions {
+		setFileOption(file.Options, option)
+	}
+
+	// Index every enum and message by its full name so field types can be resolved
+	known := make(map[string]descriptorpb.FieldDescriptorProto_Type)
+	for _, def := range r.Definitions {
+		switch d := def.(type) {
+		case *ProtoEnum:
+			known[r.packageName+"."+d.Name] = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+		case *ProtoMessage:
+			indexMessage(known, r.packageName, d)
+		}
+	}
+
+	dependencies := make(map[string]bool)
+	for _, def := range r.Definitions {
+		switch d := def.(type) {
+		case *ProtoEnum:
+			file.EnumType = append(file.EnumType, enumDescriptor(d))
+		case *ProtoMessage:
+			msg, err := messageDescriptor(d, r.packageName, known, dependencies)
+			if err != nil {
+				return nil, err
+			}
+			file.MessageType = append(file.MessageType, msg)
+		}
+	}
+
+	file.Dependency = sortedKeys(dependencies)
+
+	return file, nil
+}
+
+// setFileOption sets the language option rendered as option in the proto output
+func setFileOption(options *descriptorpb.FileOptions, option internal.FileOption) {
+	switch option.Name {
+	case "java_package":
+		options.JavaPackage = proto.String(option.Value)
+	case "java_outer_classname":
+		options.JavaOuterClassname = proto.String(option.Value)
+	case "java_multiple_files":
+		options.JavaMultipleFiles = proto.Bool(option.Value == "true")
+	case "csharp_namespace":
+		options.CsharpNamespace = proto.String(option.Value)
+	case "objc_class_prefix":
+		options.ObjcClassPrefix = proto.String(option.Value)
+	case "php_namespace":
+		options.PhpNamespace = proto.String(option.Value)
+	case "ruby_package":
+		options.RubyPackage = proto.String(option.Value)
+	case "swift_prefix":
+		options.SwiftPrefix = proto.String(option.Value)
+	}
+}
+
+// Files returns a registry containing the proto output, suitable for dynamic
+// marshaling with dynamicpb and protojson inside the same process. Field options keep
+// debug_redact, and the google.api field_behavior and resource_reference extensions as
+// unknown fields of FieldOptions. Fields carrying ExampleOption return an error, since
+// the number of that extension is not known.
+func (r *ConvertResult) Files() (*protoregistry.Files, error) {
+	file, err := r.FileDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	// Register the well-known files first, so the imports of the output resolve
+	files := new(protoregistry.Files)
+	for _, name := range sortedKeys(wellKnownTypes) {
+		// Several wrapper messages share a file
+		if _, err := files.FindFileByPath(wellKnownTypes[name].Path()); err == nil {
+			continue
+		}
+		if err := files.RegisterFile(wellKnownTypes[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	desc, err := protodesc.NewFile(file, files)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proto output: %w", err)
+	}
+	if err := files.RegisterFile(desc); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// NewMessage returns an empty dynamic message for the named proto message. Nested
+// messages are named with their parent, e.g. "Order.Item".
+func (r *ConvertResult) NewMessage(name string) (*dynamicpb.Message, error) {
+	files, err := r.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(r.packageName + "." + name))
+	if err != nil {
+		return nil, fmt.Errorf("message '%s' not found in proto output", name)
+	}
+
+	msg, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a message", name)
+	}
+
+	return dynamicpb.NewMessage(msg), nil
+}
+
+// fieldBehaviorNumbers maps google.api.FieldBehavior names to their numbers
+var fieldBehaviorNumbers = map[string]uint64{
+	"OPTIONAL":          1,
+	"REQUIRED":          2,
+	"OUTPUT_ONLY":       3,
+	"INPUT_ONLY":        4,
+	"IMMUTABLE":         5,
+	"UNORDERED_LIST":    6,
+	"NON_EMPTY_DEFAULT": 7,
+	"IDENTIFIER":        8,
+}
+
+// Field numbers of the google.api extensions of google.protobuf.FieldOptions
+const (
+	fieldBehaviorExtension     protowire.Number = 1052
+	resourceReferenceExtension protowire.Number = 1055
+)
+
+// fieldOptionsDescriptor converts the options of a field to FieldOptions. The google.api
+// extensions are encoded as the unknown fields protoc would write, since their
+// descriptors are not linked in, and read back once the extension is registered. Options
+// of other extensions, such as ExampleOption, return an error listing them, since their
+// field numbers are not known.
+func fieldOptionsDescriptor(options []string) (*descriptorpb.FieldOptions, error) {
+	result := &descriptorpb.FieldOptions{}
+	var unknown []byte
+	var unsupported []string
+	for _, option := range options {
+		name, value, _ := strings.Cut(option, " = ")
+		switch name {
+		case "debug_redact":
+			result.DebugRedact = proto.Bool(value == "true")
+		case "(google.api.field_behavior)":
+			number, ok := fieldBehaviorNumbers[value]
+			if !ok {
+				unsupported = append(unsupported, option)
+				continue
+			}
+			unknown = protowire.AppendTag(unknown, fieldBehaviorExtension, protowire.VarintType)
+			unknown = protowire.AppendVarint(unknown, number)
+		case "(google.api.resource_reference)":
+			quoted, ok := strings.CutPrefix(value, "{type: ")
+			resource, err := strconv.Unquote(strings.TrimSuffix(quoted, "}"))
+			if !ok || err != nil {
+				unsupported = append(unsupported, option)
+				continue
+			}
+			// ResourceReference.type is field 1
+			reference := protowire.AppendTag(nil, 1, protowire.BytesType)
+			reference = protowire.AppendString(reference, resource)
+			unknown = protowire.AppendTag(unknown, resourceReferenceExtension, protowire.BytesType)
+			unknown = protowire.AppendBytes(unknown, reference)
+		default:
+			unsupported = append(unsupported, option)
+		}
+	}
+	if len(unsupported) > 0 {
+		return nil, fmt.Errorf("field options cannot be represented in a descriptor: %s", strings.Join(unsupported, ", "))
+	}
+	result.ProtoReflect().SetUnknown(unknown)
+	return result, nil
+}
+
+// indexMessage records the full name of a message and its nested messages and enums
+func indexMessage(known map[string]descriptorpb.FieldDescriptorProto_Type, scope string, msg *ProtoMessage) {
+	fullName := scope + "." + msg.Name
+	known[fullName] = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	for _, enum := range msg.Enums {
+		known[fullName+"."+enum.Name] = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	}
+	for _, nested := range msg.Nested {
+		indexMessage(known, fullName, nested)
+	}
+}
+
+// enumDescriptor converts an enum definition to its descriptor
+func enumDescriptor(enum *ProtoEnum) *descriptorpb.EnumDescriptorProto {
+	result := &descriptorpb.EnumDescriptorProto{Name: proto.String(enum.Name)}
+	for _, value := range enum.Values {
+		result.Value = append(result.Value, &descriptorpb.EnumValueDescriptorProto{
+			Name:   proto.String(value.Name),
+			Number: proto.Int32(int32(value.Number)),
+		})
+	}
+	return result
+}
+
+// messageDescriptor converts a message definition and its nested messages and enums to a descriptor.
+// scope is the full name of the enclosing package or message.
+func messageDescriptor(msg *ProtoMessage, scope string, known map[string]descriptorpb.FieldDescriptorProto_Type, dependencies map[string]bool) (*descriptorpb.DescriptorProto, error) {
+	fullName := scope + "." + msg.Name
+	result := &descriptorpb.DescriptorProto{Name: proto.String(msg.Name)}
+
+	for _, enum := range msg.Enums {
+		result.EnumType = append(result.EnumType, enumDescriptor(enum))
+	}
+
+	for _, nested := range msg.Nested {
+		nestedDesc, err := messageDescriptor(nested, fullName, known, dependencies)
+		if err != nil {
+			return nil, err
+		}
+		result.NestedType = append(result.NestedType, nestedDesc)
+	}
+
+	for _, number := range msg.ReservedNumbers {
+		result.ReservedRange = append(result.ReservedRange, &descriptorpb.DescriptorProto_ReservedRange{
+			Start: proto.Int32(int32(number)),
+			End:   proto.Int32(int32(number) + 1),
+		})
+	}
+	result.ReservedName = append(result.ReservedName, msg.ReservedNames...)
+	if msg.Oneof != "" {
+		result.OneofDecl = append(result.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(msg.Oneof)})
+	}
+
+	for _, field := range msg.Fields {
+		fieldDesc := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(field.Name),
+			Number: proto.Int32(int32(field.Number)),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if msg.Oneof != "" {
+			fieldDesc.OneofIndex = proto.Int32(0)
+		}
+		if field.JSONName != "" {
+			fieldDesc.JsonName = proto.String(field.JSONName)
+		}
+		if field.Repeated {
+			fieldDesc.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		}
+		if field.Optional {
+			// protoc declares a synthetic oneof for each optional field
+			fieldDesc.Proto3Optional = proto.Bool(true)
+			fieldDesc.OneofIndex = proto.Int32(int32(len(result.OneofDecl)))
+			result.OneofDecl = append(result.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + field.Name)})
+		}
+		if len(field.Options) > 0 {
+			options, err := fieldOptionsDescriptor(field.Options)
+			if err != nil {
+				return nil, fmt.Errorf("message '%s' field '%s': %w", msg.Name, field.Name, err)
+			}
+			fieldDesc.Options = options
+		}
+
+		if field.MapKey != "" {
+			// A map is a repeated field of a generated entry message holding key and value
+			entry := &descriptorpb.DescriptorProto{
+				Name:    proto.String(mapEntryName(field.Name)),
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("key"), Number: proto.Int32(1), JsonName: proto.String("key")},
+					{Name: proto.String("value"), Number: proto.Int32(2), JsonName: proto.String("value")},
+				},
+			}
+			for i, typ := range []string{field.MapKey, field.Type} {
+				entry.Field[i].Label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+				if err := setFieldType(entry.Field[i], typ, fullName, known, dependencies); err != nil {
+					return nil, fmt.Errorf("message '%s' field '%s': %w", msg.Name, field.Name, err)
+				}
+			}
+			result.NestedType = append(result.NestedType, entry)
+
+			fieldDesc.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			fieldDesc.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			fieldDesc.TypeName = proto.String("." + fullName + "." + entry.GetName())
+			result.Field = append(result.Field, fieldDesc)
+			continue
+		}
+
+		if err := setFieldType(fieldDesc, field.Type, fullName, known, dependencies); err != nil {
+			return nil, fmt.Errorf("message '%s' field '%s': %w", msg.Name, field.Name, err)
+		}
+		result.Field = append(result.Field, fieldDesc)
+	}
+
+	return result, nil
+}
+
+// setFieldType sets the type of a field to a scalar, well-known or declared type named
+// as in the proto output, resolved from scope
+func setFieldType(fieldDesc *descriptorpb.FieldDescriptorProto, typ, scope string, known map[string]descriptorpb.FieldDescriptorProto_Type, dependencies map[string]bool) error {
+	if scalar, ok := scalarTypes[typ]; ok {
+		fieldDesc.Type = scalar.Enum()
+		return nil
+	}
+
+	if file, ok := wellKnownTypes[typ]; ok {
+		dependencies[file.Path()] = true
+		fieldDesc.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		fieldDesc.TypeName = proto.String("." + typ)
+		return nil
+	}
+
+	typeName, kind, found := resolveTypeName(typ, scope, known)
+	if !found {
+		return fmt.Errorf("unknown type '%s'", typ)
+	}
+	fieldDesc.Type = kind.Enum()
+	fieldDesc.TypeName = proto.String("." + typeName)
+	return nil
+}
+
+// mapEntryName returns the name protoc gives the entry message of a map field, e.g.
+// LabelsEntry for labels and UserLabelsEntry for user_labels
+func mapEntryName(field string) string {
+	var result strings.Builder
+	upperNext := true
+	for _, c := range field {
+		switch {
+		case c == '_':
+			upperNext = true
+		case upperNext:
+			result.WriteRune(unicode.ToUpper(c))
+			upperNext = false
+		default:
+			result.WriteRune(c)
+		}
+	}
+	return result.String() + "Entry"
+}
+
+// resolveTypeName finds the full name and kind of a type referenced from scope using
+// proto scoping rules: the innermost enclosing scope that declares the name wins
+func resolveTypeName(name, scope string, known map[string]descriptorpb.FieldDescriptorProto_Type) (string, descriptorpb.FieldDescriptorProto_Type, bool) {
+	for {
+		candidate := scope + "." + name
+		if kind, ok := known[candidate]; ok {
+			return candidate, kind, true
+		}
+
+		idx := strings.LastIndex(scope, ".")
+		if idx < 0 {
+			return "", 0, false
+		}
+		scope = scope[:idx]
+	}
+}
@@ -0,0 +1,239 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const registrySpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Code:
+      type: integer
+      enum: [200, 404]
+    Order:
+      type: object
+      properties:
+        order_id:
+          type: string
+        tags:
+          type: array
+          items:
+            type: string
+        item:
+          type: object
+          properties:
+            sku:
+              type: string
+            count:
+              type: integer
+              format: int64
+        code:
+          $ref: '#/components/schemas/Code'
+        createdAt:
+          type: string
+          format: date-time
+        customer:
+          $ref: '#/components/schemas/Customer'
+    Customer:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestConvertResultNewMessage(t *testing.T) {
+	result, err := conv.Convert([]byte(registrySpec), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	msg, err := result.NewMessage("Order")
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("testpkg.Order"), msg.Descriptor().FullName())
+
+	given := `{"order_id":"o-1","tags":["a","b"],"item":{"sku":"x","count":"3"},"code":"CODE_404","createdAt":"2024-03-01T10:00:00Z","customer":{"name":"Alice"}}`
+	require.NoError(t, protojson.Unmarshal([]byte(given), msg))
+
+	// Binary round trip through a fresh dynamic message
+	wire, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	decoded, err := result.NewMessage("Order")
+	require.NoError(t, err)
+	require.NoError(t, proto.Unmarshal(wire, decoded))
+
+	out, err := protojson.Marshal(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, given, string(out))
+
+	nested, err := result.NewMessage("Order.Item")
+	require.NoError(t, err)
+	assert.Equal(t, 2, nested.Descriptor().Fields().Len())
+}
+
+func TestConvertResultFiles(t *testing.T) {
+	result, err := conv.Convert([]byte(registrySpec), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	files, err := result.Files()
+	require.NoError(t, err)
+
+	file, err := files.FindFileByPath("testpkg.proto")
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("testpkg"), file.Package())
+	assert.Equal(t, 1, file.Enums().Len())
+	assert.Equal(t, 2, file.Messages().Len())
+
+	field := file.Messages().ByName("Order").Fields().ByName("order_id")
+	require.NotNil(t, field)
+	assert.Equal(t, "order_id", field.JSONName())
+
+	_, err = result.NewMessage("Missing")
+	require.ErrorContains(t, err, "message 'Missing' not found in proto output")
+
+	_, err = result.NewMessage("Code")
+	require.ErrorContains(t, err, "'Code' is not a message")
+}
+
+func TestConvertResultDebugRedact(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Login:
+      type: object
+      properties:
+        user:
+          type: string
+        password:
+          type: string
+          format: password
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	msg, err := result.NewMessage("Login")
+	require.NoError(t, err)
+	fields := msg.Descriptor().Fields()
+	assert.True(t, fields.ByName("password").Options().(*descriptorpb.FieldOptions).GetDebugRedact())
+	assert.False(t, fields.ByName("user").Options().(*descriptorpb.FieldOptions).GetDebugRedact())
+}
+
+func TestConvertResultFieldOptions(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Login:
+      type: object
+      properties:
+        user:
+          type: string
+          example: alice
+          x-proto-field-behavior: [REQUIRED, IMMUTABLE]
+        password:
+          type: string
+          format: password
+        owner:
+          type: string
+          x-proto-resource-ref: example.com/User
+`
+
+	for _, test := range []struct {
+		name string
+		opts conv.ConvertOptions
+		err  string
+	}{
+		{
+			name: "google.api extensions and debug_redact",
+			opts: conv.ConvertOptions{PackageName: "testpkg", PackagePath: "github.com/example/proto/v1"},
+		},
+		{
+			name: "example option",
+			opts: conv.ConvertOptions{
+				PackageName:   "testpkg",
+				PackagePath:   "github.com/example/proto/v1",
+				ExampleOption: conv.ExampleOption{Name: "api.example", Import: "api/annotations.proto"},
+			},
+			err: `message 'Login' field 'user': field options cannot be represented in a descriptor: (api.example) = "alice"`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.Convert([]byte(given), test.opts)
+			require.NoError(t, err)
+
+			msg, err := result.NewMessage("Login")
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			fields := msg.Descriptor().Fields()
+			user := fields.ByName("user").Options().(*descriptorpb.FieldOptions)
+			// (google.api.field_behavior) = REQUIRED, IMMUTABLE as unpacked field 1052
+			assert.Equal(t, []byte{0xe0, 0x41, 0x02, 0xe0, 0x41, 0x05}, []byte(user.ProtoReflect().GetUnknown()))
+			assert.True(t, fields.ByName("password").Options().(*descriptorpb.FieldOptions).GetDebugRedact())
+			owner := fields.ByName("owner").Options().(*descriptorpb.FieldOptions)
+			// (google.api.resource_reference) = {type: "example.com/User"} as field 1055
+			assert.Equal(t, append([]byte{0xfa, 0x41, 0x12, 0x0a, 0x10}, "example.com/User"...), []byte(owner.ProtoReflect().GetUnknown()))
+		})
+	}
+}
+
+func TestConvertResultFilesWithoutProto(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	_, err = result.Files()
+	require.ErrorContains(t, err, "result has no proto output")
+}
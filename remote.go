@@ -0,0 +1,140 @@
+package conv
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultRemoteTimeout bounds each fetch when RemoteRefOptions.Timeout is zero
+const defaultRemoteTimeout = 30 * time.Second
+
+// RemoteRefOptions controls $refs to URLs, such as
+// 'https://schemas.example.com/common.yaml#/components/schemas/Money'. The zero value
+// refuses every remote reference.
+type RemoteRefOptions struct {
+	// AllowedHosts lists the hosts references may be fetched from, e.g.
+	// "schemas.example.com". A reference to any other host, or a redirect to one, is an
+	// error.
+	AllowedHosts []string
+	// Timeout bounds each fetch. Defaults to 30 seconds.
+	Timeout time.Duration
+	// CacheDir, when set, stores each fetched document in this directory keyed by a hash
+	// of its URL and reads it from there instead of fetching it again. Remove the
+	// directory to fetch current versions. The directory is created if needed.
+	CacheDir string
+}
+
+// remoteFetcher loads the documents of remote references
+type remoteFetcher struct {
+	opts     RemoteRefOptions
+	maxBytes int
+	client   *http.Client
+}
+
+// newRemoteFetcher returns the fetch function for opts, or nil if no remote references
+// are allowed
+func newRemoteFetcher(opts ConvertOptions) func(string) ([]byte, error) {
+	if len(opts.RemoteRefs.AllowedHosts) == 0 {
+		return nil
+	}
+	timeout := opts.RemoteRefs.Timeout
+	if timeout == 0 {
+		timeout = defaultRemoteTimeout
+	}
+	f := &remoteFetcher{
+		opts:     opts.RemoteRefs,
+		maxBytes: opts.Limits.MaxSpecBytes,
+	}
+	f.client = &http.Client{
+		Timeout: timeout,
+		// Check every redirect like the reference itself, so an allowed host cannot
+		// send the fetch on to one that is not
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after %d redirects", len(via))
+			}
+			if err := f.checkURL(req.URL); err != nil {
+				return fmt.Errorf("redirect to '%s': %w", req.URL, err)
+			}
+			return nil
+		},
+	}
+	return f.fetch
+}
+
+// checkURL returns an error unless u is an http or https URL of an allowed host
+func (f *remoteFetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("unsupported scheme '%s'", u.Scheme)
+	}
+	if !slices.Contains(f.opts.AllowedHosts, strings.ToLower(u.Hostname())) {
+		return fmt.Errorf("host '%s' is not in RemoteRefs.AllowedHosts", u.Hostname())
+	}
+	return nil
+}
+
+// fetch returns the document at rawURL, from the cache if it holds it
+func (f *remoteFetcher) fetch(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	var cached string
+	if f.opts.CacheDir != "" {
+		cached = filepath.Join(f.opts.CacheDir, fmt.Sprintf("%x.ref", sha256.Sum256([]byte(rawURL))))
+		if data, err := os.ReadFile(cached); err == nil {
+			return data, nil
+		}
+	}
+
+	data, err := f.get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != "" {
+		if err := writeFileAtomic(cached, data); err != nil {
+			return nil, fmt.Errorf("failed to cache '%s': %w", rawURL, err)
+		}
+	}
+	return data, nil
+}
+
+// get downloads rawURL, following redirects only to allowed hosts, failing on any status
+// but 200 OK and on documents larger than Limits.MaxSpecBytes
+func (f *remoteFetcher) get(rawURL string) ([]byte, error) {
+	resp, err := f.client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET '%s': %s", rawURL, resp.Status)
+	}
+
+	body := io.Reader(resp.Body)
+	if f.maxBytes > 0 {
+		body = io.LimitReader(resp.Body, int64(f.maxBytes)+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if f.maxBytes > 0 && len(data) > f.maxBytes {
+		return nil, fmt.Errorf("'%s' exceeds limit of %d bytes", rawURL, f.maxBytes)
+	}
+	return data, nil
+}
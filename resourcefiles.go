@@ -0,0 +1,209 @@
+package conv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+// ResourceFile is one proto file produced by ConvertResourceFiles
+type ResourceFile struct {
+	// Name is the file name, the resource for a resource file, e.g. "users.proto", or
+	// the package for the common file, e.g. "api.proto"
+	Name string
+	// Resource is the first path segment of the operations grouped into the file, e.g.
+	// "users", or empty for the common file
+	Resource string
+	// Result holds the definitions declared in the file, with their examples and
+	// fixtures. Routes lists the operations of the resource. Fields using a definition
+	// of the common file reference it by its qualified name, e.g. api.Address, and the
+	// file imports the common file.
+	Result *ConvertResult
+}
+
+// ConvertResourceFiles converts openapi into one proto file per REST resource, the first
+// path segment that is not a parameter, as many teams lay out their protos: definitions
+// used only by the operations under /users, and the definitions they use, are declared
+// in users.proto. Definitions used by several resources or by none are declared in a
+// common file named after the package, along with every definition they use, and the
+// resource files import it. Every file declares the package of opts.
+//
+// The common file comes first and also holds the Go output; it is left out if it would
+// be empty. Resource files follow in path order, leaving out resources that declare no
+// definitions of their own. Cannot be combined with LowMemory, which drops the paths.
+func ConvertResourceFiles(openapi []byte, opts ConvertOptions) ([]ResourceFile, error) {
+	if opts.LowMemory {
+		return nil, &OptionsError{Problems: []string{"resource files cannot be combined with low memory, which drops paths"}}
+	}
+
+	result, err := Convert(openapi, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := resolveExternalRefs(openapi, opts)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parser.ParseDocument(resolved)
+	if err != nil {
+		return nil, &Error{Code: ErrorCodeParse, Err: err}
+	}
+	resources := doc.Resources()
+	owners := resourceOwners(result, resources)
+
+	commonFile := opts.PackageName + ".proto"
+	splitOpts := opts
+	splitOpts.CacheDir = ""
+
+	owned := make(map[string]bool)
+	for name, owner := range owners {
+		if owner != "" {
+			owned[name] = true
+		}
+	}
+	common, err := convertSplit(openapi, splitOpts, &definitionSplit{names: owned}, nil)
+	if err != nil {
+		return nil, formatError(withErrorCode(err), opts)
+	}
+	common.Routes = nil
+
+	var files []ResourceFile
+	if len(common.Definitions) > 0 || len(common.Golang) > 0 {
+		files = append(files, ResourceFile{Name: commonFile, Result: common})
+	}
+
+	for _, resource := range resources {
+		declared := false
+		others := make(map[string]bool)
+		for name, owner := range owners {
+			if owner == resource.Name {
+				declared = true
+			} else {
+				others[name] = true
+			}
+		}
+		if !declared {
+			continue
+		}
+
+		name := resourceFileName(resource.Name)
+		if name == commonFile {
+			return nil, &Error{Code: ErrorCodeInvalidInput,
+				Err: fmt.Errorf("resource '%s' has the name of the common file %s", resource.Name, commonFile)}
+		}
+
+		file, err := convertSplit(openapi, splitOpts, &definitionSplit{
+			names:      others,
+			qualifier:  opts.PackageName,
+			importPath: commonFile,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("resource '%s': %w", resource.Name, formatError(withErrorCode(err), opts))
+		}
+		// Go types are generated once, in the common file
+		file.Golang = nil
+		for schema, info := range file.TypeMap {
+			if info.Location == TypeLocationGolang {
+				delete(file.TypeMap, schema)
+			}
+		}
+		routes := make([]Route, 0, len(file.Routes))
+		for _, route := range file.Routes {
+			if resource.Paths[route.Path] {
+				routes = append(routes, route)
+			}
+		}
+		file.Routes = routes
+
+		files = append(files, ResourceFile{Name: name, Resource: resource.Name, Result: file})
+	}
+	return files, nil
+}
+
+// resourceOwners maps each top-level definition of result to the resource whose
+// operations alone use it, or to an empty string when several resources or none do
+func resourceOwners(result *ConvertResult, resources []*parser.ResourceEntry) map[string]string {
+	owners := make(map[string]string, len(result.Definitions))
+	messages := make(map[string]*ProtoMessage)
+	for _, def := range result.Definitions {
+		owners[definitionName(def)] = ""
+		if msg, ok := def.(*ProtoMessage); ok {
+			messages[msg.Name] = msg
+		}
+	}
+	local := func(name string) string {
+		return strings.TrimPrefix(name, result.packageName+".")
+	}
+
+	users := make(map[string]map[string]bool)
+	for _, resource := range resources {
+		var pending []string
+		for schema := range resource.Schemas {
+			if name, ok := result.ProtoTypeFor(schema); ok {
+				pending = append(pending, local(name))
+			}
+		}
+		for _, route := range result.Routes {
+			if resource.Paths[route.Path] {
+				pending = append(pending, local(route.Request), local(route.Response))
+			}
+		}
+
+		// Follow fields to the definitions they use, such as hoisted inline enums
+		seen := make(map[string]bool)
+		for len(pending) > 0 {
+			name := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if _, defined := owners[name]; !defined || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if users[name] == nil {
+				users[name] = make(map[string]bool)
+			}
+			users[name][resource.Name] = true
+			if msg, ok := messages[name]; ok {
+				pending = append(pending, messageTypes(msg)...)
+			}
+		}
+	}
+	for name, using := range users {
+		if len(using) == 1 {
+			for resource := range using {
+				owners[name] = resource
+			}
+		}
+	}
+
+	// Move definitions used by the common file there too, until none are left
+	for changed := true; changed; {
+		changed = false
+		for name, owner := range owners {
+			msg, ok := messages[name]
+			if owner != "" || !ok {
+				continue
+			}
+			for _, typ := range messageTypes(msg) {
+				if owners[typ] != "" {
+					owners[typ] = ""
+					changed = true
+				}
+			}
+		}
+	}
+	return owners
+}
+
+// resourceFileName returns the proto file name of a resource, lower case with characters
+// other than letters, digits and underscores replaced, e.g. "user_groups.proto" for
+// "user-groups"
+func resourceFileName(resource string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(resource)) + ".proto"
+}
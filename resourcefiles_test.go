@@ -0,0 +1,180 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const resourcesSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/UserPage'
+  /users/{id}:
+    get:
+      operationId: getUser
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /user-groups:
+    get:
+      operationId: listGroups
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Group'
+  /orders:
+    post:
+      operationId: createOrder
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        address:
+          $ref: '#/components/schemas/Address'
+    Address:
+      type: object
+      properties:
+        street:
+          type: string
+    UserPage:
+      type: object
+      properties:
+        users:
+          type: array
+          items:
+            $ref: '#/components/schemas/User'
+    Group:
+      type: object
+      properties:
+        name:
+          type: string
+    Order:
+      type: object
+      properties:
+        buyer:
+          $ref: '#/components/schemas/User'
+        status:
+          $ref: '#/components/schemas/Status'
+    Status:
+      type: integer
+      enum: [1, 2]
+    Unused:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+func TestConvertResourceFiles(t *testing.T) {
+	files, err := conv.ConvertResourceFiles([]byte(resourcesSpec), conv.ConvertOptions{
+		PackageName: "api",
+		PackagePath: "github.com/example/api",
+	})
+	require.NoError(t, err)
+
+	var names, resources []string
+	for _, file := range files {
+		names = append(names, file.Name)
+		resources = append(resources, file.Resource)
+	}
+	assert.Equal(t, []string{"api.proto", "users.proto", "user_groups.proto", "orders.proto"}, names)
+	assert.Equal(t, []string{"", "users", "user-groups", "orders"}, resources)
+
+	assert.Equal(t, []string{"User", "Address", "Unused"}, definitionNames(files[0].Result.Definitions))
+	assert.Empty(t, files[0].Result.Routes)
+	assert.Equal(t, []string{"UserPage"}, definitionNames(files[1].Result.Definitions))
+	assert.Equal(t, []string{"Group"}, definitionNames(files[2].Result.Definitions))
+	assert.NotContains(t, string(files[2].Result.Protobuf), "import")
+
+	assert.Equal(t, `syntax = "proto3";
+
+package api;
+
+import "api.proto";
+
+option go_package = "github.com/example/api";
+
+message Order {
+  api.User buyer = 1 [json_name = "buyer"];
+  Status status = 2 [json_name = "status"];
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_1 = 1;
+  STATUS_2 = 2;
+}
+
+`, string(files[3].Result.Protobuf))
+	assert.Equal(t, []conv.Route{
+		{Method: "POST", Path: "/orders", RPC: "CreateOrder", Request: "api.Order", Response: "api.Order", ContentType: "application/json"},
+	}, files[3].Result.Routes)
+	assert.Equal(t, "api.User", files[3].Result.TypeMap["User"].AliasOf)
+}
+
+func TestConvertResourceFilesErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		opts    conv.ConvertOptions
+		wantErr string
+	}{
+		{
+			name: "low memory",
+			opts: conv.ConvertOptions{
+				PackageName: "api",
+				PackagePath: "github.com/example/api",
+				LowMemory:   true,
+			},
+			wantErr: "resource files cannot be combined with low memory, which drops paths",
+		},
+		{
+			name: "resource named after the package",
+			opts: conv.ConvertOptions{
+				PackageName: "orders",
+				PackagePath: "github.com/example/api",
+			},
+			wantErr: "resource 'orders' has the name of the common file orders.proto",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := conv.ConvertResourceFiles([]byte(resourcesSpec), test.opts)
+			require.ErrorContains(t, err, test.wantErr)
+		})
+	}
+}
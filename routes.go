@@ -0,0 +1,245 @@
+package conv
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/duh-rpc/openapi-proto.go/internal"
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// ArtifactRoutes is the route table from ConvertResult.Routes, encoded as JSON
+const ArtifactRoutes ArtifactKind = "routes"
+
+// routesFile is the name the route table is written under
+const routesFile = "routes.json"
+
+// Route describes an operation of the spec, so HTTP routers and API gateways can be
+// configured from the conversion without parsing the spec again
+type Route struct {
+	// Method is the HTTP method in upper case, e.g. "GET"
+	Method string `json:"method"`
+	// Path is the path template as written in the spec, e.g. "/users/{id}"
+	Path string `json:"path"`
+	// RPC is the PascalCase operationId, or the method and path when there is none,
+	// e.g. "GetUser" or "GetUsersId"
+	RPC string `json:"rpc"`
+	// Request is the fully qualified type of the request body from ProtoTypeFor, e.g.
+	// "api.v1.CreateUser", or from GoTypeFor for schemas generated as Go, e.g.
+	// "github.com/example/go/api.Payment". Empty if there is no request body or it is
+	// not a component schema.
+	Request string `json:"request,omitempty"`
+	// Response is the fully qualified type of the first 2xx response, empty if there is
+	// none or it is not a component schema
+	Response string `json:"response,omitempty"`
+	// ContentType is the media type of the request body, the JSON one when the body
+	// lists several, e.g. "multipart/form-data". Empty if there is no request body.
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// RouteTable returns Routes encoded as indented JSON
+func (r *ConvertResult) RouteTable() ([]byte, error) {
+	routes := r.Routes
+	if routes == nil {
+		routes = []Route{}
+	}
+	data, err := json.MarshalIndent(routes, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// buildRoutes converts parsed route entries, naming request and response types after
+// the definitions generated for their schemas
+func buildRoutes(entries []*parser.RouteEntry, result *ConvertResult) []Route {
+	routes := make([]Route, 0, len(entries))
+	for _, entry := range entries {
+		routes = append(routes, Route{
+			Method:      entry.Method,
+			Path:        entry.Path,
+			RPC:         routeRPC(entry),
+			Request:     result.routeType(entry.Request),
+			Response:    result.routeType(entry.Response),
+			ContentType: entry.RequestMediaType,
+		})
+	}
+	return routes
+}
+
+// routeType returns the fully qualified type generated for a component schema, proto
+// if it has a proto definition and Go otherwise, or an empty string
+func (r *ConvertResult) routeType(schema string) string {
+	if name, ok := r.ProtoTypeFor(schema); ok {
+		return name
+	}
+	name, _ := r.GoTypeFor(schema)
+	return name
+}
+
+// routeRPC returns the PascalCase operationId of a route, or its method and path when
+// it has none
+func routeRPC(entry *parser.RouteEntry) string {
+	rpc := entry.Operation
+	if rpc == "" {
+		rpc = strings.ToLower(entry.Method) + " " + entry.Path
+	}
+	return internal.WordsToPascalCase(rpc)
+}
+
+// operationMessages returns a schema entry for each inline object schema of a route's
+// request body or response, named after the route's RPC, and points the route at it.
+// Request bodies mapped with BodyStrategyBytes by strategies get a message holding the
+// raw body instead, and multipart bodies with file parts, inline or $refs, get one with
+// their file metadata. Names taken by schemas, or by an earlier route, get a numeric
+// suffix.
+func operationMessages(entries []*parser.RouteEntry, schemas []*parser.SchemaEntry, strategies map[string]BodyStrategy) []*parser.SchemaEntry {
+	taken := make(map[string]bool, len(schemas))
+	for _, entry := range schemas {
+		taken[entry.Name] = true
+	}
+
+	var messages []*parser.SchemaEntry
+	add := func(proxy *base.SchemaProxy, name string) string {
+		if proxy == nil || !isObjectSchema(proxy.Schema()) {
+			return ""
+		}
+		unique := name
+		for i := 2; taken[unique]; i++ {
+			unique = fmt.Sprintf("%s%d", name, i)
+		}
+		taken[unique] = true
+		messages = append(messages, &parser.SchemaEntry{Name: unique, Proxy: proxy})
+		return unique
+	}
+
+	for _, entry := range entries {
+		rpc := routeRPC(entry)
+		request := entry.RequestSchema
+		switch {
+		case entry.RequestMediaType == "":
+		case bodyStrategy(entry.RequestMediaType, strategies) == BodyStrategyBytes:
+			request = rawBodySchema(entry.RequestMediaType)
+		case isMultipart(entry.RequestMediaType):
+			if upload := uploadSchema(entry.RequestBody); upload != nil {
+				request = upload
+			}
+		}
+		if name := add(request, rpc+"Request"); name != "" {
+			entry.Request = name
+		}
+		if name := add(entry.ResponseSchema, rpc+"Response"); name != "" {
+			entry.Response = name
+		}
+	}
+	return messages
+}
+
+// bodyStrategy returns the strategy for a request body of mediaType, from strategies or
+// else the default for the media type
+func bodyStrategy(mediaType string, strategies map[string]BodyStrategy) BodyStrategy {
+	essence, _, _ := strings.Cut(strings.ToLower(mediaType), ";")
+	essence = strings.TrimSpace(essence)
+	if strategy, ok := strategies[essence]; ok {
+		return strategy
+	}
+	if parser.IsJSONMediaType(essence) || essence == "multipart/form-data" || essence == "application/x-www-form-urlencoded" {
+		return BodyStrategyFields
+	}
+	return BodyStrategyBytes
+}
+
+// rawBodySchema returns an object schema with a single binary property named body, from
+// which the request message of a body mapped with BodyStrategyBytes is generated
+func rawBodySchema(mediaType string) *base.SchemaProxy {
+	properties := orderedmap.New[string, *base.SchemaProxy]()
+	properties.Set("body", base.CreateSchemaProxy(&base.Schema{
+		Type:        []string{"string"},
+		Format:      "binary",
+		Description: fmt.Sprintf("Raw %s request body", mediaType),
+	}))
+	return base.CreateSchemaProxy(&base.Schema{
+		Type:       []string{"object"},
+		Properties: properties,
+	})
+}
+
+// isMultipart reports whether mediaType is multipart/form-data, ignoring parameters
+func isMultipart(mediaType string) bool {
+	essence, _, _ := strings.Cut(strings.ToLower(mediaType), ";")
+	return strings.TrimSpace(essence) == "multipart/form-data"
+}
+
+// uploadSchema returns a copy of a multipart body schema with a <part>_filename and a
+// <part>_content_type string property after each file part, so the request message
+// carries the metadata sent with each file. Parts of an array of files get arrays of
+// metadata. A metadata property the schema already declares is kept. Returns nil if
+// the body is not an object or has no file parts.
+func uploadSchema(proxy *base.SchemaProxy) *base.SchemaProxy {
+	if proxy == nil {
+		return nil
+	}
+	schema := proxy.Schema()
+	if !isObjectSchema(schema) || schema.Properties == nil {
+		return nil
+	}
+
+	properties := orderedmap.New[string, *base.SchemaProxy]()
+	files := false
+	for name, part := range schema.Properties.FromOldest() {
+		properties.Set(name, part)
+		repeated, ok := filePart(part.Schema())
+		if !ok {
+			continue
+		}
+		files = true
+		for _, meta := range []struct{ suffix, description, repeated string }{
+			{"_filename", "Filename of the %s part", "Filenames of the %s parts"},
+			{"_content_type", "Content type of the %s part", "Content types of the %s parts"},
+		} {
+			if _, declared := schema.Properties.Get(name + meta.suffix); declared {
+				continue
+			}
+			value := &base.Schema{Type: []string{"string"}, Description: fmt.Sprintf(meta.description, name)}
+			if repeated {
+				value = &base.Schema{
+					Type:        []string{"array"},
+					Items:       &base.DynamicValue[*base.SchemaProxy, bool]{A: base.CreateSchemaProxy(&base.Schema{Type: []string{"string"}})},
+					Description: fmt.Sprintf(meta.repeated, name),
+				}
+			}
+			properties.Set(name+meta.suffix, base.CreateSchemaProxy(value))
+		}
+	}
+	if !files {
+		return nil
+	}
+
+	upload := *schema
+	upload.Properties = properties
+	return base.CreateSchemaProxy(&upload)
+}
+
+// filePart reports whether a multipart part is a file, a binary string, and whether it
+// is an array of files
+func filePart(schema *base.Schema) (repeated, ok bool) {
+	if schema == nil {
+		return false, false
+	}
+	if slices.Contains(schema.Type, "array") && schema.Items != nil && schema.Items.IsA() {
+		_, ok := filePart(schema.Items.A.Schema())
+		return true, ok
+	}
+	return false, slices.Contains(schema.Type, "string") && schema.Format == "binary"
+}
+
+// isObjectSchema reports whether schema is an object to generate a message from, rather
+// than a scalar, array or composition
+func isObjectSchema(schema *base.Schema) bool {
+	return schema != nil && slices.Contains(schema.Type, "object") &&
+		len(schema.OneOf) == 0 && len(schema.AnyOf) == 0 && len(schema.AllOf) == 0
+}
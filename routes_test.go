@@ -0,0 +1,108 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertRoutes(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users/{id}:
+    get:
+      operationId: get-user
+      responses:
+        '404':
+          description: Not found
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+    delete:
+      responses:
+        '204':
+          description: Deleted
+  /payments:
+    post:
+      operationId: createPayment
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Payment'
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                type: object
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+    Payment:
+      oneOf:
+        - $ref: '#/components/schemas/Card'
+        - $ref: '#/components/schemas/Wire'
+      discriminator:
+        propertyName: kind
+    Card:
+      type: object
+      properties:
+        kind:
+          type: string
+    Wire:
+      type: object
+      properties:
+        kind:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:   "api.v1",
+		PackagePath:   "github.com/example/proto/v1",
+		GoPackagePath: "github.com/example/go/api",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []conv.Route{
+		{Method: "GET", Path: "/users/{id}", RPC: "GetUser", Response: "api.v1.User"},
+		{Method: "DELETE", Path: "/users/{id}", RPC: "DeleteUsersId"},
+		{Method: "POST", Path: "/payments", RPC: "CreatePayment", Request: "github.com/example/go/api.Payment", ContentType: "application/json"},
+	}, result.Routes)
+
+	table, err := result.RouteTable()
+	require.NoError(t, err)
+	assert.Equal(t, `[
+  {
+    "method": "GET",
+    "path": "/users/{id}",
+    "rpc": "GetUser",
+    "response": "api.v1.User"
+  },
+  {
+    "method": "DELETE",
+    "path": "/users/{id}",
+    "rpc": "DeleteUsersId"
+  },
+  {
+    "method": "POST",
+    "path": "/payments",
+    "rpc": "CreatePayment",
+    "request": "github.com/example/go/api.Payment",
+    "contentType": "application/json"
+  }
+]
+`, string(table))
+}
@@ -0,0 +1,161 @@
+package conv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/duh-rpc/openapi-proto.go/internal/parser"
+)
+
+// Session keeps the latest conversion of each open spec document so an editor or
+// language server can show conversion problems and regenerated definitions as the user
+// types. Each edit parses the spec once, and linting and conversion share the parsed
+// document; nothing parsed is kept between edits. Documents are identified by a caller
+// chosen URI. A Session is safe for concurrent use.
+type Session struct {
+	opts ConvertOptions
+	mu   sync.Mutex
+	docs map[string]*sessionDocument
+}
+
+// SessionUpdate is the state of a document after Open or Update
+type SessionUpdate struct {
+	// Diagnostics lists every problem found in the spec, in spec order, linting the
+	// document the conversion parsed, so options such as FlattenAllOf apply. A problem
+	// that stops conversion but is not found by LintForProto, such as a parse error, is
+	// reported as a single finding without Schema. Empty when the spec converts cleanly.
+	Diagnostics []LintFinding
+	// Result is the conversion of the spec, or nil if conversion failed
+	Result *ConvertResult
+	// Changed lists the definitions added or changed since the last successful
+	// conversion of the document, in output order. All definitions are changed on Open.
+	Changed []ProtoDefinition
+	// Removed names the definitions removed since the last successful conversion
+	Removed []string
+}
+
+// sessionDocument is the cached state of one open document
+type sessionDocument struct {
+	spec   []byte
+	update *SessionUpdate
+	// definitions by name from the last successful conversion, the baseline for Changed
+	definitions map[string]ProtoDefinition
+	order       []string
+}
+
+// NewSession returns a session converting documents with opts, or an *OptionsError if
+// the options are invalid
+func NewSession(opts ConvertOptions) (*Session, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &Session{opts: opts, docs: make(map[string]*sessionDocument)}, nil
+}
+
+// Open converts a newly opened document. Opening a document that is already open
+// replaces it. Conversion problems are reported as diagnostics, not errors.
+func (s *Session) Open(uri string, spec []byte) *SessionUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := &sessionDocument{definitions: make(map[string]ProtoDefinition)}
+	s.docs[uri] = doc
+	return s.refresh(doc, spec)
+}
+
+// Update converts the new content of an open document. Content identical to the last
+// update returns the cached state without converting again, with nothing changed.
+func (s *Session) Update(uri string, spec []byte) (*SessionUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, fmt.Errorf("document '%s' is not open", uri)
+	}
+	if bytes.Equal(doc.spec, spec) {
+		return &SessionUpdate{Diagnostics: doc.update.Diagnostics, Result: doc.update.Result}, nil
+	}
+	return s.refresh(doc, spec), nil
+}
+
+// Close forgets a document. Closing a document that is not open does nothing.
+func (s *Session) Close(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.docs, uri)
+}
+
+// refresh converts spec and records it as the content of doc. The spec is parsed once,
+// linting the schemas the conversion parsed.
+func (s *Session) refresh(doc *sessionDocument, spec []byte) *SessionUpdate {
+	doc.spec = append([]byte(nil), spec...)
+	update := &SessionUpdate{}
+	doc.update = update
+
+	linted := false
+	result, err := convertParsed(spec, s.opts, func(schemas []*parser.SchemaEntry) {
+		update.Diagnostics = lintSchemas(schemas)
+		linted = true
+	})
+	if !linted && err == nil {
+		// The result came from the cache, so the spec was not parsed yet. It converted, so
+		// a spec the linter cannot parse on its own, e.g. one with file references, has no
+		// findings to report.
+		if findings, lintErr := LintForProto(spec); lintErr == nil {
+			update.Diagnostics = findings
+		}
+	}
+	if err != nil {
+		if len(update.Diagnostics) == 0 {
+			update.Diagnostics = []LintFinding{diagnosticFromError(err)}
+		}
+		return update
+	}
+	update.Result = result
+
+	definitions := make(map[string]ProtoDefinition, len(result.Definitions))
+	order := make([]string, 0, len(result.Definitions))
+	for _, def := range result.Definitions {
+		name := definitionName(def)
+		definitions[name] = def
+		order = append(order, name)
+		if previous, ok := doc.definitions[name]; !ok || !reflect.DeepEqual(previous, def) {
+			update.Changed = append(update.Changed, def)
+		}
+	}
+	for _, name := range doc.order {
+		if _, ok := definitions[name]; !ok {
+			update.Removed = append(update.Removed, name)
+		}
+	}
+	doc.definitions = definitions
+	doc.order = order
+
+	return update
+}
+
+// diagnosticFromError reports a conversion error as a finding
+func diagnosticFromError(err error) LintFinding {
+	finding := LintFinding{Code: ErrorCodeInternal, Message: err.Error()}
+	var convErr *Error
+	if errors.As(err, &convErr) {
+		finding.Code = convErr.Code
+		finding.Fixes = convErr.Fixes
+	}
+	return finding
+}
+
+// definitionName returns the name of a message or enum definition
+func definitionName(def ProtoDefinition) string {
+	switch d := def.(type) {
+	case *ProtoMessage:
+		return d.Name
+	case *ProtoEnum:
+		return d.Name
+	}
+	return ""
+}
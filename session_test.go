@@ -0,0 +1,191 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+func TestSession(t *testing.T) {
+	session, err := conv.NewSession(conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+	})
+	require.NoError(t, err)
+
+	opened := session.Open("file:///api.yaml", []byte(sessionSpec))
+	require.NotNil(t, opened.Result)
+	assert.Empty(t, opened.Diagnostics)
+	assert.Equal(t, []string{"User", "Order"}, definitionNames(opened.Changed))
+
+	for _, test := range []struct {
+		name        string
+		spec        string
+		changed     []string
+		removed     []string
+		diagnostics []conv.ErrorCode
+	}{
+		{
+			name: "unchanged content",
+			spec: sessionSpec,
+		},
+		{
+			name: "field added and schema removed",
+			spec: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        email:
+          type: string
+`,
+			changed: []string{"User"},
+			removed: []string{"Order"},
+		},
+		{
+			name: "invalid schema",
+			spec: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        items:
+          type: object
+          properties:
+            id:
+              type: string
+`,
+			diagnostics: []conv.ErrorCode{conv.ErrorCodePluralInlineName},
+		},
+		{
+			name:        "unparsable",
+			spec:        "openapi: [",
+			diagnostics: []conv.ErrorCode{conv.ErrorCodeParse},
+		},
+		{
+			name: "changes since last successful conversion",
+			spec: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        email:
+          type: string
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+`,
+			changed: []string{"Order"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			update, err := session.Update("file:///api.yaml", []byte(test.spec))
+			require.NoError(t, err)
+
+			var codes []conv.ErrorCode
+			for _, diagnostic := range update.Diagnostics {
+				codes = append(codes, diagnostic.Code)
+			}
+			assert.Equal(t, test.diagnostics, codes)
+			assert.Equal(t, test.diagnostics == nil, update.Result != nil)
+			assert.Equal(t, test.changed, definitionNames(update.Changed))
+			assert.Equal(t, test.removed, update.Removed)
+		})
+	}
+
+	session.Close("file:///api.yaml")
+	_, err = session.Update("file:///api.yaml", []byte(sessionSpec))
+	require.ErrorContains(t, err, "document 'file:///api.yaml' is not open")
+}
+
+func TestNewSessionInvalidOptions(t *testing.T) {
+	_, err := conv.NewSession(conv.ConvertOptions{})
+	require.ErrorContains(t, err, "package name cannot be empty")
+}
+
+func definitionNames(definitions []conv.ProtoDefinition) []string {
+	var names []string
+	for _, def := range definitions {
+		switch d := def.(type) {
+		case *conv.ProtoMessage:
+			names = append(names, d.Name)
+		case *conv.ProtoEnum:
+			names = append(names, d.Name)
+		}
+	}
+	return names
+}
+
+func TestSessionLintsConvertedSpec(t *testing.T) {
+	// Diagnostics come from the document the conversion parsed, so the allOf that
+	// FlattenAllOf merges is not reported
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Named:
+      type: object
+      properties:
+        name:
+          type: string
+    User:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - type: object
+          properties:
+            email:
+              type: string
+`
+
+	session, err := conv.NewSession(conv.ConvertOptions{
+		PackageName:  "testpkg",
+		PackagePath:  "github.com/example/proto/v1",
+		FlattenAllOf: true,
+	})
+	require.NoError(t, err)
+
+	update := session.Open("file:///api.yaml", []byte(given))
+	require.NotNil(t, update.Result)
+	assert.Empty(t, update.Diagnostics)
+	assert.Equal(t, []string{"Named", "User"}, definitionNames(update.Changed))
+}
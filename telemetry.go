@@ -0,0 +1,73 @@
+package conv
+
+import "time"
+
+// Phase names a step of the conversion pipeline reported to an Observer
+type Phase string
+
+const (
+	// PhaseParse covers reading the spec: resolving references, rewriting and parsing it
+	PhaseParse Phase = "parse"
+	// PhaseBuild covers building messages from schemas and classifying them as proto or Go
+	PhaseBuild Phase = "build"
+	// PhaseProto covers rendering the proto output
+	PhaseProto Phase = "proto"
+	// PhaseGo covers rendering the Go output
+	PhaseGo Phase = "go"
+)
+
+// ConversionStats summarizes a call to Convert for an Observer
+type ConversionStats struct {
+	// Schemas is the number of component schemas converted
+	Schemas int
+	// ProtoTypes and GoTypes count the schemas generated as proto and as Go
+	ProtoTypes int
+	GoTypes    int
+	// Warnings is the length of ConvertResult.Warnings
+	Warnings int
+	// Cached is true when the result was read from ConvertOptions.CacheDir
+	Cached bool
+	// Duration is the time Convert took
+	Duration time.Duration
+}
+
+// Observer receives metrics about conversions, e.g. to export them to Prometheus from a
+// service embedding the converter. PhaseDone is called from the goroutines generating
+// proto and Go output concurrently, so implementations must be safe for concurrent use.
+type Observer interface {
+	// PhaseDone is called when a phase of the pipeline completes. Failed and skipped
+	// phases, and every phase of a cached result, are not reported. With Deterministic
+	// each phase is reported twice.
+	PhaseDone(phase Phase, duration time.Duration)
+	// ConversionDone is called once when Convert returns. err is the returned error, in
+	// which case stats only carries Duration.
+	ConversionDone(stats ConversionStats, err error)
+}
+
+// observePhase reports the time since start as the duration of phase, if opts has an observer
+func observePhase(opts ConvertOptions, phase Phase, start time.Time) {
+	if opts.Observer != nil {
+		opts.Observer.PhaseDone(phase, time.Since(start))
+	}
+}
+
+// observeConversion reports the outcome of Convert, if opts has an observer
+func observeConversion(opts ConvertOptions, start time.Time, result *ConvertResult, cached bool, err error) {
+	if opts.Observer == nil {
+		return
+	}
+	stats := ConversionStats{Duration: time.Since(start)}
+	if err == nil {
+		stats.Cached = cached
+		stats.Schemas = len(result.TypeMap)
+		stats.Warnings = len(result.Warnings)
+		for _, info := range result.TypeMap {
+			if info.Location == TypeLocationGolang {
+				stats.GoTypes++
+			} else {
+				stats.ProtoTypes++
+			}
+		}
+	}
+	opts.Observer.ConversionDone(stats, err)
+}
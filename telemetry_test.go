@@ -0,0 +1,126 @@
+package conv_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver keeps everything reported to it
+type recordingObserver struct {
+	mu     sync.Mutex
+	phases []conv.Phase
+	stats  []conv.ConversionStats
+	errs   []error
+}
+
+func (o *recordingObserver) PhaseDone(phase conv.Phase, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.phases = append(o.phases, phase)
+}
+
+func (o *recordingObserver) ConversionDone(stats conv.ConversionStats, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stats = append(o.stats, stats)
+	o.errs = append(o.errs, err)
+}
+
+func TestObserver(t *testing.T) {
+	const spec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+    Dog:
+      type: object
+      properties:
+        petType:
+          type: string
+    Cat:
+      type: object
+      properties:
+        petType:
+          type: string
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+`
+
+	for _, test := range []struct {
+		name   string
+		spec   string
+		phases []conv.Phase
+		stats  conv.ConversionStats
+		err    string
+	}{
+		{
+			name:   "proto and go",
+			spec:   spec,
+			phases: []conv.Phase{conv.PhaseParse, conv.PhaseBuild, conv.PhaseGo, conv.PhaseProto},
+			stats:  conv.ConversionStats{Schemas: 4, ProtoTypes: 1, GoTypes: 3},
+		},
+		{
+			name: "invalid spec",
+			spec: "openapi: [",
+			err:  "failed to parse OpenAPI document",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			observer := &recordingObserver{}
+			_, err := conv.Convert([]byte(test.spec), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				Observer:    observer,
+			})
+			require.Len(t, observer.stats, 1)
+			assert.Positive(t, observer.stats[0].Duration)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				assert.Empty(t, observer.phases)
+				assert.Equal(t, err, observer.errs[0])
+				return
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.phases, observer.phases)
+			stats := observer.stats[0]
+			stats.Duration = 0
+			assert.Equal(t, test.stats, stats)
+			assert.NoError(t, observer.errs[0])
+		})
+	}
+}
+
+func TestObserverCached(t *testing.T) {
+	observer := &recordingObserver{}
+	opts := conv.ConvertOptions{
+		PackageName: "testpkg",
+		PackagePath: "github.com/example/proto/v1",
+		CacheDir:    t.TempDir(),
+		Observer:    observer,
+	}
+	_, err := conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+	_, err = conv.Convert([]byte(cacheSpec), opts)
+	require.NoError(t, err)
+
+	require.Len(t, observer.stats, 2)
+	assert.False(t, observer.stats[0].Cached)
+	assert.True(t, observer.stats[1].Cached)
+	assert.Equal(t, 2, observer.stats[1].Schemas)
+	assert.ElementsMatch(t, []conv.Phase{conv.PhaseParse, conv.PhaseBuild, conv.PhaseProto}, observer.phases)
+}
@@ -0,0 +1,62 @@
+package conv
+
+import (
+	"strings"
+
+	"github.com/duh-rpc/openapi-proto.go/internal"
+)
+
+// ProtoTypeFor returns the fully qualified name of the proto message or enum generated
+// for a component schema, e.g. "api.v1.User", so code generators layered on top do not
+// re-implement the naming rules. Schemas collapsed or moved by DedupErrors, TitleNames,
+// EmptyMessages, SchemaAliases or ConvertVersions resolve to the definition they now use. It returns
+// false if the schema has no proto definition, such as a string enum or a schema
+// generated as Go.
+func (r *ConvertResult) ProtoTypeFor(schema string) (string, bool) {
+	info, ok := r.TypeMap[schema]
+	if !ok || info.Location != TypeLocationProto {
+		return "", false
+	}
+	if info.AliasOf != "" {
+		if strings.Contains(info.AliasOf, ".") {
+			return info.AliasOf, true
+		}
+		return r.packageName + "." + info.AliasOf, true
+	}
+
+	enumName := internal.ToPascalCase(schema)
+	for _, def := range r.Definitions {
+		switch d := def.(type) {
+		case *ProtoMessage:
+			if d.OriginalSchema == schema {
+				return r.packageName + "." + d.Name, true
+			}
+		case *ProtoEnum:
+			if d.Name == enumName {
+				return r.packageName + "." + d.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// GoTypeFor returns the fully qualified Go identifier of the type generated for a
+// component schema: its import path and name joined by a dot, e.g.
+// "github.com/example/go/api.Payment". Schemas generated as Go use GoPackagePath; schemas
+// generated as proto use the type protoc-gen-go generates for their message or enum in
+// the go_package of the proto file. It returns false if the schema has no generated type.
+func (r *ConvertResult) GoTypeFor(schema string) (string, bool) {
+	info, ok := r.TypeMap[schema]
+	if !ok {
+		return "", false
+	}
+	if info.Location == TypeLocationGolang {
+		return r.goPackagePath + "." + schema, true
+	}
+
+	name, ok := r.ProtoTypeFor(schema)
+	if !ok || !strings.HasPrefix(name, r.packageName+".") {
+		return "", false
+	}
+	return r.packagePath + "." + strings.TrimPrefix(name, r.packageName+"."), true
+}
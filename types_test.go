@@ -0,0 +1,89 @@
+package conv_test
+
+import (
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertResultTypeFor(t *testing.T) {
+	given := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    user_profile:
+      type: object
+      properties:
+        status:
+          $ref: '#/components/schemas/Status'
+    Status:
+      type: integer
+      enum: [1, 2]
+    Payment:
+      oneOf:
+        - $ref: '#/components/schemas/Card'
+        - $ref: '#/components/schemas/Wire'
+      discriminator:
+        propertyName: kind
+    Card:
+      type: object
+      properties:
+        kind:
+          type: string
+    Wire:
+      type: object
+      properties:
+        kind:
+          type: string
+`
+
+	result, err := conv.Convert([]byte(given), conv.ConvertOptions{
+		PackageName:   "api.v1",
+		PackagePath:   "github.com/example/proto/v1",
+		GoPackagePath: "github.com/example/go/api",
+	})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name   string
+		schema string
+		proto  string
+		goType string
+	}{
+		{
+			name:   "message",
+			schema: "user_profile",
+			proto:  "api.v1.UserProfile",
+			goType: "github.com/example/proto/v1.UserProfile",
+		},
+		{
+			name:   "enum",
+			schema: "Status",
+			proto:  "api.v1.Status",
+			goType: "github.com/example/proto/v1.Status",
+		},
+		{
+			name:   "go type",
+			schema: "Payment",
+			goType: "github.com/example/go/api.Payment",
+		},
+		{
+			name:   "unknown",
+			schema: "Missing",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			proto, ok := result.ProtoTypeFor(test.schema)
+			assert.Equal(t, test.proto != "", ok)
+			assert.Equal(t, test.proto, proto)
+
+			goType, ok := result.GoTypeFor(test.schema)
+			assert.Equal(t, test.goType != "", ok)
+			assert.Equal(t, test.goType, goType)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package conv
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultUntrustedLimits are applied by ConvertUntrusted to any limit left at zero
+var DefaultUntrustedLimits = Limits{
+	MaxSpecBytes: 10 << 20,
+	MaxDepth:     32,
+	MaxMessages:  10000,
+}
+
+// ConvertUntrusted is Convert hardened for specs from untrusted sources, such as
+// user uploads. Limits left at zero in opts default to DefaultUntrustedLimits, a panic
+// while parsing or converting is returned as an error, and the call returns an error
+// wrapping ctx.Err() once ctx is done. File references stay inside opts.RootDir, or
+// opts.BaseDir, like in Convert. Remote references are refused, since a spec could
+// otherwise make the server fetch any number of documents from the allowed hosts.
+//
+// Parsing cannot be interrupted, so a conversion abandoned because ctx is done keeps
+// running in the background until it finishes or hits a limit; the limits bound how
+// long that can take.
+func ConvertUntrusted(ctx context.Context, openapi []byte, opts ConvertOptions) (*ConvertResult, error) {
+	if opts.Limits.MaxSpecBytes == 0 {
+		opts.Limits.MaxSpecBytes = DefaultUntrustedLimits.MaxSpecBytes
+	}
+	if opts.Limits.MaxDepth == 0 {
+		opts.Limits.MaxDepth = DefaultUntrustedLimits.MaxDepth
+	}
+	if opts.Limits.MaxMessages == 0 {
+		opts.Limits.MaxMessages = DefaultUntrustedLimits.MaxMessages
+	}
+
+	if len(opts.RemoteRefs.AllowedHosts) > 0 {
+		err := &OptionsError{Problems: []string{"remote references cannot be enabled for untrusted specs"}}
+		return nil, formatError(withErrorCode(err), opts)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, formatError(&Error{Code: ErrorCodeCanceled, Err: err}, opts)
+	}
+
+	type outcome struct {
+		result *ConvertResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err := &Error{Code: ErrorCodePanic, Err: fmt.Errorf("conversion panicked: %v", r)}
+				done <- outcome{err: formatError(err, opts)}
+			}
+		}()
+
+		result, err := Convert(openapi, opts)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, formatError(&Error{Code: ErrorCodeCanceled, Err: ctx.Err()}, opts)
+	}
+}
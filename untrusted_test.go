@@ -0,0 +1,102 @@
+package conv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const untrustedSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestConvertUntrusted(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, test := range []struct {
+		name    string
+		ctx     context.Context
+		given   string
+		limits  conv.Limits
+		remote  conv.RemoteRefOptions
+		wantErr string
+	}{
+		{
+			name:  "valid spec",
+			ctx:   context.Background(),
+			given: untrustedSpec,
+		},
+		{
+			name:    "default spec size limit",
+			ctx:     context.Background(),
+			given:   untrustedSpec + strings.Repeat("#", conv.DefaultUntrustedLimits.MaxSpecBytes),
+			wantErr: "exceeds limit of 10485760",
+		},
+		{
+			name:    "explicit limit is kept",
+			ctx:     context.Background(),
+			given:   untrustedSpec,
+			limits:  conv.Limits{MaxSpecBytes: 10},
+			wantErr: "exceeds limit of 10",
+		},
+		{
+			name:    "cancelled context",
+			ctx:     cancelled,
+			given:   untrustedSpec,
+			wantErr: "context canceled",
+		},
+		{
+			name:    "remote references refused",
+			ctx:     context.Background(),
+			given:   untrustedSpec,
+			remote:  conv.RemoteRefOptions{AllowedHosts: []string{"schemas.example.com"}},
+			wantErr: "remote references cannot be enabled for untrusted specs",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := conv.ConvertUntrusted(test.ctx, []byte(test.given), conv.ConvertOptions{
+				PackageName: "testpkg",
+				PackagePath: "github.com/example/proto/v1",
+				Limits:      test.limits,
+				RemoteRefs:  test.remote,
+			})
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(result.Protobuf), "message User {")
+		})
+	}
+}
+
+func FuzzConvertUntrusted(f *testing.F) {
+	f.Add([]byte(untrustedSpec))
+	f.Add([]byte("openapi: 3.0.0\ncomponents:\n  schemas:\n    A:\n      type: object\n      properties:\n        b:\n          $ref: '#/components/schemas/A'\n"))
+	f.Add([]byte("openapi: 3.1.0\ncomponents:\n  schemas:\n    E:\n      type: integer\n      enum: [1, 2]\n"))
+	f.Add([]byte(`{"openapi": "3.0.0", "components": {"schemas": {"X": {"oneOf": [{"$ref": "#/components/schemas/X"}]}}}}`))
+
+	f.Fuzz(func(t *testing.T, spec []byte) {
+		_, err := conv.ConvertUntrusted(context.Background(), spec, conv.ConvertOptions{
+			PackageName: "testpkg",
+			PackagePath: "github.com/example/proto/v1",
+		})
+		if err != nil {
+			require.NotContains(t, err.Error(), "conversion panicked")
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package conv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// APIVersion is one version of an API converted by ConvertVersions
+type APIVersion struct {
+	// Spec is the OpenAPI document of this version
+	Spec []byte
+	// Options configures the proto package of this version, e.g. PackageName "api.v1".
+	// Set Lock to freeze the field numbers of a released version.
+	Options ConvertOptions
+}
+
+// CommonPackage names the proto package ConvertVersions moves shared definitions into
+type CommonPackage struct {
+	// PackageName is the name of the shared proto package, e.g. "api.common"
+	PackageName string
+	// PackagePath is the go_package of the shared proto package
+	PackagePath string
+}
+
+// VersionsResult holds the packages produced by ConvertVersions
+type VersionsResult struct {
+	// Common declares the definitions shared by every version, or is nil if none are
+	Common *ConvertResult
+	// Versions holds the package of each version, in the order given, without the shared
+	// definitions. Fields using a shared definition reference it by its qualified name,
+	// e.g. api.common.Address, and the package imports the common proto file.
+	Versions []*ConvertResult
+}
+
+// ConvertVersions converts the specs of parallel API versions into one proto package each,
+// moving the top-level messages and enums that come out identical in every version, along
+// with the field numbers, reservations and definitions they use, into a common package.
+// Definitions that differ in any version, or that use one that does, stay in each version.
+// The common package is built with the options of the first version. Moved messages record
+// their qualified name as TypeInfo.AliasOf in the TypeMap of each version.
+func ConvertVersions(versions []APIVersion, common CommonPackage) (*VersionsResult, error) {
+	if len(versions) < 2 {
+		return nil, &Error{Code: ErrorCodeInvalidInput, Err: fmt.Errorf("at least two versions are required, got %d", len(versions))}
+	}
+	if err := validateVersions(versions, common); err != nil {
+		return nil, err
+	}
+
+	results := make([]*ConvertResult, 0, len(versions))
+	for _, version := range versions {
+		result, err := Convert(version.Spec, version.Options)
+		if err != nil {
+			return nil, fmt.Errorf("version '%s': %w", version.Options.PackageName, err)
+		}
+		results = append(results, result)
+	}
+
+	shared := sharedDefinitions(results)
+	if len(shared) == 0 {
+		return &VersionsResult{Versions: results}, nil
+	}
+
+	out := &VersionsResult{}
+	commonOpts := versions[0].Options
+	commonOpts.PackageName = common.PackageName
+	commonOpts.PackagePath = common.PackagePath
+	commonOpts.GoPackagePath = ""
+	commonOpts.CacheDir = ""
+	unshared := make(map[string]bool)
+	for _, def := range results[0].Definitions {
+		if name := definitionName(def); !shared[name] {
+			unshared[name] = true
+		}
+	}
+	commonResult, err := convertSplit(versions[0].Spec, commonOpts, &definitionSplit{names: unshared}, nil)
+	if err != nil {
+		return nil, formatError(withErrorCode(err), commonOpts)
+	}
+	// Go types are generated for each version, never shared
+	commonResult.Golang = nil
+	for name, info := range commonResult.TypeMap {
+		if info.Location == TypeLocationGolang {
+			delete(commonResult.TypeMap, name)
+		}
+	}
+	out.Common = commonResult
+
+	for _, version := range versions {
+		opts := version.Options
+		opts.CacheDir = ""
+		result, err := convertSplit(version.Spec, opts, &definitionSplit{
+			names:      shared,
+			qualifier:  common.PackageName,
+			importPath: common.PackageName + ".proto",
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("version '%s': %w", opts.PackageName, formatError(withErrorCode(err), opts))
+		}
+		out.Versions = append(out.Versions, result)
+	}
+	return out, nil
+}
+
+// validateVersions checks the common package and that every package name is distinct
+func validateVersions(versions []APIVersion, common CommonPackage) error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case common.PackageName == "":
+		add("common package name cannot be empty")
+	case !protoPackage.MatchString(common.PackageName):
+		add("common package name '%s' is not a valid proto package", common.PackageName)
+	}
+	if common.PackagePath == "" {
+		add("common package path cannot be empty")
+	}
+
+	seen := map[string]bool{common.PackageName: true}
+	for _, version := range versions {
+		if seen[version.Options.PackageName] {
+			add("package name '%s' is used by more than one package", version.Options.PackageName)
+		}
+		seen[version.Options.PackageName] = true
+	}
+
+	if len(problems) > 0 {
+		return &OptionsError{Problems: problems}
+	}
+	return nil
+}
+
+// sharedDefinitions returns the names of the top-level definitions that are identical in
+// every result and only use definitions that are shared too
+func sharedDefinitions(results []*ConvertResult) map[string]bool {
+	shared := make(map[string]bool)
+	for _, def := range results[0].Definitions {
+		shared[definitionName(def)] = true
+	}
+	for _, result := range results[1:] {
+		defs := make(map[string]ProtoDefinition, len(result.Definitions))
+		for _, def := range result.Definitions {
+			defs[definitionName(def)] = def
+		}
+		for _, def := range results[0].Definitions {
+			name := definitionName(def)
+			if other, ok := defs[name]; !ok || !reflect.DeepEqual(def, other) {
+				delete(shared, name)
+			}
+		}
+	}
+
+	// Drop definitions using one that stays in each version, until none are left
+	defined := make(map[string]bool, len(results[0].Definitions))
+	for _, def := range results[0].Definitions {
+		defined[definitionName(def)] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, def := range results[0].Definitions {
+			msg, ok := def.(*ProtoMessage)
+			if !ok || !shared[msg.Name] {
+				continue
+			}
+			for _, typ := range messageTypes(msg) {
+				if defined[typ] && !shared[typ] {
+					delete(shared, msg.Name)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return shared
+}
+
+// messageTypes returns the field types of msg and its nested messages
+func messageTypes(msg *ProtoMessage) []string {
+	var types []string
+	for _, field := range msg.Fields {
+		types = append(types, field.Type)
+	}
+	for _, nested := range msg.Nested {
+		types = append(types, messageTypes(nested)...)
+	}
+	return types
+}
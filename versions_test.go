@@ -0,0 +1,185 @@
+package conv_test
+
+import (
+	"errors"
+	"testing"
+
+	conv "github.com/duh-rpc/openapi-proto.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertVersions(t *testing.T) {
+	v1 := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Address:
+      type: object
+      properties:
+        street:
+          type: string
+    Status:
+      type: integer
+      enum: [1, 2]
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        address:
+          $ref: '#/components/schemas/Address'
+    Order:
+      type: object
+      properties:
+        user:
+          $ref: '#/components/schemas/User'
+        status:
+          $ref: '#/components/schemas/Status'
+`
+	v2 := `openapi: 3.0.0
+info:
+  title: Test
+  version: 2.0.0
+components:
+  schemas:
+    Address:
+      type: object
+      properties:
+        street:
+          type: string
+    Status:
+      type: integer
+      enum: [1, 2]
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        email:
+          type: string
+        address:
+          $ref: '#/components/schemas/Address'
+    Order:
+      type: object
+      properties:
+        user:
+          $ref: '#/components/schemas/User'
+        status:
+          $ref: '#/components/schemas/Status'
+`
+
+	result, err := conv.ConvertVersions([]conv.APIVersion{
+		{Spec: []byte(v1), Options: conv.ConvertOptions{PackageName: "api.v1", PackagePath: "github.com/example/api/v1"}},
+		{Spec: []byte(v2), Options: conv.ConvertOptions{PackageName: "api.v2", PackagePath: "github.com/example/api/v2"}},
+	}, conv.CommonPackage{PackageName: "api.common", PackagePath: "github.com/example/api/common"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Common)
+	require.Len(t, result.Versions, 2)
+
+	assert.Equal(t, `syntax = "proto3";
+
+package api.common;
+
+option go_package = "github.com/example/api/common";
+
+message Address {
+  string street = 1 [json_name = "street"];
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_1 = 1;
+  STATUS_2 = 2;
+}
+
+`, string(result.Common.Protobuf))
+	assert.ElementsMatch(t, []string{"Address", "Status"}, typeMapNames(result.Common.TypeMap))
+
+	assert.Equal(t, `syntax = "proto3";
+
+package api.v2;
+
+import "api.common.proto";
+
+option go_package = "github.com/example/api/v2";
+
+message User {
+  string name = 1 [json_name = "name"];
+  string email = 2 [json_name = "email"];
+  api.common.Address address = 3 [json_name = "address"];
+}
+
+message Order {
+  User user = 1 [json_name = "user"];
+  api.common.Status status = 2 [json_name = "status"];
+}
+
+`, string(result.Versions[1].Protobuf))
+	assert.Contains(t, string(result.Versions[0].Protobuf), "package api.v1;")
+	assert.Contains(t, string(result.Versions[0].Protobuf), "message User {\n  string name = 1 [json_name = \"name\"];\n  api.common.Address address = 2")
+	assert.Equal(t, "api.common.Address", result.Versions[0].TypeMap["Address"].AliasOf)
+}
+
+func typeMapNames(typeMap map[string]*conv.TypeInfo) []string {
+	var names []string
+	for name := range typeMap {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestConvertVersionsNothingShared(t *testing.T) {
+	spec := func(field string) string {
+		return `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        ` + field + `:
+          type: string
+`
+	}
+
+	result, err := conv.ConvertVersions([]conv.APIVersion{
+		{Spec: []byte(spec("name")), Options: conv.ConvertOptions{PackageName: "api.v1", PackagePath: "github.com/example/api/v1"}},
+		{Spec: []byte(spec("fullName")), Options: conv.ConvertOptions{PackageName: "api.v2", PackagePath: "github.com/example/api/v2"}},
+	}, conv.CommonPackage{PackageName: "api.common", PackagePath: "github.com/example/api/common"})
+	require.NoError(t, err)
+	assert.Nil(t, result.Common)
+	assert.Contains(t, string(result.Versions[1].Protobuf), "string fullName = 1")
+}
+
+func TestConvertVersionsInvalid(t *testing.T) {
+	spec := []byte(`openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas: {}
+`)
+
+	_, err := conv.ConvertVersions([]conv.APIVersion{
+		{Spec: spec, Options: conv.ConvertOptions{PackageName: "api.v1", PackagePath: "github.com/example/api/v1"}},
+	}, conv.CommonPackage{PackageName: "api.common", PackagePath: "github.com/example/api/common"})
+	require.ErrorContains(t, err, "at least two versions are required, got 1")
+
+	_, err = conv.ConvertVersions([]conv.APIVersion{
+		{Spec: spec, Options: conv.ConvertOptions{PackageName: "api.v1", PackagePath: "github.com/example/api/v1"}},
+		{Spec: spec, Options: conv.ConvertOptions{PackageName: "api.v1", PackagePath: "github.com/example/api/v2"}},
+	}, conv.CommonPackage{PackageName: "api-common"})
+
+	var optsErr *conv.OptionsError
+	require.True(t, errors.As(err, &optsErr))
+	assert.Equal(t, []string{
+		"common package name 'api-common' is not a valid proto package",
+		"common package path cannot be empty",
+		"package name 'api.v1' is used by more than one package",
+	}, optsErr.Problems)
+}